@@ -18,10 +18,12 @@ import (
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/message_queue/nats"
+	"k8s-installer/pkg/metrics"
 	"k8s-installer/pkg/network"
 	"k8s-installer/pkg/node_identity"
 	"k8s-installer/pkg/server/version"
 	"k8s-installer/pkg/task_breaker"
+	"k8s-installer/pkg/tracing"
 	"k8s-installer/schema"
 
 	natServer "github.com/nats-io/nats-server/v2/server"
@@ -126,6 +128,8 @@ func startClient(cmd *cobra.Command, args []string) {
 
 	ctx, cancellation = context.WithCancel(context.Background())
 	go network.OpenPortAndListen(net.IPv4(0, 0, 0, 0), currentConfig.SignalPort, "", ctx.Done())
+	go metrics.Serve(currentConfig.MetricsPort, ctx.Done())
+	tracing.Init(currentConfig.Tracing, "k8s-installer-client", currentConfig.ClientId, ctx.Done())
 	format := "%s.%s"
 	addr := network.GetDefaultIP(true)
 	subject := fmt.Sprintf(format, addr.To4().String(), currentConfig.MessageQueue.SubjectSuffix)
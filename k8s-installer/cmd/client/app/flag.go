@@ -27,4 +27,5 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&currentConfig.Etcd.KeyFile, "etcd-key-file-path", "", currentConfig.Etcd.KeyFile, "etcd key file path only needed when auth mode set to tls")
 	flags.StringVarP(&currentConfig.Cache.CacheRuntime, "cache-runtime", "", currentConfig.Cache.CacheRuntime, "cache runtime setting either go-cache or local-ram or group-cache or no-cache default local-ram")
 	flags.IntVarP(&currentConfig.StatReportInFrequency, "stat-report-in-frequency", "", currentConfig.StatReportInFrequency, "node stat report in frequency")
+	flags.IntVarP(&currentConfig.MetricsPort, "metrics-port", "", currentConfig.MetricsPort, "port prometheus metrics are exposed at, 0 to disable")
 }
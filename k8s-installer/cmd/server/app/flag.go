@@ -20,7 +20,7 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&currentConfig.MessageQueue.ServerNodeStatusReportInSubject, "queue-node-report-subject", "", currentConfig.MessageQueue.ServerNodeStatusReportInSubject, "subject channel for node status report message queue channel")
 	flags.Uint32VarP(&currentConfig.Log.LogLevel, "log-level", "l", currentConfig.Log.LogLevel, "log level max 6")
 	flags.Uint32VarP(&currentConfig.ApiServer.ApiPort, "api-server-port", "z", currentConfig.ApiServer.ApiPort, "Api server port number")
-	flags.StringVarP(&currentConfig.Cache.CacheRuntime, "cache-runtime", "", currentConfig.Cache.CacheRuntime, "cache runtime setting either go-cache or local-ram or group-cache or no-cache default local-ram")
+	flags.StringVarP(&currentConfig.Cache.CacheRuntime, "cache-runtime", "", currentConfig.Cache.CacheRuntime, "cache runtime setting either go-cache or local-ram or group-cache or no-cache or embedded default local-ram")
 	flags.StringVarP(&currentConfig.Etcd.AuthMode, "etcd-auth-mode", "", currentConfig.Etcd.AuthMode, "etcd auth mode either basic or tls")
 	flags.StringVarP(&currentConfig.Etcd.EndPoints, "etcd-endpoints", "", currentConfig.Etcd.EndPoints, "etcd endpoints such as http(s)://10.0.0.100:2379 | http(s)://10.0.0.101:2379 | http(s)://10.0.0.102:2379")
 	flags.StringVarP(&currentConfig.Etcd.Username, "etcd-username", "", currentConfig.Etcd.Username, "etcd username only needed when auth mode set to basic")
@@ -29,4 +29,5 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&currentConfig.Etcd.CertFile, "etcd-cert-file-path", "", currentConfig.Etcd.CertFile, "etcd certification file path only needed when auth mode set to tls")
 	flags.StringVarP(&currentConfig.Etcd.KeyFile, "etcd-key-file-path", "", currentConfig.Etcd.KeyFile, "etcd key file path only needed when auth mode set to tls")
 	flags.IntVarP(&currentConfig.SignalPort, "client-signal-port", "", currentConfig.SignalPort, "set client signal port in order to help server can test it when needed")
+	flags.IntVarP(&currentConfig.MetricsPort, "metrics-port", "", currentConfig.MetricsPort, "port prometheus metrics are exposed at, 0 to disable")
 }
@@ -14,19 +14,27 @@ import (
 
 	bd "k8s-installer/pkg/block_device"
 	"k8s-installer/pkg/server/client_liveness"
+	"k8s-installer/pkg/server/embedded_store"
+	"k8s-installer/pkg/server/high_availability"
 
 	"k8s-installer/pkg/task_breaker"
 
 	apiServer "k8s-installer/internal/apiserver"
+	authProvider "k8s-installer/pkg/auth_provider"
 	runtimeCache "k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
 	"k8s-installer/pkg/config/server"
 	cfg "k8s-installer/pkg/config/viper"
 	"k8s-installer/pkg/control_manager"
 	jwtPkg "k8s-installer/pkg/jwt"
 	"k8s-installer/pkg/log"
 	messageQueue "k8s-installer/pkg/message_queue/nats"
+	"k8s-installer/pkg/metrics"
 	"k8s-installer/pkg/network"
 	"k8s-installer/pkg/node_identity"
+	"k8s-installer/pkg/schema_migration"
+	"k8s-installer/pkg/tracing"
 
 	"github.com/google/uuid"
 	natServer "github.com/nats-io/nats-server/v2/server"
@@ -81,19 +89,44 @@ func NewServerCommand() *cobra.Command {
 
 	cmd.AddCommand(genDepsCmd)
 
+	// add a sub command to move data between external etcd and embedded store
+	var overwrite bool
+	migrateStoreCmd := &cobra.Command{
+		Use:   "migrate-store to-embedded|to-etcd",
+		Short: "migrate data between external etcd and embedded store",
+		Long:  "copy every key from external etcd to embedded store or the other way round \n stop servers using either store first",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			migrateStore(args[0], overwrite)
+		},
+	}
+	migrateStoreCmd.Flags().BoolVar(&overwrite, "overwrite", false, "copy over keys already in target store instead of refusing to")
+
+	cmd.AddCommand(migrateStoreCmd)
+
+	// add a sub command to see what schema migration does to data before server does it on start
+	var dryRun bool
+	migrateSchemaCmd := &cobra.Command{
+		Use:   "migrate-schema",
+		Short: "migrate stored data to schema of current binary",
+		Long:  "upgrade data stored by older versions which server also does on start \n with --dry-run only report what would be changed",
+		Args:  cobra.MaximumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			loadConfigFile()
+			for _, report := range migrateSchema(storeEtcdConfig(), dryRun) {
+				fmt.Println(report.String())
+			}
+		},
+	}
+	migrateSchemaCmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be changed without saving anything")
+
+	cmd.AddCommand(migrateSchemaCmd)
+
 	return cmd
 }
 
 func LoadConfigFileIfFound() {
-	currentConfig = server.DefaultConfig()
-	server.DefaultConfigLookup()
-	log.DefaultLoginSetting(network.GetDefaultIP, currentConfig.Log.LogRetentionPeriod)
-	if err := cfg.ParseViperConfig(&currentConfig, cfg.DecodeOptionYaml()); err != nil {
-		log.Debugf("No configuration file config-server.(yml|yaml)\"  \" found in any of location %s", "$HOME/.k8s-installer /etc/k8s-installer")
-		log.Debug("Using default setting")
-	} else {
-		log.Debug("Load config from file done!")
-	}
+	loadConfigFile()
 
 	if !currentConfig.IsTestNode {
 		checkProductionEnvironment()
@@ -101,13 +134,16 @@ func LoadConfigFileIfFound() {
 
 	generatorNodeIdIfNotSet(&currentConfig)
 	// get runtime cache
-	cache := runtimeCache.InitCacheRuntime(currentConfig.Cache, currentConfig.Etcd, task_breaker.SetOperationStep)
+	etcdConfig := storeEtcdConfig()
+	cache := runtimeCache.InitCacheRuntime(currentConfig.Cache, etcdConfig, task_breaker.SetOperationStep)
 
 	// check server config stop if error
 	serverConfigCheck()
 	if err := cache.SaveOrUpdateServerRuntimeConfig(currentConfig.ServerId, currentConfig); err != nil {
 		log.Fatalf("Failed to save node config with id %s to cache type %s ... aborting", currentConfig.ServerId, reflect.TypeOf(cache).String())
 	}
+	// bring data saved by older versions up to date before anything reads it
+	migrateSchema(etcdConfig, false)
 	// after config set load cache data from database
 	if err := cache.SyncFromDatabase(); err != nil {
 		log.Fatalf("Cache data initialization failed due to error %s ... aborting", err)
@@ -117,13 +153,79 @@ func LoadConfigFileIfFound() {
 	log.SetLogLevel(currentConfig.Log.LogLevel)
 }
 
+func loadConfigFile() {
+	currentConfig = server.DefaultConfig()
+	server.DefaultConfigLookup()
+	log.DefaultLoginSetting(network.GetDefaultIP, currentConfig.Log.LogRetentionPeriod)
+	if err := cfg.ParseViperConfig(&currentConfig, cfg.DecodeOptionYaml()); err != nil {
+		log.Debugf("No configuration file config-server.(yml|yaml)\"  \" found in any of location %s", "$HOME/.k8s-installer /etc/k8s-installer")
+		log.Debug("Using default setting")
+	} else {
+		log.Debug("Load config from file done!")
+	}
+}
+
+/*
+storeEtcdConfig is how to connect to where data is kept
+with cache runtime embedded it is the embedded store which is started here, external etcd is not touched at all
+*/
+func storeEtcdConfig() etcdClientConfig.EtcdConfig {
+	if currentConfig.Cache.CacheRuntime != cacheConfig.RuntimeEmbedded {
+		return currentConfig.Etcd
+	}
+	store, err := embedded_store.Start(currentConfig.Cache.Embedded)
+	if err != nil {
+		log.Fatalf("Failed to start embedded store due to error %s ... aborting", err)
+	}
+	return store.EtcdConfig()
+}
+
+func migrateStore(direction string, overwrite bool) {
+	loadConfigFile()
+	if direction != "to-embedded" && direction != "to-etcd" {
+		log.Fatalf("Migration direction %s is not valid only to-embedded or to-etcd is support", direction)
+	}
+	store, err := embedded_store.Start(currentConfig.Cache.Embedded)
+	if err != nil {
+		log.Fatalf("Failed to start embedded store due to error %s ... aborting", err)
+	}
+	from, to := currentConfig.Etcd, store.EtcdConfig()
+	if direction == "to-etcd" {
+		from, to = to, from
+	}
+	copied, err := embedded_store.Migrate(from, to, overwrite)
+	store.Close()
+	if err != nil {
+		log.Fatalf("Failed to migrate data %s after %d keys are copied due to error %s", direction, copied, err)
+	}
+	log.Infof("Migrated %d keys %s", copied, direction)
+}
+
+/*
+migrateSchema stops server when data is not able to be migrated, e.g. data is saved by a newer version
+*/
+func migrateSchema(etcdConfig etcdClientConfig.EtcdConfig, dryRun bool) []schema_migration.Report {
+	reports, err := schema_migration.Run(etcdConfig, dryRun)
+	for _, report := range reports {
+		log.Infof("Schema migration dry run %v: %s", dryRun, report.String())
+	}
+	if err != nil {
+		log.Fatalf("Failed to migrate data to current schema due to error %s ... aborting", err)
+	}
+	return reports
+}
+
 func startServer(cmd *cobra.Command, args []string) {
 	ctx, cancellation = context.WithCancel(context.Background())
+	// export spans of operations to collector, it goes first so no operation is missed
+	tracing.Init(currentConfig.Tracing, "k8s-installer-server", currentConfig.ServerId, ctx.Done())
 	// start message queue server
 	go messageQueue.StartMessageQueue(generatorMessageQueueConfig(), ctx.Done())
 
-	// node agent liveness detector
-	if currentConfig.EnableAgentLivenessDetector {
+	if currentConfig.HighAvailability.Enabled {
+		startHighAvailability()
+	} else if currentConfig.EnableAgentLivenessDetector {
+		// node agent liveness detector
 		go client_liveness.ClientAgentDaemon(ctx.Done())
 	}
 
@@ -132,6 +234,12 @@ func startServer(cmd *cobra.Command, args []string) {
 	// run api server with go-restful
 	go apiServer.Start(currentConfig.ApiServer, currentConfig.ApiServer.ApiPort, apiServer.CreateGoRestful(), ctx.Done(), nil)
 
+	// expose prometheus metrics, report in age only makes sense on server
+	if err := metrics.Register(metrics.NewReportInCollector(lastReportIn)); err != nil {
+		log.Errorf("Failed to register agent report in metrics due to error %s", err.Error())
+	}
+	go metrics.Serve(currentConfig.MetricsPort, ctx.Done())
+
 	// start node report in queue
 	time.Sleep(1 * time.Second)
 	if err := messageQueue.ListenQueueGroup(currentConfig.MessageQueue.ServerNodeStatusReportInSubject,
@@ -141,11 +249,52 @@ func startServer(cmd *cobra.Command, args []string) {
 		control_manager.NodeStateReportInHandler); err != nil {
 		log.Fatalf("Failed to setup mq connection due to error %v", err)
 	}
+	// answer requests about operations of this server which other servers get
+	if err := control_manager.ListenOperationRelay(); err != nil {
+		log.Fatalf("Failed to listen on operation requests of other servers due to error %v", err)
+	}
 
 	// wait main goroutine forever
 	select {}
 }
 
+/*
+startHighAvailability joins other servers and returns once this server is registered
+only then api is served, an operation created before that would look left behind to leader
+*/
+func startHighAvailability() {
+	// loops must not run on more than one server at a time
+	var leaderLoops []func(stopChan <-chan struct{})
+	if currentConfig.EnableAgentLivenessDetector {
+		leaderLoops = append(leaderLoops, client_liveness.ClientAgentDaemon)
+	}
+	leaderLoops = append(leaderLoops, control_manager.OperationTakeoverDaemon)
+
+	joined := make(chan struct{})
+	go high_availability.Run(currentConfig.HighAvailability, currentConfig.Etcd, currentConfig.ServerId, func() {
+		// operations this server ran before restart, nobody else takes them over since it registers with the same id
+		control_manager.TakeOverOperations(func(host string) bool {
+			return host == currentConfig.ServerId
+		})
+		close(joined)
+	}, leaderLoops, ctx.Done())
+	<-joined
+}
+
+func lastReportIn() (map[string]time.Time, error) {
+	nodes, err := runtimeCache.GetCurrentCache().GetNodeInformationCollection()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]time.Time{}
+	for id, node := range nodes {
+		if reportIn, errParse := time.Parse("2006-01-02T15:04:05Z07:00", node.LastReportInDate); errParse == nil {
+			result[id] = reportIn
+		}
+	}
+	return result, nil
+}
+
 func generatorMessageQueueConfig() *natServer.Options {
 	return &natServer.Options{
 		Port:     currentConfig.MessageQueue.Port,
@@ -210,6 +359,14 @@ func serverConfigCheck() {
 		log.Warnf("No jwt sign string is set use default sign string azhzLWluc3RhbGxlcgo=")
 		currentConfig.ApiServer.JWTSignString = "azhzLWluc3RhbGxlcgo="
 	}
+
+	if currentConfig.HighAvailability.Enabled && currentConfig.Cache.CacheRuntime != cacheConfig.RuntimeNoCache {
+		log.Fatalf("High availability only works with cache runtime no-cache, %s keeps data of one server in ram abort...", currentConfig.Cache.CacheRuntime)
+	}
+
+	if errList := authProvider.CheckProviders(currentConfig.ApiServer.AuthProviders); len(errList) > 0 {
+		log.Fatalf("Auth provider config error: %s abort...", strings.Join(errList, " , "))
+	}
 }
 
 func checkProductionEnvironment() {
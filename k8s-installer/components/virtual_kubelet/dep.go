@@ -17,5 +17,34 @@ var VKMockDep dep.DepMap = schema.RegisterDep(dep.DepMap{
 			},
 		},
 	},
-	"ubuntu": {},
+	"ubuntu": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+			"aarch64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+		},
+	},
+	"el": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+			"aarch64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+		},
+	},
+	"openEuler": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+			"aarch64": {
+				"virtual-kubelet": "virtual-kubelet",
+			},
+		},
+	},
 })
@@ -9,11 +9,13 @@ require (
 	github.com/containerd/fifo v1.0.0 // indirect
 	github.com/containerd/go-runc v1.0.0 // indirect
 	github.com/containerd/ttrpc v1.0.2 // indirect
+	github.com/coreos/go-oidc/v3 v3.1.0
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/docker/go-events v0.0.0-20190806004212-e31b211e4f1c // indirect
 	github.com/emicklei/go-restful v2.13.0+incompatible
 	github.com/emicklei/go-restful-openapi v1.4.1
 	github.com/fastly/go-utils v0.0.0-20180712184237-d95a45783239 // indirect
+	github.com/go-ldap/ldap/v3 v3.4.4
 	github.com/go-openapi/spec v0.19.3
 	github.com/go-playground/validator/v10 v10.3.0
 	github.com/gogo/googleapis v1.4.1 // indirect
@@ -26,6 +28,7 @@ require (
 	github.com/mitchellh/mapstructure v1.3.0
 	github.com/nats-io/nats-server/v2 v2.4.0
 	github.com/nats-io/nats.go v1.12.0
+	github.com/prometheus/client_golang v1.0.0
 	github.com/shirou/gopsutil v0.0.0-20180427012116-c95755e4bcd7
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cast v1.3.1
@@ -37,7 +40,11 @@ require (
 	github.com/txn2/txeh v1.3.0
 	github.com/zcalusic/sysinfo v0.0.0-20210905121133-6fa2f969a900
 	go.etcd.io/etcd v0.0.0-20201125193152-8a03d2e9614b
-	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
+	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127
 	gopkg.in/yaml.v2 v2.2.8
 	gotest.tools/v3 v3.0.3 // indirect
@@ -4,15 +4,19 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	apiUtil "k8s-installer/internal/apiserver/utils"
+	apiToken "k8s-installer/pkg/api_token"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/constants"
 	jwtPkg "k8s-installer/pkg/jwt"
+	"k8s-installer/pkg/rbac"
 	"k8s-installer/pkg/util/fileutils"
 	"k8s-installer/schema"
 
@@ -41,8 +45,10 @@ func CreateGoRestful() *restful.Container {
 		CookiesAllowed: false,
 		Container:      wsContainer}
 	wsContainer.Filter(cors.Filter)
+	wsContainer.Filter(observeRequest)
+	wsContainer.Filter(auditRequest)
 	wsContainer.Filter(LicenseValid)
-	wsContainer.Filter(excludePathAuth([]string{"/api/user/v1/login", "/api/core/v1/login", "/api/authtype", "/api/core/v1/domain/sub-domain", "/api/core/v1/kubectl-exec", "api/core/v1/ssh", "/api/core/v1/licenses", "/api/license/v1/"}))
+	wsContainer.Filter(excludePathAuth([]string{"/api/user/v1/login", "/api/core/v1/login", "/api/core/v1/login/refresh", "/api/core/v1/oidc/*", "/api/core/v1/mfa/*", "/api/authtype", "/api/core/v1/domain/sub-domain", "/api/core/v1/kubectl-exec", "api/core/v1/ssh", "/api/core/v1/licenses", "/api/license/v1/"}))
 	InstallAPIs(wsContainer)
 	setRouteMapping()
 	return wsContainer
@@ -201,20 +207,40 @@ func standardAuth(req *restful.Request, resp *restful.Response, fc *restful.Filt
 		runtimeCache := cache.GetCurrentCache()
 		config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
 
-		// going to do token validation
-		signString, err := base64.StdEncoding.DecodeString(config.ApiServer.JWTSignString)
-		if err != nil {
-			apiUtil.ResponseError(resp, http.StatusInternalServerError, "Failed to decode jwt sign string")
-			return
-		}
 		user := &schema.User{}
-		if err := jwtPkg.ValidateJWT(headerToken, user, string(signString)); err != nil {
-			// token validation failed
-			apiUtil.ResponseError(resp, http.StatusUnauthorized, err.Error())
-			return
+		// roles api token is limited to, nil when authenticated by jwt
+		var tokenRoles []schema.Role
+		if apiToken.IsAPIToken(headerToken) {
+			token, status, err := authenticateAPIToken(headerToken, req)
+			if err != nil {
+				apiUtil.ResponseError(resp, status, err.Error())
+				return
+			}
+			user.Username = token.Owner
+			tokenRoles = append([]schema.Role{}, token.Roles...)
+			req.SetAttribute("api-token-id", token.Id)
+		} else {
+			// going to do token validation
+			signString, err := base64.StdEncoding.DecodeString(config.ApiServer.JWTSignString)
+			if err != nil {
+				apiUtil.ResponseError(resp, http.StatusInternalServerError, "Failed to decode jwt sign string")
+				return
+			}
+			if err := jwtPkg.ValidateJWT(headerToken, user, string(signString)); err != nil {
+				// token validation failed
+				apiUtil.ResponseError(resp, http.StatusUnauthorized, err.Error())
+				return
+			}
+			// signature alone cannot tell logged out token, session of the token is looked up on every request
+			if status, err := checkSession(user.StandardClaims.Id); err != nil {
+				apiUtil.ResponseError(resp, status, err.Error())
+				return
+			}
+			req.SetAttribute("session-id", user.StandardClaims.Id)
 		}
+		issuedAt := user.IssuedAt
 		// reload user information, always use server side data for security
-		user, err = runtimeCache.GetUser(user.Username)
+		user, err := runtimeCache.GetUser(user.Username)
 		if err != nil {
 			apiUtil.ResponseError(resp, http.StatusInternalServerError, err.Error())
 			return
@@ -224,6 +250,10 @@ func standardAuth(req *restful.Request, resp *restful.Response, fc *restful.Filt
 			apiUtil.ResponseError(resp, http.StatusForbidden, errMsg)
 			return
 		}
+		if tokenRoles == nil && issuedAt < user.TokensValidAfter {
+			apiUtil.ResponseError(resp, http.StatusUnauthorized, "User is changed since token is issued, please login again")
+			return
+		}
 
 		rolesMapping, errRole := runtimeCache.GetRoleList()
 		if errRole != nil {
@@ -240,19 +270,30 @@ func standardAuth(req *restful.Request, resp *restful.Response, fc *restful.Filt
 		//log.Debugf("request select route path is %s", req.SelectedRoutePath())
 		// try to find related route information
 
-		userPermission := uint64(0)
 		// combine user role`s permission together
-		for _, role := range user.Roles {
-			if roleFound, isFound := rolesMapping[role.Id]; isFound {
-				userPermission |= roleFound.Function
-			}
+		authorizer := rbac.NewAuthorizer(user.Roles, rolesMapping)
+		// api token never grants more than owner currently holds
+		if tokenRoles != nil {
+			authorizer.Mask = rbac.RolesFunction(tokenRoles, rolesMapping)
 		}
+		userPermission := authorizer.GlobalPermission()
 		// do authorization
-		if routeFound.ChallengeCode&userPermission == routeFound.ChallengeCode {
+		allowed := routeFound.ChallengeCode == constants.AuthenticatedOnly || routeFound.ChallengeCode&userPermission == routeFound.ChallengeCode
+		if !allowed {
+			// global roles fall short, role bindings scoped to what the request touches may still grant it
+			if allowed, userPermission, err = authorizeScoped(req, routeFound, &authorizer, user.Username); err != nil {
+				log.Errorf("Failed to check role bindings of %s during authentication check due to error: %s", req.SelectedRoutePath(), err.Error())
+				apiUtil.ResponseError(resp, http.StatusInternalServerError, "Failed to check role bindings during authentication check")
+				return
+			}
+		}
+		if allowed {
 			// ob1101 & ob 0010 = 0 authorization failed
 			// ob 0001 & ob 0001 = ob0001 authorization success
 			// match function Challenge Code can process
 			req.SetAttribute("run-by-user", user.Username)
+			req.SetAttribute("run-by-permission", userPermission)
+			req.SetAttribute("authorizer", authorizer)
 			fc.ProcessFilter(req, resp)
 		} else {
 			apiUtil.ResponseError(resp, http.StatusForbidden, fmt.Sprintf("You do not have the proper permission to call api %s", req.SelectedRoutePath()))
@@ -267,6 +308,106 @@ func standardAuth(req *restful.Request, resp *restful.Response, fc *restful.Filt
 
 }
 
+/*
+authorizeScoped loads role bindings of user into authorizer and checks them against cluster, node or region of path parameters
+it returns whether request is allowed and permission it is granted on what it touches
+list route passes when any binding grants it, handler filters what is returned
+route touching nothing a scope selects e.g. creating cluster stays with global roles
+*/
+func authorizeScoped(req *restful.Request, route apisv1.Route, authorizer *rbac.Authorizer, username string) (bool, uint64, error) {
+	permission := authorizer.GlobalPermission()
+	bindings, err := cache.GetCurrentCache().GetRoleBindingCollection()
+	if err != nil {
+		return false, permission, err
+	}
+	authorizer.Bindings = rbac.UserBindings(bindings, username)
+	if len(authorizer.Bindings) == 0 {
+		return false, permission, nil
+	}
+	if route.ScopedList {
+		return authorizer.Reachable(route.ChallengeCode), permission, nil
+	}
+	resource, err := requestResource(req)
+	if err != nil || resource == nil {
+		return false, permission, err
+	}
+	permission = authorizer.Permission(*resource)
+	return permission&route.ChallengeCode == route.ChallengeCode, permission, nil
+}
+
+// requestResource resolves what path parameters of request point to, nil when they point to nothing or nothing that exists
+func requestResource(req *restful.Request) (*rbac.Resource, error) {
+	runtimeCache := cache.GetCurrentCache()
+	if clusterId := req.PathParameter("cluster-id"); clusterId != "" {
+		cluster, err := runtimeCache.GetCluster(clusterId)
+		if err != nil || cluster == nil {
+			return nil, err
+		}
+		resource := rbac.ClusterResource(*cluster)
+		return &resource, nil
+	}
+	if nodeId := req.PathParameter("node-id"); nodeId != "" {
+		node, err := runtimeCache.GetNodeInformation(nodeId)
+		if err != nil || node == nil {
+			return nil, err
+		}
+		var cluster *schema.Cluster
+		if node.BelongsToCluster != "" {
+			if cluster, err = runtimeCache.GetCluster(node.BelongsToCluster); err != nil {
+				return nil, err
+			}
+		}
+		resource := rbac.NodeResource(*node, cluster)
+		return &resource, nil
+	}
+	if region := req.PathParameter("region"); region != "" {
+		resource := rbac.RegionResource(region)
+		return &resource, nil
+	}
+	return nil, nil
+}
+
+func checkSession(sessionId string) (int, error) {
+	if sessionId == "" {
+		return http.StatusUnauthorized, errors.New("Token of previous version is no longer accepted, please login again")
+	}
+	session, err := cache.GetCurrentCache().GetSession(sessionId)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Failed to get login session due to error: %s", err.Error())
+	}
+	if session == nil || session.RevokedAt != 0 {
+		return http.StatusUnauthorized, errors.New("Token is revoked, please login again")
+	}
+	return http.StatusOK, nil
+}
+
+// authenticateAPIToken validates api token and records when and where it is used
+func authenticateAPIToken(headerToken string, req *restful.Request) (*schema.APIToken, int, error) {
+	id, secret, ok := apiToken.Parse(headerToken)
+	if !ok {
+		return nil, http.StatusUnauthorized, errors.New("Malformed api token")
+	}
+	runtimeCache := cache.GetCurrentCache()
+	token, err := runtimeCache.GetAPIToken(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to get api token due to error: %s", err.Error())
+	}
+	now := time.Now()
+	if err := apiToken.Validate(token, secret, now); err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+	source := apiUtil.RequestSourceIP(req)
+	// saved at most once a minute per source so busy pipeline does not turn every call into a db write
+	if now.Unix()-token.LastUsedAt >= 60 || token.LastUsedIP != source {
+		token.LastUsedAt = now.Unix()
+		token.LastUsedIP = source
+		if err := runtimeCache.SaveOrUpdateAPIToken(*token); err != nil {
+			log.Errorf("Failed to record usage of api token %s due to error: %s", token.Id, err.Error())
+		}
+	}
+	return token, http.StatusOK, nil
+}
+
 func caasAuth(req *restful.Request, resp *restful.Response, fc *restful.FilterChain) {
 	token, err := getToken(req)
 	if err != nil {
@@ -347,6 +488,8 @@ func caasAuth(req *restful.Request, resp *restful.Response, fc *restful.FilterCh
 	}
 
 	if allowed {
+		// caas global role grants every function of core api
+		req.SetAttribute("authorizer", rbac.Authorizer{Global: ^uint64(0), Mask: ^uint64(0)})
 		fc.ProcessFilter(req, resp)
 		return
 	}
@@ -39,6 +39,8 @@ type Route struct {
 	WriteDataModel    interface{}
 	ReturnDefinitions []DocReturnDefinition
 	Tags              []string
+	// handler filters what it lists by role bindings of caller, so any binding granting ChallengeCode lets request in
+	ScopedList bool
 }
 
 type DocReturnDefinition struct {
@@ -61,6 +63,7 @@ var Paths = []Path{
 			roleManageV2,
 			dansManage,
 			kubectlRunCommandManage,
+			auditManage,
 		),
 	},
 	{
@@ -110,3 +113,74 @@ func appendsRoutes(s []Route, elms ...[]Route) []Route {
 	}
 	return s
 }
+
+// dryRunQueryParam is shared by apis which are able to return operation plan instead of running it
+var dryRunQueryParam = schema.Parameter{
+	Required:      false,
+	DataFormat:    "boolean",
+	DefaultValue:  "false",
+	Name:          "dry_run",
+	Description:   "set to true to return the rendered operation plan (steps, target nodes, config files) without running anything",
+	DataType:      "boolean",
+	AllowMultiple: false,
+}
+
+// rollbackOnFailureQueryParam is shared by apis which are able to undo what they did once operation fails
+var rollbackOnFailureQueryParam = schema.Parameter{
+	Required:      false,
+	DataFormat:    "boolean",
+	DefaultValue:  "false",
+	Name:          "rollback_on_failure",
+	Description:   "set to true to undo what is done and release nodes back to available pool when operation fails",
+	DataType:      "boolean",
+	AllowMultiple: false,
+}
+
+// upgradeStrategyQueryParams are shared by apis which make upgrade plan
+var upgradeStrategyQueryParams = []schema.Parameter{
+	{
+		Required:      false,
+		DataFormat:    "integer",
+		DefaultValue:  "1",
+		Name:          "batch_size",
+		Description:   "how many workers are upgraded at the same time",
+		DataType:      "integer",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "integer",
+		DefaultValue:  "0",
+		Name:          "max_unavailable",
+		Description:   "how many workers are allowed to be unavailable at the same time, caps batch_size when set",
+		DataType:      "integer",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "integer",
+		DefaultValue:  "300",
+		Name:          "health_check_timeout",
+		Description:   "seconds to wait for nodes and kube-system pods become ready after each batch",
+		DataType:      "integer",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "boolean",
+		DefaultValue:  "false",
+		Name:          "disable_health_gate",
+		Description:   "set to true to skip health check between batches",
+		DataType:      "boolean",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "pause_after_batches",
+		Description:   "comma separated batches to pause after, 0 stands for control plane",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+}
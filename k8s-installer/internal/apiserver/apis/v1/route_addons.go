@@ -53,6 +53,7 @@ var addonsManage = []Route{
 		Handler:        addons.ManageClusterAddons,
 		ChallengeCode:  constants.ChallengeCodeListNode,
 		Doc:            "List Addons",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  schema.Addons{},
 		WriteDataModel: schema.Addons{},
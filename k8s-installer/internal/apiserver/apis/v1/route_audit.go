@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"net/http"
+
+	restfulSpec "github.com/emicklei/go-restful-openapi"
+	"k8s-installer/internal/apiserver/controller/audit"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+// auditFilterQueryParams is shared by list and export of audit events
+var auditFilterQueryParams = []schema.Parameter{
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "operator",
+		Description:   "user who triggered the event or the event is about",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "action",
+		Description:   "e.g. api-request, remote-command or ssh-session",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "route",
+		Description:   "part of route or path of request",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "source_ip",
+		Description:   "source ip of request",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "string",
+		DefaultValue:  "",
+		Name:          "target",
+		Description:   "node the command or ssh session is sent to",
+		DataType:      "string",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "integer",
+		DefaultValue:  "",
+		Name:          "from",
+		Description:   "unix time events start from",
+		DataType:      "integer",
+		AllowMultiple: false,
+	},
+	{
+		Required:      false,
+		DataFormat:    "integer",
+		DefaultValue:  "",
+		Name:          "to",
+		Description:   "unix time events end at",
+		DataType:      "integer",
+		AllowMultiple: false,
+	},
+}
+
+var auditManage = []Route{
+	{
+		Path:           "/audit-events",
+		HTTPMethod:     http.MethodGet,
+		Handler:        audit.ListAuditEvent,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Tags:           []string{"Core_Audit"},
+		Doc:            "List audit events newest first",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: []schema.AuditEvent{},
+		QueryParams: append([]schema.Parameter{
+			{
+				Required:      false,
+				DataFormat:    "integer",
+				DefaultValue:  "100",
+				Name:          "limit",
+				Description:   "max events to return",
+				DataType:      "integer",
+				AllowMultiple: false,
+			},
+		}, auditFilterQueryParams...),
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				[]schema.AuditEvent{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/audit-events/export",
+		HTTPMethod:     http.MethodGet,
+		Handler:        audit.ExportAuditEvent,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Tags:           []string{"Core_Audit"},
+		Doc:            "Export audit events oldest first as json lines",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.AuditEvent{},
+		QueryParams:    auditFilterQueryParams,
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK, one audit event per line",
+				schema.AuditEvent{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/audit-events/verification",
+		HTTPMethod:     http.MethodGet,
+		Handler:        audit.VerifyAuditChain,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Tags:           []string{"Core_Audit"},
+		Doc:            "Verify hash chain of audit events, first modified or missing event is reported",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.AuditVerification{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				schema.AuditVerification{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+}
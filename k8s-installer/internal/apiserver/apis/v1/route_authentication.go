@@ -11,29 +11,19 @@ import (
 
 var routeAuthentication = []Route{
 	{
-		Path:          "/login",
-		HTTPMethod:    http.MethodPost,
-		Handler:       user.Login,
-		ChallengeCode: 0, // no need, because we always skip login api authorization, eggs or chicken which comes first ?
-		Doc:           "Login",
-		MetaData:      restfulSpec.KeyOpenAPITags,
-		ReadDataModel: schema.User{},
-		WriteDataModel: struct {
-			Token       string      `json:"token"`
-			ExpiredDate string      `json:"expired_date"`
-			Permission  uint64      `json:"permission"`
-			User        schema.User `json:"user"`
-		}{},
+		Path:           "/login",
+		HTTPMethod:     http.MethodPost,
+		Handler:        user.Login,
+		ChallengeCode:  0, // no need, because we always skip login api authorization, eggs or chicken which comes first ?
+		Doc:            "Login",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.User{},
+		WriteDataModel: schema.LoginResponse{},
 		ReturnDefinitions: []DocReturnDefinition{
 			{
 				http.StatusOK,
 				"OK",
-				struct {
-					Token       string      `json:"token"`
-					ExpiredDate string      `json:"expired_date"`
-					Permission  uint64      `json:"permission"`
-					User        schema.User `json:"user"`
-				}{},
+				schema.LoginResponse{},
 			},
 			{
 				http.StatusBadRequest,
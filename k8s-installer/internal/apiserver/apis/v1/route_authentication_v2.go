@@ -98,9 +98,9 @@ var AuthenticationV2Routes = []Route{
 		//Handler:       user.CheckLoginType,
 		Tags:          []string{"Core_Authentication"},
 		ChallengeCode: 0, // no need, because we always skip login api authorization, eggs or chicken which comes first ?
-		Doc:           "Login",
+		Doc:           "Login, user with mfa enabled sends otp_code or recovery_code as well",
 		MetaData:      restfulSpec.KeyOpenAPITags,
-		ReadDataModel: schema.User{},
+		ReadDataModel: schema.LoginRequest{},
 		WriteDataModel: struct {
 			Token       string      `json:"token"`
 			ExpiredDate string      `json:"expired_date"`
@@ -362,6 +362,616 @@ var AuthenticationV2Routes = []Route{
 			},
 		},
 	},
+	{
+		Path:          "/oidc/{provider}/login",
+		HTTPMethod:    http.MethodGet,
+		Tags:          []string{"Core_Authentication"},
+		Handler:       user.OIDCLogin,
+		ChallengeCode: 0, // skipped by authorization as login
+		Doc:           "Redirect browser to login page of oidc auth provider",
+		MetaData:      restfulSpec.KeyOpenAPITags,
+		PathParams:    []schema.Parameter{oidcProviderPathParam},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusFound,
+				"Redirect to oidc provider",
+				nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:          "/oidc/{provider}/callback",
+		HTTPMethod:    http.MethodGet,
+		Tags:          []string{"Core_Authentication"},
+		Handler:       user.OIDCCallback,
+		ChallengeCode: 0, // skipped by authorization as login
+		Doc:           "Oidc redirect url, exchanges authorization code and returns same result as login",
+		MetaData:      restfulSpec.KeyOpenAPITags,
+		PathParams:    []schema.Parameter{oidcProviderPathParam},
+		QueryParams: []schema.Parameter{
+			{
+				Required:    false,
+				DataFormat:  "string",
+				Name:        "code",
+				Description: "authorization code issued by oidc provider",
+				DataType:    "string",
+			},
+			{
+				Required:    false,
+				DataFormat:  "string",
+				Name:        "state",
+				Description: "state sent to oidc provider",
+				DataType:    "string",
+			},
+		},
+		WriteDataModel: struct {
+			Token       string      `json:"token"`
+			ExpiredDate string      `json:"expired_date"`
+			Permission  uint64      `json:"permission"`
+			User        schema.User `json:"user"`
+		}{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				struct {
+					Token       string      `json:"token"`
+					ExpiredDate string      `json:"expired_date"`
+					Permission  uint64      `json:"permission"`
+					User        schema.User `json:"user"`
+				}{},
+			},
+			{
+				http.StatusUnauthorized,
+				"Unauthorized",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusForbidden,
+				"User is not allowed by group mapping or provisioning",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/login/refresh",
+		HTTPMethod:     http.MethodPost,
+		Handler:        user.RefreshLogin,
+		Tags:           []string{"Core_Authentication"},
+		ChallengeCode:  0, // refresh token is the credential, access token may already be expired
+		Doc:            "Exchange refresh token for new access token and refresh token, each refresh token can be used only once",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.RefreshRequest{},
+		WriteDataModel: schema.LoginResponse{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				schema.LoginResponse{},
+			},
+			{
+				http.StatusUnauthorized,
+				"Unauthorized",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/logout",
+		HTTPMethod:     http.MethodPost,
+		Handler:        user.Logout,
+		Tags:           []string{"Core_Authentication"},
+		ChallengeCode:  constants.AuthenticatedOnly,
+		Doc:            "Revoke login session of current token so it`s access and refresh tokens stop working on every server",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		QueryParams: []schema.Parameter{
+			{
+				Required:      false,
+				Name:          "all",
+				Description:   "true to revoke every login session of current user",
+				DataType:      "boolean",
+				AllowMultiple: false,
+				DataFormat:    "boolean",
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				nil,
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/mfa/enrollment",
+		HTTPMethod:     http.MethodPost,
+		Handler:        user.EnrollMFA,
+		Tags:           []string{"Core_Authentication"},
+		ChallengeCode:  0, // user proves itself by password, so user whose role requires mfa can enroll before first login
+		Doc:            "Generate totp secret and recovery codes, enrollment is pending until activated. Send X-Auth-Type header same as login",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.LoginRequest{},
+		WriteDataModel: schema.MFAEnrollment{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				schema.MFAEnrollment{},
+			},
+			{
+				http.StatusUnauthorized,
+				"Unauthorized",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusConflict,
+				"Mfa already enabled",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/mfa/activation",
+		HTTPMethod:     http.MethodPost,
+		Handler:        user.ActivateMFA,
+		Tags:           []string{"Core_Authentication"},
+		ChallengeCode:  0,
+		Doc:            "Enable pending mfa enrollment with username, password and otp_code of the new authenticator",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.LoginRequest{},
+		WriteDataModel: nil,
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				http.StatusOK,
+				"OK",
+				nil,
+			},
+			{
+				http.StatusUnauthorized,
+				"Unauthorized",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusNotFound,
+				"No pending enrollment",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/users/{username}/mfa",
+		HTTPMethod:     http.MethodDelete,
+		Tags:           []string{"Core_User"},
+		Handler:        user.ResetUserMFA,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Reset mfa of user so user is able to enroll a new authenticator",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "username",
+				Description:   "username",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/login-lockouts",
+		HTTPMethod:     http.MethodGet,
+		Tags:           []string{"Core_User"},
+		Handler:        user.ListLoginLockout,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "List users, source ips and sms receivers currently locked due to too many failed attempts",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: []schema.LoginAttempt{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: []schema.LoginAttempt{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/login-lockouts/{key}",
+		HTTPMethod:     http.MethodDelete,
+		Tags:           []string{"Core_User"},
+		Handler:        user.UnlockLogin,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Unlock and clear failed attempts",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "key",
+				Description:   "user:{username}, ip:{source ip} or sms:{phone}",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/tokens",
+		HTTPMethod:     http.MethodPost,
+		Tags:           []string{"Core_User"},
+		Handler:        user.CreateAPIToken,
+		ChallengeCode:  constants.AuthenticatedOnly,
+		Doc:            "Create api token of current user or of a service account, send returned token as token header. Token cannot be created with another api token",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.APIToken{},
+		WriteDataModel: schema.APITokenCreated{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.APITokenCreated{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusForbidden,
+				"Forbidden",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/tokens",
+		HTTPMethod:     http.MethodGet,
+		Tags:           []string{"Core_User"},
+		Handler:        user.ListAPIToken,
+		ChallengeCode:  constants.AuthenticatedOnly,
+		Doc:            "List api tokens of current user, user allowed to manage users gets every token",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: []schema.APIToken{},
+		QueryParams: []schema.Parameter{
+			{
+				Required:      false,
+				Name:          "owner",
+				Description:   "only list tokens of this user or service account",
+				DataType:      "string",
+				AllowMultiple: false,
+				DataFormat:    "string",
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: []schema.APIToken{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/tokens/{token-id}",
+		HTTPMethod:     http.MethodDelete,
+		Tags:           []string{"Core_User"},
+		Handler:        user.RevokeAPIToken,
+		ChallengeCode:  constants.AuthenticatedOnly,
+		Doc:            "Revoke api token",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "token-id",
+				Description:   "id of api token",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/service-accounts",
+		HTTPMethod:     http.MethodPost,
+		Tags:           []string{"Core_User"},
+		Handler:        user.CreateOrUpdateServiceAccount,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Create service account or replace roles of existing one, service account has no password and only authenticates with api token",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.User{},
+		WriteDataModel: schema.User{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.User{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/service-accounts",
+		HTTPMethod:     http.MethodGet,
+		Tags:           []string{"Core_User"},
+		Handler:        user.ListServiceAccount,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "List service accounts",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: []schema.User{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: []schema.User{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/service-accounts/{name}",
+		HTTPMethod:     http.MethodDelete,
+		Tags:           []string{"Core_User"},
+		Handler:        user.DeleteServiceAccount,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Delete service account and revoke all of it`s api tokens",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "name",
+				Description:   "service account name",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/role-bindings",
+		HTTPMethod:     http.MethodPost,
+		Tags:           []string{"Core_User"},
+		Handler:        user.CreateRoleBinding,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Bind role to user on listed clusters, regions or clusters holding all listed labels only",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  schema.RoleBinding{},
+		WriteDataModel: schema.RoleBinding{},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.RoleBinding{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusNotFound,
+				"user not found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/role-bindings",
+		HTTPMethod:     http.MethodGet,
+		Tags:           []string{"Core_User"},
+		Handler:        user.ListRoleBinding,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "List role bindings",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: []schema.RoleBinding{},
+		QueryParams: []schema.Parameter{
+			{
+				Required:      false,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "username",
+				Description:   "only list role bindings of the user",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: []schema.RoleBinding{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/role-bindings/{binding-id}",
+		HTTPMethod:     http.MethodDelete,
+		Tags:           []string{"Core_User"},
+		Handler:        user.DeleteRoleBinding,
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Delete role binding",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "binding-id",
+				Description:   "role binding id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusNotFound,
+				"Not Found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
 }
 
 var roleManageV2 = []Route{
@@ -563,3 +1173,13 @@ var systemConfigRoute = []Route{
 		},
 	},
 }
+
+var oidcProviderPathParam = schema.Parameter{
+	Required:      true,
+	DataFormat:    "string",
+	DefaultValue:  "",
+	Name:          "provider",
+	Description:   "name of oidc auth provider",
+	DataType:      "string",
+	AllowMultiple: false,
+}
@@ -17,6 +17,7 @@ var clusterManage = []Route{
 		Handler:        cluster.CreateCluster,
 		ChallengeCode:  constants.ChallengeCodeCreateCluster,
 		Doc:            "Create cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam, rollbackOnFailureQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  schema.Cluster{},
 		WriteDataModel: schema.Cluster{},
@@ -195,6 +196,7 @@ var clusterManage = []Route{
 		HTTPMethod:     http.MethodGet,
 		Handler:        cluster.ListCluster,
 		ChallengeCode:  constants.ChallengeCodeListCluster,
+		ScopedList:     true,
 		Doc:            "List cluster info",
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
@@ -228,6 +230,7 @@ var clusterManage = []Route{
 		HTTPMethod:     http.MethodGet,
 		Handler:        cluster.ListRunningCluster,
 		ChallengeCode:  constants.ChallengeCodeListCluster,
+		ScopedList:     true,
 		Doc:            "List all running cluster",
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
@@ -293,6 +296,7 @@ var clusterManage = []Route{
 		Handler:        cluster.AddNodeToCluster,
 		ChallengeCode:  constants.ChallengeCodeAddNodeToCluster,
 		Doc:            "add node to cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam, rollbackOnFailureQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  []schema.ClusterNode{},
 		WriteDataModel: []schema.ClusterNode{},
@@ -336,6 +340,7 @@ var clusterManage = []Route{
 		Handler:        cluster.RemoveNodeFromCluster,
 		ChallengeCode:  constants.ChallengeCodeRemoveNodeFromCluster,
 		Doc:            "remove node from cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  []schema.ClusterNode{},
 		WriteDataModel: nil,
@@ -18,6 +18,7 @@ var clusterManageV2 = []Route{
 		Tags:           []string{"Core_Clusters"},
 		ChallengeCode:  constants.ChallengeCodeCreateCluster,
 		Doc:            "Create cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  schema.Cluster{},
 		WriteDataModel: schema.Cluster{},
@@ -125,6 +126,45 @@ var clusterManageV2 = []Route{
 			},
 		},
 	},
+	{
+		Path:           "/clusters/{cluster-id}/labels",
+		HTTPMethod:     http.MethodPut,
+		Handler:        cluster.UpdateClusterLabels,
+		Tags:           []string{"Core_Clusters"},
+		ChallengeCode:  constants.ChallengeCodeManageUser,
+		Doc:            "Replace labels of cluster, role bindings select clusters by them",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  map[string]string{},
+		WriteDataModel: map[string]string{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "cluster-id",
+				Description:   "cluster id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: map[string]string{},
+			},
+			{
+				http.StatusNotFound,
+				"cluster not found",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
 	{
 		Path:           "/clusters/{cluster-id}/recreate",
 		HTTPMethod:     http.MethodPut,
@@ -259,6 +299,7 @@ var clusterManageV2 = []Route{
 		Handler:        cluster.ListCluster,
 		Tags:           []string{"Core_Clusters"},
 		ChallengeCode:  constants.ChallengeCodeListCluster,
+		ScopedList:     true,
 		Doc:            "List cluster info",
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
@@ -295,6 +336,7 @@ var clusterManageV2 = []Route{
 		Tags:           []string{"Core_Clusters"},
 		Handler:        cluster.ListRunningCluster,
 		ChallengeCode:  constants.ChallengeCodeListCluster,
+		ScopedList:     true,
 		Doc:            "List all running cluster",
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
@@ -330,6 +372,7 @@ var clusterManageV2 = []Route{
 		Tags:           []string{"Core_Clusters"},
 		ChallengeCode:  constants.ChallengeCodeAddNodeToCluster,
 		Doc:            "add node to cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  []schema.ClusterNode{},
 		WriteDataModel: []schema.ClusterNode{},
@@ -374,6 +417,7 @@ var clusterManageV2 = []Route{
 		Tags:           []string{"Core_Clusters"},
 		ChallengeCode:  constants.ChallengeCodeRemoveNodeFromCluster,
 		Doc:            "remove node from cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  []schema.ClusterNode{},
 		WriteDataModel: nil,
@@ -554,6 +598,7 @@ var clusterManageV2 = []Route{
 		Tags:           []string{"Core_Clusters"},
 		ChallengeCode:  constants.ChallengeCodeListNode,
 		Doc:            "List Addons",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  schema.Addons{},
 		WriteDataModel: schema.Addons{},
@@ -15,6 +15,7 @@ var nodeManage = []Route{
 		HTTPMethod:    http.MethodGet,
 		Handler:       node.ListNode,
 		ChallengeCode: constants.ChallengeCodeListNode,
+		ScopedList:    true,
 		Doc:           "List Node Info",
 		MetaData:      restfulSpec.KeyOpenAPITags,
 		ReadDataModel: nil,
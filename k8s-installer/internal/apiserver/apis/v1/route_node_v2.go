@@ -16,6 +16,7 @@ var nodeManageV2 = []Route{
 		HTTPMethod:    http.MethodGet,
 		Handler:       node.ListNode,
 		ChallengeCode: constants.ChallengeCodeListNode,
+		ScopedList:    true,
 		Tags:          []string{"Core_Node"},
 		Doc:           "List Node Info",
 		MetaData:      restfulSpec.KeyOpenAPITags,
@@ -127,4 +127,98 @@ var operationV2Manager = []Route{
 			},
 		},
 	},
+	{
+		Path:           "/operation/{operation-id}/cancel",
+		HTTPMethod:     http.MethodPut,
+		Handler:        operation.CancelOperation,
+		Tags:           []string{"Core_Operation"},
+		ChallengeCode:  constants.ChallengeCodeContinueTask,
+		Doc:            "Cancel running operation. Running node steps are aborted and operation error handler runs to keep cluster stat consistent",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: nil,
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "operation-id",
+				Description:   "operation id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: nil,
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusPreconditionFailed,
+				"Operation is not running",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/operation/{operation-id}/progress",
+		HTTPMethod:     http.MethodGet,
+		Handler:        operation.WatchOperationProgress,
+		Tags:           []string{"Core_Operation"},
+		ChallengeCode:  constants.ChallengeCodeListCluster,
+		Doc:            "Stream live operation progress with server-sent events or websocket (when upgrade is requested)",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.OperationEvent{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "operation-id",
+				Description:   "operation id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		QueryParams: []schema.Parameter{
+			{
+				Required:      false,
+				DataFormat:    "integer",
+				DefaultValue:  "0",
+				Name:          "from_seq",
+				Description:   "resume from event with this seq, header Last-Event-ID is used when it is not set",
+				DataType:      "integer",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.OperationEvent{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
 }
@@ -293,6 +293,7 @@ var upgradeManage = []Route{
 		Handler:        upgrade.ApplyUpgradePlan,
 		ChallengeCode:  constants.ChallengeUpgradeCluster,
 		Doc:            "apply the plan do upgrade to cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
 		WriteDataModel: nil,
@@ -330,12 +331,100 @@ var upgradeManage = []Route{
 			},
 		},
 	},
+	// pause and resume rolling upgrade between batches
+	{
+		Path:           "/plan/pause/{plan-id}",
+		HTTPMethod:     http.MethodPut,
+		Handler:        upgrade.PauseUpgradePlan,
+		ChallengeCode:  constants.ChallengeUpgradeCluster,
+		Doc:            "pause the upgrade once the running batch is done and passed health check",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.UpgradePlan{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "plan-id",
+				Description:   "plan id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.UpgradePlan{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusPreconditionFailed,
+				"Precondition check failed",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/plan/resume/{plan-id}",
+		HTTPMethod:     http.MethodPut,
+		Handler:        upgrade.ResumeUpgradePlan,
+		ChallengeCode:  constants.ChallengeUpgradeCluster,
+		Doc:            "resume a paused upgrade from the next batch",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.UpgradePlan{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "plan-id",
+				Description:   "plan id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.UpgradePlan{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusPreconditionFailed,
+				"Precondition check failed",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
 	{
 		Path:           "/make/{cluster-id}/{version-name}",
 		HTTPMethod:     http.MethodPut,
 		Handler:        upgrade.MakeUpgradePlan,
 		ChallengeCode:  constants.ChallengeUpgradeCluster,
 		Doc:            "Make a upgrade plan for specified cluster",
+		QueryParams:    upgradeStrategyQueryParams,
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
 		WriteDataModel: schema.UpgradePlan{},
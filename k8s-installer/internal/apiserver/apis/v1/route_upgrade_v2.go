@@ -275,6 +275,7 @@ var upgradeV2Manage = []Route{
 		Tags:           []string{"Core_Upgrade"},
 		ChallengeCode:  constants.ChallengeUpgradeCluster,
 		Doc:            "apply the plan do upgrade to cluster",
+		QueryParams:    []schema.Parameter{dryRunQueryParam},
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
 		WriteDataModel: nil,
@@ -312,6 +313,95 @@ var upgradeV2Manage = []Route{
 			},
 		},
 	},
+	// pause and resume rolling upgrade between batches
+	{
+		Path:           "/upgrades/plans/{plan-id}/pause",
+		HTTPMethod:     http.MethodPut,
+		Handler:        upgrade.PauseUpgradePlan,
+		Tags:           []string{"Core_Upgrade"},
+		ChallengeCode:  constants.ChallengeUpgradeCluster,
+		Doc:            "pause the upgrade once the running batch is done and passed health check",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.UpgradePlan{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "plan-id",
+				Description:   "plan id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.UpgradePlan{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusPreconditionFailed,
+				"Precondition check failed",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
+	{
+		Path:           "/upgrades/plans/{plan-id}/resume",
+		HTTPMethod:     http.MethodPut,
+		Handler:        upgrade.ResumeUpgradePlan,
+		Tags:           []string{"Core_Upgrade"},
+		ChallengeCode:  constants.ChallengeUpgradeCluster,
+		Doc:            "resume a paused upgrade from the next batch",
+		MetaData:       restfulSpec.KeyOpenAPITags,
+		ReadDataModel:  nil,
+		WriteDataModel: schema.UpgradePlan{},
+		PathParams: []schema.Parameter{
+			{
+				Required:      true,
+				DataFormat:    "string",
+				DefaultValue:  "",
+				Name:          "plan-id",
+				Description:   "plan id",
+				DataType:      "string",
+				AllowMultiple: false,
+			},
+		},
+		ReturnDefinitions: []DocReturnDefinition{
+			{
+				HTTPStatus:  http.StatusOK,
+				Message:     "OK",
+				ReturnModel: schema.UpgradePlan{},
+			},
+			{
+				http.StatusBadRequest,
+				"bad request",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				schema.HttpErrorResult{},
+			},
+			{
+				http.StatusPreconditionFailed,
+				"Precondition check failed",
+				schema.HttpErrorResult{},
+			},
+		},
+	},
 	{
 		Path:           "/upgrades/plans",
 		HTTPMethod:     http.MethodPost,
@@ -322,7 +412,7 @@ var upgradeV2Manage = []Route{
 		MetaData:       restfulSpec.KeyOpenAPITags,
 		ReadDataModel:  nil,
 		WriteDataModel: schema.UpgradePlan{},
-		QueryParams: []schema.Parameter{
+		QueryParams: append([]schema.Parameter{
 			{
 				Required:      true,
 				DataFormat:    "string",
@@ -341,7 +431,7 @@ var upgradeV2Manage = []Route{
 				DataType:      "string",
 				AllowMultiple: false,
 			},
-		},
+		}, upgradeStrategyQueryParams...),
 		ReturnDefinitions: []DocReturnDefinition{
 			{
 				HTTPStatus:  http.StatusOK,
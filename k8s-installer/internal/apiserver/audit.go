@@ -0,0 +1,80 @@
+package apiserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	apiUtil "k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/audit"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+/*
+auditRequest records every request able to change something, GET is left out
+it wraps authentication so rejected requests are recorded as well, principal comes from attributes auth filter sets
+*/
+func auditRequest(req *restful.Request, resp *restful.Response, fc *restful.FilterChain) {
+	switch req.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		fc.ProcessFilter(req, resp)
+		return
+	}
+	body := ""
+	if req.Request.Body != nil {
+		// read no more than needed for the record and put it back in front of the rest for handler
+		read, err := ioutil.ReadAll(io.LimitReader(req.Request.Body, audit.MaxBodySize+1))
+		if err != nil {
+			log.Warnf("Failed to read body of %s for audit due to error: %s", req.Request.URL.Path, err.Error())
+		}
+		req.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(read), req.Request.Body))
+		if len(read) > audit.MaxBodySize {
+			body = fmt.Sprintf("more than %d bytes, not recorded", audit.MaxBodySize)
+		} else {
+			body = audit.RedactBody(read)
+		}
+	}
+	start := time.Now()
+	fc.ProcessFilter(req, resp)
+
+	event := schema.AuditEvent{
+		Time:       start.Unix(),
+		Action:     constants.AuditActionAPIRequest,
+		SourceIP:   apiUtil.RequestSourceIP(req),
+		Method:     req.Request.Method,
+		Route:      req.SelectedRoutePath(),
+		Path:       req.Request.URL.Path,
+		Parameters: audit.RedactParameters(req.Request.URL.Query()),
+		Body:       body,
+		Status:     resp.StatusCode(),
+		DurationMs: time.Since(start).Milliseconds(),
+		Result:     "success",
+	}
+	if event.Status >= http.StatusBadRequest {
+		event.Result = "failure"
+	}
+	// path parameters are identifiers rather than secrets
+	for name, value := range req.PathParameters() {
+		if event.Parameters == nil {
+			event.Parameters = map[string]string{}
+		}
+		event.Parameters[name] = value
+	}
+	event.Operator, _ = req.Attribute("run-by-user").(string)
+	if id, ok := req.Attribute("api-token-id").(string); ok {
+		event.Credential = "api-token:" + id
+	} else if id, ok := req.Attribute("session-id").(string); ok {
+		event.Credential = "session:" + id
+	}
+	if err := cache.GetCurrentCache().AddAuditEvent(event); err != nil {
+		log.Errorf("Failed to save audit event of %s %s due to error: %s", event.Method, event.Path, err.Error())
+	}
+}
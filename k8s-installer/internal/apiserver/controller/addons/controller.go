@@ -69,6 +69,16 @@ func ManageClusterAddons(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	if utils.IsDryRun(request) {
+		plan, err := control_manager.PlanAddonsManage(*cluster, addons)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to render operation plan due to error: %s", err.Error()))
+			return
+		}
+		response.WriteAsJson(plan)
+		return
+	}
+
 	if err := control_manager.AddonsManage(cluster, addons, runByUser); err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Internal server error %s", err.Error()))
 		return
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s-installer/internal/apiserver/utils"
+	auditPkg "k8s-installer/pkg/audit"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+// eventFilter holds query parameters shared by list and export
+type eventFilter struct {
+	operator string
+	action   string
+	route    string
+	sourceIP string
+	target   string
+	from     int64
+	to       int64
+}
+
+func parseFilter(request *restful.Request) (eventFilter, error) {
+	filter := eventFilter{
+		operator: request.QueryParameter("operator"),
+		action:   request.QueryParameter("action"),
+		route:    request.QueryParameter("route"),
+		sourceIP: request.QueryParameter("source_ip"),
+		target:   request.QueryParameter("target"),
+	}
+	for name, value := range map[string]*int64{"from": &filter.from, "to": &filter.to} {
+		raw := request.QueryParameter(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("unable to convert %s with value %s to unix time", name, raw)
+		}
+		*value = parsed
+	}
+	return filter, nil
+}
+
+func (filter eventFilter) match(event schema.AuditEvent) bool {
+	if filter.operator != "" && event.Operator != filter.operator && event.Username != filter.operator {
+		return false
+	}
+	if filter.action != "" && event.Action != filter.action {
+		return false
+	}
+	if filter.route != "" && !strings.Contains(event.Route, filter.route) && !strings.Contains(event.Path, filter.route) {
+		return false
+	}
+	if filter.sourceIP != "" && event.SourceIP != filter.sourceIP {
+		return false
+	}
+	if filter.target != "" && event.Target != filter.target {
+		return false
+	}
+	if filter.from != 0 && event.Time < filter.from {
+		return false
+	}
+	if filter.to != 0 && event.Time > filter.to {
+		return false
+	}
+	return true
+}
+
+func filteredEvents(request *restful.Request, response *restful.Response) ([]schema.AuditEvent, bool) {
+	filter, err := parseFilter(request)
+	if err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	events, err := cache.GetCurrentCache().GetAuditEventList()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get audit events due to error: %s", err.Error()))
+		return nil, false
+	}
+	result := []schema.AuditEvent{}
+	for _, event := range events {
+		if filter.match(event) {
+			result = append(result, event)
+		}
+	}
+	return result, true
+}
+
+// ListAuditEvent returns matching events newest first, limit defaults to 100
+func ListAuditEvent(request *restful.Request, response *restful.Response) {
+	limit := 100
+	if raw := request.QueryParameter("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("unable to convert limit with value %s to positive int", raw))
+			return
+		}
+		limit = parsed
+	}
+	events, ok := filteredEvents(request, response)
+	if !ok {
+		return
+	}
+	result := []schema.AuditEvent{}
+	for index := len(events) - 1; index >= 0 && len(result) < limit; index-- {
+		result = append(result, events[index])
+	}
+	response.WriteAsJson(result)
+}
+
+// ExportAuditEvent streams matching events oldest first as json lines, hashes are kept so the export can be verified offline
+func ExportAuditEvent(request *restful.Request, response *restful.Response) {
+	events, ok := filteredEvents(request, response)
+	if !ok {
+		return
+	}
+	response.AddHeader("Content-Type", "application/x-ndjson")
+	response.AddHeader("Content-Disposition", "attachment; filename=audit.jsonl")
+	response.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(response)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return
+		}
+	}
+}
+
+// VerifyAuditChain checks every event against the chain, first broken link is reported
+func VerifyAuditChain(request *restful.Request, response *restful.Response) {
+	runtimeCache := cache.GetCurrentCache()
+	// head first, events appended meanwhile are past it and left for next verification
+	head, err := runtimeCache.GetAuditChainHead()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get audit chain head due to error: %s", err.Error()))
+		return
+	}
+	events, err := runtimeCache.GetAuditEventList()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get audit events due to error: %s", err.Error()))
+		return
+	}
+	if head != nil {
+		for index, event := range events {
+			if event.Sequence > head.Sequence {
+				events = events[:index]
+				break
+			}
+		}
+	}
+	response.WriteAsJson(auditPkg.Verify(events, head))
+}
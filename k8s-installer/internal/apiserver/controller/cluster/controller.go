@@ -28,6 +28,7 @@ import (
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/control_manager"
+	"k8s-installer/pkg/rbac"
 	"k8s-installer/schema"
 )
 
@@ -80,7 +81,7 @@ func UpdateClusterStatus(request *restful.Request, response *restful.Response) {
 	existingCluster.Status = clusterPost.Status
 
 	if err := runtimeCache.SaveOrUpdateClusterCollection(existingCluster.ClusterId, *existingCluster); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Unable to update cluster with id %s due to error: %s ", clusterId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Unable to update cluster with id %s due to error: %s ", clusterId, err.Error()))
 		return
 	}
 
@@ -120,13 +121,50 @@ func UpdateClusterInstaller(request *restful.Request, response *restful.Response
 	}
 
 	if err := runtimeCache.SaveOrUpdateClusterCollection(existingCluster.ClusterId, *existingCluster); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Unable to update cluster with id %s due to error: %s ", clusterId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Unable to update cluster with id %s due to error: %s ", clusterId, err.Error()))
 		return
 	}
 
 	response.WriteAsJson(&clusterPost)
 }
 
+/*
+UpdateClusterLabels replaces labels of cluster
+role bindings select clusters by labels so changing them is managing access rather than cluster
+*/
+func UpdateClusterLabels(request *restful.Request, response *restful.Response) {
+	clusterId := request.PathParameter("cluster-id")
+	if strings.TrimSpace(clusterId) == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "cluster-id"))
+		return
+	}
+
+	labels := map[string]string{}
+	if err := request.ReadEntity(&labels); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runtimeCache := cache.GetCurrentCache()
+	existingCluster, err := runtimeCache.GetCluster(clusterId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Internal server error %s", err.Error()))
+		return
+	}
+	if existingCluster == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Unable to find cluster with id %s", clusterId))
+		return
+	}
+
+	existingCluster.Labels = labels
+	if err := runtimeCache.SaveOrUpdateClusterCollection(existingCluster.ClusterId, *existingCluster); err != nil {
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Unable to update cluster with id %s due to error: %s ", clusterId, err.Error()))
+		return
+	}
+
+	response.WriteAsJson(labels)
+}
+
 func ReCreateCluster(request *restful.Request, response *restful.Response) {
 	clusterId := request.PathParameter("cluster-id")
 	if strings.TrimSpace(clusterId) == "" {
@@ -382,7 +420,20 @@ func CreateCluster(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	if utils.IsDryRun(request) {
+		plan, err := control_manager.PlanSetupOrDestroyCluster(clusterPost)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to render operation plan due to error: %s", err.Error()))
+			return
+		}
+		response.WriteAsJson(plan)
+		return
+	}
+
 	clusterPost.Status = constants.StatusInstalling
+	if utils.IsRollbackOnFailure(request) {
+		clusterPost.RollbackOnFailure = true
+	}
 
 	runByUser := request.Attribute("run-by-user").(string)
 	if strings.TrimSpace(runByUser) == "" {
@@ -622,14 +673,17 @@ func ListCluster(request *restful.Request, response *restful.Response) {
 		return
 	}
 	regionId := request.QueryParameter("region_id")
+	visible := visibleClusterFilter(request)
 	clusters := []schema.Cluster{}
 	if regionId == "" {
 		for _, cluster := range clusterMap {
-			clusters = append(clusters, cluster)
+			if visible(cluster) {
+				clusters = append(clusters, cluster)
+			}
 		}
 	} else {
 		for _, cluster := range clusterMap {
-			if cluster.Region == regionId {
+			if cluster.Region == regionId && visible(cluster) {
 				clusters = append(clusters, cluster)
 			}
 		}
@@ -638,6 +692,19 @@ func ListCluster(request *restful.Request, response *restful.Response) {
 	response.WriteAsJson(clusters)
 }
 
+// visibleClusterFilter tells which clusters caller may list, caller listing by role binding only sees clusters it`s scope selects
+func visibleClusterFilter(request *restful.Request) func(cluster schema.Cluster) bool {
+	authorizer := utils.RequestAuthorizer(request)
+	if !authorizer.Scoped(constants.ChallengeCodeListCluster) {
+		return func(cluster schema.Cluster) bool {
+			return true
+		}
+	}
+	return func(cluster schema.Cluster) bool {
+		return authorizer.Allowed(constants.ChallengeCodeListCluster, rbac.ClusterResource(cluster))
+	}
+}
+
 func ListRunningCluster(request *restful.Request, response *restful.Response) {
 	runtimeCache := cache.GetCurrentCache()
 	clusterMap, err := runtimeCache.GetClusterCollection()
@@ -652,9 +719,10 @@ func ListRunningCluster(request *restful.Request, response *restful.Response) {
 		clusterInstaller = constants.ClusterInstallerKubeadm
 	}
 
+	visible := visibleClusterFilter(request)
 	clusters := []schema.Cluster{}
 	for _, cluster := range clusterMap {
-		if cluster.Status == constants.ClusterStatusRunning && (regionId == "" || cluster.Region == regionId) && cluster.ClusterInstaller == clusterInstaller {
+		if cluster.Status == constants.ClusterStatusRunning && (regionId == "" || cluster.Region == regionId) && cluster.ClusterInstaller == clusterInstaller && visible(cluster) {
 			clusters = append(clusters, cluster)
 		}
 
@@ -736,7 +804,17 @@ func AddNodeToCluster(request *restful.Request, response *restful.Response) {
 		return
 	}
 
-	if err := control_manager.AddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionCreate, runByUser); err != nil {
+	if utils.IsDryRun(request) {
+		plan, err := control_manager.PlanAddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionCreate)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to render operation plan due to error: %s", err.Error()))
+			return
+		}
+		response.WriteAsJson(plan)
+		return
+	}
+
+	if err := control_manager.AddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionCreate, runByUser, utils.IsRollbackOnFailure(request)); err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -913,7 +991,17 @@ func RemoveNodeFromCluster(request *restful.Request, response *restful.Response)
 		return
 	}
 
-	if err := control_manager.AddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionDelete, runByUser); err != nil {
+	if utils.IsDryRun(request) {
+		plan, err := control_manager.PlanAddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionDelete)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to render operation plan due to error: %s", err.Error()))
+			return
+		}
+		response.WriteAsJson(plan)
+		return
+	}
+
+	if err := control_manager.AddOrRemoveNodeToCluster(*cluster, nodes, constants.ActionDelete, runByUser, false); err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1541,7 +1629,7 @@ func MockOrUnMockCluster(request *restful.Request, response *restful.Response) {
 	}
 
 	if err := runtimeCache.SaveOrUpdateClusterCollection(cluster.ClusterId, *cluster); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Unable to save cluster to db due to error: %s", err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Unable to save cluster to db due to error: %s", err.Error()))
 		return
 	}
 
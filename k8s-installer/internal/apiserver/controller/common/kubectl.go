@@ -1,17 +1,31 @@
 package common
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	natClient "github.com/nats-io/nats.go"
+	"k8s-installer/pkg/audit"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/message_queue/nats"
 	mqHelper "k8s-installer/pkg/server/message_queue"
 	"k8s-installer/schema"
 	"time"
 )
 
-func SendingSingleCommand(mqConfig nats.MessageQueueConfig, nodeInformation schema.NodeInformation, dataToSend []byte, resultHandler func(msg *natClient.Msg) error) error {
+// what audit log keeps of a command, message body itself is encoded and carries whole cluster
+type auditedCommand struct {
+	ClusterId string           `json:"cluster_id,omitempty"`
+	TaskType  string           `json:"task_type"`
+	Commands  map[int][]string `json:"commands,omitempty"`
+}
+
+// SendingSingleCommand sends command to node and waits for reply, every command is recorded in audit log with user who runs it
+func SendingSingleCommand(mqConfig nats.MessageQueueConfig, nodeInformation schema.NodeInformation, clusterId string, dataToSend []byte, runByUser string, resultHandler func(msg *natClient.Msg) error) error {
 	var mqErr error
+	start := time.Now()
 	mqErr = nats.SendingMessageWithReply(mqHelper.GeneratorNodeSubscribe(nodeInformation, mqConfig.SubjectSuffix),
 		nodeInformation.Id,
 		nodeInformation.Id,
@@ -23,5 +37,47 @@ func SendingSingleCommand(mqConfig nats.MessageQueueConfig, nodeInformation sche
 		func(nodeId string, nodeStepId string) {
 			mqErr = errors.New(fmt.Sprintf("Node with id %s is not responding in 3 sec", nodeInformation.Id))
 		})
+	event := schema.AuditEvent{
+		Time:       start.Unix(),
+		Action:     constants.AuditActionRemoteCommand,
+		Operator:   runByUser,
+		Target:     nodeInformation.Id,
+		Body:       auditCommandBody(clusterId, dataToSend),
+		Result:     "success",
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if mqErr != nil {
+		event.Result = mqErr.Error()
+	}
+	if err := cache.GetCurrentCache().AddAuditEvent(event); err != nil {
+		log.Errorf("Failed to save audit event of command sent to node %s due to error: %s", nodeInformation.Id, err.Error())
+	}
 	return mqErr
 }
+
+// auditCommandBody decodes task out of message body and returns commands of it with sensitive arguments redacted
+func auditCommandBody(clusterId string, dataToSend []byte) string {
+	body := schema.QueueBody{}
+	if err := json.Unmarshal(dataToSend, &body); err != nil {
+		return fmt.Sprintf("%d bytes of broken message, not recorded", len(dataToSend))
+	}
+	command := auditedCommand{ClusterId: clusterId, TaskType: body.TaskType}
+	if body.TaskType == constants.TaskTypeRunCommand {
+		task := schema.TaskRunCommand{}
+		if err := json.Unmarshal(body.TaskData, &task); err != nil {
+			return fmt.Sprintf("%d bytes of broken task, not recorded", len(body.TaskData))
+		}
+		command.Commands = map[int][]string{}
+		for index, args := range task.Commands {
+			command.Commands[index] = audit.RedactCommand(args)
+		}
+	}
+	data, err := json.Marshal(command)
+	if err != nil {
+		return fmt.Sprintf("%d bytes, not recorded", len(dataToSend))
+	}
+	if len(data) > audit.MaxBodySize {
+		return fmt.Sprintf("%d commands of %d bytes, not recorded", len(command.Commands), len(data))
+	}
+	return string(data)
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-installer/pkg/constants"
+	mqHelper "k8s-installer/pkg/server/message_queue"
+	"k8s-installer/schema"
+)
+
+func TestAuditCommandBody(t *testing.T) {
+	task := schema.TaskRunCommand{
+		TaskType: constants.TaskTypeRunCommand,
+		Commands: map[int][]string{
+			0: strings.Split("kubectl exec pod-a -c app -n default -- mysql --password=secret1", " "),
+		},
+	}
+	data, err := mqHelper.CreateMsgBody("kubectl-test", "", task, schema.Cluster{ClusterId: "cluster-a", Masters: []schema.ClusterNode{{NodeId: "master-a"}}}, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := auditCommandBody("cluster-a", []byte(data))
+	if strings.Contains(body, "secret1") {
+		t.Errorf("Expected password to be redacted from %s", body)
+	}
+	for _, kept := range []string{`"cluster_id":"cluster-a"`, "pod-a", "mysql"} {
+		if !strings.Contains(body, kept) {
+			t.Errorf("Expected %s to be kept in %s", kept, body)
+		}
+	}
+	if strings.Contains(body, "master-a") {
+		t.Errorf("Expected cluster of message not to be recorded, got %s", body)
+	}
+}
@@ -88,9 +88,11 @@ func KubectlExec(request *restful.Request, response *restful.Response) {
 	}
 
 	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	// kubectl exec skips authentication so user is often unknown, audit event of the api request keeps source ip of it
+	runByUser, _ := request.Attribute("run-by-user").(string)
 	var podList k8sCore.PodList
 	// sending message to get pod list
-	err = common.SendingSingleCommand(config.MessageQueue, *node, []byte(data), func(msg *natClient.Msg) error {
+	err = common.SendingSingleCommand(config.MessageQueue, *node, cluster.ClusterId, []byte(data), runByUser, func(msg *natClient.Msg) error {
 		var reply schema.QueueReply
 		if err := json.Unmarshal(msg.Data, &reply); err != nil {
 			return err
@@ -142,7 +144,7 @@ func KubectlExec(request *restful.Request, response *restful.Response) {
 				utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to create msg body to get port information due to error: %s", err.Error()))
 				return
 			}
-			err = common.SendingSingleCommand(config.MessageQueue, *node, []byte(data), func(msg *natClient.Msg) error {
+			err = common.SendingSingleCommand(config.MessageQueue, *node, cluster.ClusterId, []byte(data), runByUser, func(msg *natClient.Msg) error {
 				var reply schema.QueueReply
 				if err := json.Unmarshal(msg.Data, &reply); err != nil {
 					result = append(result, ErrorKubectlExecResult(pod.Name, err.Error()))
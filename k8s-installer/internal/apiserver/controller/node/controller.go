@@ -23,6 +23,7 @@ import (
 	"k8s-installer/pkg/constants"
 
 	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/rbac"
 	"k8s-installer/schema"
 
 	"github.com/emicklei/go-restful"
@@ -37,16 +38,23 @@ func ListNode(request *restful.Request, response *restful.Response) {
 	}
 
 	regionId := request.QueryParameter("region_id")
+	visible, err := visibleNodeFilter(request)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Internal server error %s", err.Error()))
+		return
+	}
 	nodeList := []schema.NodeInformation{}
 
 	if regionId == "" {
 		for _, node := range nodeMap {
-			nodeList = append(nodeList, node)
+			if visible(node) {
+				nodeList = append(nodeList, node)
+			}
 		}
 	} else {
 		for _, node := range nodeMap {
 			if node.Region != nil {
-				if node.Region.ID == regionId {
+				if node.Region.ID == regionId && visible(node) {
 					nodeList = append(nodeList, node)
 				}
 			}
@@ -56,6 +64,27 @@ func ListNode(request *restful.Request, response *restful.Response) {
 	response.WriteAsJson(nodeList)
 }
 
+// visibleNodeFilter tells which nodes caller may list, caller listing by role binding only sees nodes of clusters or regions it`s scope selects
+func visibleNodeFilter(request *restful.Request) (func(node schema.NodeInformation) bool, error) {
+	authorizer := utils.RequestAuthorizer(request)
+	if !authorizer.Scoped(constants.ChallengeCodeListNode) {
+		return func(node schema.NodeInformation) bool {
+			return true
+		}, nil
+	}
+	clusterMap, err := cache.GetCurrentCache().GetClusterCollection()
+	if err != nil {
+		return nil, err
+	}
+	return func(node schema.NodeInformation) bool {
+		var cluster *schema.Cluster
+		if found, isFound := clusterMap[node.BelongsToCluster]; isFound {
+			cluster = &found
+		}
+		return authorizer.Allowed(constants.ChallengeCodeListNode, rbac.NodeResource(node, cluster))
+	}, nil
+}
+
 func ListUsableNode(request *restful.Request, response *restful.Response) {
 	rawPageSize := request.QueryParameter("page_size")
 	if rawPageSize == "" {
@@ -211,7 +240,7 @@ func UpdateNodeRole(request *restful.Request, response *restful.Response) {
 	node.Role = nodePost.Role
 
 	if err := runtimeCache.SaveOrUpdateNodeInformation(nodeId, *node); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to update node %s due to error %s", nodeId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Failed to update node %s due to error %s", nodeId, err.Error()))
 		return
 	}
 
@@ -258,7 +287,7 @@ func EnableOrDisableNode(request *restful.Request, response *restful.Response) {
 	node.IsDisabled = request.SelectedRoutePath() == "/node/v1/disable/{node-id}"
 
 	if err := runtimeCache.SaveOrUpdateNodeInformation(nodeId, *node); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
 		return
 	}
 
@@ -352,7 +381,7 @@ func updateNodeStatus(request *restful.Request, response *restful.Response, disa
 	node.IsDisabled = disable
 
 	if err := runtimeCache.SaveOrUpdateNodeInformation(nodeId, *node); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
 		return
 	}
 
@@ -384,6 +413,7 @@ func NodeSSH(request *restful.Request, response *restful.Response) {
 		return
 	}
 	defer wsConn.Close()
+	defer auditSSHSession(request, credential, time.Now())
 
 	wsConn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	wsConn.SetPongHandler(func(appData string) error {
@@ -447,6 +477,26 @@ func NodeSSH(request *restful.Request, response *restful.Response) {
 	//<-quitChan
 }
 
+// auditSSHSession records ssh session once it ends, websocket upgrade is a GET so the audit filter never sees it
+func auditSSHSession(request *restful.Request, credential *schema.SSHCredential, start time.Time) {
+	operator, _ := request.Attribute("run-by-user").(string)
+	event := schema.AuditEvent{
+		Time:       start.Unix(),
+		Action:     constants.AuditActionSSHSession,
+		Operator:   operator,
+		SourceIP:   utils.RequestSourceIP(request),
+		Method:     request.Request.Method,
+		Path:       request.Request.URL.Path,
+		Target:     fmt.Sprintf("%s:%d", credential.IpAddress, credential.Port),
+		Result:     "success",
+		DurationMs: time.Since(start).Milliseconds(),
+		Message:    fmt.Sprintf("Ssh session to %s:%d as %s", credential.IpAddress, credential.Port, string(credential.Username)),
+	}
+	if err := cache.GetCurrentCache().AddAuditEvent(event); err != nil {
+		log.Errorf("Failed to save audit event of ssh session to %s due to error: %s", event.Target, err.Error())
+	}
+}
+
 func decodedMsgToSSH(msg string) (*schema.SSHCredential, error) {
 	c := &schema.SSHCredential{}
 	decoded, err := base64.StdEncoding.DecodeString(msg)
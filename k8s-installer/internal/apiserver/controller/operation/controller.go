@@ -1,6 +1,7 @@
 package operation
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -106,7 +107,7 @@ func ContinueOperation(request *restful.Request, response *restful.Response) {
 		return
 	}
 
-	if operation.Status != constants.StatusError {
+	if operation.Status != constants.StatusError && operation.Status != constants.StatusCancelled {
 		utils.ResponseError(response, http.StatusPreconditionFailed, "Operation already complete no need continue !")
 		return
 	}
@@ -152,3 +153,54 @@ func ContinueOperation(request *restful.Request, response *restful.Response) {
 
 	response.WriteHeader(http.StatusOK)
 }
+
+func CancelOperation(request *restful.Request, response *restful.Response) {
+	operationId := request.PathParameter("operation-id")
+	if strings.TrimSpace(operationId) == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "operation-id"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+
+	operation, errOperation := runtimeCache.GetOperation(operationId)
+	if errOperation != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Internal server error %s", errOperation.Error()))
+		return
+	}
+
+	if operation == nil {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find operation with id %s ", operationId))
+		return
+	}
+
+	if operation.Status != constants.StatusProcessing {
+		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Operation is in %s stat, only running operation can be cancelled", operation.Status))
+		return
+	}
+
+	runByUser := request.Attribute("run-by-user").(string)
+	if strings.TrimSpace(runByUser) == "" {
+		utils.ResponseError(response, http.StatusInternalServerError, "Failed to find run by user!!!")
+		return
+	}
+
+	err := control_manager.CancelOperation(operationId, runByUser)
+	if err == control_manager.ErrOperationNotRunning && operation.Host != "" && operation.Host != cache.NodeId {
+		// request may hit any server, the one running operation is asked to cancel it
+		err = control_manager.CancelOperationOnServer(operation.Host, operationId, runByUser)
+	}
+	if err != nil {
+		if err == control_manager.ErrOperationNotRunning {
+			utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Operation %s is not running on server %s which handles it", operationId, operation.Host))
+			return
+		}
+		if errors.Is(err, control_manager.ErrServerNotAnswering) {
+			utils.ResponseError(response, http.StatusServiceUnavailable, fmt.Sprintf("Unable to cancel operation due to error: %s", err.Error()))
+			return
+		}
+		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Unable to cancel operation due to error: %s", err.Error()))
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,207 @@
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/control_manager"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+)
+
+const progressKeepAliveInterval = 15 * time.Second
+
+var (
+	progressUpGrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024 * 64,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+)
+
+/*
+WatchOperationProgress streams live progress of an operation
+server-sent events by default or websocket if client asks for an upgrade
+client resumes with query parameter from_seq or header Last-Event-ID (the seq of the last event it got + 1 will be sent first)
+*/
+func WatchOperationProgress(request *restful.Request, response *restful.Response) {
+	operationId := request.PathParameter("operation-id")
+	if strings.TrimSpace(operationId) == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "operation-id"))
+		return
+	}
+
+	fromSeq, err := progressFromSeq(request)
+	if err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runtimeCache := cache.GetCurrentCache()
+	operation, err := runtimeCache.GetOperation(operationId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Internal server error %s", err.Error()))
+		return
+	}
+	if operation == nil {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find operation with id %s", operationId))
+		return
+	}
+
+	history, events, unsubscribe, err := control_manager.SubscribeOperationProgress(*operation, fromSeq)
+	if err != nil {
+		utils.ResponseError(response, http.StatusServiceUnavailable, fmt.Sprintf("Unable to get progress of operation %s from server %s which runs it due to error: %s", operationId, operation.Host, err.Error()))
+		return
+	}
+	defer unsubscribe()
+
+	if len(history) == 0 && operation.Status != constants.StatusProcessing {
+		// nothing kept in memory any more just tell client how it ends
+		history = []schema.OperationEvent{{
+			Seq:         fromSeq,
+			OperationId: operation.Id,
+			Type:        constants.OperationEventOperationDone,
+			StepIndex:   -1,
+			Status:      operation.Status,
+		}}
+	}
+
+	if websocket.IsWebSocketUpgrade(request.Request) {
+		streamProgressWithWebsocket(request, response, history, events)
+	} else {
+		streamProgressWithSSE(request, response, history, events)
+	}
+}
+
+func progressFromSeq(request *restful.Request) (uint64, error) {
+	if value := request.QueryParameter("from_seq"); value != "" {
+		fromSeq, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Query parameter from_seq %s is not a valid sequence number ", value)
+		}
+		return fromSeq, nil
+	}
+	if value := request.HeaderParameter("Last-Event-ID"); value != "" {
+		lastSeq, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Header Last-Event-ID %s is not a valid sequence number ", value)
+		}
+		return lastSeq + 1, nil
+	}
+	return 0, nil
+}
+
+func streamProgressWithSSE(request *restful.Request, response *restful.Response, history []schema.OperationEvent, events <-chan schema.OperationEvent) {
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		utils.ResponseError(response, http.StatusInternalServerError, "Streaming is not supported by current response writer")
+		return
+	}
+	response.AddHeader("Content-Type", "text/event-stream")
+	response.AddHeader("Cache-Control", "no-cache")
+	response.AddHeader("Connection", "keep-alive")
+	// disable nginx buffering otherwise events arrive in batch
+	response.AddHeader("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event schema.OperationEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(response, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	for _, event := range history {
+		if err := writeEvent(event); err != nil {
+			log.Debugf("Stop streaming operation progress due to error: %s", err.Error())
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(progressKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(event); err != nil {
+				log.Debugf("Stop streaming operation progress due to error: %s", err.Error())
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(response, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-request.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func streamProgressWithWebsocket(request *restful.Request, response *restful.Response, history []schema.OperationEvent, events <-chan schema.OperationEvent) {
+	wsConn, err := progressUpGrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		log.Errorf("Failed to upgrade operation progress connection to websocket due to error: %s", err.Error())
+		return
+	}
+	defer wsConn.Close()
+
+	// client never sends anything but read is required to handle close and pong message
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range history {
+		if err := wsConn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(progressKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				wsConn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				return
+			}
+			if err := wsConn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := wsConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case <-clientGone:
+			return
+		}
+	}
+}
@@ -187,7 +187,7 @@ func AssignNodeRegion(request *restful.Request, response *restful.Response) {
 	}
 
 	if err := runtimeCache.SaveOrUpdateNodeInformation(nodeID, *node); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to disable node %s due to error %s", nodeID, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Failed to disable node %s due to error %s", nodeID, err.Error()))
 		return
 	}
 
@@ -221,7 +221,7 @@ func ClearNodeRegion(request *restful.Request, response *restful.Response) {
 	}
 	node.Region = nil
 	if err := runtimeCache.SaveOrUpdateNodeInformation(nodeId, *node); err != nil {
-		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
+		utils.ResponseError(response, utils.StatusOfSaveError(err), fmt.Sprintf("Failed to disable node %s due to error %s", nodeId, err.Error()))
 		return
 	}
 	response.WriteAsJson(node)
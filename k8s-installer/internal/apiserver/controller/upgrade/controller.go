@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"k8s-installer/internal/apiserver/utils"
+	osInfoProvider "k8s-installer/node/os"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/control_manager"
@@ -74,6 +75,16 @@ func ApplyUpgradePlan(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	if utils.IsDryRun(request) {
+		operationPlan, err := control_manager.PlanApplyUpgrade(*plan, *clusterFound)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to render operation plan due to error: %s", err.Error()))
+			return
+		}
+		response.WriteAsJson(operationPlan)
+		return
+	}
+
 	// prepare backup before upgrading
 	if err = preBackup(planId, clusterFound, runByUser); err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("prepare backup faild before upgrading: %s", err.Error()))
@@ -97,6 +108,108 @@ func preBackup(planId string, cluster *schema.Cluster, runByUser string) error {
 	return control_manager.BackupCreateWait(backup, cluster, runByUser)
 }
 
+// PauseUpgradePlan asks a running upgrade to pause once the running batch passed health check
+func PauseUpgradePlan(request *restful.Request, response *restful.Response) {
+	planId := request.PathParameter("plan-id")
+	if strings.TrimSpace(planId) == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "plan id"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	plan, err := runtimeCache.GetUpgradePlan(planId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get upgrade plan detail due to error: %s", err.Error()))
+		return
+	} else if plan == nil {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Failed to find upgrade plan %s", planId))
+		return
+	} else if plan.Status != constants.StatusUpgrading {
+		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Only upgrading plan can be paused, current status is %s", plan.Status))
+		return
+	}
+
+	plan.PauseRequested = true
+	if err := runtimeCache.CreateOrUpdateUpgradePlan(*plan); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed save upgrade plan to db due to error: %s", err.Error()))
+		return
+	}
+	response.WriteAsJson(plan)
+}
+
+// ResumeUpgradePlan continues a paused upgrade from the next batch
+func ResumeUpgradePlan(request *restful.Request, response *restful.Response) {
+	planId := request.PathParameter("plan-id")
+	if strings.TrimSpace(planId) == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "plan id"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	plan, err := runtimeCache.GetUpgradePlan(planId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get upgrade plan detail due to error: %s", err.Error()))
+		return
+	} else if plan == nil {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Failed to find upgrade plan %s", planId))
+		return
+	} else if plan.Status != constants.StatusPaused {
+		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Only paused plan can be resumed, current status is %s", plan.Status))
+		return
+	}
+
+	clusterFound, err := runtimeCache.GetCluster(plan.ClusterId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to check cluster existence due to error: %s", err.Error()))
+		return
+	} else if clusterFound == nil {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Cluster %s not found.", plan.ClusterId))
+		return
+	}
+
+	runByUser := request.Attribute("run-by-user").(string)
+	if strings.TrimSpace(runByUser) == "" {
+		utils.ResponseError(response, http.StatusInternalServerError, "Failed to find run by user!!!")
+		return
+	}
+
+	if err := control_manager.ResumeUpgrade(plan, clusterFound, runByUser); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to resume upgrade due to error: %s", err.Error()))
+		return
+	}
+	response.WriteAsJson(plan)
+}
+
+// parseUpgradeStrategy reads rolling upgrade settings from query parameters, all of them are optional
+func parseUpgradeStrategy(request *restful.Request) (schema.UpgradeStrategy, error) {
+	strategy := schema.UpgradeStrategy{}
+	intParams := map[string]*int{
+		"batch_size":           &strategy.BatchSize,
+		"max_unavailable":      &strategy.MaxUnavailable,
+		"health_check_timeout": &strategy.HealthCheckTimeout,
+	}
+	for name, value := range intParams {
+		raw := strings.TrimSpace(request.QueryParameter(name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return strategy, fmt.Errorf("Query parameter %s should be a non-negative integer, got %s", name, raw)
+		}
+		*value = parsed
+	}
+	strategy.DisableHealthGate = strings.ToLower(request.QueryParameter("disable_health_gate")) == "true"
+	if raw := strings.TrimSpace(request.QueryParameter("pause_after_batches")); raw != "" {
+		for _, batch := range strings.Split(raw, ",") {
+			parsed, err := strconv.Atoi(strings.TrimSpace(batch))
+			if err != nil || parsed < 0 {
+				return strategy, fmt.Errorf("Query parameter pause_after_batches should be comma separated non-negative integers, got %s", raw)
+			}
+			strategy.PauseAfterBatches = append(strategy.PauseAfterBatches, parsed)
+		}
+	}
+	return strategy, nil
+}
+
 func DeleteUpgradePlan(request *restful.Request, response *restful.Response) {
 	planId := request.PathParameter("plan-id")
 	if strings.TrimSpace(planId) == "" {
@@ -113,6 +226,9 @@ func DeleteUpgradePlan(request *restful.Request, response *restful.Response) {
 	} else if result.Status == constants.StatusProcessing {
 		utils.ResponseError(response, http.StatusPreconditionFailed, "Cannot delete the upgrade plan which currently executing")
 		return
+	} else if result.Status == constants.StatusPaused {
+		utils.ResponseError(response, http.StatusPreconditionFailed, "Cannot delete the upgrade plan which is paused. Resume it to finish the upgrade")
+		return
 	} else if result.Status == constants.StatusError {
 		utils.ResponseError(response, http.StatusPreconditionFailed, "Cannot delete the upgrade plan which in error state. It mays leads to control plane version not match issue which will block all upgrade function to this cluster. You should try continue upgrade operation or inform your administrator")
 		return
@@ -399,6 +515,12 @@ func MakeUpgradePlan(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	strategy, err := parseUpgradeStrategy(request)
+	if err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if clusterFound.Mock {
 		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Unable to upgrade a mocked cluster '%s' ", clusterFound.ClusterId))
 		return
@@ -633,6 +755,7 @@ func MakeUpgradePlan(request *restful.Request, response *restful.Response) {
 		TargetVersion:     versionName,
 		MessagePlanResult: planResultMessage,
 		Status:            "ReadyToUpgrade",
+		Strategy:          strategy,
 	}
 
 	if isDepPackageReady && isImageReady {
@@ -695,6 +818,12 @@ func MakeUpgradePlanV2(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	strategy, err := parseUpgradeStrategy(request)
+	if err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if clusterFound.Mock {
 		utils.ResponseError(response, http.StatusPreconditionFailed, fmt.Sprintf("Unable to upgrade a mocked cluster '%s' ", clusterFound.ClusterId))
 		return
@@ -929,6 +1058,7 @@ func MakeUpgradePlanV2(request *restful.Request, response *restful.Response) {
 		TargetVersion:     versionName,
 		MessagePlanResult: planResultMessage,
 		Status:            "ReadyToUpgrade",
+		Strategy:          strategy,
 	}
 
 	if isDepPackageReady && isImageReady {
@@ -1053,29 +1183,45 @@ func checkNodePackage(versionSet schema.UpgradableVersion, node schema.NodeInfor
 		NodeIPV4: node.Ipv4DefaultIp,
 	}
 	var depPackages []schema.OSPackage
-	switch node.SystemInfo.OS.Vendor {
+	// packages are kept by os family and version e.g. rocky 8.6 -> el 8
+	osFamily, osVersion := osInfoProvider.NormalizeOSFamily(node.SystemInfo.OS.Vendor, node.SystemInfo.OS.Version)
+	var rpmOSVersions map[string]schema.OSFamilyCentosVersion
+	switch osFamily {
 	case constants.OSFamilyCentos:
 		if versionSet.Centos == nil {
 			return schema.UpgradeNodeCheckState{}, true
 		}
-		if cpuArch == constants.CpuArchX86 {
-			depPackages = versionSet.Centos.OSVersions[node.SystemInfo.OS.Version].X8664.RPMS
-		} else if cpuArch == constants.CpuAarch64 {
-			depPackages = versionSet.Centos.OSVersions[node.SystemInfo.OS.Version].Aarch64.RPMS
+		rpmOSVersions = versionSet.Centos.OSVersions
+	case constants.OSFamilyEL:
+		if versionSet.EL == nil {
+			return schema.UpgradeNodeCheckState{}, true
+		}
+		rpmOSVersions = versionSet.EL.OSVersions
+	case constants.OSFamilyOpenEuler:
+		if versionSet.OpenEuler == nil {
+			return schema.UpgradeNodeCheckState{}, true
 		}
+		rpmOSVersions = versionSet.OpenEuler.OSVersions
 	case constants.OSFamilyUbuntu:
-		if versionSet.Centos == nil {
+		if versionSet.Ubuntu == nil {
 			return schema.UpgradeNodeCheckState{}, true
 		}
 		if cpuArch == constants.CpuArchX86 {
-			depPackages = versionSet.Ubuntu.OSVersions[node.SystemInfo.OS.Version].X8664.Debs
+			depPackages = versionSet.Ubuntu.OSVersions[osVersion].X8664.Debs
+		} else if cpuArch == constants.CpuAarch64 {
+			depPackages = versionSet.Ubuntu.OSVersions[osVersion].Aarch64.Debs
+		}
+	}
+	if rpmOSVersions != nil {
+		if cpuArch == constants.CpuArchX86 {
+			depPackages = rpmOSVersions[osVersion].X8664.RPMS
 		} else if cpuArch == constants.CpuAarch64 {
-			depPackages = versionSet.Ubuntu.OSVersions[node.SystemInfo.OS.Version].Aarch64.Debs
+			depPackages = rpmOSVersions[osVersion].Aarch64.RPMS
 		}
 	}
 
 	var result []schema.PackageCheckState
-	result, isAllNodesReady = checkUpgradeDepPkgWithOSFamily(depPackages, resourceServerAddress, node.SystemInfo.OS.Vendor, node.SystemInfo.OS.Version, versionSet.Name, cpuArch)
+	result, isAllNodesReady = checkUpgradeDepPkgWithOSFamily(depPackages, resourceServerAddress, osFamily, osVersion, versionSet.Name, cpuArch)
 	nodeCheckState.PackageCheckResult = result
 	return nodeCheckState, isAllNodesReady
 }
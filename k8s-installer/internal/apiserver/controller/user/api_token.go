@@ -0,0 +1,308 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	apiToken "k8s-installer/pkg/api_token"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+// runBy returns user of the request and permission it is authorized with, permission of api token is already narrowed down
+func runBy(request *restful.Request) (string, uint64) {
+	operator, _ := request.Attribute("run-by-user").(string)
+	permission, _ := request.Attribute("run-by-permission").(uint64)
+	return operator, permission
+}
+
+/*
+CreateAPIToken issues token acting as current user or as a service account
+token of service account requires permission to manage users
+*/
+func CreateAPIToken(request *restful.Request, response *restful.Response) {
+	operator, permission := runBy(request)
+	// token must not be able to extend it`s own life
+	if request.Attribute("api-token-id") != nil {
+		utils.ResponseError(response, http.StatusForbidden, "Api token cannot be used to create api tokens, please login instead")
+		return
+	}
+	tokenPost := schema.APIToken{}
+	if err := request.ReadEntity(&tokenPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	tokenPost.Name = strings.TrimSpace(tokenPost.Name)
+	if err := utils.Validate(tokenPost); err != nil {
+		utils.SchemaValidationFailedResponseError(response, err.Error())
+		return
+	}
+	if tokenPost.Owner == "" {
+		tokenPost.Owner = operator
+	}
+	now := time.Now()
+	if tokenPost.ExpiresAt != 0 && tokenPost.ExpiresAt <= now.Unix() {
+		utils.ResponseError(response, http.StatusBadRequest, "Expiry of api token has to be in the future")
+		return
+	}
+
+	runtimeCache := cache.GetCurrentCache()
+	owner, err := runtimeCache.GetUser(tokenPost.Owner)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
+		return
+	}
+	if owner == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Cannot found user: %s", tokenPost.Owner))
+		return
+	}
+	if owner.Username != operator {
+		if owner.Source != constants.UserSourceServiceAccount {
+			utils.ResponseError(response, http.StatusForbidden, "Api token can only be created for yourself or for a service account")
+			return
+		}
+		if permission&constants.ChallengeCodeManageUser == 0 {
+			utils.ResponseError(response, http.StatusForbidden, "You do not have the proper permission to create api token of service account")
+			return
+		}
+	}
+	roles, errRoles := tokenRoles(tokenPost.Roles, owner.Roles)
+	if errRoles != nil {
+		utils.ResponseError(response, http.StatusBadRequest, errRoles.Error())
+		return
+	}
+
+	id, token, hash, err := apiToken.Generate()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to generate api token due to error: %s", err.Error()))
+		return
+	}
+	record := schema.APIToken{
+		Id:        id,
+		Name:      tokenPost.Name,
+		Owner:     owner.Username,
+		Roles:     roles,
+		ExpiresAt: tokenPost.ExpiresAt,
+		CreatedAt: now.Unix(),
+		CreatedBy: operator,
+		Hash:      hash,
+	}
+	if err := runtimeCache.SaveOrUpdateAPIToken(record); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save api token due to error: %s", err.Error()))
+		return
+	}
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionTokenCreate,
+		Username: owner.Username,
+		Operator: operator,
+		SourceIP: requestSourceIP(request),
+		Message:  fmt.Sprintf("Api token %s %s of %s is created by %s", record.Id, record.Name, owner.Username, operator),
+	})
+	record.Hash = ""
+	response.WriteAsJson(schema.APITokenCreated{APIToken: record, Token: token})
+}
+
+// tokenRoles checks requested roles are held by owner, owner roles are taken when none is requested
+func tokenRoles(requested, ownerRoles []schema.Role) ([]schema.Role, error) {
+	held := map[string]schema.Role{}
+	for _, role := range ownerRoles {
+		held[role.Id] = schema.Role{Id: role.Id, Name: role.Name}
+	}
+	if len(requested) == 0 {
+		requested = ownerRoles
+	}
+	var result []schema.Role
+	for _, role := range requested {
+		found, isFound := held[role.Id]
+		if !isFound {
+			return nil, fmt.Errorf("Role %s is not held by owner of the token", role.Id)
+		}
+		result = append(result, found)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Owner of the token holds no role")
+	}
+	return result, nil
+}
+
+// ListAPIToken lists tokens of current user, user allowed to manage users sees every token
+func ListAPIToken(request *restful.Request, response *restful.Response) {
+	operator, permission := runBy(request)
+	owner := request.QueryParameter("owner")
+	tokens, err := cache.GetCurrentCache().GetAPITokenCollection()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get api tokens due to error: %s", err.Error()))
+		return
+	}
+	result := []schema.APIToken{}
+	for _, token := range tokens {
+		if token.Owner != operator && permission&constants.ChallengeCodeManageUser == 0 {
+			continue
+		}
+		if owner != "" && token.Owner != owner {
+			continue
+		}
+		token.Hash = ""
+		result = append(result, token)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt < result[j].CreatedAt
+	})
+	response.WriteAsJson(result)
+}
+
+func RevokeAPIToken(request *restful.Request, response *restful.Response) {
+	operator, permission := runBy(request)
+	id := strings.TrimSpace(request.PathParameter("token-id"))
+	if id == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "token-id"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	token, err := runtimeCache.GetAPIToken(id)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get api token due to error: %s", err.Error()))
+		return
+	}
+	// token of others is reported as missing rather than forbidden so ids cannot be probed
+	if token == nil || (token.Owner != operator && permission&constants.ChallengeCodeManageUser == 0) {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Cannot found api token: %s", id))
+		return
+	}
+	if token.RevokedAt == 0 {
+		revokeAPIToken(*token, operator, requestSourceIP(request))
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func revokeAPIToken(token schema.APIToken, operator, sourceIP string) {
+	token.RevokedAt = time.Now().Unix()
+	if err := cache.GetCurrentCache().SaveOrUpdateAPIToken(token); err != nil {
+		log.Errorf("Failed to revoke api token %s due to error: %s", token.Id, err.Error())
+		return
+	}
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionTokenRevoke,
+		Username: token.Owner,
+		Operator: operator,
+		SourceIP: sourceIP,
+		Message:  fmt.Sprintf("Api token %s %s of %s is revoked by %s", token.Id, token.Name, token.Owner, operator),
+	})
+}
+
+// revokeOwnerAPITokens revokes every token of deleted user or service account
+func revokeOwnerAPITokens(owner, operator, sourceIP string) {
+	tokens, err := cache.GetCurrentCache().GetAPITokenCollection()
+	if err != nil {
+		log.Errorf("Failed to get api tokens of %s due to error: %s", owner, err.Error())
+		return
+	}
+	for _, token := range tokens {
+		if token.Owner == owner && token.RevokedAt == 0 {
+			revokeAPIToken(token, operator, sourceIP)
+		}
+	}
+}
+
+/*
+CreateOrUpdateServiceAccount creates user without password for automation, it only authenticates with api token
+posting an existing service account replaces it`s roles
+*/
+func CreateOrUpdateServiceAccount(request *restful.Request, response *restful.Response) {
+	accountPost := schema.User{}
+	if err := request.ReadEntity(&accountPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	accountPost.Username = strings.TrimSpace(accountPost.Username)
+	if accountPost.Username == "" {
+		utils.ResponseError(response, http.StatusBadRequest, "Service account name cannot be empty")
+		return
+	}
+	if len(accountPost.Roles) == 0 {
+		utils.ResponseError(response, http.StatusBadRequest, "Service account requires at least one role")
+		return
+	}
+	if roleCheckResult := checkRoleExistence(accountPost.Roles); len(roleCheckResult) > 0 {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Following roles id check failed: %s", strings.Join(roleCheckResult, " , ")))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	existing, err := runtimeCache.GetUser(accountPost.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
+		return
+	}
+	account := schema.User{
+		Id:       fmt.Sprintf("%v", hash(accountPost.Username)),
+		Username: accountPost.Username,
+		Source:   constants.UserSourceServiceAccount,
+	}
+	if existing != nil {
+		if existing.Source != constants.UserSourceServiceAccount {
+			utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("User %v already exists", existing.Username))
+			return
+		}
+		account = *existing
+	}
+	account.Roles = accountPost.Roles
+	// empty password never matches so service account cannot login
+	account.Password = ""
+	if err := runtimeCache.CreateOrUpdateUser(account.Id, account); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save service account due to %v", err))
+		return
+	}
+	response.WriteAsJson(account)
+}
+
+func ListServiceAccount(request *restful.Request, response *restful.Response) {
+	users, err := cache.GetCurrentCache().GetUserList()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user list due to %v", err))
+		return
+	}
+	result := []schema.User{}
+	for _, u := range users {
+		if u.Source == constants.UserSourceServiceAccount {
+			u.Password = ""
+			result = append(result, u)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Username < result[j].Username
+	})
+	response.WriteAsJson(result)
+}
+
+func DeleteServiceAccount(request *restful.Request, response *restful.Response) {
+	name := strings.TrimSpace(request.PathParameter("name"))
+	if name == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "name"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	account, err := runtimeCache.GetUser(name)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
+		return
+	}
+	if account == nil || account.Source != constants.UserSourceServiceAccount {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Cannot found service account: %s", name))
+		return
+	}
+	runtimeCache.DeleteUser(account.Username)
+	operator, _ := runBy(request)
+	revokeOwnerAPITokens(account.Username, operator, requestSourceIP(request))
+	deleteRoleBindings(func(binding schema.RoleBinding) bool {
+		return binding.Username == account.Username
+	}, operator, requestSourceIP(request))
+	response.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"testing"
+
+	"k8s-installer/schema"
+)
+
+func TestTokenRoles(t *testing.T) {
+	ownerRoles := []schema.Role{{Id: "1", Name: "admin", Function: 3}, {Id: "2", Name: "viewer"}}
+	roles, err := tokenRoles(nil, ownerRoles)
+	if err != nil || len(roles) != 2 || roles[0].Function != 0 {
+		t.Fatalf("Expected all roles of owner without function, got %v %v", roles, err)
+	}
+	roles, err = tokenRoles([]schema.Role{{Id: "2"}}, ownerRoles)
+	if err != nil || len(roles) != 1 || roles[0].Name != "viewer" {
+		t.Fatalf("Expected viewer role only, got %v %v", roles, err)
+	}
+	if _, err := tokenRoles([]schema.Role{{Id: "3"}}, ownerRoles); err == nil {
+		t.Fatal("Expected role not held by owner to be refused")
+	}
+	if _, err := tokenRoles(nil, nil); err == nil {
+		t.Fatal("Expected owner without role to be refused")
+	}
+}
@@ -0,0 +1,190 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	authProvider "k8s-installer/pkg/auth_provider"
+	"k8s-installer/pkg/cache"
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	oidcStateCookie = "k8s-installer-oidc-state"
+	oidcNonceCookie = "k8s-installer-oidc-nonce"
+	// seconds user may stay on idp login page
+	oidcCookieMaxAge = 600
+)
+
+func findAuthProvider(name string) *apiServerConfig.AuthProvider {
+	if name == "" {
+		return nil
+	}
+	return authProvider.FindProvider(cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId).ApiServer.AuthProviders, name)
+}
+
+// providerAuthenticate checks username and password against ldap provider and provisions the user
+func providerAuthenticate(response *restful.Response, guard loginGuard, provider apiServerConfig.AuthProvider, loginPost schema.LoginRequest) *schema.User {
+	if provider.Type != constants.AuthProviderTypeLDAP {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Auth provider %s does not accept password, login through /oidc/%s/login instead", provider.Name, provider.Name))
+		return nil
+	}
+	identity, err := authProvider.LDAPAuthenticate(*provider.LDAP, loginPost.Username, loginPost.Password)
+	if err != nil {
+		log.Errorf("Ldap auth provider %s failed due to error: %s", provider.Name, err.Error())
+		utils.ResponseError(response, http.StatusBadGateway, fmt.Sprintf("Auth provider %s is not available", provider.Name))
+		return nil
+	}
+	if identity == nil {
+		guard.fail()
+		utils.ResponseError(response, http.StatusUnauthorized, "Username or password incorrect")
+		return nil
+	}
+	user, status, err := provisionExternalUser(provider, *identity)
+	if err != nil {
+		utils.ResponseError(response, status, err.Error())
+		return nil
+	}
+	return user
+}
+
+// OIDCLogin redirects browser to login page of oidc provider
+func OIDCLogin(request *restful.Request, response *restful.Response) {
+	provider := findAuthProvider(request.PathParameter("provider"))
+	if provider == nil || provider.Type != constants.AuthProviderTypeOIDC {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Oidc auth provider %s is not configured", request.PathParameter("provider")))
+		return
+	}
+	client, err := authProvider.GetOIDCClient(request.Request.Context(), *provider.OIDC)
+	if err != nil {
+		log.Error(err)
+		utils.ResponseError(response, http.StatusBadGateway, fmt.Sprintf("Auth provider %s is not available", provider.Name))
+		return
+	}
+	state, nonce := randomToken(), randomToken()
+	setOIDCCookie(request, response, oidcStateCookie, state, oidcCookieMaxAge)
+	setOIDCCookie(request, response, oidcNonceCookie, nonce, oidcCookieMaxAge)
+	http.Redirect(response.ResponseWriter, request.Request, client.AuthCodeURL(state, nonce), http.StatusFound)
+}
+
+// OIDCCallback is where oidc provider sends browser back with authorization code
+func OIDCCallback(request *restful.Request, response *restful.Response) {
+	provider := findAuthProvider(request.PathParameter("provider"))
+	if provider == nil || provider.Type != constants.AuthProviderTypeOIDC {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Oidc auth provider %s is not configured", request.PathParameter("provider")))
+		return
+	}
+	if errCode := request.QueryParameter("error"); errCode != "" {
+		utils.ResponseError(response, http.StatusUnauthorized, fmt.Sprintf("Auth provider %s refused login: %s %s", provider.Name, errCode, request.QueryParameter("error_description")))
+		return
+	}
+	// state bound to browser cookie stops login csrf
+	state, errState := request.Request.Cookie(oidcStateCookie)
+	nonce, errNonce := request.Request.Cookie(oidcNonceCookie)
+	if errState != nil || errNonce != nil || state.Value == "" || state.Value != request.QueryParameter("state") {
+		utils.ResponseError(response, http.StatusBadRequest, "Oidc login state does not match, please start login again")
+		return
+	}
+	setOIDCCookie(request, response, oidcStateCookie, "", -1)
+	setOIDCCookie(request, response, oidcNonceCookie, "", -1)
+
+	client, err := authProvider.GetOIDCClient(request.Request.Context(), *provider.OIDC)
+	if err != nil {
+		log.Error(err)
+		utils.ResponseError(response, http.StatusBadGateway, fmt.Sprintf("Auth provider %s is not available", provider.Name))
+		return
+	}
+	identity, err := client.Exchange(request.Request.Context(), request.QueryParameter("code"), nonce.Value)
+	if err != nil {
+		log.Warnf("Oidc login through auth provider %s failed due to error: %s", provider.Name, err.Error())
+		utils.ResponseError(response, http.StatusUnauthorized, err.Error())
+		return
+	}
+	user, status, err := provisionExternalUser(*provider, *identity)
+	if err != nil {
+		utils.ResponseError(response, status, err.Error())
+		return
+	}
+	// second factor of oidc login is up to the provider, installer totp only guards password logins
+	writeLoginResponse(response, user)
+}
+
+/*
+provisionExternalUser creates or refreshes installer user of an identity vouched by auth provider
+roles are always taken from group mapping so changes in directory apply on next login
+local user with same name is never taken over
+*/
+func provisionExternalUser(provider apiServerConfig.AuthProvider, identity authProvider.Identity) (*schema.User, int, error) {
+	runtimeCache := cache.GetCurrentCache()
+	existing, err := runtimeCache.GetUser(identity.Username)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to get user information due to error: %s", err.Error())
+	}
+	if existing != nil && existing.Source != provider.Name {
+		return nil, http.StatusForbidden, fmt.Errorf("User %s already exists and is not managed by auth provider %s", identity.Username, provider.Name)
+	}
+	if existing == nil && !provider.JITProvisioning {
+		return nil, http.StatusForbidden, fmt.Errorf("User %s is not provisioned and auth provider %s does not create users on login", identity.Username, provider.Name)
+	}
+	roles, err := runtimeCache.GetRoleList()
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to get role list due to error: %s", err.Error())
+	}
+	user := schema.User{
+		Id:       fmt.Sprintf("%v", hash(identity.Username)),
+		Username: identity.Username,
+		Source:   provider.Name,
+	}
+	if existing != nil {
+		user = *existing
+	}
+	user.Roles = authProvider.MapRoles(provider, identity.Groups, roles)
+	if len(user.Roles) == 0 {
+		return nil, http.StatusForbidden, fmt.Errorf("None of groups of user %s is mapped to installer role", identity.Username)
+	}
+	if existing != nil && !sameRoles(existing.Roles, user.Roles) {
+		user.TokensValidAfter = time.Now().Unix()
+	}
+	if identity.Phone != "" {
+		user.Phone = identity.Phone
+	}
+	if err := runtimeCache.CreateOrUpdateUser(user.Id, user); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to save user %s due to error: %s", user.Username, err.Error())
+	}
+	if existing == nil {
+		log.Infof("User %s is created by auth provider %s", user.Username, provider.Name)
+	}
+	user.Password = ""
+	return &user, http.StatusOK, nil
+}
+
+func setOIDCCookie(request *restful.Request, response *restful.Response, name, value string, maxAge int) {
+	http.SetCookie(response.ResponseWriter, &http.Cookie{
+		Name:  name,
+		Value: value,
+		Path:  "/",
+		// negative max age removes the cookie
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   request.Request.TLS != nil,
+		// lax so cookie is sent when idp redirects browser back
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func randomToken() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatalf("Failed to read random bytes due to error: %s", err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -21,6 +21,7 @@ import (
 
 	"k8s-installer/pkg/cache"
 	jwtPkg "k8s-installer/pkg/jwt"
+	"k8s-installer/pkg/password"
 	"k8s-installer/schema"
 
 	"github.com/dgrijalva/jwt-go"
@@ -65,6 +66,13 @@ func SendMesssageCode(request *restful.Request, response *restful.Response) {
 		phone = userInfo.Phone
 	}
 
+	// every code sent counts as an attempt so codes cannot be requested in a loop
+	guard := newLoginGuard(request, constants.LoginAttemptSMSPrefix+phone, userPost.Username)
+	if !guard.allow(response) {
+		return
+	}
+	guard.fail()
+
 	msgUrl := url.URL{
 		Scheme:   "http",
 		Host:     conf.MesssagePlatformHost,
@@ -112,6 +120,11 @@ func ValidateMesssageCode(request *restful.Request, response *restful.Response)
 		phone = userInfo.Phone
 	}
 
+	guard := newLoginGuard(request, constants.LoginAttemptSMSPrefix+phone, msgPost.UserName)
+	if !guard.allow(response) {
+		return
+	}
+
 	msgUrl := url.URL{
 		Scheme:   "http",
 		Host:     conf.MesssagePlatformHost,
@@ -123,6 +136,11 @@ func ValidateMesssageCode(request *restful.Request, response *restful.Response)
 		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Messsage request failed: %s", err.Error()))
 		return
 	}
+	if msgResp.DataObject.Result {
+		guard.succeed()
+	} else {
+		guard.fail()
+	}
 	response.WriteAsJson(msgResp)
 }
 
@@ -169,34 +187,88 @@ func GetAuthType(request *restful.Request, response *restful.Response) {
 		return
 	}
 	resp := schema.SystemAuthType{
-		Standard: true,
-		CaaS:     ksHost != "",
+		Standard:  true,
+		CaaS:      ksHost != "",
+		Providers: []schema.AuthProviderInfo{},
+	}
+	for _, provider := range cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId).ApiServer.AuthProviders {
+		resp.Providers = append(resp.Providers, schema.AuthProviderInfo{Name: provider.Name, Type: provider.Type})
 	}
 	response.WriteHeaderAndEntity(http.StatusOK, resp)
 }
 
-func standardLogin(request *restful.Request, response *restful.Response) {
-	userPost := schema.User{}
-	if err := request.ReadEntity(&userPost); err != nil {
+// passwordLogin authenticates user by password and by totp code when user has mfa enabled
+func passwordLogin(request *restful.Request, response *restful.Response) {
+	loginPost := schema.LoginRequest{}
+	if err := request.ReadEntity(&loginPost); err != nil {
 		utils.ResponseError(response, http.StatusBadRequest, err.Error())
 		return
 	}
-	if userPost.Password == "" {
-		utils.ResponseError(response, http.StatusBadRequest, "Password cannot be empty")
+	user, guard := authenticatePassword(request, response, loginPost)
+	if user == nil {
+		return
+	}
+	if !checkSecondFactor(response, guard, user, loginPost) {
 		return
 	}
+	guard.succeed()
+	writeLoginResponse(response, user)
+}
+
+/*
+authenticatePassword checks username and password of local user or of ldap provider named by X-Auth-Type header
+error response is written when it returns nil user
+failures are counted by returned guard, caller clears them once every factor passes
+*/
+func authenticatePassword(request *restful.Request, response *restful.Response, loginPost schema.LoginRequest) (*schema.User, loginGuard) {
+	guard := newLoginGuard(request, constants.LoginAttemptUserPrefix+loginPost.Username, loginPost.Username)
+	if loginPost.Password == "" {
+		utils.ResponseError(response, http.StatusBadRequest, "Password cannot be empty")
+		return nil, guard
+	}
+	if !guard.allow(response) {
+		return nil, guard
+	}
+	if provider := findAuthProvider(request.HeaderParameter(constants.AuthTypeHeader)); provider != nil {
+		return providerAuthenticate(response, guard, *provider, loginPost), guard
+	}
 	runtimeCache := cache.GetCurrentCache()
-	user, err := runtimeCache.Login(userPost.Username, userPost.Password)
+	user, err := runtimeCache.Login(loginPost.Username, loginPost.Password)
 
 	if err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
-		return
+		return nil, guard
 	}
 
 	if user == nil {
+		guard.fail()
 		utils.ResponseError(response, http.StatusUnauthorized, "Username or password incorrect")
+		return nil, guard
+	}
+
+	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	// internal user admin cannot be modified through api so it is exempted from expiry
+	if strings.ToLower(user.Username) != "admin" && password.IsExpired(config.ApiServer.PasswordPolicy, user.PasswordChangedAt, time.Now()) {
+		utils.ResponseError(response, http.StatusForbidden, "Password expired, please ask administrator to reset it")
+		return nil, guard
+	}
+	return user, guard
+}
+
+// writeLoginResponse starts a login session for authenticated user and writes it`s tokens
+func writeLoginResponse(response *restful.Response, user *schema.User) {
+	session, refreshToken, err := newSession(user.Username, time.Now())
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to create login session due to error: %s", err.Error()))
 		return
 	}
+	writeTokenResponse(response, user, session, refreshToken)
+}
+
+// writeTokenResponse signs access token of the session and writes it together with refresh token and permission of the user
+func writeTokenResponse(response *restful.Response, user *schema.User, session schema.Session, refreshToken string) {
+	runtimeCache := cache.GetCurrentCache()
+	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
 
 	rolesMapping, errRole := runtimeCache.GetRoleList()
 	if errRole != nil {
@@ -213,15 +285,25 @@ func standardLogin(request *restful.Request, response *restful.Response) {
 		}
 	}
 
-	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
 	signString, err := base64.StdEncoding.DecodeString(config.ApiServer.JWTSignString)
 	if err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, "Failed to decode jwt sign string")
 		return
 	}
 
-	expired := time.Now().Add(time.Duration(config.ApiServer.JWTExpiredAfterHours) * time.Hour).Unix()
+	now := time.Now()
+	lifetime := time.Duration(config.ApiServer.JWTExpiredAfterHours) * time.Hour
+	if config.ApiServer.AccessTokenExpiredAfterMinutes > 0 {
+		lifetime = time.Duration(config.ApiServer.AccessTokenExpiredAfterMinutes) * time.Minute
+	}
+	expired := now.Add(lifetime).Unix()
+	// access token never outlives it`s session
+	if expired > session.ExpiresAt {
+		expired = session.ExpiresAt
+	}
 	user.StandardClaims = jwt.StandardClaims{
+		Id:        session.Id,
+		IssuedAt:  now.Unix(),
 		ExpiresAt: expired,
 	}
 	signedToken, errJWT := jwtPkg.CreateJWT(user, string(signString), config.ApiServer.JWTSignMethod)
@@ -230,33 +312,27 @@ func standardLogin(request *restful.Request, response *restful.Response) {
 		return
 	}
 
-	// unset password for security consideration
-	user.Password = ""
-
-	expiredString := time.Unix(expired, 0).Format("2006-01-02T15:04:05Z07:00")
-	response.WriteAsJson(struct {
-		Token       string      `json:"token"`
-		ExpiredDate string      `json:"expired_date"`
-		Permission  uint64      `json:"permission"`
-		User        schema.User `json:"user"`
-	}{
-		Token:       signedToken,
-		ExpiredDate: expiredString,
-		Permission:  userPermission,
-		User:        *user,
+	response.WriteAsJson(schema.LoginResponse{
+		Token:              signedToken,
+		ExpiredDate:        time.Unix(expired, 0).Format("2006-01-02T15:04:05Z07:00"),
+		RefreshToken:       refreshToken,
+		RefreshExpiredDate: time.Unix(session.ExpiresAt, 0).Format("2006-01-02T15:04:05Z07:00"),
+		Permission:         userPermission,
+		User:               *user,
 	})
 }
 
 func Login(request *restful.Request, response *restful.Response) {
 	switch request.HeaderParameter(constants.AuthTypeHeader) {
 	case constants.StandardAuth:
-		standardLogin(request, response)
+		passwordLogin(request, response)
 	case constants.CaaSAuth:
 		ksOauth(request, response)
 	default:
+		// ldap provider named by header is picked up by passwordLogin as well
 		// wait front-end integrate, then return error , now workarround
 		// utils.ResponseError(response, http.StatusInternalServerError, "This authentication method does not support")
-		standardLogin(request, response)
+		passwordLogin(request, response)
 	}
 }
 
@@ -338,6 +414,19 @@ func CreateOrUpdateUser(request *restful.Request, response *restful.Response) {
 	}
 
 	runtimeCache := cache.GetCurrentCache()
+	policy := runtimeCache.GetServerRuntimeConfig(cache.NodeId).ApiServer.PasswordPolicy
+	if err := password.CheckPolicy(policy, userPost.Username, userPost.Password); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userPost.Password, err = password.Hash(userPost.Password); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to hash password due to %v", err))
+		return
+	}
+	userPost.PasswordChangedAt = time.Now().Unix()
+	// users of auth provider are only created by their first login
+	userPost.Source = ""
+
 	u, err := runtimeCache.GetUser(userPost.Username)
 	if err != nil {
 		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user due to %v", err))
@@ -352,12 +441,19 @@ func CreateOrUpdateUser(request *restful.Request, response *restful.Response) {
 				utils.ResponseError(response, http.StatusBadRequest, "Cannot create or modify internal user admin")
 				return
 			}
+			if u.Source != "" {
+				utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("User %s is managed by auth provider %s and cannot be modified", u.Username, u.Source))
+				return
+			}
 			// check role id exists
 			roleCheckResult := checkRoleExistence(userPost.Roles)
 			u.Roles = userPost.Roles
 			u.Password = userPost.Password
+			u.PasswordChangedAt = userPost.PasswordChangedAt
 			u.Phone = userPost.Phone
 			u.KsAccount = u.KsAccount
+			// password and roles are replaced, tokens issued before no longer represent the user
+			u.TokensValidAfter = time.Now().Unix()
 
 			if len(roleCheckResult) > 0 {
 				utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("(ignored) Following roles id check failed: %s", strings.Join(roleCheckResult, " , ")))
@@ -367,6 +463,7 @@ func CreateOrUpdateUser(request *restful.Request, response *restful.Response) {
 				utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to create new user due to %v", err))
 				return
 			} else {
+				u.Password = ""
 				response.WriteAsJson(u)
 				return
 			}
@@ -401,6 +498,7 @@ func CreateOrUpdateUser(request *restful.Request, response *restful.Response) {
 				utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to create new user due to %v", err))
 				return
 			} else {
+				userPost.Password = ""
 				response.WriteAsJson(userPost)
 			}
 		}
@@ -434,6 +532,7 @@ func ListUser(request *restful.Request, response *restful.Response) {
 			}
 		}
 
+		u.Password = ""
 		ulj = append(ulj, u)
 	}
 
@@ -465,6 +564,15 @@ func DeleteUser(request *restful.Request, response *restful.Response) {
 	}
 
 	runtimeCache.DeleteUser(username)
+	if err := runtimeCache.DeleteUserMFA(username); err != nil {
+		log.Errorf("Failed to delete mfa of user %s due to error: %s", username, err.Error())
+	}
+	revokeUserSessions(username)
+	operator, _ := runBy(request)
+	revokeOwnerAPITokens(username, operator, requestSourceIP(request))
+	deleteRoleBindings(func(binding schema.RoleBinding) bool {
+		return binding.Username == username
+	}, operator, requestSourceIP(request))
 	response.WriteHeader(http.StatusOK)
 }
 
@@ -486,6 +594,7 @@ func getStandardUser(request *restful.Request, response *restful.Response) {
 		return
 	}
 
+	user.Password = ""
 	response.WriteAsJson(user)
 }
 
@@ -698,6 +807,10 @@ func DeleteRole(request *restful.Request, response *restful.Response) {
 	}
 
 	runtimeCache.DeleteRole(roleId)
+	operator, _ := runBy(request)
+	deleteRoleBindings(func(binding schema.RoleBinding) bool {
+		return binding.RoleId == roleId
+	}, operator, requestSourceIP(request))
 }
 
 func GetRole(request *restful.Request, response *restful.Response) {
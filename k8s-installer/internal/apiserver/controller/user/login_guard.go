@@ -0,0 +1,201 @@
+package user
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/cache"
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+/*
+loginGuard throttles login and sms code attempts of one subject e.g. user or phone together with source ip of the request
+attempts are kept in cache layer so every api server shares them
+*/
+type loginGuard struct {
+	protection apiServerConfig.LoginProtection
+	// subject key e.g. user:admin and it`s failure limit, source ip is always tracked as well
+	keys     []string
+	limits   map[string]int
+	sourceIP string
+	username string
+}
+
+func newLoginGuard(request *restful.Request, subjectKey, username string) loginGuard {
+	protection := cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId).ApiServer.LoginProtection
+	sourceIP := requestSourceIP(request)
+	ipKey := constants.LoginAttemptIPPrefix + sourceIP
+	return loginGuard{
+		protection: protection,
+		keys:       []string{subjectKey, ipKey},
+		limits:     map[string]int{subjectKey: protection.MaxUserFailures, ipKey: protection.MaxSourceFailures},
+		sourceIP:   sourceIP,
+		username:   username,
+	}
+}
+
+// requestSourceIP takes peer address only, forwarded headers are set by client and cannot be trusted
+func requestSourceIP(request *restful.Request) string {
+	host, _, err := net.SplitHostPort(request.Request.RemoteAddr)
+	if err != nil {
+		return request.Request.RemoteAddr
+	}
+	return host
+}
+
+// allow writes 429 with Retry-After and returns false when subject or source ip has to wait
+func (guard loginGuard) allow(response *restful.Response) bool {
+	runtimeCache := cache.GetCurrentCache()
+	now := time.Now()
+	for _, key := range guard.keys {
+		attempt, err := runtimeCache.GetLoginAttempt(key)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get login attempt due to error: %s", err.Error()))
+			return false
+		}
+		if attempt == nil {
+			continue
+		}
+		if wait := attemptWait(*attempt, now); wait > 0 {
+			response.AddHeader("Retry-After", strconv.Itoa(int(wait.Seconds())))
+			if attempt.LockedUntil > now.Unix() {
+				utils.ResponseError(response, http.StatusTooManyRequests, fmt.Sprintf("Too many failed attempts, %s is locked until %s", key, time.Unix(attempt.LockedUntil, 0).Format(time.RFC3339)))
+			} else {
+				utils.ResponseError(response, http.StatusTooManyRequests, fmt.Sprintf("Too many failed attempts, retry after %d seconds", int(wait.Seconds())))
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func (guard loginGuard) fail() {
+	runtimeCache := cache.GetCurrentCache()
+	now := time.Now()
+	for _, key := range guard.keys {
+		// failures against other servers at the same time must add up, so it is counted on the latest attempt in cache layer
+		locked := false
+		attempt, err := runtimeCache.IncrementLoginAttempt(key, func(attempt *schema.LoginAttempt) {
+			locked = recordFailure(attempt, guard.limits[key], guard.protection, now)
+		})
+		if err != nil {
+			log.Errorf("Failed to save login attempt %s due to error: %s", key, err.Error())
+			continue
+		}
+		if locked {
+			message := fmt.Sprintf("%s is locked until %s after %d failed attempts", key, time.Unix(attempt.LockedUntil, 0).Format(time.RFC3339), guard.limits[key])
+			log.Warn(message)
+			addAuditEvent(schema.AuditEvent{
+				Action:   constants.AuditActionLoginLockout,
+				Username: guard.username,
+				SourceIP: guard.sourceIP,
+				Message:  message,
+			})
+		}
+	}
+}
+
+// succeed clears failures of subject, failures of source ip are kept so one valid account cannot be used to reset them
+func (guard loginGuard) succeed() {
+	if err := cache.GetCurrentCache().DeleteLoginAttempt(guard.keys[0]); err != nil {
+		log.Errorf("Failed to clear login attempt %s due to error: %s", guard.keys[0], err.Error())
+	}
+}
+
+func attemptWait(attempt schema.LoginAttempt, now time.Time) time.Duration {
+	until := attempt.NextAttemptAt
+	if attempt.LockedUntil > until {
+		until = attempt.LockedUntil
+	}
+	return time.Unix(until, 0).Sub(now)
+}
+
+/*
+recordFailure adds a failure to the attempt and returns true when the attempt gets locked
+failures older than the window start over, delay before next attempt doubles on every failure
+*/
+func recordFailure(attempt *schema.LoginAttempt, limit int, protection apiServerConfig.LoginProtection, now time.Time) bool {
+	window := time.Duration(protection.FailureWindowMinutes) * time.Minute
+	if attempt.Failures == 0 || now.Sub(time.Unix(attempt.FirstFailure, 0)) > window {
+		attempt.Failures = 0
+		attempt.FirstFailure = now.Unix()
+	}
+	attempt.Failures++
+	delay := time.Second << uint(attempt.Failures-1)
+	if maxDelay := time.Duration(protection.MaxDelaySeconds) * time.Second; delay > maxDelay || delay <= 0 {
+		delay = maxDelay
+	}
+	attempt.NextAttemptAt = now.Add(delay).Unix()
+	if limit > 0 && attempt.Failures >= limit {
+		attempt.LockedUntil = now.Add(time.Duration(protection.LockoutMinutes) * time.Minute).Unix()
+		attempt.Failures = 0
+		return true
+	}
+	return false
+}
+
+func addAuditEvent(event schema.AuditEvent) {
+	if err := cache.GetCurrentCache().AddAuditEvent(event); err != nil {
+		log.Errorf("Failed to save audit event %s due to error: %s", event.Action, err.Error())
+	}
+}
+
+func ListLoginLockout(request *restful.Request, response *restful.Response) {
+	attempts, err := cache.GetCurrentCache().GetLoginAttemptCollection()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get login attempts due to error: %s", err.Error()))
+		return
+	}
+	now := time.Now().Unix()
+	result := []schema.LoginAttempt{}
+	for _, attempt := range attempts {
+		if attempt.LockedUntil > now {
+			result = append(result, attempt)
+		}
+	}
+	response.WriteAsJson(result)
+}
+
+func UnlockLogin(request *restful.Request, response *restful.Response) {
+	key := strings.TrimSpace(request.PathParameter("key"))
+	if key == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "key"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	attempt, err := runtimeCache.GetLoginAttempt(key)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get login attempt due to error: %s", err.Error()))
+		return
+	}
+	if attempt == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("No login attempt found with key %s", key))
+		return
+	}
+	if err := runtimeCache.DeleteLoginAttempt(key); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to unlock %s due to error: %s", key, err.Error()))
+		return
+	}
+	operator, _ := request.Attribute("run-by-user").(string)
+	event := schema.AuditEvent{
+		Action:   constants.AuditActionLoginUnlock,
+		Operator: operator,
+		SourceIP: requestSourceIP(request),
+		Message:  fmt.Sprintf("%s is unlocked by %s", key, operator),
+	}
+	if strings.HasPrefix(key, constants.LoginAttemptUserPrefix) {
+		event.Username = strings.TrimPrefix(key, constants.LoginAttemptUserPrefix)
+	}
+	addAuditEvent(event)
+	response.WriteHeader(http.StatusOK)
+}
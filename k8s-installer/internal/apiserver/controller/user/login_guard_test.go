@@ -0,0 +1,39 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/schema"
+)
+
+func TestRecordFailure(t *testing.T) {
+	protection := apiServerConfig.LoginProtection{FailureWindowMinutes: 15, LockoutMinutes: 15, MaxDelaySeconds: 4}
+	now := time.Unix(1600000000, 0)
+	attempt := &schema.LoginAttempt{Key: "user:alice"}
+	for i, expectedDelay := range []int64{1, 2, 4, 4} {
+		if recordFailure(attempt, 5, protection, now) {
+			t.Fatalf("Expected no lockout after %d failures", i+1)
+		}
+		if attempt.NextAttemptAt-now.Unix() != expectedDelay {
+			t.Fatalf("Expected delay %d after %d failures, got %d", expectedDelay, i+1, attempt.NextAttemptAt-now.Unix())
+		}
+	}
+	if !recordFailure(attempt, 5, protection, now) {
+		t.Fatal("Expected lockout after 5 failures")
+	}
+	if wait := attemptWait(*attempt, now); wait != 15*time.Minute {
+		t.Fatalf("Expected to wait for lockout of 15 minutes, got %s", wait)
+	}
+
+	// failures outside of the window start over
+	later := now.Add(time.Hour)
+	recordFailure(attempt, 5, protection, later)
+	if attempt.Failures != 1 || attempt.FirstFailure != later.Unix() {
+		t.Fatalf("Expected failures to start over, got %d failures since %d", attempt.Failures, attempt.FirstFailure)
+	}
+	if attemptWait(*attempt, later.Add(2*time.Second)) > 0 {
+		t.Fatal("Expected attempt to be accepted once delay passed")
+	}
+}
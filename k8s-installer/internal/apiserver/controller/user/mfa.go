@@ -0,0 +1,236 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/password"
+	"k8s-installer/pkg/totp"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+/*
+checkSecondFactor asks user with mfa enabled for totp or recovery code
+user without mfa passes unless one of it`s roles holds a role function mfa policy requires
+*/
+func checkSecondFactor(response *restful.Response, guard loginGuard, user *schema.User, loginPost schema.LoginRequest) bool {
+	runtimeCache := cache.GetCurrentCache()
+	mfa, err := runtimeCache.GetUserMFA(user.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get mfa of user due to error: %s", err.Error()))
+		return false
+	}
+	if mfa == nil || !mfa.Enabled {
+		roles, err := runtimeCache.GetRoleList()
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get role list due to error: %s", err.Error()))
+			return false
+		}
+		required := runtimeCache.GetServerRuntimeConfig(cache.NodeId).ApiServer.MFAPolicy.RequiredFunction
+		if rolesRequireMFA(required, user.Roles, roles) {
+			utils.ResponseErrorWithCode(response, http.StatusForbidden, constants.ErrMFAEnrollmentRequired, "Role of user requires mfa, please enroll an authenticator first")
+			return false
+		}
+		return true
+	}
+	if loginPost.OTPCode == "" && loginPost.RecoveryCode == "" {
+		utils.ResponseErrorWithCode(response, http.StatusUnauthorized, constants.ErrMFARequired, "Otp code or recovery code is required")
+		return false
+	}
+	if !verifySecondFactor(mfa, loginPost, time.Now()) {
+		guard.fail()
+		utils.ResponseErrorWithCode(response, http.StatusUnauthorized, constants.ErrMFARequired, "Otp code or recovery code incorrect")
+		return false
+	}
+	if err := runtimeCache.SaveOrUpdateUserMFA(*mfa); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save mfa of user due to error: %s", err.Error()))
+		return false
+	}
+	return true
+}
+
+func rolesRequireMFA(requiredFunction uint64, userRoles []schema.Role, roles schema.RoleCollection) bool {
+	if requiredFunction == 0 {
+		return false
+	}
+	// function stored with user may be stale, role list is the source of truth
+	for _, role := range userRoles {
+		if found, isFound := roles[role.Id]; isFound && found.Function&requiredFunction != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySecondFactor checks otp code or recovery code and updates mfa so the same code cannot be used again
+func verifySecondFactor(mfa *schema.UserMFA, loginPost schema.LoginRequest, now time.Time) bool {
+	if loginPost.OTPCode != "" {
+		step, ok := totp.Validate(mfa.Secret, loginPost.OTPCode, now, mfa.LastUsedStep)
+		if ok {
+			mfa.LastUsedStep = step
+		}
+		return ok
+	}
+	code := totp.NormalizeRecoveryCode(loginPost.RecoveryCode)
+	for index, hashed := range mfa.RecoveryCodes {
+		if match, _ := password.Verify(hashed, code); match {
+			mfa.RecoveryCodes = append(mfa.RecoveryCodes[:index], mfa.RecoveryCodes[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+/*
+EnrollMFA generates totp secret and recovery codes for user proven by password
+it does not need jwt so user whose role requires mfa is able to enroll before first login
+enrollment stays pending until ActivateMFA receives a valid code
+*/
+func EnrollMFA(request *restful.Request, response *restful.Response) {
+	loginPost := schema.LoginRequest{}
+	if err := request.ReadEntity(&loginPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, guard := authenticatePassword(request, response, loginPost)
+	if user == nil {
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	existing, err := runtimeCache.GetUserMFA(user.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get mfa of user due to error: %s", err.Error()))
+		return
+	}
+	// password alone must not be enough to replace an authenticator
+	if existing != nil && existing.Enabled {
+		utils.ResponseError(response, http.StatusConflict, fmt.Sprintf("Mfa of user %s is already enabled, ask administrator to reset it before enrolling a new authenticator", user.Username))
+		return
+	}
+	guard.succeed()
+
+	policy := runtimeCache.GetServerRuntimeConfig(cache.NodeId).ApiServer.MFAPolicy
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to generate totp secret due to error: %s", err.Error()))
+		return
+	}
+	codes, err := totp.GenerateRecoveryCodes(policy.RecoveryCodeCount)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to generate recovery codes due to error: %s", err.Error()))
+		return
+	}
+	mfa := schema.UserMFA{
+		Username:      user.Username,
+		Secret:        secret,
+		RecoveryCodes: []string{},
+	}
+	for _, code := range codes {
+		hashed, err := password.Hash(code)
+		if err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to hash recovery code due to error: %s", err.Error()))
+			return
+		}
+		mfa.RecoveryCodes = append(mfa.RecoveryCodes, hashed)
+	}
+	if err := runtimeCache.SaveOrUpdateUserMFA(mfa); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save mfa of user due to error: %s", err.Error()))
+		return
+	}
+	response.WriteAsJson(schema.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(policy.Issuer, user.Username, secret),
+		RecoveryCodes:   codes,
+	})
+}
+
+// ActivateMFA enables pending enrollment once user proves the authenticator generates valid codes
+func ActivateMFA(request *restful.Request, response *restful.Response) {
+	loginPost := schema.LoginRequest{}
+	if err := request.ReadEntity(&loginPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, guard := authenticatePassword(request, response, loginPost)
+	if user == nil {
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	mfa, err := runtimeCache.GetUserMFA(user.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get mfa of user due to error: %s", err.Error()))
+		return
+	}
+	if mfa == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("No pending mfa enrollment found for user %s", user.Username))
+		return
+	}
+	if mfa.Enabled {
+		utils.ResponseError(response, http.StatusConflict, fmt.Sprintf("Mfa of user %s is already enabled", user.Username))
+		return
+	}
+	now := time.Now()
+	step, ok := totp.Validate(mfa.Secret, loginPost.OTPCode, now, mfa.LastUsedStep)
+	if !ok {
+		guard.fail()
+		utils.ResponseErrorWithCode(response, http.StatusUnauthorized, constants.ErrMFARequired, "Otp code incorrect")
+		return
+	}
+	guard.succeed()
+	mfa.Enabled = true
+	mfa.LastUsedStep = step
+	mfa.EnabledAt = now.Unix()
+	if err := runtimeCache.SaveOrUpdateUserMFA(*mfa); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save mfa of user due to error: %s", err.Error()))
+		return
+	}
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionMFAEnable,
+		Username: user.Username,
+		Operator: user.Username,
+		SourceIP: requestSourceIP(request),
+		Message:  fmt.Sprintf("Mfa of user %s is enabled", user.Username),
+	})
+	response.WriteHeader(http.StatusOK)
+}
+
+// ResetUserMFA removes authenticator of user e.g. when both phone and recovery codes are lost, user enrolls again afterwards
+func ResetUserMFA(request *restful.Request, response *restful.Response) {
+	username := strings.TrimSpace(request.PathParameter("username"))
+	if username == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "username"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	mfa, err := runtimeCache.GetUserMFA(username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get mfa of user due to error: %s", err.Error()))
+		return
+	}
+	if mfa == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("User %s has no mfa enrolled", username))
+		return
+	}
+	if err := runtimeCache.DeleteUserMFA(username); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to reset mfa of user %s due to error: %s", username, err.Error()))
+		return
+	}
+	operator, _ := request.Attribute("run-by-user").(string)
+	log.Infof("Mfa of user %s is reset by %s", username, operator)
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionMFAReset,
+		Username: username,
+		Operator: operator,
+		SourceIP: requestSourceIP(request),
+		Message:  fmt.Sprintf("Mfa of user %s is reset by %s", username, operator),
+	})
+	response.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/password"
+	"k8s-installer/pkg/totp"
+	"k8s-installer/schema"
+)
+
+func TestRolesRequireMFA(t *testing.T) {
+	roles := schema.RoleCollection{
+		"1": {Id: "1", Name: "admin", Function: constants.ChallengeCodeDeleteCluster | constants.ChallengeCodeListCluster},
+		"2": {Id: "2", Name: "viewer", Function: constants.ChallengeCodeListCluster},
+	}
+	required := constants.ChallengeCodeDeleteCluster
+	// function kept in user is ignored
+	if rolesRequireMFA(required, []schema.Role{{Id: "2", Function: constants.ChallengeCodeDeleteCluster}}, roles) {
+		t.Fatal("Expected viewer not to require mfa")
+	}
+	if !rolesRequireMFA(required, []schema.Role{{Id: "2"}, {Id: "1"}}, roles) {
+		t.Fatal("Expected admin to require mfa")
+	}
+	if rolesRequireMFA(0, []schema.Role{{Id: "1"}}, roles) {
+		t.Fatal("Expected no mfa required without policy")
+	}
+}
+
+func TestVerifySecondFactor(t *testing.T) {
+	secret, _ := totp.GenerateSecret()
+	hashed, _ := password.Hash("abcde-12345")
+	mfa := &schema.UserMFA{Username: "alice", Secret: secret, Enabled: true, RecoveryCodes: []string{hashed}}
+	now := time.Unix(1600000000, 0)
+
+	code, _ := totp.Code(secret, totp.Step(now))
+	if !verifySecondFactor(mfa, schema.LoginRequest{OTPCode: code}, now) {
+		t.Fatal("Expected valid otp code to pass")
+	}
+	if verifySecondFactor(mfa, schema.LoginRequest{OTPCode: code}, now) {
+		t.Fatal("Expected replayed otp code to fail")
+	}
+	if !verifySecondFactor(mfa, schema.LoginRequest{RecoveryCode: "ABCDE12345"}, now) {
+		t.Fatal("Expected recovery code to pass")
+	}
+	if len(mfa.RecoveryCodes) != 0 || verifySecondFactor(mfa, schema.LoginRequest{RecoveryCode: "abcde-12345"}, now) {
+		t.Fatal("Expected recovery code to be consumed")
+	}
+}
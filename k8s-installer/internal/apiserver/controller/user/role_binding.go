@@ -0,0 +1,162 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+/*
+CreateRoleBinding grants role to user on clusters, regions or labelled clusters only
+binding is read on every request so it takes effect without login again
+*/
+func CreateRoleBinding(request *restful.Request, response *restful.Response) {
+	bindingPost := schema.RoleBinding{}
+	if err := request.ReadEntity(&bindingPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	bindingPost.Username = strings.TrimSpace(bindingPost.Username)
+	if err := utils.Validate(bindingPost); err != nil {
+		utils.SchemaValidationFailedResponseError(response, err.Error())
+		return
+	}
+	// empty scope would read as everywhere, global role belongs to user roles instead
+	if bindingPost.Scope.IsEmpty() {
+		utils.ResponseError(response, http.StatusBadRequest, "Scope of role binding requires at least one cluster, region or cluster label")
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	user, err := runtimeCache.GetUser(bindingPost.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
+		return
+	}
+	if user == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Cannot found user: %s", bindingPost.Username))
+		return
+	}
+	if roleCheckResult := checkRoleExistence([]schema.Role{{Id: bindingPost.RoleId}}); len(roleCheckResult) > 0 {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Following roles id check failed: %s", strings.Join(roleCheckResult, " , ")))
+		return
+	}
+	if regionCheckResult := checkRegionExistence(bindingPost.Scope.Regions); len(regionCheckResult) > 0 {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Following regions id check failed: %s", strings.Join(regionCheckResult, " , ")))
+		return
+	}
+
+	operator, _ := runBy(request)
+	binding := schema.RoleBinding{
+		Id:        randomToken(),
+		Username:  user.Username,
+		RoleId:    bindingPost.RoleId,
+		Scope:     bindingPost.Scope,
+		CreatedAt: time.Now().Unix(),
+		CreatedBy: operator,
+	}
+	if err := runtimeCache.SaveOrUpdateRoleBinding(binding); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save role binding due to error: %s", err.Error()))
+		return
+	}
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionRoleBind,
+		Username: binding.Username,
+		Operator: operator,
+		SourceIP: requestSourceIP(request),
+		Message:  fmt.Sprintf("Role %s is bound to %s by %s with binding %s", binding.RoleId, binding.Username, operator, binding.Id),
+	})
+	response.WriteAsJson(binding)
+}
+
+func checkRegionExistence(regions []string) []string {
+	var result []string
+	runtimeCache := cache.GetCurrentCache()
+	for _, regionId := range regions {
+		region, err := runtimeCache.GetRegion(regionId)
+		if err != nil {
+			result = append(result, err.Error())
+		} else if region == nil {
+			result = append(result, fmt.Sprintf("Unable found region with id %s", regionId))
+		}
+	}
+	return result
+}
+
+// ListRoleBinding lists role bindings, query parameter username narrows them down to one user
+func ListRoleBinding(request *restful.Request, response *restful.Response) {
+	username := request.QueryParameter("username")
+	bindings, err := cache.GetCurrentCache().GetRoleBindingCollection()
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get role bindings due to error: %s", err.Error()))
+		return
+	}
+	result := []schema.RoleBinding{}
+	for _, binding := range bindings {
+		if username == "" || binding.Username == username {
+			result = append(result, binding)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt < result[j].CreatedAt
+	})
+	response.WriteAsJson(result)
+}
+
+func DeleteRoleBinding(request *restful.Request, response *restful.Response) {
+	id := strings.TrimSpace(request.PathParameter("binding-id"))
+	if id == "" {
+		utils.ResponseError(response, http.StatusBadRequest, fmt.Sprintf("Unable to find path parameter: %s", "binding-id"))
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	binding, err := runtimeCache.GetRoleBinding(id)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get role binding due to error: %s", err.Error()))
+		return
+	}
+	if binding == nil {
+		utils.ResponseError(response, http.StatusNotFound, fmt.Sprintf("Cannot found role binding: %s", id))
+		return
+	}
+	operator, _ := runBy(request)
+	deleteRoleBinding(*binding, operator, requestSourceIP(request))
+	response.WriteHeader(http.StatusOK)
+}
+
+func deleteRoleBinding(binding schema.RoleBinding, operator, sourceIP string) {
+	if err := cache.GetCurrentCache().DeleteRoleBinding(binding.Id); err != nil {
+		log.Errorf("Failed to delete role binding %s due to error: %s", binding.Id, err.Error())
+		return
+	}
+	addAuditEvent(schema.AuditEvent{
+		Action:   constants.AuditActionRoleUnbind,
+		Username: binding.Username,
+		Operator: operator,
+		SourceIP: sourceIP,
+		Message:  fmt.Sprintf("Role %s is unbound from %s by %s with binding %s", binding.RoleId, binding.Username, operator, binding.Id),
+	})
+}
+
+// deleteRoleBindings removes every binding matching e.g. of deleted user or role
+func deleteRoleBindings(match func(binding schema.RoleBinding) bool, operator, sourceIP string) {
+	bindings, err := cache.GetCurrentCache().GetRoleBindingCollection()
+	if err != nil {
+		log.Errorf("Failed to get role bindings due to error: %s", err.Error())
+		return
+	}
+	for _, binding := range bindings {
+		if match(binding) {
+			deleteRoleBinding(binding, operator, sourceIP)
+		}
+	}
+}
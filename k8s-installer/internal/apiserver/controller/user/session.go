@@ -0,0 +1,223 @@
+package user
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s-installer/internal/apiserver/utils"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	"github.com/emicklei/go-restful"
+)
+
+var (
+	errRefreshTokenInvalid = errors.New("Invalid refresh token, please login again")
+	errRefreshTokenReused  = errors.New("Refresh token is already used, session is revoked, please login again")
+)
+
+func sessionLifetime() time.Duration {
+	return time.Duration(cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId).ApiServer.JWTExpiredAfterHours) * time.Hour
+}
+
+// newSession saves a session of user and returns it with refresh token, expired sessions of the user are cleaned up meanwhile
+func newSession(username string, now time.Time) (schema.Session, string, error) {
+	secret := randomToken()
+	session := schema.Session{
+		Id:          randomToken(),
+		Username:    username,
+		RefreshHash: refreshHash(secret),
+		CreatedAt:   now.Unix(),
+		ExpiresAt:   now.Add(sessionLifetime()).Unix(),
+	}
+	runtimeCache := cache.GetCurrentCache()
+	if err := runtimeCache.SaveOrUpdateSession(session); err != nil {
+		return session, "", err
+	}
+	if sessions, err := runtimeCache.GetSessionCollection(); err == nil {
+		for id, expired := range sessions {
+			if expired.Username == username && expired.ExpiresAt < now.Unix() {
+				if err := runtimeCache.DeleteSession(id); err != nil {
+					log.Warnf("Failed to clean up expired session %s due to error: %s", id, err.Error())
+				}
+			}
+		}
+	}
+	return session, session.Id + "." + secret, nil
+}
+
+func refreshHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseRefreshToken(refreshToken string) (string, string, bool) {
+	parts := strings.SplitN(refreshToken, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+/*
+rotateRefreshToken replaces refresh token of the session and returns the new secret
+presenting the token replaced by last rotation means two parties hold it so session is revoked
+session created before user got changed e.g. roles is revoked as well
+*/
+func rotateRefreshToken(session *schema.Session, secret string, tokensValidAfter int64, now time.Time, lifetime time.Duration) (string, error) {
+	hashed := refreshHash(secret)
+	if session.RevokedAt != 0 || session.ExpiresAt <= now.Unix() {
+		return "", errRefreshTokenInvalid
+	}
+	if session.PreviousRefreshHash != "" && subtle.ConstantTimeCompare([]byte(hashed), []byte(session.PreviousRefreshHash)) == 1 {
+		session.RevokedAt = now.Unix()
+		return "", errRefreshTokenReused
+	}
+	if subtle.ConstantTimeCompare([]byte(hashed), []byte(session.RefreshHash)) != 1 {
+		return "", errRefreshTokenInvalid
+	}
+	if session.CreatedAt < tokensValidAfter {
+		session.RevokedAt = now.Unix()
+		return "", errRefreshTokenInvalid
+	}
+	newSecret := randomToken()
+	session.PreviousRefreshHash = session.RefreshHash
+	session.RefreshHash = refreshHash(newSecret)
+	session.RefreshedAt = now.Unix()
+	session.ExpiresAt = now.Add(lifetime).Unix()
+	return newSecret, nil
+}
+
+// RefreshLogin exchanges refresh token for new access token and refresh token of the same session
+func RefreshLogin(request *restful.Request, response *restful.Response) {
+	refreshPost := schema.RefreshRequest{}
+	if err := request.ReadEntity(&refreshPost); err != nil {
+		utils.ResponseError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	sessionId, secret, ok := parseRefreshToken(refreshPost.RefreshToken)
+	if !ok {
+		utils.ResponseError(response, http.StatusUnauthorized, errRefreshTokenInvalid.Error())
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	session, err := runtimeCache.GetSession(sessionId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get login session due to error: %s", err.Error()))
+		return
+	}
+	if session == nil {
+		utils.ResponseError(response, http.StatusUnauthorized, errRefreshTokenInvalid.Error())
+		return
+	}
+	user, err := runtimeCache.GetUser(session.Username)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get user information due to error: %s", err.Error()))
+		return
+	}
+	if user == nil {
+		utils.ResponseError(response, http.StatusUnauthorized, errRefreshTokenInvalid.Error())
+		return
+	}
+	revoked := session.RevokedAt
+	newSecret, errRotate := rotateRefreshToken(session, secret, user.TokensValidAfter, time.Now(), sessionLifetime())
+	if errRotate != nil {
+		// rotation revokes the session when token is reused or user is changed
+		if session.RevokedAt != revoked {
+			if err := runtimeCache.SaveOrUpdateSession(*session); err != nil {
+				log.Errorf("Failed to revoke session %s due to error: %s", session.Id, err.Error())
+			}
+		}
+		if errRotate == errRefreshTokenReused {
+			log.Warnf("Refresh token of session %s of user %s is reused, session is revoked", session.Id, session.Username)
+			addAuditEvent(schema.AuditEvent{
+				Action:   constants.AuditActionRefreshReuse,
+				Username: session.Username,
+				SourceIP: requestSourceIP(request),
+				Message:  fmt.Sprintf("Refresh token of session %s is reused, session is revoked", session.Id),
+			})
+		}
+		utils.ResponseError(response, http.StatusUnauthorized, errRotate.Error())
+		return
+	}
+	if err := runtimeCache.SaveOrUpdateSession(*session); err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to save login session due to error: %s", err.Error()))
+		return
+	}
+	user.Password = ""
+	writeTokenResponse(response, user, *session, session.Id+"."+newSecret)
+}
+
+// Logout revokes session of current access token, or every session of current user with query parameter all=true
+func Logout(request *restful.Request, response *restful.Response) {
+	sessionId, _ := request.Attribute("session-id").(string)
+	operator, _ := runBy(request)
+	if request.QueryParameter("all") == "true" {
+		revokeUserSessions(operator)
+		response.WriteHeader(http.StatusOK)
+		return
+	}
+	if sessionId == "" {
+		utils.ResponseError(response, http.StatusBadRequest, "Request is not authenticated by login session, revoke api token instead")
+		return
+	}
+	runtimeCache := cache.GetCurrentCache()
+	session, err := runtimeCache.GetSession(sessionId)
+	if err != nil {
+		utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to get login session due to error: %s", err.Error()))
+		return
+	}
+	if session != nil && session.RevokedAt == 0 {
+		session.RevokedAt = time.Now().Unix()
+		if err := runtimeCache.SaveOrUpdateSession(*session); err != nil {
+			utils.ResponseError(response, http.StatusInternalServerError, fmt.Sprintf("Failed to revoke login session due to error: %s", err.Error()))
+			return
+		}
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// revokeUserSessions revokes every login session of user e.g. when user is deleted
+func revokeUserSessions(username string) {
+	runtimeCache := cache.GetCurrentCache()
+	sessions, err := runtimeCache.GetSessionCollection()
+	if err != nil {
+		log.Errorf("Failed to get login sessions of user %s due to error: %s", username, err.Error())
+		return
+	}
+	now := time.Now().Unix()
+	for _, session := range sessions {
+		if session.Username != username || session.RevokedAt != 0 {
+			continue
+		}
+		session.RevokedAt = now
+		if err := runtimeCache.SaveOrUpdateSession(session); err != nil {
+			log.Errorf("Failed to revoke session %s of user %s due to error: %s", session.Id, username, err.Error())
+		}
+	}
+}
+
+// sameRoles tells whether two role lists hold same role ids regardless of order
+func sameRoles(a, b []schema.Role) bool {
+	ids := map[string]int{}
+	for _, role := range a {
+		ids[role.Id]++
+	}
+	for _, role := range b {
+		ids[role.Id]--
+	}
+	for _, count := range ids {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"k8s-installer/schema"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	session := &schema.Session{Id: "s1", Username: "alice", RefreshHash: refreshHash("first"), CreatedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+
+	second, err := rotateRefreshToken(session, "first", 0, now, 8*time.Hour)
+	if err != nil {
+		t.Fatalf("Expected rotation to succeed, got %v", err)
+	}
+	if session.ExpiresAt != now.Add(8*time.Hour).Unix() || session.RefreshHash != refreshHash(second) {
+		t.Fatalf("Expected session to be extended with new refresh token, got %+v", session)
+	}
+	if _, err := rotateRefreshToken(session, "unknown", 0, now, time.Hour); err != errRefreshTokenInvalid || session.RevokedAt != 0 {
+		t.Fatalf("Expected unknown token to be refused without revoking session, got %v", err)
+	}
+	// replaced token presented again
+	if _, err := rotateRefreshToken(session, "first", 0, now, time.Hour); err != errRefreshTokenReused || session.RevokedAt == 0 {
+		t.Fatalf("Expected reuse to revoke session, got %v", err)
+	}
+	if _, err := rotateRefreshToken(session, second, 0, now, time.Hour); err != errRefreshTokenInvalid {
+		t.Fatalf("Expected revoked session to be refused, got %v", err)
+	}
+
+	changed := &schema.Session{Id: "s2", RefreshHash: refreshHash("token"), CreatedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	if _, err := rotateRefreshToken(changed, "token", now.Unix()+1, now, time.Hour); err == nil || changed.RevokedAt == 0 {
+		t.Fatal("Expected session older than user change to be revoked")
+	}
+}
+
+func TestParseRefreshToken(t *testing.T) {
+	if id, secret, ok := parseRefreshToken("abc.def"); !ok || id != "abc" || secret != "def" {
+		t.Fatalf("Unexpected parse result %s %s %v", id, secret, ok)
+	}
+	for _, invalid := range []string{"", "abc", ".def", "abc."} {
+		if _, _, ok := parseRefreshToken(invalid); ok {
+			t.Fatalf("Expected %q not to be parsed", invalid)
+		}
+	}
+}
+
+func TestSameRoles(t *testing.T) {
+	if !sameRoles([]schema.Role{{Id: "1"}, {Id: "2"}}, []schema.Role{{Id: "2", Name: "viewer"}, {Id: "1"}}) {
+		t.Fatal("Expected same roles in different order to match")
+	}
+	if sameRoles([]schema.Role{{Id: "1"}}, []schema.Role{{Id: "1"}, {Id: "2"}}) {
+		t.Fatal("Expected added role to be detected")
+	}
+}
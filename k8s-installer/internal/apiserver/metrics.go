@@ -0,0 +1,23 @@
+package apiserver
+
+import (
+	"time"
+
+	"k8s-installer/pkg/metrics"
+
+	"github.com/emicklei/go-restful"
+)
+
+/*
+observeRequest records latency of every api request
+route template is used rather than path so ids in path do not blow up label values
+*/
+func observeRequest(req *restful.Request, resp *restful.Response, fc *restful.FilterChain) {
+	start := time.Now()
+	fc.ProcessFilter(req, resp)
+	route := req.SelectedRoutePath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.ObserveAPIRequest(req.Request.Method, route, resp.StatusCode(), time.Since(start))
+}
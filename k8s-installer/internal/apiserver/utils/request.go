@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net"
+	"strings"
+
+	"k8s-installer/pkg/rbac"
+
+	"github.com/emicklei/go-restful"
+)
+
+// IsDryRun returns true when caller only wants to see what the request is going to do
+func IsDryRun(request *restful.Request) bool {
+	return strings.ToLower(request.QueryParameter("dry_run")) == "true"
+}
+
+// IsRollbackOnFailure returns true when caller wants operation to be rolled back once it fails
+func IsRollbackOnFailure(request *restful.Request) bool {
+	return strings.ToLower(request.QueryParameter("rollback_on_failure")) == "true"
+}
+
+/*
+RequestAuthorizer returns authorizer the auth filter worked out for the request
+list handlers use it to return only resources caller may see, zero value without filter sees nothing scoped
+*/
+func RequestAuthorizer(request *restful.Request) rbac.Authorizer {
+	authorizer, _ := request.Attribute("authorizer").(rbac.Authorizer)
+	return authorizer
+}
+
+// RequestSourceIP takes peer address only, forwarded headers are set by client and cannot be trusted
+func RequestSourceIP(request *restful.Request) string {
+	host, _, err := net.SplitHostPort(request.Request.RemoteAddr)
+	if err != nil {
+		return request.Request.RemoteAddr
+	}
+	return host
+}
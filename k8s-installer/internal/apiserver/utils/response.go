@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/log"
 	"k8s-installer/schema"
 	"net/http"
@@ -44,3 +45,11 @@ func ResponseErrorWithList(resp *restful.Response, errorCode int, errorMessage s
 func WriteSingleEmptyStructWithStatus(resp *restful.Response) {
 	_, _ = resp.Write([]byte("{}"))
 }
+
+// StatusOfSaveError is 409 when object was modified by someone else since it was read so user knows to read again and retry, 500 otherwise
+func StatusOfSaveError(err error) int {
+	if cache.IsConflict(err) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -107,9 +107,49 @@ func ValidateUpgrade(version schema.UpgradableVersion) error {
 
 		}
 	}
+
+	if version.EL != nil {
+		if err := checkRPMPackageSets(version.Name, constants.OSFamilyEL, version.EL.OSVersions); err != nil {
+			return err
+		}
+	}
+	if version.OpenEuler != nil {
+		if err := checkRPMPackageSets(version.Name, constants.OSFamilyOpenEuler, version.OpenEuler.OSVersions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRPMPackageSets validates package sets of rpm based os family which keeps packages per os version
+func checkRPMPackageSets(k8sVersion, osFamily string, osVersions map[string]schema.OSFamilyCentosVersion) error {
+	for osVerId, osVer := range osVersions {
+		if err := checkOSFamilyVersion(osFamily, osVerId); err != nil {
+			return printUpgradeCheckFailedMessage(k8sVersion, err)
+		}
+		for _, rpms := range [][]schema.OSPackage{osVer.X8664.RPMS, osVer.Aarch64.RPMS} {
+			for _, rpmName := range rpms {
+				if err := v.Struct(rpmName); err != nil {
+					return printUpgradeCheckFailedMessage(k8sVersion, err)
+				}
+				if !strings.HasSuffix(rpmName.Name, ".rpm") {
+					return printUpgradeCheckFailedMessage(k8sVersion, errors.New(fmt.Sprintf("%s package %s is not a .rpm file", osFamily, rpmName.Name)))
+				}
+			}
+		}
+	}
 	return nil
 }
 
+func checkOSFamilyVersion(osFamily, ver string) error {
+	for _, supported := range constants.OSFamilyVersions[osFamily] {
+		if supported == ver {
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("%s os version %s is not support", osFamily, ver))
+}
+
 func printUpgradeCheckFailedMessage(version string, err error) error {
 	return errors.New(fmt.Sprintf("Validation check failed for k8s Version %s due to %s", version, err.Error()))
 }
@@ -125,7 +165,7 @@ func checkCentosVersionCheck(ver string) error {
 }
 
 func checkUbuntuVersionCheck(ver string) error {
-	return nil
+	return checkOSFamilyVersion(constants.OSFamilyUbuntu, ver)
 }
 
 func checkCentosRPMName(packageName string) error {
@@ -133,6 +173,10 @@ func checkCentosRPMName(packageName string) error {
 }
 
 func checkUbuntuRPMName(packageName string) error {
+	// package name is the file name on resource server which is installed with dpkg
+	if !strings.HasSuffix(packageName, ".deb") {
+		return errors.New(fmt.Sprintf("Ubuntu package %s is not a .deb file", packageName))
+	}
 	return nil
 }
 
@@ -41,7 +41,7 @@ func (C ContainerD) v7(offline bool, taskCRI schema.TaskCRI, cluster schema.Clus
 	}
 
 	if taskCRI.CRIType.PrivateRegistryAddress != "" {
-		if err := setUpContainerdConfigFile(taskCRI, cluster); err != nil {
+		if err := setUpContainerdConfigFile(taskCRI, cluster, "/etc/sysconfig/kubelet"); err != nil {
 			return err
 		}
 	}
@@ -176,7 +176,8 @@ func (c ContainerD) Remove(config schema.TaskCRI) error {
 	return err
 }
 
-func setUpContainerdConfigFile(config schema.TaskCRI, cluster schema.Cluster) error {
+// kubeletEnvFile is where kubelet systemd unit reads KUBELET_EXTRA_ARGS from which differs between os families
+func setUpContainerdConfigFile(config schema.TaskCRI, cluster schema.Cluster, kubeletEnvFile string) error {
 
 	httpTemplate :=
 		`    [plugins."io.containerd.grpc.v1.cri".registry.mirrors."%s"]
@@ -238,6 +239,8 @@ oom_score = 0
       container_annotations = []
       privileged_without_host_devices = false
       base_runtime_spec = ""
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+        SystemdCgroup = %t
   [plugins."io.containerd.grpc.v1.cri".cni]
     bin_dir = "/opt/cni/bin"
     conf_dir = "/etc/cni/net.d"
@@ -314,7 +317,7 @@ oom_score = 0
 	rtc := cache.GetCurrentCache()
 	clientConfig := rtc.GetClientRuntimeConfig(cache.NodeId)
 
-	err := util.WriteTxtToFile("/etc/containerd/config.toml", fmt.Sprintf(templateContainerdConfig, clientConfig.CRIRootDir, cluster.ContainerRuntime.PrivateRegistryAddress, cluster.ContainerRuntime.PrivateRegistryPort, fullConfig))
+	err := util.WriteTxtToFile("/etc/containerd/config.toml", fmt.Sprintf(templateContainerdConfig, clientConfig.CRIRootDir, cluster.ContainerRuntime.PrivateRegistryAddress, cluster.ContainerRuntime.PrivateRegistryPort, osInfoProvider.GetCgroupDriver() == constants.CgroupDriverSystemd, fullConfig))
 	if err != nil {
 		log.Debugf("Failed to set containerd config file /etc/containerd/config.toml due to error %s: ", err.Error())
 		return err
@@ -328,12 +331,12 @@ KUBELET_EXTRA_ARGS=--container-runtime=remote --runtime-request-timeout=15m --co
 #containerd
 `
 	template = fmt.Sprintf(template, cluster.ContainerRuntime.PrivateRegistryAddress, cluster.ContainerRuntime.PrivateRegistryPort)
-	err = fileutils.AppendFile("/etc/sysconfig/kubelet", template, "#containerd")
+	err = fileutils.AppendFile(kubeletEnvFile, template, "#containerd")
 	if err != nil {
-		log.Debugf("Failed to set kubelet config file /etc/sysconfig/kubelet due to error %s: ", err.Error())
+		log.Debugf("Failed to set kubelet config file %s due to error %s: ", kubeletEnvFile, err.Error())
 		return err
 	}
-	log.Debug("Done with containerd config file /etc/sysconfig/kubelet")
+	log.Debugf("Done with containerd config file %s", kubeletEnvFile)
 	return nil
 }
 
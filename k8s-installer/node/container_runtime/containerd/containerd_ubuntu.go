@@ -0,0 +1,104 @@
+package containerd
+
+import (
+	"bytes"
+	"errors"
+
+	"k8s-installer/node/container_runtime"
+	"k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/ubuntu"
+	"k8s-installer/pkg/command"
+	"k8s-installer/pkg/config/client"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/containerd"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+/*
+ContainerDUbuntu installs containerd from debs
+kata is not shipped for ubuntu so only runc is available
+*/
+type ContainerDUbuntu struct {
+}
+
+func (C ContainerDUbuntu) Install(offline bool, taskCRI schema.TaskCRI, cluster schema.Cluster, config client.Config, resourceServerURL, k8sVersion string, md5Dep dep.DepMap) error {
+	var err error
+	if offline {
+		log.Debug("Offline is set to true. Go offline containerd installation script")
+		err = installContainerdOfflineUbuntu(config, resourceServerURL, k8sVersion, md5Dep)
+	} else {
+		log.Debug("Offline is set to false. Go online containerd installation script")
+		err = installContainerdOnlineUbuntu()
+	}
+	if err != nil {
+		log.Errorf("Failed to install containerd due to error %s", err.Error())
+		return err
+	}
+
+	if taskCRI.CRIType.PrivateRegistryAddress != "" {
+		if err := setUpContainerdConfigFile(taskCRI, cluster, "/etc/default/kubelet"); err != nil {
+			return err
+		}
+	}
+
+	err = family.StartSystemdService(true, true, "containerd")
+	if err != nil {
+		log.Errorf("Failed to start containerd daemon due to error %s", err.Error())
+	}
+
+	container_runtime.LoadContainerRuntimeTar(config.LocalImagePath, constants.CRITypeContainerd)
+
+	return err
+}
+
+func (C ContainerDUbuntu) Remove(config schema.TaskCRI) error {
+	var stdErr bytes.Buffer
+	var err error
+	log.Debugf("Attempt to remove all container and it`s task")
+	if err := containerd.DeleteAllContainer("k8s.io"); err != nil {
+		return err
+	}
+
+	family.StopSystemdService("containerd")
+
+	err = ubuntu.UninstallPackage(ubuntu.DepPackageNames(containerdVersion))
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Attempt to remove containerd folder /etc/containerd")
+	_, stdErr, err = command.RunCmd("rm", "-rf", "/etc/containerd")
+	if err != nil {
+		log.Error("Failed to remove /etc/containerd due to following error:")
+		errMsg := stdErr.String()
+		log.Errorf("StdErr %s", errMsg)
+		err = errors.New(errMsg)
+	}
+	return err
+}
+
+func (C ContainerDUbuntu) CleanDataDir() error {
+	return ContainerD{}.CleanDataDir()
+}
+
+func installContainerdOnlineUbuntu() error {
+	if err := ubuntu.AddDockerAptRepo(); err != nil {
+		return err
+	}
+	log.Debug("Install containerd")
+	return ubuntu.AptInstall("containerd.io")
+}
+
+func installContainerdOfflineUbuntu(config client.Config, resourceServerURL string, k8sVersion string, md5Dep dep.DepMap) error {
+	saveTo := config.YamlDataDir + "/containerd"
+
+	if err := family.CommonDownloadDep(resourceServerURL, containerdVersion, saveTo,
+		k8sVersion, md5Dep); err != nil {
+		return err
+	}
+
+	log.Debug("Installing all containerd debs")
+	return ubuntu.InstallDebPackages(saveTo)
+}
@@ -43,5 +43,65 @@ var containerdVersion dep.DepMap = schema.RegisterDep(dep.DepMap{
 			},
 		},
 	},
-	"ubuntu": {},
+	// ubuntu 20.04 debs
+	"ubuntu": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io": "containerd.io_1.4.4-1_amd64.deb",
+			},
+			"aarch64": {
+				"containerd.io": "containerd.io_1.4.4-1_arm64.deb",
+			},
+		},
+	},
+	// el 8 rpms, containerd 1.6 supports cgroup v2
+	"el": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el8.x86_64.rpm",
+				"container-selinux": "container-selinux-2.189.0-1.module+el8.7.0+1152+a4c0ac0f.noarch.rpm",
+			},
+			"aarch64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el8.aarch64.rpm",
+				"container-selinux": "container-selinux-2.189.0-1.module+el8.7.0+1152+a4c0ac0f.noarch.rpm",
+			},
+		},
+	},
+	// rpms which differ on el 9, see dep.OSKey
+	"el-9": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el9.x86_64.rpm",
+				"container-selinux": "container-selinux-2.189.0-1.el9.noarch.rpm",
+			},
+			"aarch64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el9.aarch64.rpm",
+				"container-selinux": "container-selinux-2.189.0-1.el9.noarch.rpm",
+			},
+		},
+	},
+	// openEuler 22.03 rpms
+	"openEuler": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el8.x86_64.rpm",
+				"container-selinux": "container-selinux-2.138-4.oe2203.noarch.rpm",
+			},
+			"aarch64": {
+				"containerd.io":     "containerd.io-1.6.9-3.1.el8.aarch64.rpm",
+				"container-selinux": "container-selinux-2.138-4.oe2203.noarch.rpm",
+			},
+		},
+	},
+	// rpms which differ on openEuler 20.03, see dep.OSKey
+	"openEuler-20.03": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"container-selinux": "container-selinux-2.73-3.oe1.noarch.rpm",
+			},
+			"aarch64": {
+				"container-selinux": "container-selinux-2.73-3.oe1.noarch.rpm",
+			},
+		},
+	},
 })
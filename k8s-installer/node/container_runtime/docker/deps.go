@@ -34,12 +34,135 @@ var dockerVersion dep.DepMap = schema.RegisterDep(dep.DepMap{
 				"apr":                    "apr-1.4.8-7.el7.x86_64.rpm",
 			},
 			"aarch64": {
-				"containerd.io": "containerd.io-1.2.13-3.2.el7.aarch64.rpm",
-				"docker-ce": "docker-ce-19.03.12-3.el7.aarch64.rpm",
+				"containerd.io":     "containerd.io-1.2.13-3.2.el7.aarch64.rpm",
+				"docker-ce":         "docker-ce-19.03.12-3.el7.aarch64.rpm",
 				"container-selinux": "container-selinux-2.119.2-1.911c772.el7_8.noarch.rpm",
-				"docker-ce-cli": "docker-ce-cli-19.03.12-3.el7.aarch64.rpm",
+				"docker-ce-cli":     "docker-ce-cli-19.03.12-3.el7.aarch64.rpm",
+			},
+		},
+	},
+	// ubuntu 20.04 debs
+	"ubuntu": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io": "containerd.io_1.2.13-2_amd64.deb",
+				"docker-ce":     "docker-ce_19.03.12~3-0~ubuntu-focal_amd64.deb",
+				"docker-ce-cli": "docker-ce-cli_19.03.12~3-0~ubuntu-focal_amd64.deb",
+			},
+			"aarch64": {
+				"containerd.io": "containerd.io_1.2.13-2_arm64.deb",
+				"docker-ce":     "docker-ce_19.03.12~3-0~ubuntu-focal_arm64.deb",
+				"docker-ce-cli": "docker-ce-cli_19.03.12~3-0~ubuntu-focal_arm64.deb",
+			},
+		},
+	},
+	// debs which differ on ubuntu 18.04, see dep.OSKey
+	"ubuntu-18.04": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"docker-ce":     "docker-ce_19.03.12~3-0~ubuntu-bionic_amd64.deb",
+				"docker-ce-cli": "docker-ce-cli_19.03.12~3-0~ubuntu-bionic_amd64.deb",
+			},
+			"aarch64": {
+				"docker-ce":     "docker-ce_19.03.12~3-0~ubuntu-bionic_arm64.deb",
+				"docker-ce-cli": "docker-ce-cli_19.03.12~3-0~ubuntu-bionic_arm64.deb",
+			},
+		},
+	},
+	// el 8 rpms, docker 20.10 is the first release supporting cgroup v2
+	"el": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el8.x86_64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el8.x86_64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el8.x86_64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el8.x86_64.rpm",
+				"container-selinux":         "container-selinux-2.189.0-1.module+el8.7.0+1152+a4c0ac0f.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.9-1.module+el8.7.0+1152+a4c0ac0f.x86_64.rpm",
+				"fuse3-libs":                "fuse3-libs-3.3.0-16.el8.x86_64.rpm",
+				"slirp4netns":               "slirp4netns-1.2.0-2.module+el8.7.0+1152+a4c0ac0f.x86_64.rpm",
+				"libslirp":                  "libslirp-4.4.0-1.module+el8.7.0+1152+a4c0ac0f.x86_64.rpm",
+			},
+			"aarch64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el8.aarch64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el8.aarch64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el8.aarch64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el8.aarch64.rpm",
+				"container-selinux":         "container-selinux-2.189.0-1.module+el8.7.0+1152+a4c0ac0f.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.9-1.module+el8.7.0+1152+a4c0ac0f.aarch64.rpm",
+				"fuse3-libs":                "fuse3-libs-3.3.0-16.el8.aarch64.rpm",
+				"slirp4netns":               "slirp4netns-1.2.0-2.module+el8.7.0+1152+a4c0ac0f.aarch64.rpm",
+				"libslirp":                  "libslirp-4.4.0-1.module+el8.7.0+1152+a4c0ac0f.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on el 9, see dep.OSKey
+	"el-9": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el9.x86_64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el9.x86_64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el9.x86_64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el9.x86_64.rpm",
+				"container-selinux":         "container-selinux-2.189.0-1.el9.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.9-1.el9.x86_64.rpm",
+				"fuse3-libs":                "fuse3-libs-3.10.2-5.el9.x86_64.rpm",
+				"slirp4netns":               "slirp4netns-1.2.0-2.el9.x86_64.rpm",
+				"libslirp":                  "libslirp-4.4.0-7.el9.x86_64.rpm",
+			},
+			"aarch64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el9.aarch64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el9.aarch64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el9.aarch64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el9.aarch64.rpm",
+				"container-selinux":         "container-selinux-2.189.0-1.el9.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.9-1.el9.aarch64.rpm",
+				"fuse3-libs":                "fuse3-libs-3.10.2-5.el9.aarch64.rpm",
+				"slirp4netns":               "slirp4netns-1.2.0-2.el9.aarch64.rpm",
+				"libslirp":                  "libslirp-4.4.0-7.el9.aarch64.rpm",
+			},
+		},
+	},
+	// openEuler 22.03 rpms, docker-ce el 8 rpms are used since docker ships nothing for openEuler
+	"openEuler": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el8.x86_64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el8.x86_64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el8.x86_64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el8.x86_64.rpm",
+				"container-selinux":         "container-selinux-2.138-4.oe2203.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.3.0-2.oe2203.x86_64.rpm",
+				"slirp4netns":               "slirp4netns-1.1.12-1.oe2203.x86_64.rpm",
+				"libcgroup":                 "libcgroup-0.42.2-3.oe2203.x86_64.rpm",
+			},
+			"aarch64": {
+				"containerd.io":             "containerd.io-1.6.9-3.1.el8.aarch64.rpm",
+				"docker-ce":                 "docker-ce-20.10.21-3.el8.aarch64.rpm",
+				"docker-ce-cli":             "docker-ce-cli-20.10.21-3.el8.aarch64.rpm",
+				"docker-ce-rootless-extras": "docker-ce-rootless-extras-20.10.21-3.el8.aarch64.rpm",
+				"container-selinux":         "container-selinux-2.138-4.oe2203.noarch.rpm",
+				"fuse-overlayfs":            "fuse-overlayfs-1.3.0-2.oe2203.aarch64.rpm",
+				"slirp4netns":               "slirp4netns-1.1.12-1.oe2203.aarch64.rpm",
+				"libcgroup":                 "libcgroup-0.42.2-3.oe2203.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on openEuler 20.03, see dep.OSKey
+	"openEuler-20.03": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"container-selinux": "container-selinux-2.73-3.oe1.noarch.rpm",
+				"fuse-overlayfs":    "fuse-overlayfs-0.3-2.oe1.x86_64.rpm",
+				"slirp4netns":       "slirp4netns-1.1.8-1.oe1.x86_64.rpm",
+				"libcgroup":         "libcgroup-0.42.2-1.oe1.x86_64.rpm",
+			},
+			"aarch64": {
+				"container-selinux": "container-selinux-2.73-3.oe1.noarch.rpm",
+				"fuse-overlayfs":    "fuse-overlayfs-0.3-2.oe1.aarch64.rpm",
+				"slirp4netns":       "slirp4netns-1.1.8-1.oe1.aarch64.rpm",
+				"libcgroup":         "libcgroup-0.42.2-1.oe1.aarch64.rpm",
 			},
 		},
 	},
-	"ubuntu": {},
 })
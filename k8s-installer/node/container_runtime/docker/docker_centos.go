@@ -107,6 +107,15 @@ func installDockerOnline() error {
 }
 
 func removeDocker() error {
+	if err := stopDockerAndRemoveSock(); err != nil {
+		return err
+	}
+
+	fileList := fileutils.GetDepList(dockerVersion)
+	return centos.UninstallPackage(fileList)
+}
+
+func stopDockerAndRemoveSock() error {
 	if err := family.StopSystemdService("docker.service"); err != nil {
 		return err
 	}
@@ -135,16 +144,14 @@ func removeDocker() error {
 		log.Errorf("Failed to remove /var/run/docker due to following error:")
 		log.Errorf("StdErr %s", stdErr.String())
 	}
-
-	fileList := fileutils.GetDepList(dockerVersion)
-	return centos.UninstallPackage(fileList)
+	return nil
 }
 
 func setUpDockerConfigFile(cri schema.TaskCRI, cluster schema.Cluster) error {
 	template := `{
   "data-root": "%s/docker",
   "insecure-registries": [%s],
-  "exec-opts": ["native.cgroupdriver=systemd"],
+  "exec-opts": ["native.cgroupdriver=%s"],
   "log-driver": "json-file",
   "log-opts": {
     "max-size": "%dm",
@@ -193,7 +200,7 @@ func setUpDockerConfigFile(cri schema.TaskCRI, cluster schema.Cluster) error {
 	rtc := cache.GetCurrentCache()
 	clientConf := rtc.GetClientRuntimeConfig(cache.NodeId)
 
-	err := util.WriteTxtToFile("/etc/docker/daemon.json", fmt.Sprintf(template, clientConf.CRIRootDir, registries, cri.LogSize, cri.LogMaxFile))
+	err := util.WriteTxtToFile("/etc/docker/daemon.json", fmt.Sprintf(template, clientConf.CRIRootDir, registries, osInfoProvider.GetCgroupDriver(), cri.LogSize, cri.LogMaxFile))
 	if err != nil {
 		log.Errorf("Failed to set docker config file /etc/docker/daemon.json due to error %s: ", err.Error())
 		return err
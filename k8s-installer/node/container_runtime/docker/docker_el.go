@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"k8s-installer/node/container_runtime"
+	"k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/centos"
+	"k8s-installer/node/os/family/el"
+	"k8s-installer/pkg/config/client"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+/*
+CRIDockerEL installs docker-ce from rpms on el 8/9 and openEuler
+docker 20.10 or later is required for cgroup v2 which el 9 boots with
+*/
+type CRIDockerEL struct {
+}
+
+func (C CRIDockerEL) Install(offline bool, taskCRI schema.TaskCRI, cluster schema.Cluster, config client.Config, resourceServerURL, k8sVersion string, md5Dep dep.DepMap) error {
+	var err error
+	if offline {
+		log.Debug("Offline is set to true. Go offline docker installation script")
+		err = installDockerOfflineEL(config, resourceServerURL, k8sVersion, md5Dep)
+	} else {
+		log.Debug("Offline is set to false. Go online docker installation script")
+		err = installDockerOnlineEL()
+	}
+	if err != nil {
+		log.Errorf("Failed to install docker due to error %s", err.Error())
+		return err
+	}
+
+	if taskCRI.CRIType.PrivateRegistryAddress != "" {
+		if err := setUpDockerConfigFile(taskCRI, cluster); err != nil {
+			return err
+		}
+	}
+
+	err = family.StartSystemdService(true, true, "docker")
+	if err != nil {
+		log.Errorf("Failed to start docker daemon due to error %s", err.Error())
+	}
+
+	container_runtime.LoadContainerRuntimeTar(config.LocalImagePath, constants.CRITypeDocker)
+	return err
+}
+
+func (C CRIDockerEL) Remove(config schema.TaskCRI) error {
+	if err := stopDockerAndRemoveSock(); err != nil {
+		return err
+	}
+	packages, err := el.DepPackageNames(dockerVersion)
+	if err != nil {
+		return err
+	}
+	return centos.UninstallPackage(packages)
+}
+
+func (C CRIDockerEL) CleanDataDir() error {
+	return CRIDockerCentos{}.CleanDataDir()
+}
+
+func installDockerOnlineEL() error {
+	if err := el.AddDockerDnfRepo(); err != nil {
+		return err
+	}
+	log.Debug("Install docker-ce")
+	return el.DnfInstall("docker-ce", "docker-ce-cli", "containerd.io")
+}
+
+func installDockerOfflineEL(config client.Config, resourceServerURL string, k8sVersion string, md5Dep dep.DepMap) error {
+	saveTo := config.YamlDataDir + "/docker"
+
+	if err := family.CommonDownloadDep(resourceServerURL, dockerVersion, saveTo,
+		k8sVersion, md5Dep); err != nil {
+		return err
+	}
+
+	log.Debug("Installing all docker rpms")
+	return el.InstallRpmPackages(saveTo)
+}
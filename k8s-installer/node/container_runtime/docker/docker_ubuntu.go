@@ -1 +1,75 @@
 package docker
+
+import (
+	"k8s-installer/node/container_runtime"
+	"k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/ubuntu"
+	"k8s-installer/pkg/config/client"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+type CRIDockerUbuntu struct {
+}
+
+func (C CRIDockerUbuntu) Install(offline bool, taskCRI schema.TaskCRI, cluster schema.Cluster, config client.Config, resourceServerURL, k8sVersion string, md5Dep dep.DepMap) error {
+	var err error
+	if offline {
+		log.Debug("Offline is set to true. Go offline docker installation script")
+		err = installDockerOfflineUbuntu(config, resourceServerURL, k8sVersion, md5Dep)
+	} else {
+		log.Debug("Offline is set to false. Go online docker installation script")
+		err = installDockerOnlineUbuntu()
+	}
+	if err != nil {
+		log.Errorf("Failed to install docker due to error %s", err.Error())
+		return err
+	}
+
+	if taskCRI.CRIType.PrivateRegistryAddress != "" {
+		if err := setUpDockerConfigFile(taskCRI, cluster); err != nil {
+			return err
+		}
+	}
+
+	err = family.StartSystemdService(true, true, "docker")
+	if err != nil {
+		log.Errorf("Failed to start docker daemon due to error %s", err.Error())
+	}
+
+	container_runtime.LoadContainerRuntimeTar(config.LocalImagePath, constants.CRITypeDocker)
+	return err
+}
+
+func (C CRIDockerUbuntu) Remove(config schema.TaskCRI) error {
+	if err := stopDockerAndRemoveSock(); err != nil {
+		return err
+	}
+	return ubuntu.UninstallPackage(ubuntu.DepPackageNames(dockerVersion))
+}
+
+func (C CRIDockerUbuntu) CleanDataDir() error {
+	return CRIDockerCentos{}.CleanDataDir()
+}
+
+func installDockerOnlineUbuntu() error {
+	if err := ubuntu.AddDockerAptRepo(); err != nil {
+		return err
+	}
+	log.Debug("Install docker-ce")
+	return ubuntu.AptInstall("docker-ce", "docker-ce-cli", "containerd.io")
+}
+
+func installDockerOfflineUbuntu(config client.Config, resourceServerURL string, k8sVersion string, md5Dep dep.DepMap) error {
+	saveTo := config.YamlDataDir + "/docker"
+
+	if err := family.CommonDownloadDep(resourceServerURL, dockerVersion, saveTo,
+		k8sVersion, md5Dep); err != nil {
+		return err
+	}
+
+	log.Debug("Installing all docker debs")
+	return ubuntu.InstallDebPackages(saveTo)
+}
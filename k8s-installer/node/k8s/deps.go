@@ -47,5 +47,160 @@ var KubeDepMapping dep.DepMap = schema.RegisterDep(dep.DepMap{
 			},
 		},
 	},
-	"ubuntu": {},
+	// ubuntu 20.04 debs
+	"ubuntu": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"conntrack":      "conntrack_1.4.5-2_amd64.deb",
+				"kubeadm":        "kubeadm_1.18.6-00_amd64.deb",
+				"cri-tools":      "cri-tools_1.13.0-01_amd64.deb",
+				"kubectl":        "kubectl_1.18.6-00_amd64.deb",
+				"ebtables":       "ebtables_2.0.11-3build1_amd64.deb",
+				"kubelet":        "kubelet_1.18.6-00_amd64.deb",
+				"ipvsadm":        "ipvsadm_1.31-1_amd64.deb",
+				"kubernetes-cni": "kubernetes-cni_0.8.6-00_amd64.deb",
+				"socat":          "socat_1.7.3.3-2_amd64.deb",
+			},
+			"aarch64": {
+				"conntrack":      "conntrack_1.4.5-2_arm64.deb",
+				"kubeadm":        "kubeadm_1.18.6-00_arm64.deb",
+				"cri-tools":      "cri-tools_1.13.0-01_arm64.deb",
+				"kubectl":        "kubectl_1.18.6-00_arm64.deb",
+				"ebtables":       "ebtables_2.0.11-3build1_arm64.deb",
+				"kubelet":        "kubelet_1.18.6-00_arm64.deb",
+				"ipvsadm":        "ipvsadm_1.31-1_arm64.deb",
+				"kubernetes-cni": "kubernetes-cni_0.8.6-00_arm64.deb",
+				"socat":          "socat_1.7.3.3-2_arm64.deb",
+			},
+		},
+	},
+	// debs which differ on ubuntu 18.04, see dep.OSKey
+	"ubuntu-18.04": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"conntrack": "conntrack_1.4.4+snapshot20161117-6ubuntu2_amd64.deb",
+				"ebtables":  "ebtables_2.0.10.4-3.5ubuntu2.18.04.3_amd64.deb",
+				"ipvsadm":   "ipvsadm_1.28-3ubuntu0.18.04.1_amd64.deb",
+				"socat":     "socat_1.7.3.2-2ubuntu2_amd64.deb",
+			},
+			"aarch64": {
+				"conntrack": "conntrack_1.4.4+snapshot20161117-6ubuntu2_arm64.deb",
+				"ebtables":  "ebtables_2.0.10.4-3.5ubuntu2.18.04.3_arm64.deb",
+				"ipvsadm":   "ipvsadm_1.28-3ubuntu0.18.04.1_arm64.deb",
+				"socat":     "socat_1.7.3.2-2ubuntu2_arm64.deb",
+			},
+		},
+	},
+	// el 8 rpms, rocky almalinux and rhel share them
+	"el": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"kubeadm":                "kubeadm-1.18.6-0.x86_64.rpm",
+				"kubectl":                "kubectl-1.18.6-0.x86_64.rpm",
+				"kubelet":                "kubelet-1.18.6-0.x86_64.rpm",
+				"cri-tools":              "cri-tools-1.13.0-0.x86_64.rpm",
+				"kubernetes-cni":         "kubernetes-cni-0.8.7-0.x86_64.rpm",
+				"conntrack":              "conntrack-tools-1.4.4-11.el8.x86_64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-15.el8.x86_64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-11.el8.x86_64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.4-3.el8.x86_64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-1.el8.x86_64.rpm",
+				"socat":                  "socat-1.7.4.1-1.el8.x86_64.rpm",
+				"iproute-tc":             "iproute-tc-5.18.0-1.el8.x86_64.rpm",
+			},
+			"aarch64": {
+				"kubeadm":                "kubeadm-1.18.6-0.aarch64.rpm",
+				"kubectl":                "kubectl-1.18.6-0.aarch64.rpm",
+				"kubelet":                "kubelet-1.18.6-0.aarch64.rpm",
+				"cri-tools":              "cri-tools-1.13.0-0.aarch64.rpm",
+				"kubernetes-cni":         "kubernetes-cni-0.8.7-0.aarch64.rpm",
+				"conntrack":              "conntrack-tools-1.4.4-11.el8.aarch64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-15.el8.aarch64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-11.el8.aarch64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.4-3.el8.aarch64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-1.el8.aarch64.rpm",
+				"socat":                  "socat-1.7.4.1-1.el8.aarch64.rpm",
+				"iproute-tc":             "iproute-tc-5.18.0-1.el8.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on el 9, see dep.OSKey
+	"el-9": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"conntrack":              "conntrack-tools-1.4.7-2.el9.x86_64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-22.el9.x86_64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-19.el9.x86_64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.5-1.el9.x86_64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-6.el9.x86_64.rpm",
+				"socat":                  "socat-1.7.4.1-5.el9.x86_64.rpm",
+				"iproute-tc":             "iproute-tc-6.2.0-5.el9.x86_64.rpm",
+			},
+			"aarch64": {
+				"conntrack":              "conntrack-tools-1.4.7-2.el9.aarch64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-22.el9.aarch64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-19.el9.aarch64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.5-1.el9.aarch64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-6.el9.aarch64.rpm",
+				"socat":                  "socat-1.7.4.1-5.el9.aarch64.rpm",
+				"iproute-tc":             "iproute-tc-6.2.0-5.el9.aarch64.rpm",
+			},
+		},
+	},
+	// openEuler 22.03 rpms
+	"openEuler": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"kubeadm":                "kubeadm-1.18.6-0.x86_64.rpm",
+				"kubectl":                "kubectl-1.18.6-0.x86_64.rpm",
+				"kubelet":                "kubelet-1.18.6-0.x86_64.rpm",
+				"cri-tools":              "cri-tools-1.13.0-0.x86_64.rpm",
+				"kubernetes-cni":         "kubernetes-cni-0.8.7-0.x86_64.rpm",
+				"conntrack":              "conntrack-tools-1.4.6-6.oe2203.x86_64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-16.oe2203.x86_64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-15.oe2203.x86_64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.5-2.oe2203.x86_64.rpm",
+				"ebtables":               "ebtables-2.0.11-10.oe2203.x86_64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-4.oe2203.x86_64.rpm",
+				"socat":                  "socat-1.7.3.2-8.oe2203.x86_64.rpm",
+			},
+			"aarch64": {
+				"kubeadm":                "kubeadm-1.18.6-0.aarch64.rpm",
+				"kubectl":                "kubectl-1.18.6-0.aarch64.rpm",
+				"kubelet":                "kubelet-1.18.6-0.aarch64.rpm",
+				"cri-tools":              "cri-tools-1.13.0-0.aarch64.rpm",
+				"kubernetes-cni":         "kubernetes-cni-0.8.7-0.aarch64.rpm",
+				"conntrack":              "conntrack-tools-1.4.6-6.oe2203.aarch64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-16.oe2203.aarch64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-15.oe2203.aarch64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.5-2.oe2203.aarch64.rpm",
+				"ebtables":               "ebtables-2.0.11-10.oe2203.aarch64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-4.oe2203.aarch64.rpm",
+				"socat":                  "socat-1.7.3.2-8.oe2203.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on openEuler 20.03, see dep.OSKey
+	"openEuler-20.03": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"conntrack":              "conntrack-tools-1.4.6-1.oe1.x86_64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-15.oe1.x86_64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-13.oe1.x86_64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.2-14.oe1.x86_64.rpm",
+				"ebtables":               "ebtables-2.0.11-6.oe1.x86_64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-2.oe1.x86_64.rpm",
+				"socat":                  "socat-1.7.3.2-8.oe1.x86_64.rpm",
+			},
+			"aarch64": {
+				"conntrack":              "conntrack-tools-1.4.6-1.oe1.aarch64.rpm",
+				"libnetfilter_cthelper":  "libnetfilter_cthelper-1.0.0-15.oe1.aarch64.rpm",
+				"libnetfilter_cttimeout": "libnetfilter_cttimeout-1.0.0-13.oe1.aarch64.rpm",
+				"libnetfilter_queue":     "libnetfilter_queue-1.0.2-14.oe1.aarch64.rpm",
+				"ebtables":               "ebtables-2.0.11-6.oe1.aarch64.rpm",
+				"ipvsadm":                "ipvsadm-1.31-2.oe1.aarch64.rpm",
+				"socat":                  "socat-1.7.3.2-8.oe1.aarch64.rpm",
+			},
+		},
+	},
 })
@@ -9,7 +9,10 @@ import (
 	"strings"
 	"time"
 
+	osInfoProvider "k8s-installer/node/os"
 	"k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/el"
+	"k8s-installer/node/os/family/ubuntu"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/command"
 	"k8s-installer/pkg/config/client"
@@ -99,8 +102,20 @@ func DestroyKubeNode(kubeadm schema.TaskKubeadm, config client.Config, cluster s
 		return nil, err
 	}
 
+	osFamily, _ := osInfoProvider.GetOSFamily()
+	if osFamily == constants.OSFamilyUbuntu {
+		// debs of every kubernetes version share the same package names
+		// failure already logged as warning and ignored like rpm removal does
+		_ = ubuntu.UninstallPackage(ubuntu.DepPackageNames(KubeDepMapping))
+		return nil, nil
+	}
+
 	if cluster.ControlPlane.KubernetesVersion == constants.V1_18_6 {
 		packages := fileutils.GetDepList(KubeDepMapping)
+		if osFamily == constants.OSFamilyEL || osFamily == constants.OSFamilyOpenEuler {
+			// rpm names differ from centos 7 ones
+			packages, _ = el.DepPackageNames(KubeDepMapping)
+		}
 		subCommand := []string{"-e", "--nodeps"}
 		subCommand = append(subCommand, packages...)
 		// run setenforce 0
@@ -474,7 +489,8 @@ func createKubeletInitConfiguration(cluster schema.Cluster, configuration *kubea
                 KubeletConfiguration.FeatureGates["IPv6DualStack"] = true
         }
 
-	KubeletConfiguration.CgroupDriver = "systemd"
+	// kubelet config is shared by every node via kubelet-config configmap, PreCheck makes sure all nodes agree on the driver
+	KubeletConfiguration.CgroupDriver = osInfoProvider.GetCgroupDriver()
 
 	if cluster.ContainerRuntime.CRIType != constants.CRITypeDocker {
 		rtc := cache.GetCurrentCache()
@@ -24,5 +24,74 @@ var HaproxyVersionMapping dep.DepMap = schema.RegisterDep(dep.DepMap{
 			},
 		},
 	},
-	"ubuntu": {},
+	// ubuntu 20.04 debs
+	"ubuntu": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy":     "haproxy_2.0.13-2ubuntu0.1_amd64.deb",
+				"liblua5.3-0": "liblua5.3-0_5.3.3-1.1ubuntu2_amd64.deb",
+			},
+			"aarch64": {
+				"haproxy":     "haproxy_2.0.13-2ubuntu0.1_arm64.deb",
+				"liblua5.3-0": "liblua5.3-0_5.3.3-1.1ubuntu2_arm64.deb",
+			},
+		},
+	},
+	// debs which differ on ubuntu 18.04, see dep.OSKey
+	"ubuntu-18.04": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy":     "haproxy_1.8.8-1ubuntu0.11_amd64.deb",
+				"liblua5.3-0": "liblua5.3-0_5.3.3-1ubuntu0.18.04.1_amd64.deb",
+			},
+			"aarch64": {
+				"haproxy":     "haproxy_1.8.8-1ubuntu0.11_arm64.deb",
+				"liblua5.3-0": "liblua5.3-0_5.3.3-1ubuntu0.18.04.1_arm64.deb",
+			},
+		},
+	},
+	// el 8 rpms
+	"el": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy": "haproxy-1.8.27-5.el8.x86_64.rpm",
+			},
+			"aarch64": {
+				"haproxy": "haproxy-1.8.27-5.el8.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on el 9, see dep.OSKey
+	"el-9": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy": "haproxy-2.4.17-3.el9.x86_64.rpm",
+			},
+			"aarch64": {
+				"haproxy": "haproxy-2.4.17-3.el9.aarch64.rpm",
+			},
+		},
+	},
+	// openEuler 22.03 rpms
+	"openEuler": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy": "haproxy-2.4.8-2.oe2203.x86_64.rpm",
+			},
+			"aarch64": {
+				"haproxy": "haproxy-2.4.8-2.oe2203.aarch64.rpm",
+			},
+		},
+	},
+	// rpms which differ on openEuler 20.03, see dep.OSKey
+	"openEuler-20.03": {
+		constants.V1_18_6: {
+			"x86_64": {
+				"haproxy": "haproxy-2.0.14-2.oe1.x86_64.rpm",
+			},
+			"aarch64": {
+				"haproxy": "haproxy-2.0.14-2.oe1.aarch64.rpm",
+			},
+		},
+	},
 })
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	osInfoProvider "k8s-installer/node/os"
 	"k8s-installer/node/os/family"
 	"k8s-installer/pkg/command"
 	"k8s-installer/pkg/config/client"
@@ -19,6 +20,28 @@ type Haproxy struct {
 }
 
 func (h Haproxy) Install(offline bool, task schema.TaskLoadBalance, cluster schema.Cluster, config client.Config, resourceServerURL, osVersion, cpuArch string, md5Dep dep.DepMap) error {
+	osFamily, err := osInfoProvider.GetOSFamily()
+	if err != nil {
+		return err
+	}
+	if osFamily == constants.OSFamilyUbuntu {
+		err = installHaproxyUbuntu(offline, config, resourceServerURL, md5Dep)
+	} else {
+		err = installHaproxyCentos(offline, config, resourceServerURL, md5Dep)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Creating haproxy config to /etc/haproxy/haproxy.cfg")
+
+	if err := util.WriteTxtToFile("/etc/haproxy/haproxy.cfg", string(task.ProxyConfig)); err != nil {
+		return err
+	}
+	return err
+}
+
+func installHaproxyCentos(offline bool, config client.Config, resourceServerURL string, md5Dep dep.DepMap) error {
 	var stdErr bytes.Buffer
 	var err error
 
@@ -49,19 +72,19 @@ func (h Haproxy) Install(offline bool, task schema.TaskLoadBalance, cluster sche
 			return err
 		}
 	}
-
-	log.Debug("Creating haproxy config to /etc/haproxy/haproxy.cfg")
-
-	if err := util.WriteTxtToFile("/etc/haproxy/haproxy.cfg", string(task.ProxyConfig)); err != nil {
-		return err
-	}
-	return err
+	return nil
 }
 
 func (h Haproxy) Remove() error {
 	log.Debug("Try to remove haproxy")
+	osFamily, err := osInfoProvider.GetOSFamily()
+	if err != nil {
+		return err
+	}
+	if osFamily == constants.OSFamilyUbuntu {
+		return removeHaproxyUbuntu()
+	}
 	var stdErr bytes.Buffer
-	var err error
 	_, stdErr, err = command.RunCmd("yum", "remove", "haproxy", "-y")
 	if err != nil {
 		log.Errorf("Failed to remove haproxy due to following error:")
@@ -0,0 +1,28 @@
+package loadbalancer
+
+import (
+	"k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/ubuntu"
+	"k8s-installer/pkg/config/client"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+)
+
+func installHaproxyUbuntu(offline bool, config client.Config, resourceServerURL string, md5Dep dep.DepMap) error {
+	if offline {
+		log.Debug("Installing haproxy offline")
+		saveTo := config.YamlDataDir + "/haproxy"
+		if err := family.CommonDownloadDep(resourceServerURL, HaproxyVersionMapping, saveTo, constants.V1_18_6, md5Dep); err != nil {
+			return err
+		}
+		log.Debug("Installing all haproxy debs")
+		return ubuntu.InstallDebPackages(saveTo)
+	}
+	log.Debug("Installing haproxy online")
+	return ubuntu.AptInstall("haproxy")
+}
+
+func removeHaproxyUbuntu() error {
+	return ubuntu.UninstallPackage(ubuntu.DepPackageNames(HaproxyVersionMapping))
+}
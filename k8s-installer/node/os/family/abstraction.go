@@ -1,6 +1,8 @@
 package family
 
 import (
+	"context"
+
 	"k8s-installer/pkg/dep"
 	"k8s-installer/schema"
 
@@ -27,15 +29,16 @@ type IOSVersion interface {
 	JoinOrDestroyControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5 dep.DepMap) (map[string]string, error)
 	JoinOrDestroyWorkNode(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5 dep.DepMap) (map[string]string, error)
 	RunKubectl(kubectl schema.TaskKubectl, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
-	RunCommand(commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
-	AsyncRunCommand(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
+	// ctx is done when node step is aborted by server
+	RunCommand(ctx context.Context, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
+	AsyncRunCommand(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
 	CopyTextFile(fileToCopy schema.TaskCopyTextBaseFile, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error)
 	InstallOrDestroyVirtualKubelet(vk schema.TaskVirtualKubelet, resourceServerURL string, clusterInformation schema.Cluster, md5 dep.DepMap) (map[string]string, error)
 	PrintJoinString(printJoinString schema.TaskPrintJoin) (map[string]string, error)
 	CommonLink(from dep.DepMap, saveTo string, linkTo string) (map[string]string, error)
 	GoCurl(task schema.TaskCurl) (map[string]string, error)
-	CommonDownloadDep(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep dep.DepMap, saveTo string, k8sVersion string, md5 dep.DepMap) (map[string]string, error)
-	CommonDownload(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error)
+	CommonDownloadDep(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep dep.DepMap, saveTo string, k8sVersion string, md5 dep.DepMap) (map[string]string, error)
+	CommonDownload(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error)
 	GenerateKSClusterConfig(cluster schema.Cluster, ipAddress string) (map[string]string, error)
 	ConfigPromtail(clusterInformatInstallOrDestroyVirtualKubeletion schema.Cluster) (map[string]string, error)
 	// to ask client load container image
@@ -2,6 +2,7 @@ package version
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -529,7 +530,7 @@ func (v V7) RunKubectl(kubectl schema.TaskKubectl, preReturnData map[string]stri
 	return k8s.KubectlExecutor(kubectl, v.Config)
 }
 
-func (v V7) RunCommand(commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
+func (v V7) RunCommand(ctx context.Context, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
 	var err error
 	var stdErr, stdOut bytes.Buffer
 	result := map[string]string{}
@@ -545,7 +546,7 @@ func (v V7) RunCommand(commandToRun schema.TaskRunCommand, preReturnData map[str
 		if len(commandToRun.Commands[index]) == 0 {
 			continue
 		}
-		stdOut, stdErr, err = command.RunCmd(commandToRun.Commands[index][0], commandToRun.Commands[index][1:]...)
+		stdOut, stdErr, err = command.RunCmdContext(ctx, commandToRun.Commands[index][0], commandToRun.Commands[index][1:]...)
 		if err != nil {
 			log.Errorf("Failed to run command %v due to following error:", commandToRun.Commands[index])
 			errMsg := fmt.Sprintf("Global error: %s. Std error: %s", err.Error(), stdErr.String())
@@ -581,7 +582,7 @@ func (v V7) RunCommand(commandToRun schema.TaskRunCommand, preReturnData map[str
 	}
 }
 
-func (v V7) AsyncRunCommand(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
+func (v V7) AsyncRunCommand(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
 	var err error
 	var stdErr, stdOut bytes.Buffer
 	stat := constants.StatusSuccessful
@@ -599,7 +600,7 @@ func (v V7) AsyncRunCommand(operationId string, nodeId string, nodeStepId string
 			continue
 		}
 		// ignore stdout
-		stdOut, stdErr, err = command.RunCmd(commandToRun.Commands[index][0], commandToRun.Commands[index][1:]...)
+		stdOut, stdErr, err = command.RunCmdContext(ctx, commandToRun.Commands[index][0], commandToRun.Commands[index][1:]...)
 		if err != nil {
 			log.Errorf("Failed to run command %s due to following error:", commandToRun.Commands[index][0])
 			errMsg := fmt.Sprintf("Global error: %s. Std error:%s", err.Error(), stdErr.String())
@@ -781,11 +782,11 @@ func (v V7) GoCurl(task schema.TaskCurl) (map[string]string, error) {
 	}, nil
 }
 
-func (v V7) CommonDownloadDep(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Sum depMap.DepMap) (map[string]string, error) {
+func (v V7) CommonDownloadDep(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Sum depMap.DepMap) (map[string]string, error) {
 
 	stat := constants.StatusSuccessful
 	var message string
-	err := family.CommonDownloadDep(resourceServerURL, dep, saveTo, k8sVersion, md5Sum)
+	err := family.CommonDownloadDepContext(ctx, resourceServerURL, dep, saveTo, k8sVersion, md5Sum)
 	if err != nil {
 		message = fmt.Sprintf("Operation %s excution failed with node task %s on node %s due to error %v",
 			operationId,
@@ -803,11 +804,11 @@ func (v V7) CommonDownloadDep(operationId string, nodeId string, nodeStepId stri
 	return nil, err
 }
 
-func (v V7) CommonDownload(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error) {
+func (v V7) CommonDownload(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error) {
 
 	stat := constants.StatusSuccessful
 	var message string
-	err := family.CommonDownload(resourceServerURL, FromDir, k8sVersion, fileList, saveTo, isUseDefaultPath, nil)
+	err := family.CommonDownloadContext(ctx, resourceServerURL, FromDir, k8sVersion, fileList, saveTo, isUseDefaultPath, nil)
 	if err != nil {
 		message = fmt.Sprintf("Operation %s excution failed with node task %s on node %s due to error %v",
 			operationId,
@@ -2,26 +2,31 @@ package family
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"path"
 	"reflect"
 	"strings"
 
+	containerRuntime "k8s-installer/node/container_runtime"
 	blockDevice "k8s-installer/pkg/block_device"
 	depMap "k8s-installer/pkg/dep"
 
 	osInfoProvider "k8s-installer/node/os"
 	"k8s-installer/pkg/command"
+	"k8s-installer/pkg/config/client"
 	config "k8s-installer/pkg/config/downloader"
 	"k8s-installer/pkg/downloader"
 	backdown "k8s-installer/pkg/downloader/back_downloader"
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/tracing"
 	"k8s-installer/pkg/util"
 	"k8s-installer/pkg/util/fileutils"
 	"k8s-installer/schema"
 
 	natsLib "github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func StartSystemdService(enabled, restart bool, unit string) error {
@@ -262,16 +267,23 @@ vm.max_map_count=262144
 // 	},
 // }
 
-// Download from /resourceServer/{k8sVersion}/{osInfo.OS.Vendor}/{osInfo.OS.Version}/{osInfo.Kernel.Architecture}/"package"/{file}
-// file = dep[osInfo.OS.Vendor][k8sVersion][osInfo.Kernel.Architecture]
+// Download from /resourceServer/{k8sVersion}/{osFamily}/{osVersion}/{osInfo.Kernel.Architecture}/"package"/{file}
+// file = dep[osFamily][k8sVersion][osInfo.Kernel.Architecture] overridden by dep["{osFamily}-{osVersion}"][k8sVersion][osInfo.Kernel.Architecture]
+// osFamily and osVersion are os vendor and version normalized by os.NormalizeOSFamily e.g. rocky 8.6 -> el 8
 // Save to: {saveTo}/{file}
 func CommonDownloadDep(resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Dep depMap.DepMap) error {
+	return CommonDownloadDepContext(context.Background(), resourceServerURL, dep, saveTo, k8sVersion, md5Dep)
+}
+
+// CommonDownloadDepContext is CommonDownloadDep with download spans started under ctx
+func CommonDownloadDepContext(ctx context.Context, resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Dep depMap.DepMap) error {
 
 	osInfo, errOSInfo := osInfoProvider.GetAllSystemInformation()
 	if errOSInfo != nil {
 		log.Errorf("Failed to get node cpu arch due to error %s", errOSInfo.Error())
 		return errOSInfo
 	}
+	osFamily, osVersion := osInfoProvider.NormalizeOSFamily(osInfo.OS.Vendor, osInfo.OS.Version)
 
 	_m, errMarshal := json.Marshal(dep)
 	if errMarshal != nil {
@@ -286,27 +298,60 @@ func CommonDownloadDep(resourceServerURL string, dep depMap.DepMap, saveTo strin
 
 	var fileList []string
 	var md5List []string
-	log.Debugf("Try to range %v[%v][%v][%v]", reflect.TypeOf(m).String(), osInfo.OS.Vendor, k8sVersion, osInfo.Kernel.Architecture)
-	if _, ok := m[osInfo.OS.Vendor][k8sVersion][osInfo.Kernel.Architecture]; !ok {
-		return fmt.Errorf("The %v do not support %v[%v][%v][%v]", reflect.TypeOf(m).String(), reflect.TypeOf(m).String(), osInfo.OS.Vendor, k8sVersion, osInfo.Kernel.Architecture)
+	log.Debugf("Try to range %v[%v][%v][%v]", reflect.TypeOf(m).String(), osFamily, k8sVersion, osInfo.Kernel.Architecture)
+	packages, ok := m.Packages(osFamily, osVersion, k8sVersion, osInfo.Kernel.Architecture)
+	if !ok {
+		return fmt.Errorf("The %v do not support %v[%v][%v][%v]", reflect.TypeOf(m).String(), reflect.TypeOf(m).String(), osFamily, k8sVersion, osInfo.Kernel.Architecture)
 	}
+	md5Packages, _ := md5Dep.Packages(osFamily, osVersion, k8sVersion, osInfo.Kernel.Architecture)
 
-	for k, v := range m[osInfo.OS.Vendor][k8sVersion][osInfo.Kernel.Architecture] {
+	for k, v := range packages {
 		fileList = append(fileList, v)
 		if md5Dep != nil {
-			md5List = append(md5List, md5Dep[osInfo.OS.Vendor][k8sVersion][osInfo.Kernel.Architecture][k])
+			md5List = append(md5List, md5Packages[k])
 		}
 		// log.Debugf("md5Dep:%v md5List:%v", md5Dep, md5List)
 	}
 
-	errCommonDownload := CommonDownload(resourceServerURL, "", k8sVersion, fileList, saveTo, true, md5List)
+	errCommonDownload := CommonDownloadContext(ctx, resourceServerURL, "", k8sVersion, fileList, saveTo, true, md5List)
 	if errCommonDownload != nil {
 		return errCommonDownload
 	}
 	return nil
 }
 
+/*
+MergeAdditionalVersionDep returns a copy of dep merged with packages of upgraded k8s version
+only packages kept under os family or it`s os version specific key e.g. ubuntu-18.04 are merged so dep itself stays untouched
+*/
+func MergeAdditionalVersionDep(dep, additionalDep depMap.DepMap, osFamily string) depMap.DepMap {
+	mergedDep := depMap.DepMap{}
+	for osKey, versions := range dep {
+		mergedDep[osKey] = depMap.DepVersion{}
+		for k8sVersion, arches := range versions {
+			mergedDep[osKey][k8sVersion] = arches
+		}
+	}
+	for osKey, versions := range additionalDep {
+		if osKey != osFamily && !strings.HasPrefix(osKey, osFamily+"-") {
+			continue
+		}
+		if _, found := mergedDep[osKey]; !found {
+			mergedDep[osKey] = depMap.DepVersion{}
+		}
+		for k8sVersion, arches := range versions {
+			mergedDep[osKey][k8sVersion] = arches
+		}
+	}
+	return mergedDep
+}
+
 func CommonDownload(resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool, md5List []string) error {
+	return CommonDownloadContext(context.Background(), resourceServerURL, FromDir, k8sVersion, fileList, saveTo, isUseDefaultPath, md5List)
+}
+
+// CommonDownloadContext is CommonDownload with download spans started under ctx
+func CommonDownloadContext(ctx context.Context, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool, md5List []string) error {
 
 	osInfo, errOSInfo := osInfoProvider.GetAllSystemInformation()
 	if errOSInfo != nil {
@@ -321,7 +366,8 @@ func CommonDownload(resourceServerURL string, FromDir string, k8sVersion string,
 
 	DefaultDownloadDir := ""
 	if isUseDefaultPath {
-		DefaultDownloadDir = path.Join(k8sVersion, osInfo.OS.Vendor, osInfo.OS.Version, osInfo.Kernel.Architecture, "package")
+		osFamily, osVersion := osInfoProvider.NormalizeOSFamily(osInfo.OS.Vendor, osInfo.OS.Version)
+		DefaultDownloadDir = path.Join(k8sVersion, osFamily, osVersion, osInfo.Kernel.Architecture, "package")
 	}
 
 	for k, f := range fileList {
@@ -340,7 +386,12 @@ func CommonDownload(resourceServerURL string, FromDir string, k8sVersion string,
 		getter := backdown.NewBackDownloader(cfg)
 
 		// log.Debugf("Try to download file from %s to %s", cfg.URL, cfg.RV.RealTarget)
-		if errDoDownloadTimeout := downloader.DoDownloadTimeout(getter, timeout); errDoDownloadTimeout != nil {
+		downloadCtx, span := tracing.Start(ctx, "download "+path.Base(f),
+			attribute.String("download.url", cfg.URL),
+			attribute.String("download.target", cfg.RV.RealTarget))
+		errDoDownloadTimeout := downloader.DoDownloadTimeoutContext(downloadCtx, getter, timeout)
+		tracing.End(span, errDoDownloadTimeout)
+		if errDoDownloadTimeout != nil {
 			log.Errorf("Failed to down file %s to %s due to error: %s", cfg.URL, cfg.RV.RealTarget, errDoDownloadTimeout.Error())
 			return errDoDownloadTimeout
 		}
@@ -391,3 +442,37 @@ func MountOrUmount(dev string, targetDir string, fsType string, enableBootCheck
 	}
 	return nil
 }
+
+// UmountOrRemoveCRIDataDir umounts cri device from data dir if it`s mounted otherwise cleans the data dir
+func UmountOrRemoveCRIDataDir(dev, targetDir, backupFilePath string, cri containerRuntime.INodeContainerRuntime) error {
+	if cri != nil {
+		if isMount, err := blockDevice.IsMountPoint(targetDir); err != nil || !isMount {
+			// simply remove the container data dir
+			if err := cri.CleanDataDir(); err != nil {
+				return err
+			}
+		} else {
+			// umount the device
+			if err := blockDevice.Mount(dev, "", blockDevice.XFS, true, true, true, backupFilePath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func MountIfCriDevIsSet(mountPoint string, config client.Config, enableBootCheck bool, umount bool, force bool) error {
+	if config.CRIMountDev == "" {
+		log.Warn("Cri device is not set, skip mount use os disk")
+		return nil
+	}
+
+	if result, err := blockDevice.DeviceIsReady(config.CRIMountDev); err != nil {
+		log.Errorf("Failed to check Cri Device %s due to error %s.", config.CRIMountDev, err.Error())
+		return err
+	} else if !result {
+		log.Warnf("Cri Device %s is not ready please ensure the device is ready", mountPoint)
+		return err
+	}
+	return MountOrUmount(config.CRIMountDev, mountPoint, blockDevice.XFS, enableBootCheck, umount, force, config.YamlDataDir)
+}
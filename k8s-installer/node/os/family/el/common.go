@@ -0,0 +1,148 @@
+package el
+
+import (
+	"bytes"
+	"strings"
+
+	osInfoProvider "k8s-installer/node/os"
+	"k8s-installer/pkg/command"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/util"
+)
+
+const dockerRepoFile = "/etc/yum.repos.d/docker-ce.repo"
+
+func DnfInstall(packages ...string) error {
+	var stdErr bytes.Buffer
+	var err error
+	subCommand := []string{"install", "-y"}
+	subCommand = append(subCommand, packages...)
+	log.Debugf("Try to run command dnf install -y %s", strings.Join(packages, " "))
+	_, stdErr, err = command.RunCmd("dnf", subCommand...)
+	if err != nil {
+		log.Errorf("Failed to install %s due to following error:", strings.Join(packages, ","))
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	return nil
+}
+
+// InstallRpmPackages installs all .rpm files in given dir
+func InstallRpmPackages(dir string) error {
+	var stdErr bytes.Buffer
+	var err error
+	log.Debugf("Installing all rpms in %s", dir)
+	_, stdErr, err = command.RunCmd("rpm", "-ivh", "--replacefiles", "--replacepkgs", "--nodeps", dir+"/*.rpm")
+	if err != nil {
+		log.Errorf("Failed to install rpms in %s due to following error:", dir)
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	return nil
+}
+
+/*
+DepPackageNames returns name of rpms in dep for os family and version of this node which is what rpm -e knows them by
+rpm file names are used rather than keys of dep since keys are not always the package name e.g. conntrack
+*/
+func DepPackageNames(depMap dep.DepMap) ([]string, error) {
+	osInfo, err := osInfoProvider.GetAllSystemInformation()
+	if err != nil {
+		return nil, err
+	}
+	osFamily, osVersion := osInfoProvider.NormalizeOSFamily(osInfo.OS.Vendor, osInfo.OS.Version)
+	var names []string
+	packages, _ := depMap.Packages(osFamily, osVersion, constants.V1_18_6, osInfo.Kernel.Architecture)
+	for _, file := range packages {
+		names = append(names, strings.TrimSuffix(file, ".rpm"))
+	}
+	return names, nil
+}
+
+// AddDockerDnfRepo adds docker-ce repo which serves el 8 and el 9, openEuler is pointed to el 8 packages of it
+func AddDockerDnfRepo() error {
+	var stdErr bytes.Buffer
+	var err error
+	if err = DnfInstall("dnf-plugins-core"); err != nil {
+		return err
+	}
+	log.Debug("Download repo for docker")
+	_, stdErr, err = command.RunCmd("dnf", "config-manager", "--add-repo", "https://download.docker.com/linux/centos/docker-ce.repo")
+	if err != nil {
+		log.Errorf("Failed to add docker repo due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+
+	osFamily, err := osInfoProvider.GetOSFamily()
+	if err != nil {
+		return err
+	}
+	if osFamily == constants.OSFamilyOpenEuler {
+		// $releasever of openEuler is e.g. 22.03 which docker-ce repo knows nothing about
+		log.Debug("Pin docker repo to el 8 for openEuler")
+		_, stdErr, err = command.RunCmd("sed", "-i", "s/\\$releasever/8/g", dockerRepoFile)
+		if err != nil {
+			log.Errorf("Failed to pin docker repo to el 8 due to following error:")
+			log.Errorf("StdErr %s", stdErr.String())
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+DisableNftablesService stops nftables.service if it`s active
+the service flushes the whole ruleset on reload which wipes out rules written by kube-proxy and cni through iptables-nft
+*/
+func DisableNftablesService() error {
+	var stdErr bytes.Buffer
+	var err error
+	_, _, err = command.RunCmd("systemctl", "is-active", "--quiet", "nftables")
+	if err != nil {
+		log.Debug("Service nftables is inactive. Skip!!!")
+		return nil
+	}
+	log.Debug("Service nftables is active. Run stop and disable script!!!")
+	_, stdErr, err = command.RunCmd("systemctl", "disable", "--now", "nftables")
+	if err != nil {
+		log.Error("Failed to disable nftables due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	return nil
+}
+
+// IptablesNftBackend tells whether iptables of the node is iptables-nft rather than iptables-legacy
+func IptablesNftBackend() bool {
+	stdOut, _, err := command.RunCmd("iptables", "-V")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(stdOut.String(), "nf_tables")
+}
+
+func OnlineInstallK8sDnfDep(enableIpvs bool) error {
+	// packages.cloud.google.com only publishes el7 repo which works for el 8 and el 9 as well
+	kubernetesRepo := `[kubernetes]
+name=Kubernetes
+baseurl=https://packages.cloud.google.com/yum/repos/kubernetes-el7-$basearch
+enabled=1
+gpgcheck=1
+repo_gpgcheck=1
+gpgkey=https://packages.cloud.google.com/yum/doc/yum-key.gpg https://packages.cloud.google.com/yum/doc/rpm-package-key.gpg
+exclude=kubelet kubeadm kubectl`
+	log.Debug("Try to set repo...")
+	if err := util.WriteTxtToFile("/etc/yum.repos.d/kubernetes.repo", kubernetesRepo); err != nil {
+		return err
+	}
+
+	// ebtables is gone since el 8, tc is shipped separately as iproute-tc and checked by kubeadm preflight
+	packages := []string{"kubelet-1.18.6", "kubeadm-1.18.6", "kubectl-1.18.6", "iproute-tc", "--disableexcludes=kubernetes"}
+	if enableIpvs {
+		packages = append(packages, "ipvsadm")
+	}
+	return DnfInstall(packages...)
+}
@@ -0,0 +1,22 @@
+package el
+
+import "k8s-installer/node/os/family"
+
+/*
+EL stands for rhel 8/9 and it`s rebuilds rocky and almalinux
+*/
+type EL struct {
+	Version family.IOSVersion
+}
+
+func (c EL) GetOSVersion() family.IOSVersion {
+	return c.Version
+}
+
+func (c EL) GetOSFamily() string {
+	return "el"
+}
+
+func (c EL) GetOSFullName() string {
+	panic("implement me")
+}
@@ -0,0 +1,323 @@
+package version
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+
+	containerRuntime "k8s-installer/node/container_runtime"
+	"k8s-installer/node/container_runtime/containerd"
+	"k8s-installer/node/container_runtime/docker"
+	"k8s-installer/node/k8s"
+	osInfoProvider "k8s-installer/node/os"
+	linuxFamily "k8s-installer/node/os/family"
+	"k8s-installer/node/os/family/centos"
+	centosVersion "k8s-installer/node/os/family/centos/version"
+	"k8s-installer/node/os/family/el"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/command"
+	"k8s-installer/pkg/constants"
+	depMap "k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/util"
+	"k8s-installer/pkg/util/fileutils"
+	"k8s-installer/schema"
+)
+
+/*
+stands for rhel 8 and it`s rebuilds
+it inherits centos 7 for everything but package manager, firewall and container runtime
+packages are installed with dnf or from rpms kept under el or el-{major version} in dep
+*/
+type V8 struct {
+	centosVersion.V7
+}
+
+func (v V8) BasicNodeSetup(basicSetup schema.TaskBasicConfig, clusterInformation schema.Cluster) (map[string]string, error) {
+	if basicSetup.Action == constants.ActionCreate {
+		return v.basicNodeSetup(basicSetup, clusterInformation)
+	}
+	return nil, nil
+}
+
+func (v V8) basicNodeSetup(basicSetup schema.TaskBasicConfig, clusterInformation schema.Cluster) (map[string]string, error) {
+	var err error
+	var isActive bool
+	returnData := map[string]string{}
+
+	if err = checkCgroupVersion(clusterInformation.ControlPlane.KubernetesVersion); err != nil {
+		return returnData, err
+	}
+
+	// disable firewall if it`s on
+	isActive, err = linuxFamily.CheckSystemdService("firewalld")
+	if err != nil {
+		log.Debugf("Systemd not found skip!!!")
+	} else if isActive {
+		log.Debug("Service firewalld is active. Run stop and disable script!!!")
+		if err = linuxFamily.DisableFirewalld(); err != nil {
+			return returnData, err
+		}
+	}
+
+	// iptables is backed by nftables since el 8, make sure nothing flushes the ruleset behind kube-proxy
+	if el.IptablesNftBackend() {
+		log.Debug("Iptables is backed by nf_tables")
+	}
+	if err = el.DisableNftablesService(); err != nil {
+		return returnData, err
+	}
+
+	// disable selinux if it`s on
+	isActive, err = linuxFamily.GetEnforce()
+	if err != nil {
+		return returnData, err
+	}
+	if isActive {
+		log.Debugf("Selinux is not disabled. Run disable script!!!")
+		if err = centos.DisableSelinux(); err != nil {
+			return returnData, err
+		}
+	}
+
+	// load kernel modular
+	if err = linuxFamily.EnableKernelOptions(); err != nil {
+		return returnData, err
+	}
+
+	// enable ipv4 ipv6 forwarding
+	if err = linuxFamily.EnableIPV46Forwarding(); err != nil {
+		return returnData, err
+	}
+
+	if err = linuxFamily.DisableSwapForever(); err != nil {
+		return returnData, err
+	}
+
+	//  Increase the Maximum Number of File Descriptors
+	if err = linuxFamily.IncreaseMaximumNumberOfFileDescriptors(); err != nil {
+		return returnData, err
+	}
+
+	return returnData, nil
+}
+
+// checkCgroupVersion refuses nodes running cgroup v2 with kubelet which does not understand it
+func checkCgroupVersion(k8sVersion string) error {
+	if osInfoProvider.GetCgroupVersion() != 2 {
+		return nil
+	}
+	k8sVersion = util.StringDefaultIfNotSet(k8sVersion, constants.V1_18_6)
+	log.Debugf("Node is running cgroup v2, kubernetes version is %s", k8sVersion)
+	if newer, err := util.NewerK8sVersion(k8sVersion, constants.CgroupV2MinK8sVersion); err != nil {
+		return err
+	} else if newer == constants.CgroupV2MinK8sVersion {
+		return fmt.Errorf("Kubernetes %s does not support cgroup v2, please boot the node with systemd.unified_cgroup_hierarchy=0 or use kubernetes %s or later", k8sVersion, constants.CgroupV2MinK8sVersion)
+	}
+	return nil
+}
+
+func (v V8) InstallOrRemoveContainerRuntime(taskCri schema.TaskCRI, clusterInformation schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
+	taskCri.K8sVersion = util.StringDefaultIfNotSet(taskCri.K8sVersion, constants.V1_18_6)
+	// When server process is running on the same node with client,
+	// skip the task for remaining docker/containerd.
+	if v.Config.IsPlexing {
+		return map[string]string{}, nil
+	}
+
+	if taskCri.Action == constants.ActionCreate {
+		return v.installContainerRuntime(taskCri, clusterInformation, resourceServerURL, taskCri.K8sVersion, md5Dep)
+	}
+	return v.removeContainerRuntime(taskCri)
+}
+
+func (v V8) containerRuntime(criType string) (containerRuntime.INodeContainerRuntime, string, error) {
+	rtc := cache.GetCurrentCache()
+	clientConfig := rtc.GetClientRuntimeConfig(cache.NodeId)
+	switch criType {
+	case constants.CRITypeDocker:
+		return docker.CRIDockerEL{}, clientConfig.CRIRootDir + "/docker", nil
+	case constants.CRITypeContainerd:
+		return containerd.ContainerDEL{}, clientConfig.CRIRootDir + "/containerd", nil
+	}
+	return nil, "", fmt.Errorf("Container runtime %s currently not support yet", criType)
+}
+
+func (v V8) installContainerRuntime(taskCri schema.TaskCRI, cluster schema.Cluster, resourceServerURL, k8sVersion string, md5Dep depMap.DepMap) (map[string]string, error) {
+	returnData := map[string]string{}
+
+	isExists, _ := linuxFamily.CheckSystemdServiceExists(taskCri.CRIType.CRIType)
+	if isExists && taskCri.CRIType.ReinstallIfAlreadyInstall {
+		log.Debug("Config says we need reinstall. Go remove container runtime first !!!")
+		if data, err := v.removeContainerRuntime(taskCri); err != nil {
+			log.Errorf("Failed to remove container runtime during reinstall due to error %s", err.Error())
+			return data, err
+		}
+		log.Debug("Done with remove container runtime")
+	}
+
+	cri, criRootDir, err := v.containerRuntime(taskCri.CRIType.CRIType)
+	if err != nil {
+		log.Errorf("Failed to set up container runtime %s due to error %s", taskCri.CRIType.CRIType, err.Error())
+		return returnData, err
+	}
+	if criRootDir != "/var/lib/"+taskCri.CRIType.CRIType {
+		if err := util.CreateDirIfNotExists(criRootDir); err != nil {
+			log.Errorf("Failed to create %s root dir with location '%s'", taskCri.CRIType.CRIType, criRootDir)
+		}
+	}
+	// mount the cri device if config CRIMountDev is set
+	if err := linuxFamily.MountIfCriDevIsSet(criRootDir, v.Config, true, false, true); err != nil {
+		return returnData, err
+	}
+	err = cri.Install(v.Config.Offline, taskCri, cluster, v.Config, resourceServerURL, k8sVersion, md5Dep)
+	if err != nil {
+		log.Errorf("Failed to set up container runtime %s due to error %s", taskCri.CRIType.CRIType, err.Error())
+	}
+	return returnData, err
+}
+
+func (v V8) removeContainerRuntime(taskCri schema.TaskCRI) (map[string]string, error) {
+	log.Debugf("Try to stop kubelet or container will be recreate after we kill it")
+	if err := linuxFamily.StopSystemdService("kubelet"); err != nil {
+		log.Errorf("Failed to stop systemd service kubelet due to error: %s", err.Error())
+		log.Error("Skip it and try to remove container runtime directly")
+	}
+
+	returnData := map[string]string{}
+	isExists, _ := linuxFamily.CheckSystemdServiceExists(taskCri.CRIType.CRIType)
+	cri, dataDir, err := v.containerRuntime(taskCri.CRIType.CRIType)
+	if err != nil {
+		log.Errorf("Failed to remove container runtime %s due to error %s", taskCri.CRIType.CRIType, err.Error())
+		return returnData, nil
+	}
+	if isExists {
+		if err := cri.Remove(taskCri); err != nil {
+			return returnData, err
+		}
+	} else {
+		// when service cri service does not exists ,which will result in error.
+		// that said we should ignore the error and move on
+		log.Debugf("Service %s does not found nothing need to be done...", taskCri.CRIType.CRIType)
+	}
+	return returnData, linuxFamily.UmountOrRemoveCRIDataDir(v.Config.CRIMountDev, dataDir, v.Config.YamlDataDir, cri)
+}
+
+func (v V8) InitOrDestroyFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	if kubeadm.Action != constants.ActionCreate {
+		return v.V7.InitOrDestroyFirstControlPlane(kubeadm, resourceServerURL, clusterInformation, md5Dep)
+	}
+
+	returnData := map[string]string{}
+	if err := v.installK8sDep(clusterInformation, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+	if data, err := k8s.InitFirstControlPlane(kubeadm, v.Config); err != nil {
+		return nil, err
+	} else {
+		returnData = data
+	}
+
+	log.Debug("Creating .kube dir")
+	if err := util.CreateDirIfNotExists("/root/.kube"); err != nil {
+		log.Errorf("Fail to create dir %s due to error: %v", "/root/.kube", err.Error())
+		return nil, err
+	}
+
+	log.Debug("Remove old config if exists")
+	_, _, _ = command.RunCmd("rm", "-f", "/root/.kube/config")
+
+	log.Debug("Copy admin.conf to .kube/config")
+	var stdErr bytes.Buffer
+	var err error
+	if _, stdErr, err = command.RunCmd("cp", "/etc/kubernetes/admin.conf", "/root/.kube/config"); err != nil {
+		log.Error("Failed to copy admin.conf to .kube/config due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return nil, errors.New(stdErr.String())
+	}
+
+	if err := k8s.ApplyCNIConfig(kubeadm, v.Config); err != nil {
+		return nil, err
+	}
+	return returnData, nil
+}
+
+func (v V8) JoinOrDestroyControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	if kubeadm.Action != constants.ActionCreate {
+		return v.V7.JoinOrDestroyControlPlane(kubeadm, resourceServerURL, preReturnData, clusterInformation, md5Dep)
+	}
+
+	if err := v.installK8sDep(clusterInformation, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{}, k8s.JoinControlPlane(preReturnData)
+}
+
+func (v V8) JoinOrDestroyWorkNode(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	if kubeadm.Action != constants.ActionCreate || clusterInformation.ClusterInstaller == constants.ClusterInstallerRancher {
+		return v.V7.JoinOrDestroyWorkNode(kubeadm, resourceServerURL, preReturnData, clusterInformation, md5Dep)
+	}
+
+	if err := v.installK8sDep(clusterInformation, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{}, k8s.JoinWorker(preReturnData)
+}
+
+func (v V8) installK8sDep(cluster schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) error {
+	if !v.Config.Offline {
+		return el.OnlineInstallK8sDnfDep(cluster.ControlPlane.EnableIPVS)
+	}
+
+	osFamily, err := osInfoProvider.GetOSFamily()
+	if err != nil {
+		return err
+	}
+	cluster.ControlPlane.KubernetesVersion = util.StringDefaultIfNotSet(cluster.ControlPlane.KubernetesVersion, constants.V1_18_6)
+	saveTo := path.Join(v.Config.YamlDataDir, "kubernetes-"+cluster.ControlPlane.KubernetesVersion)
+
+	mergedDep := k8s.KubeDepMapping
+	if cluster.ControlPlane.KubernetesVersion != constants.V1_18_6 {
+		mergedDep = linuxFamily.MergeAdditionalVersionDep(k8s.KubeDepMapping, cluster.AdditionalVersionDep, osFamily)
+	}
+	if err := linuxFamily.CommonDownloadDep(resourceServerURL, mergedDep, saveTo, cluster.ControlPlane.KubernetesVersion, md5Dep); err != nil {
+		return err
+	}
+
+	log.Debug("Installing all kubernetes rpms")
+	return el.InstallRpmPackages(saveTo)
+}
+
+func (v V8) CommonLink(from depMap.DepMap, saveTo string, linkTo string) (map[string]string, error) {
+	osInfo, errOSInfo := osInfoProvider.GetAllSystemInformation()
+	if errOSInfo != nil {
+		log.Errorf("Failed to get node cpu arch due to error %s", errOSInfo.Error())
+		return nil, errOSInfo
+	}
+	osFamily, osVersion := osInfoProvider.NormalizeOSFamily(osInfo.OS.Vendor, osInfo.OS.Version)
+	packages, _ := from.Packages(osFamily, osVersion, constants.V1_18_6, osInfo.Kernel.Architecture)
+	for k, v := range packages {
+		log.Debugf("Create SymbolicLink from %v to %v", path.Join(saveTo, v), path.Join(linkTo, k))
+		err := fileutils.SymbolicLink(path.Join(saveTo, v), path.Join(linkTo, k))
+		if err != nil {
+			log.Errorf("Create SymbolicLink from %v to %v err: %v", path.Join(saveTo, v), path.Join(linkTo, k), err)
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,10 @@
+package version
+
+/*
+stands for rhel 9 and it`s rebuilds
+it is set up the same way as 8, rpms which differ are picked by os version when downloading, see dep.OSKey
+el 9 boots with cgroup v2 by default which is checked during basic node setup
+*/
+type V9 struct {
+	V8
+}
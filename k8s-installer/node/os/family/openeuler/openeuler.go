@@ -0,0 +1,19 @@
+package openeuler
+
+import "k8s-installer/node/os/family"
+
+type OpenEuler struct {
+	Version family.IOSVersion
+}
+
+func (c OpenEuler) GetOSVersion() family.IOSVersion {
+	return c.Version
+}
+
+func (c OpenEuler) GetOSFamily() string {
+	return "openEuler"
+}
+
+func (c OpenEuler) GetOSFullName() string {
+	panic("implement me")
+}
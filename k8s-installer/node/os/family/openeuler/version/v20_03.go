@@ -0,0 +1,9 @@
+package version
+
+/*
+stands for openEuler 20.03 LTS
+rpms which differ from 22.03 are kept under openEuler-20.03 in dep, see dep.OSKey
+*/
+type V2003 struct {
+	V2203
+}
@@ -0,0 +1,11 @@
+package version
+
+import elVersion "k8s-installer/node/os/family/el/version"
+
+/*
+stands for openEuler 22.03 LTS
+openEuler is rpm and dnf based like el 8 so it is set up the same way, rpms are kept under openEuler in dep
+*/
+type V2203 struct {
+	elVersion.V8
+}
@@ -0,0 +1,148 @@
+package ubuntu
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strings"
+
+	"k8s-installer/pkg/command"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/util"
+)
+
+func DisableUfw() error {
+	var stdErr bytes.Buffer
+	var err error
+	log.Debug("Try to run command ufw disable")
+	_, stdErr, err = command.RunCmd("ufw", "disable")
+	if err != nil {
+		log.Error("Failed to disable ufw due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	log.Debug("Successfully disable ufw")
+	return nil
+}
+
+// InstallDebPackages installs all .deb files in given dir the way rpm -ivh --nodeps does on centos
+func InstallDebPackages(dir string) error {
+	var stdErr bytes.Buffer
+	var err error
+	// dpkg does not expand wildcard by itself
+	installCommand := "dpkg -i --force-overwrite --force-depends --force-confold " + path.Join(dir, "*.deb")
+	log.Debugf("Try to run command %s", installCommand)
+	_, stdErr, err = command.RunCmd("/bin/sh", "-c", installCommand)
+	if err != nil {
+		log.Errorf("Failed to install debs in %s due to following error:", dir)
+		errMsg := stdErr.String()
+		log.Errorf("StdErr %s", errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+func UninstallPackage(packages []string) error {
+	var stdErr bytes.Buffer
+	var err error
+	subCommand := []string{"--purge", "--force-depends"}
+	subCommand = append(subCommand, packages...)
+	log.Debug("Try to run command dpkg --purge")
+	_, stdErr, err = command.RunCmd("dpkg", subCommand...)
+	if err != nil {
+		log.Warnf("Failed to removed all package due to following error:")
+		log.Warnf("StdErr %s", stdErr.String())
+	}
+	log.Debugf("Successfully removed all package %s", strings.Join(packages, ","))
+	return err
+}
+
+// DepPackageNames returns name of ubuntu packages in dep which is what dpkg knows them by
+func DepPackageNames(depMap dep.DepMap) []string {
+	var names []string
+	for name := range depMap[constants.OSFamilyUbuntu][constants.V1_18_6][constants.CpuArchX86] {
+		names = append(names, name)
+	}
+	return names
+}
+
+func AptInstall(packages ...string) error {
+	var stdErr bytes.Buffer
+	var err error
+	log.Debug("Try to run command apt-get update")
+	_, stdErr, err = command.RunCmd("apt-get", "update")
+	if err != nil {
+		log.Error("Failed to run command apt-get update due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	// never let apt ask anything
+	installCommand := "DEBIAN_FRONTEND=noninteractive apt-get install -y " + strings.Join(packages, " ")
+	log.Debugf("Try to run command %s", installCommand)
+	_, stdErr, err = command.RunCmd("/bin/sh", "-c", installCommand)
+	if err != nil {
+		log.Errorf("Failed to run command %s due to following error:", installCommand)
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	return nil
+}
+
+// AddDockerAptRepo adds docker apt repo which provides both docker-ce and containerd.io
+func AddDockerAptRepo() error {
+	var stdErr bytes.Buffer
+	var err error
+	if err := AptInstall("apt-transport-https", "ca-certificates", "curl", "gnupg", "lsb-release"); err != nil {
+		return err
+	}
+	log.Debug("Try to add docker apt key")
+	_, stdErr, err = command.RunCmd("/bin/sh", "-c", "curl -fsSL https://download.docker.com/linux/ubuntu/gpg | apt-key add -")
+	if err != nil {
+		log.Error("Failed to add docker apt key due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	repo := "deb [arch=$(dpkg --print-architecture)] https://download.docker.com/linux/ubuntu $(lsb_release -cs) stable"
+	log.Debug("Try to add docker apt repo")
+	_, stdErr, err = command.RunCmd("/bin/sh", "-c", "echo \""+repo+"\" > /etc/apt/sources.list.d/docker.list")
+	if err != nil {
+		log.Error("Failed to add docker apt repo due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	return nil
+}
+
+func OnlineInstallK8sAptDep(enableIpvs bool) error {
+	kubernetesRepo := `deb https://apt.kubernetes.io/ kubernetes-xenial main`
+	var stdErr bytes.Buffer
+	var err error
+	log.Debug("Try to set repo...")
+	_, stdErr, err = command.RunCmd("/bin/sh", "-c", "curl -fsSL https://packages.cloud.google.com/apt/doc/apt-key.gpg | apt-key add -")
+	if err != nil {
+		log.Error("Failed to add kubernetes apt key due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+		return err
+	}
+	if err := util.WriteTxtToFile("/etc/apt/sources.list.d/kubernetes.list", kubernetesRepo); err != nil {
+		return err
+	}
+
+	packages := []string{"kubelet=" + constants.V1_18_6 + "-00", "kubeadm=" + constants.V1_18_6 + "-00", "kubectl=" + constants.V1_18_6 + "-00", "ebtables", "conntrack", "socat"}
+	if enableIpvs {
+		packages = append(packages, "ipvsadm")
+	}
+	if err := AptInstall(packages...); err != nil {
+		return err
+	}
+
+	// keep apt from upgrading kubernetes behind our back
+	_, stdErr, err = command.RunCmd("apt-mark", "hold", "kubelet", "kubeadm", "kubectl")
+	if err != nil {
+		log.Error("Failed to run command apt-mark hold kubelet kubeadm kubectl due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+	}
+	return err
+}
@@ -1,24 +1,58 @@
 package version
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	natsLib "github.com/nats-io/nats.go"
+
+	containerRuntime "k8s-installer/node/container_runtime"
+	"k8s-installer/node/container_runtime/containerd"
+	"k8s-installer/node/container_runtime/docker"
 	"k8s-installer/node/k8s"
+	"k8s-installer/node/loadbalancer"
+	osInfoProvider "k8s-installer/node/os"
+	linuxFamily "k8s-installer/node/os/family"
+	centosVersion "k8s-installer/node/os/family/centos/version"
+	"k8s-installer/node/os/family/ubuntu"
+	"k8s-installer/node/reportor"
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/command"
 	"k8s-installer/pkg/config/client"
+	"k8s-installer/pkg/constants"
 	depMap "k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/util"
+	"k8s-installer/pkg/util/fileutils"
 	"k8s-installer/schema"
-
-	natsLib "github.com/nats-io/nats.go"
 )
 
+/*
+stands for ubuntu 18.04
+packages are installed from debs, everything which does not depend on os family is done the same way as centos 7
+*/
 type V1804 struct {
 	client.Config
 }
 
+// osIndependent handles tasks which have nothing to do with package manager or os services
+func (v V1804) osIndependent() centosVersion.V7 {
+	return centosVersion.V7{Config: v.Config}
+}
+
 func (v V1804) TaskSetHost(hosts schema.TaskSetHosts) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().TaskSetHost(hosts)
 }
 
 func (v V1804) BasicNodeSetup(basicSetup schema.TaskBasicConfig, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	if basicSetup.Action == constants.ActionCreate {
+		return v.basicNodeSetup(basicSetup, clusterInformation)
+	} else {
+		return v.basicNodeSetupDestroy(basicSetup, clusterInformation)
+	}
 }
 
 func (v V1804) basicNodeSetupDestroy(basicSetup schema.TaskBasicConfig, clusterInformation schema.Cluster) (map[string]string, error) {
@@ -26,137 +60,426 @@ func (v V1804) basicNodeSetupDestroy(basicSetup schema.TaskBasicConfig, clusterI
 }
 
 func (v V1804) basicNodeSetup(basicSetup schema.TaskBasicConfig, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	var err error
+	var isExists bool
+	returnData := map[string]string{}
+	// disable ufw if it`s installed, ubuntu has no selinux to take care of
+	isExists, err = linuxFamily.CheckSystemdServiceExists("ufw")
+	if err != nil || !isExists {
+		log.Debugf("Service ufw not found skip!!!")
+	} else {
+		log.Debug("Service ufw found. Run disable script!!!")
+		if err = ubuntu.DisableUfw(); err != nil {
+			return returnData, err
+		}
+	}
+
+	// load kernel modular
+	err = linuxFamily.EnableKernelOptions()
+	if err != nil {
+		return returnData, err
+	}
+
+	// enable ipv4 ipv6 forwarding
+	err = linuxFamily.EnableIPV46Forwarding()
+	if err != nil {
+		return returnData, err
+	}
+
+	err = linuxFamily.DisableSwapForever()
+	if err != nil {
+		return returnData, err
+	}
+
+	//  Increase the Maximum Number of File Descriptors
+	err = linuxFamily.IncreaseMaximumNumberOfFileDescriptors()
+	if err != nil {
+		return returnData, err
+	}
+
+	return returnData, nil
 }
 
 func (v V1804) PurgeAll(cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
-}
+	if err := linuxFamily.StopSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
 
-func (v V1804) InstallOrRemoveContainerRuntime(taskCri schema.TaskCRI, clusterInformation schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
 	return nil, nil
 }
 
-func (v V1804) installContainerRuntime(taskCri schema.TaskCRI, cluster schema.Cluster, resourceServerURL, k8sVersion string) (map[string]string, error) {
-	return nil, nil
+func (v V1804) InstallOrRemoveContainerRuntime(taskCri schema.TaskCRI, clusterInformation schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
+	taskCri.K8sVersion = util.StringDefaultIfNotSet(taskCri.K8sVersion, constants.V1_18_6)
+	// When server process is running on the same node with client,
+	// skip the task for remaining docker/containerd.
+	if v.Config.IsPlexing {
+		return map[string]string{}, nil
+	}
+
+	if taskCri.Action == constants.ActionCreate {
+		return v.installContainerRuntime(taskCri, clusterInformation, resourceServerURL, taskCri.K8sVersion, md5Dep)
+	}
+	return v.removeContainerRuntime(taskCri)
+}
+
+func (v V1804) installContainerRuntime(taskCri schema.TaskCRI, cluster schema.Cluster, resourceServerURL, k8sVersion string, md5Dep depMap.DepMap) (map[string]string, error) {
+	var err error
+	var isExists bool
+
+	returnData := map[string]string{}
+
+	// check docker already installed
+	isExists, err = linuxFamily.CheckSystemdServiceExists(taskCri.CRIType.CRIType)
+
+	if isExists && taskCri.CRIType.ReinstallIfAlreadyInstall {
+		log.Debug("Config says we need reinstall. Go remove container runtime first !!!")
+		if returnData, err = v.removeContainerRuntime(taskCri); err != nil {
+			log.Errorf("Failed to remove container runtime docker during reinstall due to error %s", err.Error())
+			return returnData, err
+		}
+		log.Debug("Done with remove container runtime")
+	}
+	rtc := cache.GetCurrentCache()
+	clientConfig := rtc.GetClientRuntimeConfig(cache.NodeId)
+
+	var cri containerRuntime.INodeContainerRuntime
+	criRootDir := ""
+	switch taskCri.CRIType.CRIType {
+	case constants.CRITypeDocker:
+		criRootDir = clientConfig.CRIRootDir + "/docker"
+		cri = docker.CRIDockerUbuntu{}
+	case constants.CRITypeContainerd:
+		criRootDir = clientConfig.CRIRootDir + "/containerd"
+		cri = containerd.ContainerDUbuntu{}
+	default:
+		err = fmt.Errorf("Container runtime %s currently not support yet", taskCri.CRIType.CRIType)
+		log.Errorf("Failed to set up container runtime %s due to error %s", taskCri.CRIType.CRIType, err.Error())
+		return returnData, err
+	}
+
+	if clientConfig.CRIRootDir != "/var/lib" {
+		if err := util.CreateDirIfNotExists(criRootDir); err != nil {
+			log.Errorf("Failed to create %s root dir with location '%s'", taskCri.CRIType.CRIType, criRootDir)
+		}
+	}
+	// mount the cri device if config CRIMountDev is set
+	if err := linuxFamily.MountIfCriDevIsSet(criRootDir, v.Config, true, false, true); err != nil {
+		return returnData, err
+	}
+	err = cri.Install(v.Config.Offline, taskCri, cluster, v.Config, resourceServerURL, k8sVersion, md5Dep)
+	if err != nil {
+		log.Errorf("Failed to set up container runtime %s due to error %s", taskCri.CRIType.CRIType, err.Error())
+	}
+
+	return returnData, err
 }
 
 func (v V1804) removeContainerRuntime(taskCri schema.TaskCRI) (map[string]string, error) {
-	return nil, nil
+	log.Debugf("Try to stop kubelet or container will be recreate after we kill it")
+	if err := linuxFamily.StopSystemdService("kubelet"); err != nil {
+		log.Errorf("Failed to stop systemd service kubelet due to error: %s", err.Error())
+		log.Error("Skip it and try to remove container runtime directly")
+	}
+
+	var err error
+	var isExists bool
+	var cri containerRuntime.INodeContainerRuntime
+	returnData := map[string]string{}
+	isExists, err = linuxFamily.CheckSystemdServiceExists(taskCri.CRIType.CRIType)
+	rtc := cache.GetCurrentCache()
+	clientConfig := rtc.GetClientRuntimeConfig(cache.NodeId)
+	dataDir := ""
+	switch taskCri.CRIType.CRIType {
+	case constants.CRITypeDocker:
+		cri = docker.CRIDockerUbuntu{}
+		dataDir = clientConfig.CRIRootDir + "/docker"
+	case constants.CRITypeContainerd:
+		cri = containerd.ContainerDUbuntu{}
+		dataDir = clientConfig.CRIRootDir + "/containerd"
+	default:
+		err = fmt.Errorf("Container runtime %s currently not support yet ", taskCri.CRIType.CRIType)
+	}
+	if isExists && cri != nil {
+		err = cri.Remove(taskCri)
+		if err != nil {
+			return returnData, err
+		}
+		return returnData, linuxFamily.UmountOrRemoveCRIDataDir(v.Config.CRIMountDev, dataDir, v.Config.YamlDataDir, cri)
+	}
+
+	if err := linuxFamily.UmountOrRemoveCRIDataDir(v.Config.CRIMountDev, dataDir, v.Config.YamlDataDir, cri); err != nil {
+		return returnData, err
+	}
+	// when service cri service does not exists ,which will result in error.
+	// that said we should ignore the error and move on
+	log.Debugf("Service %s does not found nothing need to be done...", taskCri.CRIType.CRIType)
+	return returnData, nil
 }
 
 func (v V1804) InstallOrRemoveLoadBalance(loadBalance schema.TaskLoadBalance, clusterInformation schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
-	return nil, nil
+	if loadBalance.Action == constants.ActionCreate {
+		return v.installLoadBalance(loadBalance, clusterInformation, resourceServerURL, md5Dep)
+	} else {
+		return v.removeLoadBalance(loadBalance)
+	}
 }
 
-func (v V1804) installLoadBalance(loadBalance schema.TaskLoadBalance, clusterInformation schema.Cluster, resourceServerURL string) (map[string]string, error) {
-	return nil, nil
-}
+func (v V1804) installLoadBalance(loadBalance schema.TaskLoadBalance, clusterInformation schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
+	proxy := loadbalancer.CreateProxy(loadBalance.ProxyType)
 
-func (v V1804) InitOrDestroyFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	osInfo, errOSInfo := osInfoProvider.GetAllSystemInformation()
+	if errOSInfo != nil {
+		log.Errorf("Failed to get node cpu arch due to error %s", errOSInfo.Error())
+		return nil, errOSInfo
+	}
+
+	log.Debug("Try to install proxy")
+	if err := proxy.Install(v.Config.Offline, loadBalance, clusterInformation, v.Config, resourceServerURL, osInfo.OS.Version, osInfo.Kernel.Architecture, md5Dep); err != nil {
+		return nil, err
+	}
+
+	// haproxy deb starts service with default config right after installed so restart it to pick up ours
+	log.Debugf("Try to enable service %s", proxy.GetSystemdServiceName())
+	if err := linuxFamily.StartSystemdService(true, true, proxy.GetSystemdServiceName()); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
-func (v V1804) destroyFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, cluster schema.Cluster) (map[string]string, error) {
+func (v V1804) removeLoadBalance(loadBalance schema.TaskLoadBalance) (map[string]string, error) {
+	proxy := loadbalancer.CreateProxy(loadBalance.ProxyType)
+	if err := proxy.Remove(); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
-func (v V1804) initFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+func (v V1804) InitOrDestroyFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	if kubeadm.Action == constants.ActionCreate {
+		return v.initFirstControlPlane(kubeadm, resourceServerURL, clusterInformation, md5Dep)
+	}
+	return v.destroyFirstControlPlane(kubeadm, resourceServerURL, clusterInformation)
 }
 
-func offlineInstallK8sYumDep(cluster schema.Cluster, resourceServerURL string) error {
-	return nil
+func (v V1804) destroyFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, cluster schema.Cluster) (map[string]string, error) {
+	return v.destroyK8sNode(kubeadm, cluster)
+}
+
+func (v V1804) initFirstControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, cluster schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	returnData := map[string]string{}
+	if err := v.installK8sDep(cluster, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+	if data, err := k8s.InitFirstControlPlane(kubeadm, v.Config); err != nil {
+		return nil, err
+	} else {
+		returnData = data
+	}
+
+	log.Debug("Creating .kube dir")
+	if err := util.CreateDirIfNotExists("/root/.kube"); err != nil {
+		log.Errorf("Fail to create dir %s due to error: %v", "/root/.kube", err.Error())
+		return nil, err
+	}
+
+	log.Debug("Remove old config if exists")
+	_, _, _ = command.RunCmd("rm", "-f", "/root/.kube/config")
+
+	log.Debug("Copy admin.conf to .kube/config")
+	if _, stdErr, err := command.RunCmd("cp", "/etc/kubernetes/admin.conf", "/root/.kube/config"); err != nil {
+		log.Error("Failed to copy admin.conf to .kube/config due to following error:")
+		log.Errorf("StdErr %s", stdErr.String())
+	}
+
+	if err := k8s.ApplyCNIConfig(kubeadm, v.Config); err != nil {
+		return nil, err
+	}
+	return returnData, nil
+}
+
+func (v V1804) installK8sDep(cluster schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) error {
+	if v.Config.Offline {
+		// do offline install package from debs
+		return offlineInstallK8sAptDep(v.Config, cluster, resourceServerURL, md5Dep)
+	}
+	return ubuntu.OnlineInstallK8sAptDep(cluster.ControlPlane.EnableIPVS)
+}
+
+func offlineInstallK8sAptDep(config client.Config, cluster schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) error {
+	if len(cluster.ControlPlane.KubernetesVersion) == 0 {
+		cluster.ControlPlane.KubernetesVersion = constants.V1_18_6
+	}
+	saveTo := path.Join(config.YamlDataDir, "kubernetes-"+cluster.ControlPlane.KubernetesVersion)
+
+	mergedDep := k8s.KubeDepMapping
+	if cluster.ControlPlane.KubernetesVersion != constants.V1_18_6 {
+		mergedDep = linuxFamily.MergeAdditionalVersionDep(k8s.KubeDepMapping, cluster.AdditionalVersionDep, constants.OSFamilyUbuntu)
+	}
+	if err := linuxFamily.CommonDownloadDep(resourceServerURL, mergedDep, saveTo, cluster.ControlPlane.KubernetesVersion, md5Dep); err != nil {
+		return err
+	}
+
+	log.Debug("Installing all kubernetes debs")
+	return ubuntu.InstallDebPackages(saveTo)
 }
 
 func (v V1804) JoinOrDestroyControlPlane(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
-	return nil, nil
+	if kubeadm.Action == constants.ActionCreate {
+		return v.joinControlPlane(preReturnData, clusterInformation, resourceServerURL, md5Dep)
+	}
+	return v.destroyControlPlane(kubeadm, clusterInformation)
 }
 
 func (v V1804) destroyControlPlane(kubeadm schema.TaskKubeadm, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	return v.destroyK8sNode(kubeadm, cluster)
 }
 
-func (v V1804) joinControlPlane(preReturnData map[string]string, cluster schema.Cluster, resourceServerURL string) (map[string]string, error) {
-	return nil, nil
+func (v V1804) joinControlPlane(preReturnData map[string]string, cluster schema.Cluster, resourceServerURL string, md5Dep depMap.DepMap) (map[string]string, error) {
+	if err := v.installK8sDep(cluster, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{}, k8s.JoinControlPlane(preReturnData)
 }
 
 func (v V1804) JoinOrDestroyWorkNode(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, clusterInformation schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
-	return nil, nil
+	if kubeadm.Action == constants.ActionCreate {
+		return v.joinWorkNode(kubeadm, resourceServerURL, preReturnData, clusterInformation, md5Dep)
+	}
+	return v.destroyWorkNode(kubeadm, clusterInformation)
 }
 
-func (v V1804) joinWorkNode(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+func (v V1804) joinWorkNode(kubeadm schema.TaskKubeadm, resourceServerURL string, preReturnData map[string]string, cluster schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
+	if cluster.ClusterInstaller == constants.ClusterInstallerRancher {
+		return map[string]string{}, k8s.JoinRancherNode(preReturnData)
+	}
+
+	if err := v.installK8sDep(cluster, resourceServerURL, md5Dep); err != nil {
+		return nil, err
+	}
+
+	if err := linuxFamily.EnableSystemdService("kubelet"); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{}, k8s.JoinWorker(preReturnData)
 }
 
 func (v V1804) destroyWorkNode(kubeadm schema.TaskKubeadm, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	return v.destroyK8sNode(kubeadm, cluster)
 }
 
 func (v V1804) RenameHostname(renameHostnameTask schema.TaskRenameHostName, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	var err error
+	var stdErr bytes.Buffer
+	returnData := map[string]string{}
+	log.Debug("Attempt to change hostname...")
+	// hostnamectl keeps cloud-init and systemd-hostnamed in line with /etc/hostname
+	_, stdErr, err = command.RunCmd("hostnamectl", "set-hostname", renameHostnameTask.Hostname)
+	if err != nil {
+		log.Error("Failed to rename hostname due to following error:")
+		errMsg := stdErr.String()
+		log.Errorf("StdErr %s", errMsg)
+		err = errors.New(errMsg)
+	}
+
+	log.Debug("Attempt to change hostname forever...")
+	if err := util.WriteTxtToFile("/etc/hostname", renameHostnameTask.Hostname); err != nil {
+		log.Errorf("Failed to set /etc/hostname due to following error: %s", err.Error())
+		return returnData, err
+	}
+
+	log.Debug("Attempt to set /etc/hosts...")
+	if err := util.AppendTxtToFile("/etc/hosts", "\n"+renameHostnameTask.Hosts, 0644); err != nil {
+		log.Errorf("Failed to set /etc/hosts due to following error: %s", err.Error())
+		return returnData, err
+	}
+
+	return returnData, err
 }
 
 func (v V1804) RunKubectl(kubectl schema.TaskKubectl, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
 	return k8s.KubectlExecutor(kubectl, v.Config)
 }
 
-func (v V1804) RunCommand(commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+func (v V1804) RunCommand(ctx context.Context, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
+	return v.osIndependent().RunCommand(ctx, commandToRun, preReturnData, clusterInformation)
 }
 
-func (v V1804) AsyncRunCommand(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+func (v V1804) AsyncRunCommand(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, commandToRun schema.TaskRunCommand, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
+	return v.osIndependent().AsyncRunCommand(ctx, operationId, nodeId, nodeStepId, msg, commandToRun, preReturnData, clusterInformation)
 }
 
 func (v V1804) CopyTextFile(fileToCopy schema.TaskCopyTextBaseFile, preReturnData map[string]string, clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().CopyTextFile(fileToCopy, preReturnData, clusterInformation)
 }
 
 func (v V1804) InstallOrDestroyVirtualKubelet(vk schema.TaskVirtualKubelet, resourceServerURL string, cluster schema.Cluster, md5Dep depMap.DepMap) (map[string]string, error) {
-	return nil, nil
-}
-
-func (v V1804) DestroyVirtualKubelet(vk schema.TaskVirtualKubelet, resourceServerURL string, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
-}
-
-func (v V1804) InstallVirtualKubelet(vk schema.TaskVirtualKubelet, resourceServerURL string, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().InstallOrDestroyVirtualKubelet(vk, resourceServerURL, cluster, md5Dep)
 }
 
 func (v V1804) PrintJoinString(printJoinString schema.TaskPrintJoin) (map[string]string, error) {
-	return nil, nil
+	return k8s.PrintJoinString(printJoinString)
 }
 
 func (v V1804) CommonLink(from depMap.DepMap, saveTo string, linkTo string) (map[string]string, error) {
+	osInfo, errOSInfo := osInfoProvider.GetAllSystemInformation()
+	if errOSInfo != nil {
+		log.Errorf("Failed to get node cpu arch due to error %s", errOSInfo.Error())
+		return nil, errOSInfo
+	}
+	packages, _ := from.Packages(constants.OSFamilyUbuntu, osInfo.OS.Version, constants.V1_18_6, osInfo.Kernel.Architecture)
+	for k, v := range packages {
+		log.Debugf("Create SymbolicLink from %v to %v", path.Join(saveTo, v), path.Join(linkTo, k))
+		err := fileutils.SymbolicLink(path.Join(saveTo, v), path.Join(linkTo, k))
+		if err != nil {
+			log.Errorf("Create SymbolicLink from %v to %v err: %v", path.Join(saveTo, v), path.Join(linkTo, k), err)
+			return nil, err
+		}
+	}
 	return nil, nil
 }
 
 func (v V1804) destroyK8sNode(kubeadm schema.TaskKubeadm, cluster schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	_, err := k8s.DestroyKubeNode(kubeadm, v.Config, cluster)
+	if err != nil {
+		log.Errorf("Failed to destroy kube node due to error %s", err.Error())
+		log.Error("But we are going to report node stat anyway")
+	}
+	//ignore error and do node reporting
+	log.Debug("Reporting new node stat to server immediately")
+	// only consider Reporting node stat as an error to fail task
+	return nil, reportor.ReportIn(v.Config)
 }
 
 func (v V1804) GoCurl(task schema.TaskCurl) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().GoCurl(task)
 }
 
-func (v V1804) CommonDownloadDep(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Dep depMap.DepMap) (map[string]string, error) {
-	return nil, nil
+func (v V1804) CommonDownloadDep(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, dep depMap.DepMap, saveTo string, k8sVersion string, md5Dep depMap.DepMap) (map[string]string, error) {
+	return v.osIndependent().CommonDownloadDep(ctx, operationId, nodeId, nodeStepId, msg, resourceServerURL, dep, saveTo, k8sVersion, md5Dep)
 }
 
-func (v V1804) CommonDownload(operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error) {
-	return nil, nil
+func (v V1804) CommonDownload(ctx context.Context, operationId string, nodeId string, nodeStepId string, msg *natsLib.Msg, resourceServerURL string, FromDir string, k8sVersion string, fileList []string, saveTo string, isUseDefaultPath bool) (map[string]string, error) {
+	return v.osIndependent().CommonDownload(ctx, operationId, nodeId, nodeStepId, msg, resourceServerURL, FromDir, k8sVersion, fileList, saveTo, isUseDefaultPath)
 }
 
 func (v V1804) ConfigPromtail(clusterInformation schema.Cluster) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().ConfigPromtail(clusterInformation)
 }
 
 func (v V1804) PreLoadImage(preLoad schema.TaskPreLoadImage, containerRuntimeType string) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().PreLoadImage(preLoad, containerRuntimeType)
 }
 
 func (v V1804) GenerateKSClusterConfig(cluster schema.Cluster, ipAddress string) (map[string]string, error) {
-	return nil, nil
+	return v.osIndependent().GenerateKSClusterConfig(cluster, ipAddress)
 }
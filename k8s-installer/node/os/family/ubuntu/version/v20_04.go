@@ -0,0 +1,9 @@
+package version
+
+/*
+stands for ubuntu 20.04
+it is set up the same way as 18.04, debs which differ are picked by os version when downloading, see dep.OSKey
+*/
+type V2004 struct {
+	V1804
+}
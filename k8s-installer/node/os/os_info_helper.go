@@ -1,11 +1,14 @@
 package os
 
 import (
+	"io/ioutil"
+	"os"
 	"os/user"
 	"strconv"
 	"strings"
 
 	"github.com/zcalusic/sysinfo"
+	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/util"
 )
@@ -23,23 +26,80 @@ func GetAllSystemInformation() (*sysinfo.SysInfo, error) {
 	return sysInfo, nil
 }
 
+/*
+NormalizeOSFamily maps os vendor and version reported by os-release to the os family and version packages are kept for
+rhel, rocky, almalinux and centos 8 or later all go to el with major version only e.g. rocky 8.6 -> el 8
+openEuler reports "20.03" or "22.03" as is
+*/
+func NormalizeOSFamily(vendor, version string) (string, string) {
+	major := strings.Split(version, ".")[0]
+	switch vendor {
+	case constants.OSVendorRHEL, constants.OSVendorRocky, constants.OSVendorAlmaLinux:
+		return constants.OSFamilyEL, major
+	case constants.OSFamilyCentos:
+		if val, err := strconv.Atoi(major); err == nil && val >= 8 {
+			return constants.OSFamilyEL, major
+		}
+	}
+	return vendor, version
+}
+
 func GetOSFamily() (string, error) {
+	osFamily, _, err := GetOSFamilyAndVersion()
+	return osFamily, err
+}
+
+func GetOSFamilyAndVersion() (string, string, error) {
 	sysInfo := &sysinfo.SysInfo{}
 	var err error
 	if sysInfo, err = GetAllSystemInformation(); err != nil {
-		return "", err
+		return "", "", err
+	}
+	osFamily, osVersion := NormalizeOSFamily(sysInfo.OS.Vendor, sysInfo.OS.Version)
+	return osFamily, osVersion, nil
+}
+
+// GetCgroupVersion returns 2 when node runs the unified cgroup v2 hierarchy otherwise 1
+func GetCgroupVersion() int {
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err == nil {
+		return 2
+	}
+	return 1
+}
+
+// GetInitSystem returns name of pid 1 e.g. systemd
+func GetInitSystem() string {
+	comm, err := ioutil.ReadFile("/proc/1/comm")
+	if err != nil {
+		log.Debugf("Failed to read /proc/1/comm due to error %s", err.Error())
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+/*
+CgroupDriver picks cgroup driver of kubelet and container runtime by init system
+systemd driver is used whenever systemd is pid 1 so there are no two cgroup managers on the node, cgroup v2 requires it as well
+unknown init system e.g. node reported by old agent is taken as systemd which is what every supported os runs
+*/
+func CgroupDriver(initSystem string) string {
+	if initSystem == "" || initSystem == constants.InitSystemSystemd {
+		return constants.CgroupDriverSystemd
 	}
-	return sysInfo.OS.Vendor, nil
+	return constants.CgroupDriverCgroupfs
+}
+
+func GetCgroupDriver() string {
+	return CgroupDriver(GetInitSystem())
 }
 
 func ValidationOsFamily(families []string) (bool, error) {
-	sysInfo := &sysinfo.SysInfo{}
-	var err error
-	if sysInfo, err = GetAllSystemInformation(); err != nil {
+	osFamily, err := GetOSFamily()
+	if err != nil {
 		return false, err
 	}
 	for _, supportedOS := range families {
-		if sysInfo.OS.Vendor == supportedOS {
+		if osFamily == supportedOS {
 			return true, nil
 		}
 	}
@@ -47,11 +107,12 @@ func ValidationOsFamily(families []string) (bool, error) {
 }
 
 func ValidationOsFamilyWithGivenSysInfo(families, versions []string, givenSysInfo sysinfo.SysInfo, checkVersion bool) bool {
+	osFamily, osVersion := NormalizeOSFamily(givenSysInfo.OS.Vendor, givenSysInfo.OS.Version)
 	for _, supportedOS := range families {
-		if givenSysInfo.OS.Vendor == supportedOS {
+		if osFamily == supportedOS {
 			if checkVersion {
 				for _, version := range versions {
-					if version == givenSysInfo.OS.Version {
+					if version == osVersion {
 						return true
 					}
 				}
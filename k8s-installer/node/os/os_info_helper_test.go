@@ -0,0 +1,54 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/zcalusic/sysinfo"
+
+	"k8s-installer/pkg/constants"
+)
+
+func TestNormalizeOSFamily(t *testing.T) {
+	cases := []struct {
+		vendor, version, family, familyVersion string
+	}{
+		{"centos", "7", "centos", "7"},
+		{"centos", "8", "el", "8"},
+		{"rocky", "8.6", "el", "8"},
+		{"almalinux", "9.1", "el", "9"},
+		{"rhel", "9.0", "el", "9"},
+		{"ubuntu", "20.04", "ubuntu", "20.04"},
+		{"openEuler", "22.03", "openEuler", "22.03"},
+	}
+	for _, c := range cases {
+		family, version := NormalizeOSFamily(c.vendor, c.version)
+		if family != c.family || version != c.familyVersion {
+			t.Fatalf("Expect %s %s to be %s %s but got %s %s", c.vendor, c.version, c.family, c.familyVersion, family, version)
+		}
+	}
+}
+
+func TestValidationOsFamilyWithGivenSysInfo(t *testing.T) {
+	families := []string{"centos", "el"}
+	versions := []string{"7", "8"}
+	rocky := sysinfo.SysInfo{OS: sysinfo.OS{Vendor: "rocky", Version: "8.6"}}
+	if !ValidationOsFamilyWithGivenSysInfo(families, versions, rocky, true) {
+		t.Fatal("Expect rocky 8.6 to be supported as el 8")
+	}
+	alma := sysinfo.SysInfo{OS: sysinfo.OS{Vendor: "almalinux", Version: "9.1"}}
+	if ValidationOsFamilyWithGivenSysInfo(families, versions, alma, true) {
+		t.Fatal("Expect almalinux 9.1 not to be supported when el 9 is not listed")
+	}
+}
+
+func TestCgroupDriver(t *testing.T) {
+	for initSystem, expected := range map[string]string{
+		"systemd": constants.CgroupDriverSystemd,
+		"":        constants.CgroupDriverSystemd,
+		"init":    constants.CgroupDriverCgroupfs,
+	} {
+		if driver := CgroupDriver(initSystem); driver != expected {
+			t.Errorf("Expected cgroup driver %s for init system '%s', got %s", expected, initSystem, driver)
+		}
+	}
+}
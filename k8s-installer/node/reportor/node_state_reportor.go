@@ -91,6 +91,8 @@ func gatherNodeInformation() schema.NodeInformation {
 	if err == nil {
 		nodeInformation.DefaultMetworkInterface = nic.Name
 	}
+	nodeInformation.CgroupVersion = os.GetCgroupVersion()
+	nodeInformation.InitSystem = os.GetInitSystem()
 
 	if !clientConfig.IsTestNode {
 		if !CheckBlockDeviceIsReadyForProduction(&nodeInformation, clientConfig) {
@@ -0,0 +1,84 @@
+package api_token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+/*
+api token looks like kit_{id}_{secret}
+id locates the token record without scanning, only sha256 of secret is stored
+secret is 256 bits random so a fast hash is enough, unlike user password it cannot be guessed
+*/
+const (
+	idSize     = 8
+	secretSize = 32
+)
+
+// Generate returns id, token shown to user once and hash to store
+func Generate() (string, string, string, error) {
+	id, err := randomHex(idSize)
+	if err != nil {
+		return "", "", "", err
+	}
+	secret, err := randomHex(secretSize)
+	if err != nil {
+		return "", "", "", err
+	}
+	return id, constants.APITokenPrefix + id + "_" + secret, Hash(secret), nil
+}
+
+// IsAPIToken tells api token from jwt, jwt never starts with the prefix
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, constants.APITokenPrefix)
+}
+
+func Parse(token string) (id, secret string, ok bool) {
+	if !IsAPIToken(token) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(token, constants.APITokenPrefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func Verify(storedHash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(storedHash), []byte(Hash(secret))) == 1
+}
+
+// Validate checks secret against stored token, same error is returned for unknown token and wrong secret
+func Validate(token *schema.APIToken, secret string, now time.Time) error {
+	if token == nil || !Verify(token.Hash, secret) {
+		return errors.New("Invalid api token")
+	}
+	if token.RevokedAt != 0 {
+		return errors.New("Api token is revoked")
+	}
+	if token.ExpiresAt != 0 && now.Unix() >= token.ExpiresAt {
+		return errors.New("Api token is expired")
+	}
+	return nil
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,52 @@
+package api_token
+
+import (
+	"testing"
+	"time"
+
+	"k8s-installer/schema"
+)
+
+func TestGenerateAndParse(t *testing.T) {
+	id, token, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedId, secret, ok := Parse(token)
+	if !ok || parsedId != id {
+		t.Fatalf("Expected token %s to be parsed with id %s", token, id)
+	}
+	if !Verify(hash, secret) {
+		t.Fatal("Expected secret to match stored hash")
+	}
+	if Verify(hash, secret+"0") {
+		t.Fatal("Expected modified secret not to match")
+	}
+	for _, invalid := range []string{"eyJhbGciOiJIUzI1NiJ9.e30.x", "kit_", "kit_abc", "kit__abc"} {
+		if _, _, ok := Parse(invalid); ok {
+			t.Fatalf("Expected %s not to be parsed", invalid)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, token, hash, _ := Generate()
+	_, secret, _ := Parse(token)
+	now := time.Unix(1600000000, 0)
+	stored := &schema.APIToken{Id: "1", Hash: hash}
+	if err := Validate(stored, secret, now); err != nil {
+		t.Fatalf("Expected token to be valid, got %v", err)
+	}
+	if err := Validate(nil, secret, now); err == nil {
+		t.Fatal("Expected unknown token to be refused")
+	}
+	stored.ExpiresAt = now.Unix()
+	if err := Validate(stored, secret, now); err == nil {
+		t.Fatal("Expected expired token to be refused")
+	}
+	stored.ExpiresAt = 0
+	stored.RevokedAt = now.Unix()
+	if err := Validate(stored, secret, now); err == nil {
+		t.Fatal("Expected revoked token to be refused")
+	}
+}
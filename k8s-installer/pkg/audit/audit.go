@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s-installer/schema"
+)
+
+const (
+	Redacted = "******"
+	// body larger than this is summarized rather than recorded
+	MaxBodySize = 64 << 10
+)
+
+var (
+	// any parameter or body field whose name contains one of these is redacted
+	sensitiveParts = []string{"password", "passwd", "secret", "token", "credential", "private", "passphrase"}
+	// names too short to match as part, msg of ssh api carries encoded credential
+	sensitiveNames = map[string]bool{"key": true, "msg": true, "randomcode": true, "otp_code": true, "recovery_code": true, "authorization": true, "cookie": true}
+)
+
+// Hash of event chained to previous one, hash field of the event itself is left out
+func Hash(event schema.AuditEvent) string {
+	event.Hash = ""
+	data, _ := json.Marshal(event)
+	sum := sha256.Sum256(append([]byte(event.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain links event to head and fills in it`s sequence, id and hash, head is moved to the event
+func Chain(event *schema.AuditEvent, head *schema.AuditChainHead) {
+	event.Sequence = head.Sequence + 1
+	event.Id = fmt.Sprintf("%020d", event.Sequence)
+	event.PrevHash = head.Hash
+	event.Hash = Hash(*event)
+	head.Sequence = event.Sequence
+	head.Hash = event.Hash
+}
+
+/*
+Verify walks the chain in sequence order
+modified event fails it`s own hash, removed event leaves a gap or a prev hash mismatch
+events removed from the end are caught by head, head is nil when nothing has been recorded
+*/
+func Verify(events []schema.AuditEvent, head *schema.AuditChainHead) schema.AuditVerification {
+	result := schema.AuditVerification{Valid: true, Count: len(events)}
+	broken := func(sequence uint64, message string) schema.AuditVerification {
+		result.Valid = false
+		result.BrokenAt = sequence
+		result.Message = message
+		return result
+	}
+	previous := schema.AuditChainHead{}
+	for _, event := range events {
+		if event.Sequence != previous.Sequence+1 {
+			return broken(previous.Sequence+1, fmt.Sprintf("Event %d is missing", previous.Sequence+1))
+		}
+		if event.PrevHash != previous.Hash {
+			return broken(event.Sequence, fmt.Sprintf("Event %d is not chained to event %d", event.Sequence, previous.Sequence))
+		}
+		if Hash(event) != event.Hash {
+			return broken(event.Sequence, fmt.Sprintf("Event %d is modified", event.Sequence))
+		}
+		previous = schema.AuditChainHead{Sequence: event.Sequence, Hash: event.Hash}
+	}
+	if head == nil {
+		head = &schema.AuditChainHead{}
+	}
+	if previous != *head {
+		return broken(previous.Sequence+1, fmt.Sprintf("Chain ends at event %d but head is event %d", previous.Sequence, head.Sequence))
+	}
+	return result
+}
+
+func IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveNames[name] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactParameters keeps first value of each parameter, values of sensitive ones are replaced
+func RedactParameters(parameters map[string][]string) map[string]string {
+	if len(parameters) == 0 {
+		return nil
+	}
+	result := map[string]string{}
+	for name, values := range parameters {
+		switch {
+		case IsSensitive(name):
+			result[name] = Redacted
+		case len(values) > 0:
+			result[name] = values[0]
+		default:
+			result[name] = ""
+		}
+	}
+	return result
+}
+
+/*
+RedactCommand returns command with values of sensitive arguments replaced
+both --name=value and name=value are checked, a sensitive flag without value redacts the argument after it
+*/
+func RedactCommand(command []string) []string {
+	result := make([]string, len(command))
+	redactNext := false
+	for index, arg := range command {
+		if redactNext {
+			result[index] = Redacted
+			redactNext = false
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if position := strings.Index(name, "="); position > 0 {
+			if IsSensitive(name[:position]) {
+				result[index] = arg[:len(arg)-len(name)+position+1] + Redacted
+				continue
+			}
+		} else if name != arg && IsSensitive(name) {
+			redactNext = true
+		}
+		result[index] = arg
+	}
+	return result
+}
+
+// RedactBody returns json body with values of sensitive fields replaced at any depth, anything else is only summarized
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > MaxBodySize {
+		return fmt.Sprintf("%d bytes, not recorded", len(body))
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("%d bytes of non json body, not recorded", len(body))
+	}
+	data, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Sprintf("%d bytes, not recorded", len(body))
+	}
+	return string(data)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			if IsSensitive(key) {
+				typed[key] = Redacted
+			} else {
+				typed[key] = redactValue(item)
+			}
+		}
+		return typed
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = redactValue(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
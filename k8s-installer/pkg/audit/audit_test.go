@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-installer/schema"
+)
+
+func chain(n int) ([]schema.AuditEvent, *schema.AuditChainHead) {
+	head := &schema.AuditChainHead{}
+	var events []schema.AuditEvent
+	for i := 0; i < n; i++ {
+		event := schema.AuditEvent{Time: int64(1600000000 + i), Action: "api-request", Operator: "admin", Path: "/api/core/v1/clusters"}
+		Chain(&event, head)
+		events = append(events, event)
+	}
+	return events, head
+}
+
+func TestVerify(t *testing.T) {
+	events, head := chain(5)
+	if result := Verify(events, head); !result.Valid || result.Count != 5 {
+		t.Fatalf("Expected untouched chain to be valid, got %+v", result)
+	}
+	if result := Verify(nil, nil); !result.Valid {
+		t.Fatalf("Expected empty chain to be valid, got %+v", result)
+	}
+
+	modified, _ := chain(5)
+	modified[2].Operator = "someone"
+	if result := Verify(modified, head); result.Valid || result.BrokenAt != 3 {
+		t.Fatalf("Expected modified event 3 to be reported, got %+v", result)
+	}
+
+	removed := append(append([]schema.AuditEvent{}, events[:1]...), events[2:]...)
+	if result := Verify(removed, head); result.Valid || result.BrokenAt != 2 {
+		t.Fatalf("Expected removed event 2 to be reported, got %+v", result)
+	}
+
+	// rehashing modified event still breaks link of the next one
+	rehashed, _ := chain(5)
+	rehashed[2].Operator = "someone"
+	rehashed[2].Hash = Hash(rehashed[2])
+	if result := Verify(rehashed, head); result.Valid || result.BrokenAt != 4 {
+		t.Fatalf("Expected event 4 to be reported, got %+v", result)
+	}
+
+	if result := Verify(events[:4], head); result.Valid || result.BrokenAt != 5 {
+		t.Fatalf("Expected truncated tail to be reported, got %+v", result)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	body := RedactBody([]byte(`{"username":"admin","password":"secret1","nested":[{"private_registry_key":"k","name":"n"}],"otp_code":"123456"}`))
+	for _, leaked := range []string{"secret1", `"k"`, "123456"} {
+		if strings.Contains(body, leaked) {
+			t.Errorf("Expected %s to be redacted from %s", leaked, body)
+		}
+	}
+	for _, kept := range []string{"admin", `"n"`} {
+		if !strings.Contains(body, kept) {
+			t.Errorf("Expected %s to be kept in %s", kept, body)
+		}
+	}
+	if body := RedactBody([]byte("not json password=1")); strings.Contains(body, "password") {
+		t.Errorf("Expected non json body to be summarized, got %s", body)
+	}
+}
+
+func TestRedactParameters(t *testing.T) {
+	result := RedactParameters(map[string][]string{"token": {"abc"}, "msg": {"encoded"}, "region_id": {"r1"}})
+	if result["token"] != Redacted || result["msg"] != Redacted || result["region_id"] != "r1" {
+		t.Errorf("Unexpected redacted parameters %v", result)
+	}
+}
+
+func TestRedactCommand(t *testing.T) {
+	command := RedactCommand([]string{"kubectl", "exec", "pod", "--", "login", "--password", "secret1", "--token=abc", "PASSPHRASE=xyz", "-n", "default"})
+	expected := []string{"kubectl", "exec", "pod", "--", "login", "--password", Redacted, "--token=" + Redacted, "PASSPHRASE=" + Redacted, "-n", "default"}
+	if strings.Join(command, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v got %v", expected, command)
+	}
+}
@@ -0,0 +1,106 @@
+package auth_provider
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/pkg/log"
+)
+
+const (
+	defaultLDAPUserFilter         = "(uid=%s)"
+	defaultLDAPGroupFilter        = "(member=%s)"
+	defaultLDAPGroupNameAttribute = "cn"
+)
+
+/*
+LDAPAuthenticate finds user with service account then binds as the user to check password
+returns nil identity without error when username or password is wrong
+*/
+func LDAPAuthenticate(config apiServerConfig.LDAPProvider, username, password string) (*Identity, error) {
+	// empty password is an unauthenticated bind which most servers accept
+	if username == "" || password == "" {
+		return nil, nil
+	}
+	conn, err := ldap.DialURL(config.URL, ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to ldap server %s due to error: %s", config.URL, err.Error())
+	}
+	defer conn.Close()
+	if config.StartTLS {
+		if err := conn.StartTLS(&tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}); err != nil {
+			return nil, fmt.Errorf("Failed to start tls with ldap server %s due to error: %s", config.URL, err.Error())
+		}
+	}
+	if err := serviceBind(conn, config); err != nil {
+		return nil, err
+	}
+
+	userFilter := config.UserFilter
+	if userFilter == "" {
+		userFilter = defaultLDAPUserFilter
+	}
+	attributes := []string{"dn"}
+	if config.PhoneAttribute != "" {
+		attributes = append(attributes, config.PhoneAttribute)
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(config.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(userFilter, ldap.EscapeFilter(username)), attributes, nil))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search ldap user %s due to error: %s", username, err.Error())
+	}
+	if len(result.Entries) != 1 {
+		log.Debugf("Ldap user filter matches %d entries for user %s", len(result.Entries), username)
+		return nil, nil
+	}
+	userEntry := result.Entries[0]
+
+	if err := conn.Bind(userEntry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to bind ldap user %s due to error: %s", userEntry.DN, err.Error())
+	}
+
+	identity := &Identity{Username: username}
+	if config.PhoneAttribute != "" {
+		identity.Phone = userEntry.GetAttributeValue(config.PhoneAttribute)
+	}
+	if config.GroupBaseDN == "" {
+		return identity, nil
+	}
+	// search groups as service account again as user may not be allowed to read them
+	if err := serviceBind(conn, config); err != nil {
+		return nil, err
+	}
+	groupFilter := config.GroupFilter
+	if groupFilter == "" {
+		groupFilter = defaultLDAPGroupFilter
+	}
+	groupNameAttribute := config.GroupNameAttribute
+	if groupNameAttribute == "" {
+		groupNameAttribute = defaultLDAPGroupNameAttribute
+	}
+	groups, err := conn.Search(ldap.NewSearchRequest(config.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(groupFilter, ldap.EscapeFilter(userEntry.DN)), []string{groupNameAttribute}, nil))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search ldap groups of user %s due to error: %s", username, err.Error())
+	}
+	for _, group := range groups.Entries {
+		identity.Groups = append(identity.Groups, group.GetAttributeValue(groupNameAttribute))
+	}
+	return identity, nil
+}
+
+func serviceBind(conn *ldap.Conn, config apiServerConfig.LDAPProvider) error {
+	if config.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(config.BindDN, config.BindPassword); err != nil {
+		return fmt.Errorf("Failed to bind ldap service account %s due to error: %s", config.BindDN, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package auth_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+)
+
+const (
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+)
+
+type OIDCClient struct {
+	config   apiServerConfig.OIDCProvider
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// discovery is done once per issuer, keys are refreshed by verifier itself
+var oidcClients sync.Map
+
+func GetOIDCClient(ctx context.Context, config apiServerConfig.OIDCProvider) (*OIDCClient, error) {
+	cacheKey := config.Issuer + "|" + config.ClientID
+	if client, found := oidcClients.Load(cacheKey); found {
+		return client.(*OIDCClient), nil
+	}
+	provider, err := oidc.NewProvider(ctx, config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to discover oidc issuer %s due to error: %s", config.Issuer, err.Error())
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email", "groups"}
+	}
+	client := &OIDCClient{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+	}
+	oidcClients.Store(cacheKey, client)
+	return client, nil
+}
+
+func (client *OIDCClient) AuthCodeURL(state, nonce string) string {
+	return client.oauth2.AuthCodeURL(state, oidc.Nonce(nonce))
+}
+
+// Exchange redeems authorization code and reads identity from verified id token
+func (client *OIDCClient) Exchange(ctx context.Context, code, nonce string) (*Identity, error) {
+	token, err := client.oauth2.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to exchange oidc authorization code due to error: %s", err.Error())
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("Oidc token response does not contain id_token")
+	}
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to verify oidc id token due to error: %s", err.Error())
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("Oidc id token nonce does not match")
+	}
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	return identityFromClaims(client.config, claims)
+}
+
+func identityFromClaims(config apiServerConfig.OIDCProvider, claims map[string]interface{}) (*Identity, error) {
+	usernameClaim := config.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = defaultOIDCUsernameClaim
+	}
+	username, _ := claims[usernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("Oidc id token does not contain claim %s", usernameClaim)
+	}
+	identity := &Identity{Username: username}
+	if config.PhoneClaim != "" {
+		identity.Phone, _ = claims[config.PhoneClaim].(string)
+	}
+	groupsClaim := config.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultOIDCGroupsClaim
+	}
+	switch groups := claims[groupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+	return identity, nil
+}
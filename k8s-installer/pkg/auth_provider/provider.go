@@ -0,0 +1,78 @@
+package auth_provider
+
+import (
+	"fmt"
+	"strings"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+// Identity is the user an external provider vouches for
+type Identity struct {
+	Username string
+	Phone    string
+	Groups   []string
+}
+
+func FindProvider(providers []apiServerConfig.AuthProvider, name string) *apiServerConfig.AuthProvider {
+	for index := range providers {
+		if providers[index].Name == name {
+			return &providers[index]
+		}
+	}
+	return nil
+}
+
+func CheckProviders(providers []apiServerConfig.AuthProvider) []string {
+	var errList []string
+	names := map[string]bool{}
+	for _, provider := range providers {
+		switch {
+		case provider.Name == "":
+			errList = append(errList, "Auth provider name cannot be empty")
+			continue
+		case provider.Name == constants.StandardAuth || provider.Name == constants.CaaSAuth || provider.Name == constants.UserSourceServiceAccount:
+			errList = append(errList, fmt.Sprintf("Auth provider name %s is reserved", provider.Name))
+		case names[provider.Name]:
+			errList = append(errList, fmt.Sprintf("Auth provider name %s is duplicated", provider.Name))
+		}
+		names[provider.Name] = true
+		switch provider.Type {
+		case constants.AuthProviderTypeLDAP:
+			if provider.LDAP == nil || provider.LDAP.URL == "" || provider.LDAP.UserBaseDN == "" {
+				errList = append(errList, fmt.Sprintf("Ldap auth provider %s requires url and user-base-dn", provider.Name))
+			}
+		case constants.AuthProviderTypeOIDC:
+			if provider.OIDC == nil || provider.OIDC.Issuer == "" || provider.OIDC.ClientID == "" || provider.OIDC.RedirectURL == "" {
+				errList = append(errList, fmt.Sprintf("Oidc auth provider %s requires issuer, client-id and redirect-url", provider.Name))
+			}
+		default:
+			errList = append(errList, fmt.Sprintf("Auth provider %s has unsupported type '%s', supported types are %s,%s", provider.Name, provider.Type, constants.AuthProviderTypeLDAP, constants.AuthProviderTypeOIDC))
+		}
+	}
+	return errList
+}
+
+/*
+MapRoles turns groups of external user into installer roles
+mapping and default roles may refer to a role by either id or name, unknown ones are skipped
+*/
+func MapRoles(provider apiServerConfig.AuthProvider, groups []string, roles schema.RoleCollection) []schema.Role {
+	wanted := append([]string{}, provider.DefaultRoles...)
+	for _, group := range groups {
+		wanted = append(wanted, provider.GroupRoleMapping[group]...)
+	}
+	var result []schema.Role
+	added := map[string]bool{}
+	for _, roleRef := range wanted {
+		for _, role := range roles {
+			if (role.Id == roleRef || strings.EqualFold(role.Name, roleRef)) && !added[role.Id] {
+				added[role.Id] = true
+				result = append(result, role)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package auth_provider
+
+import (
+	"testing"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+	"k8s-installer/schema"
+)
+
+func TestMapRoles(t *testing.T) {
+	roles := schema.RoleCollection{
+		"1": {Id: "1", Name: "admin", Function: 1},
+		"2": {Id: "2", Name: "viewer", Function: 2},
+	}
+	provider := apiServerConfig.AuthProvider{
+		DefaultRoles:     []string{"viewer"},
+		GroupRoleMapping: map[string][]string{"ops": {"1", "Viewer"}, "dev": {"missing"}},
+	}
+	mapped := MapRoles(provider, []string{"ops", "dev"}, roles)
+	if len(mapped) != 2 || mapped[0].Id != "2" || mapped[1].Id != "1" {
+		t.Fatalf("Expected viewer then admin role, got %v", mapped)
+	}
+	if mapped := MapRoles(apiServerConfig.AuthProvider{}, []string{"ops"}, roles); len(mapped) != 0 {
+		t.Fatalf("Expected no role without mapping, got %v", mapped)
+	}
+}
+
+func TestCheckProviders(t *testing.T) {
+	providers := []apiServerConfig.AuthProvider{
+		{Name: "corp", Type: "ldap", LDAP: &apiServerConfig.LDAPProvider{URL: "ldap://ldap", UserBaseDN: "dc=corp"}},
+		{Name: "corp", Type: "oidc", OIDC: &apiServerConfig.OIDCProvider{Issuer: "https://sso", ClientID: "installer"}},
+		{Name: "standard", Type: "saml"},
+	}
+	// duplicated name, missing redirect url, reserved name and unsupported type
+	if errList := CheckProviders(providers); len(errList) != 4 {
+		t.Fatalf("Expected 4 errors, got %v", errList)
+	}
+	if errList := CheckProviders(providers[:1]); len(errList) != 0 {
+		t.Fatalf("Expected no error, got %v", errList)
+	}
+}
+
+func TestIdentityFromClaims(t *testing.T) {
+	identity, err := identityFromClaims(apiServerConfig.OIDCProvider{PhoneClaim: "phone_number"}, map[string]interface{}{
+		"preferred_username": "alice",
+		"phone_number":       "123",
+		"groups":             []interface{}{"ops", "dev"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" || identity.Phone != "123" || len(identity.Groups) != 2 {
+		t.Fatalf("Unexpected identity %+v", identity)
+	}
+	if _, err := identityFromClaims(apiServerConfig.OIDCProvider{UsernameClaim: "email"}, map[string]interface{}{"preferred_username": "alice"}); err == nil {
+		t.Fatal("Expected error when username claim is missing")
+	}
+}
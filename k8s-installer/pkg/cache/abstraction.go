@@ -22,6 +22,13 @@ type ICache interface {
 	IUpgrade
 	IRegion
 	IDNS
+	ILoginAttempt
+	IAudit
+	IMFA
+	IAPIToken
+	ISession
+	IRoleBinding
+	IServerRegistry
 	SyncFromDatabase() error
 	initialization(config etcdClientConfig.EtcdConfig, operationLoader func(operation schema.Operation, cluster schema.Cluster, config server.Config, nodeCollectionList schema.NodeInformationCollection) (schema.Operation, error)) error
 	SaveOrUpdateServerRuntimeConfig(serverId string, config server.Config) error
@@ -66,13 +73,61 @@ type IUserRole interface {
 	DeleteRole(roleId string)
 }
 
+type ILoginAttempt interface {
+	GetLoginAttempt(key string) (*schema.LoginAttempt, error)
+	GetLoginAttemptCollection() (schema.LoginAttemptCollection, error)
+	SaveOrUpdateLoginAttempt(attempt schema.LoginAttempt) error
+	IncrementLoginAttempt(key string, increment func(attempt *schema.LoginAttempt)) (*schema.LoginAttempt, error)
+	DeleteLoginAttempt(key string) error
+}
+
+type IAudit interface {
+	AddAuditEvent(event schema.AuditEvent) error
+	GetAuditEventList() ([]schema.AuditEvent, error)
+	GetAuditChainHead() (*schema.AuditChainHead, error)
+}
+
+type IMFA interface {
+	GetUserMFA(username string) (*schema.UserMFA, error)
+	SaveOrUpdateUserMFA(mfa schema.UserMFA) error
+	DeleteUserMFA(username string) error
+}
+
+type IAPIToken interface {
+	GetAPIToken(id string) (*schema.APIToken, error)
+	GetAPITokenCollection() (schema.APITokenCollection, error)
+	SaveOrUpdateAPIToken(token schema.APIToken) error
+}
+
+type ISession interface {
+	GetSession(id string) (*schema.Session, error)
+	GetSessionCollection() (schema.SessionCollection, error)
+	SaveOrUpdateSession(session schema.Session) error
+	DeleteSession(id string) error
+}
+
+type IRoleBinding interface {
+	GetRoleBinding(id string) (*schema.RoleBinding, error)
+	GetRoleBindingCollection() (schema.RoleBindingCollection, error)
+	SaveOrUpdateRoleBinding(binding schema.RoleBinding) error
+	DeleteRoleBinding(id string) error
+}
+
+type IServerRegistry interface {
+	GetLiveServers() (map[string]string, error)
+}
+
 type IOperation interface {
 	GetOperationCollection() (schema.OperationCollection, error)
 	GetOperation(operationId string) (*schema.Operation, error)
+	// GetOperationDoNotLoadStep returns operation without breaking it down into steps again
+	GetOperationDoNotLoadStep(operationId string) (*schema.Operation, error)
 	GetClusterOperation(cluster schema.Cluster) ([]schema.Operation, error)
 	GetClusterOperationDoNotLoadStep(cluster schema.Cluster) ([]schema.Operation, error)
 	SaveOrUpdateOperationCollection(operationId string, operation schema.Operation) error
 	SetOperationStatus(operationId, status string) error
+	// ClaimOperation moves operation run by fromHost to toHost, false meaning it is not run by fromHost any more
+	ClaimOperation(operationId, fromHost, toHost string) (bool, error)
 	DeleteOperation(operationId string) error
 }
 
@@ -135,9 +190,12 @@ func initOrGetNoCache(config etcdClientConfig.EtcdConfig, operationLoader func(o
 
 func InitCacheRuntime(config cache.CacheConfig, etcdConfig etcdClientConfig.EtcdConfig, operationLoader func(operation schema.Operation, cluster schema.Cluster, config server.Config, nodeCollectionList schema.NodeInformationCollection) (schema.Operation, error)) ICache {
 	switch config.CacheRuntime {
-	case "local-ram":
+	case cache.RuntimeLocalRam:
+		return initOrGetLocalRam(etcdConfig, operationLoader)
+	case cache.RuntimeEmbedded:
+		// embedded store only serves one server, nothing stops data from being kept in ram
 		return initOrGetLocalRam(etcdConfig, operationLoader)
-	case "no-cache":
+	case cache.RuntimeNoCache:
 		return initOrGetNoCache(etcdConfig, operationLoader)
 	default:
 		return initOrGetLocalRam(etcdConfig, operationLoader)
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/schema"
+)
+
+/*
+api tokens are never cached in ram
+revoking a token on one server has to take effect on every server at once
+*/
+type APITokenStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+func (store APITokenStore) GetAPIToken(id string) (*schema.APIToken, error) {
+	return getAPITokenFromDB(id, store.etcdConfig)
+}
+
+func (store APITokenStore) GetAPITokenCollection() (schema.APITokenCollection, error) {
+	return getAPITokenCollectionFromDB(store.etcdConfig)
+}
+
+func (store APITokenStore) SaveOrUpdateAPIToken(token schema.APIToken) error {
+	return createOrUpdateAPITokenToDB(token, store.etcdConfig)
+}
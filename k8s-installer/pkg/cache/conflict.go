@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+/*
+ErrConflict is returned by saving cluster, node or operation someone else saved first since it was read
+resource version of saved object is compared with the one in etcd, zero version meaning saving regardless
+api returns it to user as 409, internal writers merge with the latest object by Update* below
+*/
+var ErrConflict = errors.New("object has been modified since it was read, please read it again and retry")
+
+// how many times Update* reads the latest object again before giving up
+const conflictRetries = 5
+
+/*
+UpdateCluster applies mutate to the latest cluster and saves it, cluster is read and mutated again every time someone else saves first
+mutate gets an empty cluster when it does not exist yet, returning error stops the update
+*/
+func UpdateCluster(runtimeCache IClusterNode, clusterId string, mutate func(latest *schema.Cluster) error) (*schema.Cluster, error) {
+	for attempt := 1; ; attempt++ {
+		cluster, err := runtimeCache.GetCluster(clusterId)
+		if err != nil {
+			return nil, err
+		}
+		if cluster == nil {
+			cluster = &schema.Cluster{}
+		}
+		if err := mutate(cluster); err != nil {
+			return nil, err
+		}
+		err = runtimeCache.SaveOrUpdateClusterCollection(clusterId, *cluster)
+		if !retryOnConflict(err, attempt, "cluster", clusterId) {
+			return cluster, err
+		}
+	}
+}
+
+/*
+UpdateNodeInformation applies mutate to the latest node and saves it, node is read and mutated again every time someone else saves first
+mutate gets an empty node when it does not exist yet, returning error stops the update
+*/
+func UpdateNodeInformation(runtimeCache IClusterNode, nodeId string, mutate func(latest *schema.NodeInformation) error) (*schema.NodeInformation, error) {
+	for attempt := 1; ; attempt++ {
+		node, err := runtimeCache.GetNodeInformation(nodeId)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			node = &schema.NodeInformation{}
+		}
+		if err := mutate(node); err != nil {
+			return nil, err
+		}
+		err = runtimeCache.SaveOrUpdateNodeInformation(nodeId, *node)
+		if !retryOnConflict(err, attempt, "node", nodeId) {
+			return node, err
+		}
+	}
+}
+
+/*
+UpdateOperation applies mutate to the latest operation and saves it, operation is read and mutated again every time someone else saves first
+operation is read as it is saved, steps of it are not built again so mutate must not rely on them
+mutate gets an empty operation when it does not exist yet, returning error stops the update
+*/
+func UpdateOperation(runtimeCache IOperation, operationId string, mutate func(latest *schema.Operation) error) (*schema.Operation, error) {
+	for attempt := 1; ; attempt++ {
+		operation, err := runtimeCache.GetOperationDoNotLoadStep(operationId)
+		if err != nil {
+			return nil, err
+		}
+		if operation == nil {
+			operation = &schema.Operation{}
+		}
+		if err := mutate(operation); err != nil {
+			return nil, err
+		}
+		err = runtimeCache.SaveOrUpdateOperationCollection(operationId, *operation)
+		if !retryOnConflict(err, attempt, "operation", operationId) {
+			return operation, err
+		}
+	}
+}
+
+func retryOnConflict(err error, attempt int, kind, id string) bool {
+	if !errors.Is(err, ErrConflict) {
+		return false
+	}
+	if attempt >= conflictRetries {
+		log.Warnf("Give up saving %s %s after %d conflicts", kind, id, attempt)
+		return false
+	}
+	log.Debugf("%s %s is modified by someone else, merge with the latest one and retry", kind, id)
+	return true
+}
+
+// IsConflict tells whether err is caused by saving object someone else saved first
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
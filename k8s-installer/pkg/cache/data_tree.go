@@ -0,0 +1,17 @@
+package cache
+
+// DataPrefix is the key prefix records of collection (e.g. cluster, operation) are stored under, empty when collection is unknown
+func DataPrefix(collection string) string {
+	return etcdDataTree[collection]
+}
+
+/*
+schema version every collection is saved at, set by schema migration as migrations register
+records are stamped with it on save so migration knows which of them an older server has written
+*/
+var schemaVersions = map[string]int{}
+
+// SetSchemaVersion is only called while migrations register on init, nothing saves before that so it is not locked
+func SetSchemaVersion(collection string, version int) {
+	schemaVersions[collection] = version
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/metrics"
 	"strings"
 	"time"
 
@@ -33,6 +34,15 @@ func (etcdClient *EtcdClient) ConnectDB() error {
 	return nil
 }
 
+// Connect returns a client which stays connected until caller closes it, for leases and watches which outlive a single request
+func (etcdClient *EtcdClient) Connect() (*etcdv3.Client, error) {
+	clientConfig, err := etcdClient.getClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return etcdv3.New(*clientConfig)
+}
+
 func (etcdClient *EtcdClient) CloseConnection() error {
 	return etcdClient.v3Client.Close()
 }
@@ -77,7 +87,9 @@ func (etcdClient *EtcdClient) Put(etcdPath string, objToSave string) error {
 	if err := etcdClient.ConnectDB(); err != nil {
 		return nil
 	}
+	start := time.Now()
 	pr, errPut := etcdClient.Kv.Put(etcdClient.Ctx, etcdPath, objToSave)
+	metrics.ObserveEtcdRequest("put", start, errPut)
 	if errPut != nil {
 		return errPut
 	}
@@ -101,7 +113,9 @@ func (etcdClient *EtcdClient) Get(path string, withPrefix bool) (*etcdv3.GetResp
 		opts = append(opts, etcdv3.WithPrefix())
 	}
 
+	start := time.Now()
 	kvs, err := etcdClient.Kv.Get(etcdClient.Ctx, path, opts...)
+	metrics.ObserveEtcdRequest("get", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +133,9 @@ func (etcdClient *EtcdClient) GetWithPage(path string, index, pageSize int64) (*
 	}
 
 	// count all match keys
+	start := time.Now()
 	kvsAll, err := etcdClient.Kv.Get(etcdClient.Ctx, path, opts...)
-
+	metrics.ObserveEtcdRequest("get", start, err)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -161,10 +176,87 @@ func (etcdClient *EtcdClient) Delete(path string) error {
 		return err
 	}
 
+	start := time.Now()
 	res, err := etcdClient.Kv.Delete(etcdClient.Ctx, path)
+	metrics.ObserveEtcdRequest("delete", start, err)
 	if err != nil {
 		return err
 	}
 	log.Debugf("Successfully delete data from etcd with revision id: %d", res.Header.Revision)
 	return nil
 }
+
+// CurrentRevision returns revision of the whole store, changes made after it are able to be watched from revision + 1
+func (etcdClient *EtcdClient) CurrentRevision() (int64, error) {
+	defer etcdClient.CloseConnection()
+	if err := etcdClient.ConnectDB(); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	res, err := etcdClient.Kv.Get(etcdClient.Ctx, "/", etcdv3.WithCountOnly())
+	metrics.ObserveEtcdRequest("get", start, err)
+	if err != nil {
+		return 0, err
+	}
+	return res.Header.Revision, nil
+}
+
+// GetWithRevision returns value of the key and revision it is last modified at, revision 0 means key does not exist
+func (etcdClient *EtcdClient) GetWithRevision(path string) ([]byte, int64, error) {
+	defer etcdClient.CloseConnection()
+	if err := etcdClient.ConnectDB(); err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
+	res, err := etcdClient.Kv.Get(etcdClient.Ctx, path)
+	metrics.ObserveEtcdRequest("get", start, err)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(res.Kvs) == 0 {
+		return nil, 0, nil
+	}
+	return res.Kvs[0].Value, res.Kvs[0].ModRevision, nil
+}
+
+// PutWithRevision puts value only when key is still at revision, revision 0 meaning put regardless
+// it returns revision value is saved at, false means someone else changed key first
+func (etcdClient *EtcdClient) PutWithRevision(path, value string, revision int64) (int64, bool, error) {
+	defer etcdClient.CloseConnection()
+	if err := etcdClient.ConnectDB(); err != nil {
+		return 0, false, err
+	}
+	txn := etcdClient.Kv.Txn(etcdClient.Ctx)
+	if revision != 0 {
+		txn = txn.If(etcdv3.Compare(etcdv3.ModRevision(path), "=", revision))
+	}
+	start := time.Now()
+	res, err := txn.Then(etcdv3.OpPut(path, value)).Commit()
+	metrics.ObserveEtcdRequest("txn", start, err)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.Header.Revision, res.Succeeded, nil
+}
+
+// PutIfRevision puts every key in one transaction only when guard key is still at the revision, false means someone else changed it first
+func (etcdClient *EtcdClient) PutIfRevision(guardPath string, revision int64, objsToSave map[string]string) (bool, error) {
+	defer etcdClient.CloseConnection()
+	if err := etcdClient.ConnectDB(); err != nil {
+		return false, err
+	}
+	var puts []etcdv3.Op
+	for path, obj := range objsToSave {
+		puts = append(puts, etcdv3.OpPut(path, obj))
+	}
+	start := time.Now()
+	res, err := etcdClient.Kv.Txn(etcdClient.Ctx).
+		If(etcdv3.Compare(etcdv3.ModRevision(guardPath), "=", revision)).
+		Then(puts...).
+		Commit()
+	metrics.ObserveEtcdRequest("txn", start, err)
+	if err != nil {
+		return false, err
+	}
+	return res.Succeeded, nil
+}
@@ -2,8 +2,11 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"k8s-installer/pkg/audit"
 	"k8s-installer/pkg/coredns"
+	"strings"
 
 	etcdConfig "k8s-installer/pkg/config/etcd_client"
 	"k8s-installer/pkg/log"
@@ -25,20 +28,32 @@ var etcdDataTree = map[string]string{
 	"region":                     "/regions/",
 	"top-level-domain":           "/domain/top-level-domain/",
 	"sub-domain":                 "/domain/sub-domain/",
+	"login-attempt":              "/login-attempts/",
+	"audit":                      "/audit-chain/",
+	"audit-head":                 "/audit-chain-head",
+	"mfa":                        "/mfa/",
+	"api-token":                  "/api-tokens/",
+	"session":                    "/sessions/",
+	"role-binding":               "/role-bindings/",
+	"server-registry":            "/server-registry/",
+	"leader-election":            "/leader-election/",
 }
 
 func createOrUpdateTopLevelDomainToDB(domain coredns.TopLevelDomain, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["top-level-domain"] + domain.Domain
+	domain.SchemaVersion = schemaVersions["top-level-domain"]
 	return commandCreate(path, domain, config)
 }
 
 func createOrUpdateSubDomainToDB(domain coredns.DNSDomain, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["sub-domain"] + fmt.Sprintf("%s/%s", domain.TopLevelDomain, domain.Domain)
+	domain.SchemaVersion = schemaVersions["sub-domain"]
 	return commandCreate(path, domain, config)
 }
 
 func createOrUpdateClusterUpgradePlanToDB(plan schema.UpgradePlan, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["upgrade-plan"] + plan.Id
+	plan.SchemaVersion = schemaVersions["upgrade-plan"]
 	return commandCreate(path, plan, config)
 }
 
@@ -49,22 +64,40 @@ func createOrUpdateClusterNodeRelationToDB(clusterId string, data map[string]byt
 
 func createOrUpdateRoleToDB(roleId string, role schema.Role, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["role"] + roleId
+	role.SchemaVersion = schemaVersions["role"]
 	return commandCreate(path, role, config)
 }
 
 func createOrUpdateUserToDB(userId string, user schema.User, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["user"] + user.Username
+	user.SchemaVersion = schemaVersions["user"]
 	return commandCreate(path, user, config)
 }
 
-func createOrUpdateClusterToDB(clusterId string, cluster schema.Cluster, config etcdConfig.EtcdConfig) error {
-	path := etcdDataTree["cluster"] + clusterId
-	return commandCreate(path, cluster, config)
+func createOrUpdateClusterToDB(clusterId string, cluster *schema.Cluster, config etcdConfig.EtcdConfig) error {
+	toSave := *cluster
+	toSave.ResourceVersion = 0
+	toSave.SchemaVersion = schemaVersions["cluster"]
+	revision, err := commandSave(etcdDataTree["cluster"]+clusterId, toSave, cluster.ResourceVersion, config)
+	if err != nil {
+		return err
+	}
+	cluster.ResourceVersion = revision
+	cluster.SchemaVersion = toSave.SchemaVersion
+	return nil
 }
 
-func createOrUpdateNodeInformationToDB(nodeId string, node schema.NodeInformation, config etcdConfig.EtcdConfig) error {
-	path := etcdDataTree["node"] + nodeId
-	return commandCreate(path, node, config)
+func createOrUpdateNodeInformationToDB(nodeId string, node *schema.NodeInformation, config etcdConfig.EtcdConfig) error {
+	toSave := *node
+	toSave.ResourceVersion = 0
+	toSave.SchemaVersion = schemaVersions["node"]
+	revision, err := commandSave(etcdDataTree["node"]+nodeId, toSave, node.ResourceVersion, config)
+	if err != nil {
+		return err
+	}
+	node.ResourceVersion = revision
+	node.SchemaVersion = toSave.SchemaVersion
+	return nil
 }
 
 func createOrUpdadeLicense(license schema.LicenseInfo, config etcdConfig.EtcdConfig) error {
@@ -91,9 +124,39 @@ func commandCreate(path string, objToSave interface{}, config etcdConfig.EtcdCon
 	return nil
 }
 
-func createOrUpdateOperationToDB(operationId string, operation schema.Operation, config etcdConfig.EtcdConfig) error {
-	path := etcdDataTree["operation"] + operationId
-	return commandCreate(path, operation, config)
+func createOrUpdateOperationToDB(operationId string, operation *schema.Operation, config etcdConfig.EtcdConfig) error {
+	toSave := *operation
+	toSave.ResourceVersion = 0
+	toSave.SchemaVersion = schemaVersions["operation"]
+	revision, err := commandSave(etcdDataTree["operation"]+operationId, toSave, operation.ResourceVersion, config)
+	if err != nil {
+		return err
+	}
+	operation.ResourceVersion = revision
+	operation.SchemaVersion = toSave.SchemaVersion
+	return nil
+}
+
+/*
+commandSave saves object only when its key is still at revision object was read at, revision 0 meaning saving regardless
+revision is kept by etcd rather than in saved value, the one object is saved at is returned
+*/
+func commandSave(path string, objToSave interface{}, revision int64, config etcdConfig.EtcdConfig) (int64, error) {
+	data, err := json.Marshal(objToSave)
+	if err != nil {
+		return 0, err
+	}
+	client := EtcdClient{
+		EtcdClientConfig: config,
+	}
+	newRevision, saved, err := client.PutWithRevision(path, string(data), revision)
+	if err != nil {
+		return 0, err
+	}
+	if !saved {
+		return 0, fmt.Errorf("%s: %w", path, ErrConflict)
+	}
+	return newRevision, nil
 }
 
 func createOrUpdateUpgradeVersionToDB(versionName string, version schema.UpgradableVersion, config etcdConfig.EtcdConfig) error {
@@ -193,15 +256,19 @@ func getUpgradeVersionListFromDB(config etcdConfig.EtcdConfig) ([]schema.Upgrada
 }
 
 func getClusterCollectionFromDB(config etcdConfig.EtcdConfig) (schema.ClusterCollection, error) {
-	var results map[string]schema.Cluster
-	data, err := commonQueryMap(etcdDataTree["cluster"], config, true)
+	results := schema.ClusterCollection{}
+	err := queryWithRevision(etcdDataTree["cluster"], config, true, func(key string, value []byte, revision int64) error {
+		cluster := schema.Cluster{}
+		if err := json.Unmarshal(value, &cluster); err != nil {
+			return err
+		}
+		cluster.ResourceVersion = revision
+		results[key] = cluster
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	errParse := json.Unmarshal(data, &results)
-	if errParse != nil {
-		return nil, errParse
-	}
 	return results, nil
 }
 
@@ -223,37 +290,41 @@ func getUpgradePlanFromDB(planId string, config etcdConfig.EtcdConfig) (*schema.
 }
 
 func getClusterFromDB(clusterId string, config etcdConfig.EtcdConfig) (*schema.Cluster, error) {
-	var results []schema.Cluster
-	data, err := commonQuery(etcdDataTree["cluster"]+clusterId, config, false)
-	if err != nil {
-		return nil, err
-	}
-	if len(data) > 0 {
-		if err = json.Unmarshal(data, &results); err != nil {
-			return nil, err
+	var result *schema.Cluster
+	err := queryWithRevision(etcdDataTree["cluster"]+clusterId, config, false, func(key string, value []byte, revision int64) error {
+		if result != nil {
+			return nil
 		}
-		if len(results) > 0 {
-			return &results[0], err
+		result = &schema.Cluster{}
+		if err := json.Unmarshal(value, result); err != nil {
+			return err
 		}
+		result.ResourceVersion = revision
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return result, nil
 }
 
 func getClusterWithShortIdFromDB(clusterId string, config etcdConfig.EtcdConfig) (*schema.Cluster, error) {
-	var results []schema.Cluster
-	data, err := commonQuery(etcdDataTree["cluster"]+clusterId, config, true)
-	if err != nil {
-		return nil, err
-	}
-	if len(data) > 0 {
-		if err = json.Unmarshal(data, &results); err != nil {
-			return nil, err
+	var result *schema.Cluster
+	err := queryWithRevision(etcdDataTree["cluster"]+clusterId, config, true, func(key string, value []byte, revision int64) error {
+		if result != nil {
+			return nil
 		}
-		if len(results) > 0 {
-			return &results[0], err
+		result = &schema.Cluster{}
+		if err := json.Unmarshal(value, result); err != nil {
+			return err
 		}
+		result.ResourceVersion = revision
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return result, nil
 }
 
 func getClusterNodeRelationCollectionFromDB(config etcdConfig.EtcdConfig) (schema.ClusterNodeRelationShipCollection, error) {
@@ -287,15 +358,19 @@ func getClusterNodeRelationFromDB(clusterId string, config etcdConfig.EtcdConfig
 }
 
 func getNodeInformationCollectionFromDB(config etcdConfig.EtcdConfig) (schema.NodeInformationCollection, error) {
-	var results map[string]schema.NodeInformation
-	data, err := commonQueryMap(etcdDataTree["node"], config, true)
+	results := schema.NodeInformationCollection{}
+	err := queryWithRevision(etcdDataTree["node"], config, true, func(key string, value []byte, revision int64) error {
+		node := schema.NodeInformation{}
+		if err := json.Unmarshal(value, &node); err != nil {
+			return err
+		}
+		node.ResourceVersion = revision
+		results[key] = node
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	errParse := json.Unmarshal(data, &results)
-	if errParse != nil {
-		return nil, errParse
-	}
 	return results, nil
 }
 
@@ -360,20 +435,19 @@ func getSubDomainOfTldFromDB(topLevelDomain string, config etcdConfig.EtcdConfig
 }
 
 func getNodeInformationFromDB(nodeId string, config etcdConfig.EtcdConfig) (*schema.NodeInformation, error) {
-	var results []schema.NodeInformation
-	data, err := commonQuery(etcdDataTree["node"]+nodeId, config, false)
+	var result *schema.NodeInformation
+	err := queryWithRevision(etcdDataTree["node"]+nodeId, config, false, func(key string, value []byte, revision int64) error {
+		result = &schema.NodeInformation{}
+		if err := json.Unmarshal(value, result); err != nil {
+			return err
+		}
+		result.ResourceVersion = revision
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if len(data) > 0 {
-		if err = json.Unmarshal(data, &results); err != nil {
-			return nil, err
-		}
-		if len(results) > 0 {
-			return &results[0], err
-		}
-	}
-	return nil, nil
+	return result, nil
 }
 
 func getUpgradeVersionFromDB(versionName string, config etcdConfig.EtcdConfig) (*schema.UpgradableVersion, error) {
@@ -394,32 +468,35 @@ func getUpgradeVersionFromDB(versionName string, config etcdConfig.EtcdConfig) (
 }
 
 func getOperationFromDB(operationId string, config etcdConfig.EtcdConfig) (*schema.Operation, error) {
-	var results []schema.Operation
-	data, err := commonQuery(etcdDataTree["operation"]+operationId, config, false)
+	var result *schema.Operation
+	err := queryWithRevision(etcdDataTree["operation"]+operationId, config, false, func(key string, value []byte, revision int64) error {
+		result = &schema.Operation{}
+		if err := json.Unmarshal(value, result); err != nil {
+			return err
+		}
+		result.ResourceVersion = revision
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if len(data) > 0 {
-		if err = json.Unmarshal(data, &results); err != nil {
-			return nil, err
-		}
-		if len(results) > 0 {
-			return &results[0], err
-		}
-	}
-	return nil, nil
+	return result, nil
 }
 
 func getOperationCollectionFromDB(config etcdConfig.EtcdConfig) (schema.OperationCollection, error) {
-	var results map[string]schema.Operation
-	data, err := commonQueryMap(etcdDataTree["operation"], config, true)
+	results := schema.OperationCollection{}
+	err := queryWithRevision(etcdDataTree["operation"], config, true, func(key string, value []byte, revision int64) error {
+		operation := schema.Operation{}
+		if err := json.Unmarshal(value, &operation); err != nil {
+			return err
+		}
+		operation.ResourceVersion = revision
+		results[key] = operation
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	errParse := json.Unmarshal(data, &results)
-	if errParse != nil {
-		return nil, errParse
-	}
 	return results, nil
 }
 
@@ -531,6 +608,38 @@ func deleteClusterCollectionFromDB(clusterId string, config etcdConfig.EtcdConfi
 	return commandDelete(etcdDataTree["cluster"]+clusterId, config)
 }
 
+/*
+claimOperationInDB moves operation from one host to another
+it only saves when operation is still run by fromHost and nobody changed it since read, nil meaning someone else got there first
+*/
+func claimOperationInDB(operationId, fromHost, toHost string, config etcdConfig.EtcdConfig) (*schema.Operation, error) {
+	operation, err := getOperationFromDB(operationId, config)
+	if err != nil || operation == nil || operation.Host != fromHost {
+		return nil, err
+	}
+	operation.Host = toHost
+	if err := createOrUpdateOperationToDB(operationId, operation, config); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return operation, nil
+}
+
+func getLiveServersFromDB(config etcdConfig.EtcdConfig) (map[string]string, error) {
+	client := EtcdClient{EtcdClientConfig: config}
+	queryResult, err := client.Get(etcdDataTree["server-registry"], true)
+	if err != nil {
+		return nil, err
+	}
+	results := map[string]string{}
+	for _, kv := range queryResult.Kvs {
+		results[strings.TrimPrefix(string(kv.Key), etcdDataTree["server-registry"])] = string(kv.Value)
+	}
+	return results, nil
+}
+
 func deleteOperationFromDB(operationId string, config etcdConfig.EtcdConfig) error {
 	return commandDelete(etcdDataTree["operation"]+operationId, config)
 }
@@ -561,6 +670,27 @@ func commonQuery(path string, config etcdConfig.EtcdConfig, withPrefix bool) ([]
 	return util.UnitToSlice(queryResult.Kvs), nil
 }
 
+/*
+queryWithRevision hands value of every key found along with revision it is last modified at to each
+key is the last part of key path same as commonQueryMap does
+*/
+func queryWithRevision(path string, config etcdConfig.EtcdConfig, withPrefix bool, each func(key string, value []byte, revision int64) error) error {
+	client := EtcdClient{
+		EtcdClientConfig: config,
+	}
+	queryResult, err := client.Get(path, withPrefix)
+	if err != nil {
+		return err
+	}
+	for _, kv := range queryResult.Kvs {
+		key := string(kv.Key)
+		if err := each(key[strings.LastIndex(key, "/")+1:], kv.Value, kv.ModRevision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func commonQueryPage(path string, index, pageSize int64, config etcdConfig.EtcdConfig) ([]byte, int64, error) {
 	client := EtcdClient{
 		EtcdClientConfig: config,
@@ -628,6 +758,7 @@ func loadAllDataFromETCD(config etcdConfig.EtcdConfig) (nodeInformationCollectio
 
 func createOrUpdateRegionToDB(region schema.Region, config etcdConfig.EtcdConfig) error {
 	path := etcdDataTree["region"] + region.ID
+	region.SchemaVersion = schemaVersions["region"]
 	return commandCreate(path, region, config)
 }
 
@@ -647,3 +778,283 @@ func getRegionListFromDB(config etcdConfig.EtcdConfig) (schema.RegionCollection,
 func deleteRegionFromDB(regionID string, config etcdConfig.EtcdConfig) error {
 	return commandDelete(etcdDataTree["region"]+regionID, config)
 }
+
+func createOrUpdateLoginAttemptToDB(attempt schema.LoginAttempt, config etcdConfig.EtcdConfig) error {
+	return commandCreate(etcdDataTree["login-attempt"]+attempt.Key, attempt, config)
+}
+
+func getLoginAttemptFromDB(key string, config etcdConfig.EtcdConfig) (*schema.LoginAttempt, error) {
+	var results []schema.LoginAttempt
+	data, err := commonQuery(etcdDataTree["login-attempt"]+key, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], err
+		}
+	}
+	return nil, nil
+}
+
+func getLoginAttemptCollectionFromDB(config etcdConfig.EtcdConfig) (schema.LoginAttemptCollection, error) {
+	var results map[string]schema.LoginAttempt
+	data, err := commonQueryMap(etcdDataTree["login-attempt"], config, true)
+	if err != nil {
+		return nil, err
+	}
+	errParse := json.Unmarshal(data, &results)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return results, nil
+}
+
+func deleteLoginAttemptFromDB(key string, config etcdConfig.EtcdConfig) error {
+	return commandDelete(etcdDataTree["login-attempt"]+key, config)
+}
+
+/*
+incrementLoginAttemptToDB applies increment to attempt of key and saves it only when key is unchanged since it was read
+servers failing the same key at the same time retry on the newer attempt rather than overwrite each other`s failures
+*/
+func incrementLoginAttemptToDB(key string, increment func(attempt *schema.LoginAttempt), config etcdConfig.EtcdConfig) (*schema.LoginAttempt, error) {
+	path := etcdDataTree["login-attempt"] + key
+	for retry := 0; retry < 10; retry++ {
+		client := EtcdClient{EtcdClientConfig: config}
+		data, revision, err := client.GetWithRevision(path)
+		if err != nil {
+			return nil, err
+		}
+		attempt := schema.LoginAttempt{Key: key}
+		if revision != 0 {
+			if err := json.Unmarshal(data, &attempt); err != nil {
+				return nil, err
+			}
+		}
+		increment(&attempt)
+		attemptData, err := json.Marshal(attempt)
+		if err != nil {
+			return nil, err
+		}
+		// mod revision of a missing key compares equal to 0, so the first failure is created only once as well
+		client = EtcdClient{EtcdClientConfig: config}
+		saved, err := client.PutIfRevision(path, revision, map[string]string{path: string(attemptData)})
+		if err != nil {
+			return nil, err
+		}
+		if saved {
+			return &attempt, nil
+		}
+	}
+	return nil, errors.New("Login attempt " + key + " keeps changing, gave up incrementing it")
+}
+
+/*
+appendAuditEventToDB links event to the chain head and saves both in one transaction
+transaction only commits when head is unchanged, so servers appending at the same time retry on the new head rather than fork the chain
+*/
+func appendAuditEventToDB(event schema.AuditEvent, config etcdConfig.EtcdConfig) error {
+	for retry := 0; retry < 10; retry++ {
+		client := EtcdClient{EtcdClientConfig: config}
+		data, revision, err := client.GetWithRevision(etcdDataTree["audit-head"])
+		if err != nil {
+			return err
+		}
+		head := schema.AuditChainHead{}
+		if revision != 0 {
+			if err := json.Unmarshal(data, &head); err != nil {
+				return err
+			}
+		}
+		audit.Chain(&event, &head)
+		eventData, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		headData, err := json.Marshal(head)
+		if err != nil {
+			return err
+		}
+		client = EtcdClient{EtcdClientConfig: config}
+		saved, err := client.PutIfRevision(etcdDataTree["audit-head"], revision, map[string]string{
+			etcdDataTree["audit"] + event.Id: string(eventData),
+			etcdDataTree["audit-head"]:       string(headData),
+		})
+		if err != nil {
+			return err
+		}
+		if saved {
+			return nil
+		}
+	}
+	return errors.New("Audit chain head keeps changing, gave up appending event")
+}
+
+func getAuditChainHeadFromDB(config etcdConfig.EtcdConfig) (*schema.AuditChainHead, error) {
+	client := EtcdClient{EtcdClientConfig: config}
+	data, revision, err := client.GetWithRevision(etcdDataTree["audit-head"])
+	if err != nil || revision == 0 {
+		return nil, err
+	}
+	head := schema.AuditChainHead{}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+func getAuditEventListFromDB(config etcdConfig.EtcdConfig) ([]schema.AuditEvent, error) {
+	var results []schema.AuditEvent
+	data, err := commonQuery(etcdDataTree["audit"], config, true)
+	if err != nil {
+		return nil, err
+	}
+	errParse := json.Unmarshal(data, &results)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return results, nil
+}
+
+func createOrUpdateUserMFAToDB(mfa schema.UserMFA, config etcdConfig.EtcdConfig) error {
+	mfa.SchemaVersion = schemaVersions["mfa"]
+	return commandCreate(etcdDataTree["mfa"]+mfa.Username, mfa, config)
+}
+
+func getUserMFAFromDB(username string, config etcdConfig.EtcdConfig) (*schema.UserMFA, error) {
+	var results []schema.UserMFA
+	data, err := commonQuery(etcdDataTree["mfa"]+username, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], err
+		}
+	}
+	return nil, nil
+}
+
+func deleteUserMFAFromDB(username string, config etcdConfig.EtcdConfig) error {
+	return commandDelete(etcdDataTree["mfa"]+username, config)
+}
+
+func createOrUpdateAPITokenToDB(token schema.APIToken, config etcdConfig.EtcdConfig) error {
+	token.SchemaVersion = schemaVersions["api-token"]
+	return commandCreate(etcdDataTree["api-token"]+token.Id, token, config)
+}
+
+func getAPITokenFromDB(id string, config etcdConfig.EtcdConfig) (*schema.APIToken, error) {
+	var results []schema.APIToken
+	data, err := commonQuery(etcdDataTree["api-token"]+id, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], err
+		}
+	}
+	return nil, nil
+}
+
+func getAPITokenCollectionFromDB(config etcdConfig.EtcdConfig) (schema.APITokenCollection, error) {
+	var results map[string]schema.APIToken
+	data, err := commonQueryMap(etcdDataTree["api-token"], config, true)
+	if err != nil {
+		return nil, err
+	}
+	errParse := json.Unmarshal(data, &results)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return results, nil
+}
+
+func createOrUpdateSessionToDB(session schema.Session, config etcdConfig.EtcdConfig) error {
+	session.SchemaVersion = schemaVersions["session"]
+	return commandCreate(etcdDataTree["session"]+session.Id, session, config)
+}
+
+func getSessionFromDB(id string, config etcdConfig.EtcdConfig) (*schema.Session, error) {
+	var results []schema.Session
+	data, err := commonQuery(etcdDataTree["session"]+id, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], err
+		}
+	}
+	return nil, nil
+}
+
+func getSessionCollectionFromDB(config etcdConfig.EtcdConfig) (schema.SessionCollection, error) {
+	var results map[string]schema.Session
+	data, err := commonQueryMap(etcdDataTree["session"], config, true)
+	if err != nil {
+		return nil, err
+	}
+	errParse := json.Unmarshal(data, &results)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return results, nil
+}
+
+func deleteSessionFromDB(id string, config etcdConfig.EtcdConfig) error {
+	return commandDelete(etcdDataTree["session"]+id, config)
+}
+
+func createOrUpdateRoleBindingToDB(binding schema.RoleBinding, config etcdConfig.EtcdConfig) error {
+	binding.SchemaVersion = schemaVersions["role-binding"]
+	return commandCreate(etcdDataTree["role-binding"]+binding.Id, binding, config)
+}
+
+func getRoleBindingFromDB(id string, config etcdConfig.EtcdConfig) (*schema.RoleBinding, error) {
+	var results []schema.RoleBinding
+	data, err := commonQuery(etcdDataTree["role-binding"]+id, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], err
+		}
+	}
+	return nil, nil
+}
+
+func getRoleBindingCollectionFromDB(config etcdConfig.EtcdConfig) (schema.RoleBindingCollection, error) {
+	var results map[string]schema.RoleBinding
+	data, err := commonQueryMap(etcdDataTree["role-binding"], config, true)
+	if err != nil {
+		return nil, err
+	}
+	errParse := json.Unmarshal(data, &results)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return results, nil
+}
+
+func deleteRoleBindingFromDB(id string, config etcdConfig.EtcdConfig) error {
+	return commandDelete(etcdDataTree["role-binding"]+id, config)
+}
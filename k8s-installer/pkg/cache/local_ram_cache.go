@@ -9,6 +9,7 @@ import (
 
 /*
 local cache should only use with single server
+changes made to etcd by others are applied to ram by watching database, but writes are not coordinated among servers
 */
 type LocalRam struct {
 	UserRoleCache
@@ -16,29 +17,41 @@ type LocalRam struct {
 	ClusterNodeCache
 	UpgradeCache
 	RegionCache
-	IDNS
+	LocalRamDns
+	ILoginAttempt
+	IAudit
+	IMFA
+	IAPIToken
+	ISession
+	IRoleBinding
+	IServerRegistry
+	etcdConfig etcdClientConfig.EtcdConfig
 }
 
 func (g *LocalRam) initialization(etcdConfig etcdClientConfig.EtcdConfig, operationLoader func(operation schema.Operation, cluster schema.Cluster, config server.Config, nodeCollectionList schema.NodeInformationCollection) (schema.Operation, error)) error {
+	g.etcdConfig = etcdConfig
 	g.UserRoleCache = UserRoleCache{etcdConfig: etcdConfig}
-	g.OperationCache = OperationCache{etcdConfig: etcdConfig, operationLoader: operationLoader}
+	g.OperationCache = OperationCache{etcdConfig: etcdConfig, operationLoader: operationLoader, clusterNodes: &g.ClusterNodeCache}
 	g.ClusterNodeCache = ClusterNodeCache{etcdConfig: etcdConfig}
 	g.UpgradeCache = UpgradeCache{etcdConfig: etcdConfig}
-	g.IDNS = LocalRamDns{etcdConfig: etcdConfig}
+	g.LocalRamDns = LocalRamDns{etcdConfig: etcdConfig}
+	g.ILoginAttempt = LoginAttemptStore{etcdConfig: etcdConfig}
+	g.IAudit = AuditStore{etcdConfig: etcdConfig}
+	g.IMFA = MFAStore{etcdConfig: etcdConfig}
+	g.IAPIToken = APITokenStore{etcdConfig: etcdConfig}
+	g.ISession = SessionStore{etcdConfig: etcdConfig}
+	g.IRoleBinding = RoleBindingStore{etcdConfig: etcdConfig}
+	g.IServerRegistry = ServerRegistryStore{etcdConfig: etcdConfig}
 	return nil
 }
 
 func (g *LocalRam) SyncFromDatabase() error {
-	if err := g.UserRoleCache.initialization(); err != nil {
+	revision, err := g.reload()
+	if err != nil {
 		return err
 	}
-	if err := g.ClusterNodeCache.initialization(); err != nil {
-		return err
-	}
-	if err := g.OperationCache.initialization(&g.ClusterNodeCache); err != nil {
-		return err
-	}
-	return g.UpgradeCache.initialization()
+	go g.watchDatabase(revision)
+	return nil
 }
 
 func (g LocalRam) SaveOrUpdateServerRuntimeConfig(nodeId string, config server.Config) error {
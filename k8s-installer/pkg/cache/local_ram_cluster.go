@@ -4,6 +4,7 @@ import (
 	"sort"
 
 	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/pkg/log"
 	"k8s-installer/schema"
 )
 
@@ -33,25 +34,56 @@ func (cachedClusterNode *ClusterNodeCache) initialization() error {
 
 func (cachedClusterNode *ClusterNodeCache) GetAvailableNodesMap(pageIndex, pageSize int64,
 	filter func(information schema.NodeInformation) *schema.NodeInformation) (schema.NodeInformationCollection, int64, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	return getAvailableNodesMap(cachedClusterNode.nodeInformationCollection, pageIndex, pageSize, filter)
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetNodeInformationCollection() (schema.NodeInformationCollection, error) {
-	return cachedClusterNode.nodeInformationCollection, nil
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.NodeInformationCollection{}
+	for key, node := range cachedClusterNode.nodeInformationCollection {
+		results[key] = node
+	}
+	return results, nil
 }
 
 func (cachedClusterNode *ClusterNodeCache) SaveOrUpdateNodeInformation(nodeId string, nodeInformation schema.NodeInformation) error {
-	if err := createOrUpdateNodeInformationToDB(nodeId, nodeInformation, cachedClusterNode.etcdConfig); err != nil {
+	if err := createOrUpdateNodeInformationToDB(nodeId, &nodeInformation, cachedClusterNode.etcdConfig); err != nil {
+		if IsConflict(err) {
+			cachedClusterNode.refreshNodeInformation(nodeId)
+		}
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedClusterNode.nodeInformationCollection[nodeId] = nodeInformation
 	return nil
 }
 
+// refreshNodeInformation replaces node in ram with the one in database, saving calls it once it finds ram out of date
+func (cachedClusterNode *ClusterNodeCache) refreshNodeInformation(nodeId string) {
+	node, err := getNodeInformationFromDB(nodeId, cachedClusterNode.etcdConfig)
+	if err != nil {
+		log.Warnf("Failed to refresh node %s from database due to error: %s", nodeId, err.Error())
+		return
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	if node == nil {
+		delete(cachedClusterNode.nodeInformationCollection, nodeId)
+		return
+	}
+	cachedClusterNode.nodeInformationCollection[nodeId] = *node
+}
+
 func (cachedClusterNode *ClusterNodeCache) DeleteNodeInformation(nodeId string) error {
 	if err := deleteNodeInformationFromDB(nodeId, cachedClusterNode.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	delete(cachedClusterNode.nodeInformationCollection, nodeId)
 	return nil
 }
@@ -70,13 +102,34 @@ func (cachedClusterNode *ClusterNodeCache) GetClusterCollection() (schema.Cluste
 }
 
 func (cachedClusterNode *ClusterNodeCache) SaveOrUpdateClusterCollection(clusterId string, cluster schema.Cluster) error {
-	if err := createOrUpdateClusterToDB(clusterId, cluster, cachedClusterNode.etcdConfig); err != nil {
+	if err := createOrUpdateClusterToDB(clusterId, &cluster, cachedClusterNode.etcdConfig); err != nil {
+		if IsConflict(err) {
+			cachedClusterNode.refreshCluster(clusterId)
+		}
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedClusterNode.clusterCollection[clusterId] = cluster
 	return nil
 }
 
+// refreshCluster replaces cluster in ram with the one in database, saving calls it once it finds ram out of date
+func (cachedClusterNode *ClusterNodeCache) refreshCluster(clusterId string) {
+	cluster, err := getClusterFromDB(clusterId, cachedClusterNode.etcdConfig)
+	if err != nil {
+		log.Warnf("Failed to refresh cluster %s from database due to error: %s", clusterId, err.Error())
+		return
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	if cluster == nil {
+		delete(cachedClusterNode.clusterCollection, clusterId)
+		return
+	}
+	cachedClusterNode.clusterCollection[clusterId] = *cluster
+}
+
 func (cachedClusterNode *ClusterNodeCache) DeleteClusterCollection(clusterId string) error {
 	panic("implement me")
 }
@@ -87,13 +140,8 @@ func (cachedClusterNode *ClusterNodeCache) AddClusterNodeRelationShip(clusterId
 		return nil
 	}
 
-	var dataToSave map[string]byte
-
-	if _, found := cachedClusterNode.clusterNodeRelationShip[clusterId]; found {
-		dataToSave = cachedClusterNode.clusterNodeRelationShip[clusterId]
-	} else {
-		dataToSave = map[string]byte{}
-	}
+	// a copy, map in ram is swapped only after saved
+	dataToSave, _ := cachedClusterNode.GetClusterNodeRelationShip(clusterId)
 
 	for _, node := range nodes {
 		dataToSave[node] = 0
@@ -103,22 +151,32 @@ func (cachedClusterNode *ClusterNodeCache) AddClusterNodeRelationShip(clusterId
 		return err
 	}
 
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedClusterNode.clusterNodeRelationShip[clusterId] = dataToSave
 	return nil
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetClusterNodeRelationShip(clusterId string) (map[string]byte, error) {
-	return cachedClusterNode.clusterNodeRelationShip[clusterId], nil
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	result := map[string]byte{}
+	for node, value := range cachedClusterNode.clusterNodeRelationShip[clusterId] {
+		result[node] = value
+	}
+	return result, nil
 }
 
 func (cachedClusterNode *ClusterNodeCache) RemoveClusterNodeRelationShip(clusterId string, nodes []string) error {
-	remainNodes := cachedClusterNode.clusterNodeRelationShip[clusterId]
+	remainNodes, _ := cachedClusterNode.GetClusterNodeRelationShip(clusterId)
 	for _, node := range nodes {
 		delete(remainNodes, node)
 	}
 	if err := createOrUpdateClusterNodeRelationToDB(clusterId, remainNodes, cachedClusterNode.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedClusterNode.clusterNodeRelationShip[clusterId] = remainNodes
 	return nil
 }
@@ -128,11 +186,15 @@ func (cachedClusterNode *ClusterNodeCache) RemoveAllClusterNodeRelationShip(clus
 	if err := deleteClusterNodeRelation(clusterId, cachedClusterNode.etcdConfig); err != nil {
 		return err
 	}
-	delete(cachedClusterNode.nodeInformationCollection, clusterId)
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	delete(cachedClusterNode.clusterNodeRelationShip, clusterId)
 	return nil
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetNodeInformationWithCluster(clusterId string) ([]schema.NodeInformation, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	var results []schema.NodeInformation
 	if relation, found := cachedClusterNode.clusterNodeRelationShip[clusterId]; !found {
 		return results, nil
@@ -147,6 +209,8 @@ func (cachedClusterNode *ClusterNodeCache) GetNodeInformationWithCluster(cluster
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetNodeInformationCollectionWithPage(pageIndex, pageSize int64) (schema.NodeInformationCollection, int64, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	nodeCount := int64(len(cachedClusterNode.nodeInformationCollection))
 	if nodeCount == 0 {
 		return nil, 0, nil
@@ -175,6 +239,8 @@ func (cachedClusterNode *ClusterNodeCache) GetNodeInformationCollectionWithPage(
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetCluster(clusterId string) (*schema.Cluster, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if _, exists := cachedClusterNode.clusterCollection[clusterId]; exists {
 		cluster := cachedClusterNode.clusterCollection[clusterId]
 		return &cluster, nil
@@ -187,6 +253,8 @@ func (cachedClusterNode *ClusterNodeCache) GetClusterWithShortId(shortClusterId
 }
 
 func (cachedClusterNode *ClusterNodeCache) GetNodeInformation(nodeId string) (*schema.NodeInformation, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if _, exists := cachedClusterNode.nodeInformationCollection[nodeId]; exists {
 		nodeInfo := cachedClusterNode.nodeInformationCollection[nodeId]
 		return &nodeInfo, nil
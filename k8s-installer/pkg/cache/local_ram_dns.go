@@ -1,52 +1,133 @@
 package cache
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
 	"k8s-installer/pkg/coredns"
 	"k8s-installer/schema"
 )
 
 type LocalRamDns struct {
-	clusterCollection schema.ClusterCollection
-	etcdConfig        etcdClientConfig.EtcdConfig
+	etcdConfig      etcdClientConfig.EtcdConfig
+	topLevelDomains schema.TopLevelDomainCollection
+	// key is {top level domain}/{domain} same as in etcd
+	subDomains schema.SubDomainCollection
+}
+
+func (l *LocalRamDns) initialization() error {
+	var err error
+	l.topLevelDomains, err = getTopLevelDomainCollectionFromDB(l.etcdConfig)
+	if err != nil {
+		return err
+	}
+	l.subDomains = schema.SubDomainCollection{}
+	client := EtcdClient{EtcdClientConfig: l.etcdConfig}
+	queryResult, err := client.Get(etcdDataTree["sub-domain"], true)
+	if err != nil {
+		return err
+	}
+	for _, kv := range queryResult.Kvs {
+		domain := coredns.DNSDomain{}
+		if err := json.Unmarshal(kv.Value, &domain); err != nil {
+			return err
+		}
+		l.subDomains[strings.TrimPrefix(string(kv.Key), etcdDataTree["sub-domain"])] = domain
+	}
+	return nil
+}
+
+func (l *LocalRamDns) CreateOrUpdateTopLevelDomain(domain coredns.TopLevelDomain) error {
+	if err := createOrUpdateTopLevelDomainToDB(domain, l.etcdConfig); err != nil {
+		return err
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	l.topLevelDomains[domain.Domain] = domain
+	return nil
 }
 
-func (l LocalRamDns) CreateOrUpdateTopLevelDomain(domain coredns.TopLevelDomain) error {
-	panic("implement me")
+func (l *LocalRamDns) GetTopLevelDomainList() (schema.TopLevelDomainCollection, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.TopLevelDomainCollection{}
+	for key, domain := range l.topLevelDomains {
+		results[key] = domain
+	}
+	return results, nil
 }
 
-func (l LocalRamDns) GetTopLevelDomainList() (schema.TopLevelDomainCollection, error) {
-	panic("implement me")
+func (l *LocalRamDns) GetTopLevelDomain(domain string) (*coredns.TopLevelDomain, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	if found, exists := l.topLevelDomains[domain]; exists {
+		return &found, nil
+	}
+	return nil, nil
 }
 
-func (l LocalRamDns) GetTopLevelDomain(domain string) (*coredns.TopLevelDomain, error) {
-	panic("implement me")
+func (l *LocalRamDns) DeleteTopLevelDomain(domain string) error {
+	if err := deleteTopLevelDomainFromDB(domain, l.etcdConfig); err != nil {
+		return err
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	delete(l.topLevelDomains, domain)
+	return nil
 }
 
-func (l LocalRamDns) DeleteTopLevelDomain(domain string) error {
-	panic("implement me")
+func (l *LocalRamDns) CreateOrUpdateSubDomain(domain coredns.DNSDomain) error {
+	if err := createOrUpdateSubDomainToDB(domain, l.etcdConfig); err != nil {
+		return err
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	l.subDomains[fmt.Sprintf("%s/%s", domain.TopLevelDomain, domain.Domain)] = domain
+	return nil
 }
 
-func (l LocalRamDns) CreateOrUpdateSubDomain(domain coredns.DNSDomain) error {
-	panic("implement me")
+func (l *LocalRamDns) GetSubDomainList(topLevelDomain string) (schema.SubDomainCollection, error) {
+	return l.subDomainsOf(topLevelDomain + "/"), nil
 }
 
-func (l LocalRamDns) GetSubDomainList(topLevelDomain string) (schema.SubDomainCollection, error) {
-	panic("implement me")
+func (l *LocalRamDns) GetAllSubDomainList() (schema.SubDomainCollection, error) {
+	return l.subDomainsOf(""), nil
 }
 
-func (l LocalRamDns) GetAllSubDomainList() (schema.SubDomainCollection, error) {
-	panic("implement me")
+func (l *LocalRamDns) GetSubDomain(topLevelDomain, domain string) (*coredns.DNSDomain, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	if found, exists := l.subDomains[fmt.Sprintf("%s/%s", topLevelDomain, domain)]; exists {
+		return &found, nil
+	}
+	return nil, nil
 }
 
-func (l LocalRamDns) GetSubDomain(topLevelDomain, domain string) (*coredns.DNSDomain, error) {
-	panic("implement me")
+func (l *LocalRamDns) DeleteSubDomain(topLevelDomain, domain string) error {
+	if err := deleteSubDomainFromDB(topLevelDomain, domain, l.etcdConfig); err != nil {
+		return err
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	delete(l.subDomains, fmt.Sprintf("%s/%s", topLevelDomain, domain))
+	return nil
 }
 
-func (l LocalRamDns) DeleteSubDomain(topLevelDomain, domain string) error {
-	panic("implement me")
+func (l *LocalRamDns) GetSubDomainOfTls(topLevelDomain string) (schema.SubDomainCollection, error) {
+	return l.subDomainsOf(topLevelDomain + "/"), nil
 }
 
-func (l LocalRamDns) GetSubDomainOfTls(topLevelDomain string) (schema.SubDomainCollection, error) {
-	panic("implement me")
+// subDomainsOf returns sub domains keyed by domain the same way as reading them from database
+func (l *LocalRamDns) subDomainsOf(keyPrefix string) schema.SubDomainCollection {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.SubDomainCollection{}
+	for key, domain := range l.subDomains {
+		if strings.HasPrefix(key, keyPrefix) {
+			results[key[strings.LastIndex(key, "/")+1:]] = domain
+		}
+	}
+	return results
 }
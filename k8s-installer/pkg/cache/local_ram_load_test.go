@@ -0,0 +1,111 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+	"k8s-installer/pkg/config/server"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/server/embedded_store"
+	"k8s-installer/schema"
+)
+
+func freeURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return fmt.Sprintf("http://%s", listener.Addr().String())
+}
+
+// loading upgrade operation reads upgrade plan from cache again, just like task breaker does
+func loadUpgradeOperation(operation schema.Operation, cluster schema.Cluster, config server.Config, nodeCollectionList schema.NodeInformationCollection) (schema.Operation, error) {
+	plan, err := cache.GetCurrentCache().GetUpgradePlan(operation.RequestParameter.QueryParameters["planId"].(string))
+	if err != nil {
+		return operation, err
+	}
+	if plan == nil {
+		return operation, fmt.Errorf("upgrade plan of operation %s is not found", operation.Id)
+	}
+	operation.Name = "upgrade to " + plan.TargetVersion
+	return operation, nil
+}
+
+func TestLocalRamLoadUpgradeOperation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-ram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := embedded_store.Start(cacheConfig.EmbeddedStoreConfig{
+		DataDir:         dir,
+		ListenClientURL: freeURL(t),
+		ListenPeerURL:   freeURL(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	client, err := (&cache.EtcdClient{EtcdClientConfig: store.EtcdConfig()}).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	put := func(key string, value interface{}) {
+		data, _ := json.Marshal(value)
+		if _, err := client.Put(context.Background(), key, string(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	upgradeOperation := func(id string) schema.Operation {
+		return schema.Operation{
+			Id:               id,
+			ClusterId:        "cluster-1",
+			OperationType:    constants.OperationTypeUpgradeCluster,
+			RequestParameter: schema.RequestParameter{QueryParameters: map[string]interface{}{"planId": "plan-1"}},
+		}
+	}
+	put(cache.DataPrefix("cluster")+"cluster-1", schema.Cluster{ClusterId: "cluster-1"})
+	put(cache.DataPrefix("upgrade-plan")+"plan-1", schema.UpgradePlan{Id: "plan-1", ClusterId: "cluster-1", TargetVersion: "v1.19.8"})
+	put(cache.DataPrefix("operation")+"operation-1", upgradeOperation("operation-1"))
+
+	runtime := cache.InitCacheRuntime(cacheConfig.CacheConfig{CacheRuntime: cacheConfig.RuntimeLocalRam}, store.EtcdConfig(), loadUpgradeOperation)
+	synced := make(chan error, 1)
+	go func() { synced <- runtime.SyncFromDatabase() }()
+	select {
+	case err := <-synced:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("loading upgrade operation from database hangs")
+	}
+	if operation, _ := runtime.GetOperation("operation-1"); operation == nil || operation.Name != "upgrade to v1.19.8" {
+		t.Errorf("expected upgrade operation to be loaded with its plan got %v", operation)
+	}
+
+	// written by another server
+	put(cache.DataPrefix("operation")+"operation-2", upgradeOperation("operation-2"))
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if operation, _ := runtime.GetOperation("operation-2"); operation != nil {
+			if operation.Name != "upgrade to v1.19.8" {
+				t.Errorf("expected upgrade operation put by others to be loaded with its plan got %v", operation)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("upgrade operation put by others does not show up")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -11,49 +11,120 @@ type OperationCache struct {
 	etcdConfig          etcdClientConfig.EtcdConfig
 	operationCollection schema.OperationCollection
 	operationLoader     func(operation schema.Operation, cluster schema.Cluster, config server.Config, nodeCollectionList schema.NodeInformationCollection) (schema.Operation, error)
+	// steps of operation are rebuilt against its cluster and nodes
+	clusterNodes *ClusterNodeCache
 }
 
-func (cachedOperation *OperationCache) initialization(cachedClusterNode *ClusterNodeCache) error {
-	var err error
-	cachedOperation.operationCollection, err = getOperationCollectionFromDB(cachedOperation.etcdConfig)
+// initialization must not be called with lock held, see load
+func (cachedOperation *OperationCache) initialization() error {
+	operationCollection, err := getOperationCollectionFromDB(cachedOperation.etcdConfig)
 	if err != nil {
 		return err
 	}
-	for key, operation := range cachedOperation.operationCollection {
-		if _, exists := cachedClusterNode.clusterCollection[operation.ClusterId]; exists {
-			if operation, err := cachedOperation.operationLoader(operation, cachedClusterNode.clusterCollection[operation.ClusterId],
-				serverRuntimeConfigCollection[NodeId],
-				cachedClusterNode.nodeInformationCollection); err != nil {
-				log.Fatalf("Failed to initial operation collection due to error: %s", err)
-			} else {
-				cachedOperation.operationCollection[key] = operation
-			}
+	for key, operation := range operationCollection {
+		if operation, err := cachedOperation.load(operation); err != nil {
+			log.Fatalf("Failed to initial operation collection due to error: %s", err)
+		} else {
+			operationCollection[key] = operation
 		}
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	cachedOperation.operationCollection = operationCollection
 	return nil
 }
 
+/*
+load rebuilds steps of operation, operation of a cluster not found is kept as it is
+steps are built from a copy of cluster and nodes taken under lock, then with lock released
+since loader reads cache again (e.g. upgrade plan) and lock is not able to be taken twice
+*/
+func (cachedOperation *OperationCache) load(operation schema.Operation) (schema.Operation, error) {
+	localRamLock.RLock()
+	cluster, exists := cachedOperation.clusterNodes.clusterCollection[operation.ClusterId]
+	nodeCollection := schema.NodeInformationCollection{}
+	for key, node := range cachedOperation.clusterNodes.nodeInformationCollection {
+		nodeCollection[key] = node
+	}
+	localRamLock.RUnlock()
+	if !exists {
+		return operation, nil
+	}
+	return cachedOperation.operationLoader(operation, cluster, serverRuntimeConfigCollection[NodeId], nodeCollection)
+}
+
 func (cachedOperation *OperationCache) GetOperationCollection() (schema.OperationCollection, error) {
-	return cachedOperation.operationCollection, nil
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.OperationCollection{}
+	for key, operation := range cachedOperation.operationCollection {
+		results[key] = operation
+	}
+	return results, nil
 }
 
 func (cachedOperation *OperationCache) SaveOrUpdateOperationCollection(operationId string, operation schema.Operation) error {
-	if err := createOrUpdateOperationToDB(operationId, operation, cachedOperation.etcdConfig); err != nil {
+	if err := createOrUpdateOperationToDB(operationId, &operation, cachedOperation.etcdConfig); err != nil {
+		if IsConflict(err) {
+			cachedOperation.refresh(operationId)
+		}
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedOperation.operationCollection[operationId] = operation
 	return nil
 }
 
+// refresh replaces operation in ram with the one in database, saving calls it once it finds ram out of date
+func (cachedOperation *OperationCache) refresh(operationId string) {
+	operation, err := getOperationFromDB(operationId, cachedOperation.etcdConfig)
+	if err != nil {
+		log.Warnf("Failed to refresh operation %s from database due to error: %s", operationId, err.Error())
+		return
+	}
+	if operation == nil {
+		localRamLock.Lock()
+		defer localRamLock.Unlock()
+		delete(cachedOperation.operationCollection, operationId)
+		return
+	}
+	loaded, err := cachedOperation.load(*operation)
+	if err != nil {
+		log.Warnf("Failed to refresh operation %s from database due to error: %s", operationId, err.Error())
+		return
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	cachedOperation.operationCollection[operationId] = loaded
+}
+
 func (cachedOperation *OperationCache) SetOperationStatus(operationId, status string) error {
 	panic("implement me")
 }
 
+func (cachedOperation *OperationCache) ClaimOperation(operationId, fromHost, toHost string) (bool, error) {
+	claimed, err := claimOperationInDB(operationId, fromHost, toHost, cachedOperation.etcdConfig)
+	if err != nil || claimed == nil {
+		return false, err
+	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	if operation, exists := cachedOperation.operationCollection[operationId]; exists {
+		operation.Host = toHost
+		operation.ResourceVersion = claimed.ResourceVersion
+		cachedOperation.operationCollection[operationId] = operation
+	}
+	return true, nil
+}
+
 func (cachedOperation *OperationCache) DeleteOperation(operationId string) error {
 	panic("implement me")
 }
 
 func (cachedOperation *OperationCache) GetOperation(operationId string) (*schema.Operation, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if _, exists := cachedOperation.operationCollection[operationId]; exists {
 		operation := cachedOperation.operationCollection[operationId]
 		return &operation, nil
@@ -61,6 +132,11 @@ func (cachedOperation *OperationCache) GetOperation(operationId string) (*schema
 	return nil, nil
 }
 
+// steps of operation in ram are already built once loaded, nothing to skip
+func (cachedOperation *OperationCache) GetOperationDoNotLoadStep(operationId string) (*schema.Operation, error) {
+	return cachedOperation.GetOperation(operationId)
+}
+
 func (cachedOperation *OperationCache) GetClusterOperationDoNotLoadStep(cluster schema.Cluster) ([]schema.Operation, error) {
 	return nil, nil
 }
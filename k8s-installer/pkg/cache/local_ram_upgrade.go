@@ -26,6 +26,8 @@ func (cachedUpgradeVersion *UpgradeCache) initialization() error {
 }
 
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradeVersionList() ([]schema.UpgradableVersion, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	var versionKeys []string
 	var results []schema.UpgradableVersion
 	for key := range cachedUpgradeVersion.upgradeVersionCollection {
@@ -39,13 +41,21 @@ func (cachedUpgradeVersion *UpgradeCache) GetUpgradeVersionList() ([]schema.Upgr
 }
 
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradeVersionCollection() (schema.UpgradeVersionCollection, error) {
-	return cachedUpgradeVersion.upgradeVersionCollection, nil
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.UpgradeVersionCollection{}
+	for key, version := range cachedUpgradeVersion.upgradeVersionCollection {
+		results[key] = version
+	}
+	return results, nil
 }
 
 func (cachedUpgradeVersion *UpgradeCache) CreateOrUpdateUpgradeVersion(version schema.UpgradableVersion) error {
 	if err := createOrUpdateUpgradeVersionToDB(version.Name, version, cachedUpgradeVersion.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedUpgradeVersion.upgradeVersionCollection[version.Name] = version
 	return nil
 }
@@ -54,10 +64,14 @@ func (cachedUpgradeVersion *UpgradeCache) DeleteUpgradeVersion(version string) e
 	if err := deleteUpgradeVersion(version, cachedUpgradeVersion.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	delete(cachedUpgradeVersion.upgradeVersionCollection, version)
 	return nil
 }
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradeVersion(versionName string) (*schema.UpgradableVersion, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if found, exists := cachedUpgradeVersion.upgradeVersionCollection[versionName]; exists {
 		return &found, nil
 	} else {
@@ -69,15 +83,25 @@ func (cachedUpgradeVersion *UpgradeCache) CreateOrUpdateUpgradePlan(plan schema.
 	if err := createOrUpdateClusterUpgradePlanToDB(plan, cachedUpgradeVersion.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedUpgradeVersion.upgradePlanCollection[plan.Id] = plan
 	return nil
 }
 
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradePlanCollection() (schema.UpgradePlanCollection, error) {
-	return cachedUpgradeVersion.upgradePlanCollection, nil
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
+	results := schema.UpgradePlanCollection{}
+	for key, plan := range cachedUpgradeVersion.upgradePlanCollection {
+		results[key] = plan
+	}
+	return results, nil
 }
 
 func (cachedUpgradeVersion *UpgradeCache) QueryUpgradePlan(filter func(information schema.UpgradePlan) *schema.UpgradePlan) ([]schema.UpgradePlan, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	var results []schema.UpgradePlan
 	for _, plan := range cachedUpgradeVersion.upgradePlanCollection {
 		if filter != nil {
@@ -93,6 +117,8 @@ func (cachedUpgradeVersion *UpgradeCache) QueryUpgradePlan(filter func(informati
 }
 
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradePlanList() ([]schema.UpgradePlan, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	var results []schema.UpgradePlan
 	for _, plan := range cachedUpgradeVersion.upgradePlanCollection {
 		results = append(results, plan)
@@ -101,6 +127,8 @@ func (cachedUpgradeVersion *UpgradeCache) GetUpgradePlanList() ([]schema.Upgrade
 }
 
 func (cachedUpgradeVersion *UpgradeCache) GetUpgradePlan(id string) (*schema.UpgradePlan, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if found, exists := cachedUpgradeVersion.upgradePlanCollection[id]; exists {
 		return &found, nil
 	} else {
@@ -112,6 +140,8 @@ func (cachedUpgradeVersion *UpgradeCache) DeleteUpgradePlan(id string) error {
 	if err := deleteUpgradePlanFromDB(id, cachedUpgradeVersion.etcdConfig); err != nil {
 		return nil
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	delete(cachedUpgradeVersion.upgradePlanCollection, id)
 	return nil
 }
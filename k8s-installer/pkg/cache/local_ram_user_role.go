@@ -16,6 +16,9 @@ type UserRoleCache struct {
 }
 
 func (cachedUser *UserRoleCache) initialization() error {
+	if err := migratePlaintextPasswords(cachedUser.etcdConfig); err != nil {
+		return err
+	}
 	var err error
 	cachedUser.users, err = getUserListFromDB(cachedUser.etcdConfig)
 	if err != nil {
@@ -28,30 +31,24 @@ func (cachedUser *UserRoleCache) initialization() error {
 	return nil
 }
 
-func (cachedUser *UserRoleCache) Login(username, password string) (*schema.User, error) {
+func (cachedUser *UserRoleCache) Login(username, plain string) (*schema.User, error) {
 	userList, err := getUserListFromDB(cachedUser.etcdConfig)
 	if err != nil {
 		return nil, err
 	}
-	for _, user := range userList {
-		if username == user.Username && password == user.Password {
-			return &schema.User{
-				Id:        user.Id,
-				Username:  user.Username,
-				Password:  user.Password,
-				Roles:     user.Roles,
-				Phone:     user.Phone,
-				KsAccount: user.KsAccount,
-			}, nil
-		}
-	}
-	return nil, nil
+	return loginUser(userList, username, plain, cachedUser.etcdConfig, func(user schema.User) {
+		localRamLock.Lock()
+		defer localRamLock.Unlock()
+		cachedUser.users[user.Username] = user
+	}), nil
 }
 
 func (cachedUser *UserRoleCache) CreateOrUpdateUser(username string, user schema.User) error {
 	if err := createOrUpdateUserToDB(username, user, cachedUser.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedUser.users[user.Username] = user
 	return nil
 }
@@ -61,6 +58,8 @@ func (cachedUser *UserRoleCache) GetUserList() (schema.UserCollection, error) {
 }
 
 func (cachedUser *UserRoleCache) GetUser(username string) (*schema.User, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if user, found := cachedUser.users[username]; !found {
 		return nil, nil
 	} else {
@@ -73,6 +72,8 @@ func (cachedUser *UserRoleCache) DeleteUser(userId string) {
 	if err != nil {
 		log.Error(err)
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	delete(cachedUser.users, userId)
 }
 
@@ -92,6 +93,8 @@ func (cachedUser *UserRoleCache) CreateOrUpdateRole(roleId string, role schema.R
 	if err := createOrUpdateRoleToDB(roleId, role, cachedUser.etcdConfig); err != nil {
 		return err
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	cachedUser.roles[roleId] = role
 	return nil
 }
@@ -101,6 +104,8 @@ func (cachedUser *UserRoleCache) GetRoleList() (schema.RoleCollection, error) {
 }
 
 func (cachedUser *UserRoleCache) GetRole(roleId string) (*schema.Role, error) {
+	localRamLock.RLock()
+	defer localRamLock.RUnlock()
 	if _, exists := cachedUser.roles[roleId]; exists {
 		role := cachedUser.roles[roleId]
 		return &role, nil
@@ -113,5 +118,7 @@ func (cachedUser *UserRoleCache) DeleteRole(roleId string) {
 	if err != nil {
 		log.Error(err)
 	}
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
 	delete(cachedUser.roles, roleId)
 }
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/coredns"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+// guards every map of local ram cache, they are written by api handlers and database watch at the same time
+var localRamLock sync.RWMutex
+
+const watchRetryInterval = 5 * time.Second
+
+/*
+ramCollection is one kind of data kept in ram, put and remove apply a change seen in etcd to it
+id is the part of the key after prefix, revision is the one value is saved at
+put runs without lock since building steps of operation reads cache again, it returns what to apply to ram under lock
+*/
+type ramCollection struct {
+	prefix string
+	put    func(id string, value []byte, revision int64) (func(), error)
+	remove func(id string)
+}
+
+/*
+watchDatabase applies changes made to etcd after revision to ram
+so writes of other servers and the ones made to etcd by hand show up here instead of silently diverging
+changes already compacted away by etcd are not able to be replayed, ram is loaded all over again then
+*/
+func (g *LocalRam) watchDatabase(revision int64) {
+	for {
+		var err error
+		revision, err = g.watchFrom(revision)
+		if err == rpctypes.ErrCompacted {
+			log.Warnf("Changes after revision %d are compacted, reload cache from database", revision)
+			if revision, err = g.reload(); err == nil {
+				continue
+			}
+		}
+		log.Errorf("Failed to watch database changes after revision %d due to error: %s, retry in %s", revision, err, watchRetryInterval)
+		time.Sleep(watchRetryInterval)
+	}
+}
+
+// watchFrom returns revision of last change applied once watch breaks
+func (g *LocalRam) watchFrom(revision int64) (int64, error) {
+	client, err := (&EtcdClient{EtcdClientConfig: g.etcdConfig}).Connect()
+	if err != nil {
+		return revision, err
+	}
+	defer client.Close()
+	// fail over to another member rather than wait on one cut off from leader
+	ctx, cancel := context.WithCancel(etcdv3.WithRequireLeader(context.Background()))
+	defer cancel()
+
+	collections := g.ramCollections()
+	for response := range client.Watch(ctx, "/", etcdv3.WithPrefix(), etcdv3.WithRev(revision+1)) {
+		if err := response.Err(); err != nil {
+			return revision, err
+		}
+		for _, event := range response.Events {
+			applyEvent(collections, event)
+			revision = event.Kv.ModRevision
+		}
+	}
+	return revision, errors.New("watch channel is closed")
+}
+
+func applyEvent(collections []ramCollection, event *etcdv3.Event) {
+	key := string(event.Kv.Key)
+	for _, collection := range collections {
+		if !strings.HasPrefix(key, collection.prefix) {
+			continue
+		}
+		id := strings.TrimPrefix(key, collection.prefix)
+		if event.Type == mvccpb.DELETE {
+			localRamLock.Lock()
+			collection.remove(id)
+			localRamLock.Unlock()
+		} else if apply, err := collection.put(id, event.Kv.Value, event.Kv.ModRevision); err != nil {
+			log.Errorf("Failed to apply change of key %s to cache due to error: %s", key, err.Error())
+		} else {
+			localRamLock.Lock()
+			apply()
+			localRamLock.Unlock()
+		}
+		return
+	}
+}
+
+/*
+reload loads every collection from database again, it returns revision data is loaded at
+revision is taken first so nothing changed during loading is missed, changes seen twice are simply applied again
+operations go last, their steps are built from clusters, nodes and upgrade plans already in ram
+*/
+func (g *LocalRam) reload() (int64, error) {
+	revision, err := (&EtcdClient{EtcdClientConfig: g.etcdConfig}).CurrentRevision()
+	if err != nil {
+		return 0, err
+	}
+	if err := g.reloadWithoutOperation(); err != nil {
+		return 0, err
+	}
+	return revision, g.OperationCache.initialization()
+}
+
+func (g *LocalRam) reloadWithoutOperation() error {
+	localRamLock.Lock()
+	defer localRamLock.Unlock()
+	if err := g.UserRoleCache.initialization(); err != nil {
+		return err
+	}
+	if err := g.ClusterNodeCache.initialization(); err != nil {
+		return err
+	}
+	if err := g.UpgradeCache.initialization(); err != nil {
+		return err
+	}
+	return g.LocalRamDns.initialization()
+}
+
+func (g *LocalRam) ramCollections() []ramCollection {
+	return []ramCollection{
+		{
+			prefix: etcdDataTree["cluster"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				cluster := schema.Cluster{}
+				if err := json.Unmarshal(value, &cluster); err != nil {
+					return nil, err
+				}
+				cluster.ResourceVersion = revision
+				return func() { g.clusterCollection[id] = cluster }, nil
+			},
+			remove: func(id string) { delete(g.clusterCollection, id) },
+		},
+		{
+			prefix: etcdDataTree["node"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				node := schema.NodeInformation{}
+				if err := json.Unmarshal(value, &node); err != nil {
+					return nil, err
+				}
+				node.ResourceVersion = revision
+				return func() { g.nodeInformationCollection[id] = node }, nil
+			},
+			remove: func(id string) { delete(g.nodeInformationCollection, id) },
+		},
+		{
+			prefix: etcdDataTree["node-cluster-relation-ship"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				relation := map[string]byte{}
+				if err := json.Unmarshal(value, &relation); err != nil {
+					return nil, err
+				}
+				return func() { g.clusterNodeRelationShip[id] = relation }, nil
+			},
+			remove: func(id string) { delete(g.clusterNodeRelationShip, id) },
+		},
+		{
+			prefix: etcdDataTree["operation"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				// most changes are echo of our own writes, rebuilding steps of them is a waste
+				localRamLock.RLock()
+				cached, found := g.operationCollection[id]
+				localRamLock.RUnlock()
+				if found && cached.ResourceVersion >= revision {
+					return func() {}, nil
+				}
+				operation := schema.Operation{}
+				if err := json.Unmarshal(value, &operation); err != nil {
+					return nil, err
+				}
+				operation.ResourceVersion = revision
+				loaded, err := g.OperationCache.load(operation)
+				if err != nil {
+					return nil, err
+				}
+				return func() {
+					// a newer one may be saved by us while steps are built
+					if cached, found := g.operationCollection[id]; !found || cached.ResourceVersion < revision {
+						g.operationCollection[id] = loaded
+					}
+				}, nil
+			},
+			remove: func(id string) { delete(g.operationCollection, id) },
+		},
+		{
+			prefix: etcdDataTree["user"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				user := schema.User{}
+				if err := json.Unmarshal(value, &user); err != nil {
+					return nil, err
+				}
+				return func() { g.users[id] = user }, nil
+			},
+			remove: func(id string) { delete(g.users, id) },
+		},
+		{
+			prefix: etcdDataTree["role"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				role := schema.Role{}
+				if err := json.Unmarshal(value, &role); err != nil {
+					return nil, err
+				}
+				return func() { g.roles[id] = role }, nil
+			},
+			remove: func(id string) { delete(g.roles, id) },
+		},
+		{
+			prefix: etcdDataTree["upgrade-version"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				version := schema.UpgradableVersion{}
+				if err := json.Unmarshal(value, &version); err != nil {
+					return nil, err
+				}
+				return func() { g.upgradeVersionCollection[id] = version }, nil
+			},
+			remove: func(id string) { delete(g.upgradeVersionCollection, id) },
+		},
+		{
+			prefix: etcdDataTree["upgrade-plan"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				plan := schema.UpgradePlan{}
+				if err := json.Unmarshal(value, &plan); err != nil {
+					return nil, err
+				}
+				return func() { g.upgradePlanCollection[id] = plan }, nil
+			},
+			remove: func(id string) { delete(g.upgradePlanCollection, id) },
+		},
+		{
+			prefix: etcdDataTree["top-level-domain"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				domain := coredns.TopLevelDomain{}
+				if err := json.Unmarshal(value, &domain); err != nil {
+					return nil, err
+				}
+				return func() { g.topLevelDomains[id] = domain }, nil
+			},
+			remove: func(id string) { delete(g.topLevelDomains, id) },
+		},
+		{
+			// id of sub domain is {top level domain}/{domain}
+			prefix: etcdDataTree["sub-domain"],
+			put: func(id string, value []byte, revision int64) (func(), error) {
+				domain := coredns.DNSDomain{}
+				if err := json.Unmarshal(value, &domain); err != nil {
+					return nil, err
+				}
+				return func() { g.subDomains[id] = domain }, nil
+			},
+			remove: func(id string) { delete(g.subDomains, id) },
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"testing"
+
+	"k8s-installer/schema"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+func TestApplyEvent(t *testing.T) {
+	g := &LocalRam{}
+	g.clusterCollection = schema.ClusterCollection{"cluster-gone": {}}
+	g.topLevelDomains = schema.TopLevelDomainCollection{}
+	g.subDomains = schema.SubDomainCollection{}
+	collections := g.ramCollections()
+
+	put := func(key, value string) *etcdv3.Event {
+		return &etcdv3.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value), ModRevision: 7}}
+	}
+	applyEvent(collections, put("/clusters/cluster-1", `{"cluster_id":"cluster-1"}`))
+	applyEvent(collections, &etcdv3.Event{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("/clusters/cluster-gone")}})
+	applyEvent(collections, put("/domain/sub-domain/example.com/www", `{"domain":"www","top_level_domain":"example.com"}`))
+	applyEvent(collections, put("/domain/sub-domain/example.org/www", `{"domain":"www","top_level_domain":"example.org"}`))
+	// not kept in ram
+	applyEvent(collections, put("/sessions/session-1", `{}`))
+
+	if cluster, found := g.clusterCollection["cluster-1"]; !found || cluster.ResourceVersion != 7 {
+		t.Errorf("expected cluster put by others to show up at its revision got %v", cluster)
+	}
+	if _, found := g.clusterCollection["cluster-gone"]; found {
+		t.Error("expected cluster deleted by others to be gone")
+	}
+	subDomains, _ := g.GetSubDomainList("example.com")
+	if len(subDomains) != 1 || subDomains["www"].TopLevelDomain != "example.com" {
+		t.Errorf("expected sub domain of example.com only got %v", subDomains)
+	}
+	if len(g.subDomains) != 2 {
+		t.Errorf("expected sub domains of two top level domains got %v", g.subDomains)
+	}
+}
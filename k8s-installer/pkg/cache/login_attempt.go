@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"time"
+
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/schema"
+)
+
+/*
+login attempts and audit events are never cached in ram
+every server reads and writes db directly so attempts made against different servers add up
+*/
+type LoginAttemptStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+func (store LoginAttemptStore) GetLoginAttempt(key string) (*schema.LoginAttempt, error) {
+	return getLoginAttemptFromDB(key, store.etcdConfig)
+}
+
+func (store LoginAttemptStore) GetLoginAttemptCollection() (schema.LoginAttemptCollection, error) {
+	return getLoginAttemptCollectionFromDB(store.etcdConfig)
+}
+
+func (store LoginAttemptStore) SaveOrUpdateLoginAttempt(attempt schema.LoginAttempt) error {
+	return createOrUpdateLoginAttemptToDB(attempt, store.etcdConfig)
+}
+
+// IncrementLoginAttempt applies increment to the latest attempt of key atomically and returns the attempt saved
+func (store LoginAttemptStore) IncrementLoginAttempt(key string, increment func(attempt *schema.LoginAttempt)) (*schema.LoginAttempt, error) {
+	return incrementLoginAttemptToDB(key, increment, store.etcdConfig)
+}
+
+func (store LoginAttemptStore) DeleteLoginAttempt(key string) error {
+	return deleteLoginAttemptFromDB(key, store.etcdConfig)
+}
+
+type AuditStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+// AddAuditEvent appends event to the audit chain, sequence id and hash are filled in by the chain
+func (store AuditStore) AddAuditEvent(event schema.AuditEvent) error {
+	if event.Time == 0 {
+		event.Time = time.Now().Unix()
+	}
+	return appendAuditEventToDB(event, store.etcdConfig)
+}
+
+// GetAuditEventList returns events in sequence order, id is zero padded sequence so etcd sorts them
+func (store AuditStore) GetAuditEventList() ([]schema.AuditEvent, error) {
+	return getAuditEventListFromDB(store.etcdConfig)
+}
+
+func (store AuditStore) GetAuditChainHead() (*schema.AuditChainHead, error) {
+	return getAuditChainHeadFromDB(store.etcdConfig)
+}
@@ -0,0 +1,55 @@
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+	"k8s-installer/pkg/server/embedded_store"
+	"k8s-installer/schema"
+)
+
+func TestIncrementLoginAttemptAddsUpConcurrentFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "login-attempt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := embedded_store.Start(cacheConfig.EmbeddedStoreConfig{
+		DataDir:         dir,
+		ListenClientURL: freeURL(t),
+		ListenPeerURL:   freeURL(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	runtime := cache.InitCacheRuntime(cacheConfig.CacheConfig{CacheRuntime: cacheConfig.RuntimeNoCache}, store.EtcdConfig(), nil)
+
+	// every failure reads and writes db on its own, just like failures against different servers do
+	failures := 8
+	var wait sync.WaitGroup
+	for i := 0; i < failures; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if _, err := runtime.IncrementLoginAttempt("user:alice", func(attempt *schema.LoginAttempt) {
+				attempt.Failures++
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wait.Wait()
+
+	attempt, err := runtime.GetLoginAttempt("user:alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt == nil || attempt.Failures != failures {
+		t.Fatalf("expected %d failures got %v", failures, attempt)
+	}
+}
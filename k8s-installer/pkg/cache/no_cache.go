@@ -18,6 +18,13 @@ type NoCache struct {
 	UpgradeVersionNoCache
 	RegionNoCache
 	IDNS
+	ILoginAttempt
+	IAudit
+	IMFA
+	IAPIToken
+	ISession
+	IRoleBinding
+	IServerRegistry
 	//etcdConfig                        etcdClientConfig.EtcdConfig
 }
 
@@ -28,6 +35,13 @@ func (n *NoCache) initialization(config etcdClientConfig.EtcdConfig, operationLo
 	n.UpgradeVersionNoCache = UpgradeVersionNoCache{etcdConfig: config}
 	n.RegionNoCache = RegionNoCache{etcdConfig: config}
 	n.IDNS = NoCacheDns{etcdConfig: config}
+	n.ILoginAttempt = LoginAttemptStore{etcdConfig: config}
+	n.IAudit = AuditStore{etcdConfig: config}
+	n.IMFA = MFAStore{etcdConfig: config}
+	n.IAPIToken = APITokenStore{etcdConfig: config}
+	n.ISession = SessionStore{etcdConfig: config}
+	n.IRoleBinding = RoleBindingStore{etcdConfig: config}
+	n.IServerRegistry = ServerRegistryStore{etcdConfig: config}
 	return nil
 }
 
@@ -52,5 +66,5 @@ func (g NoCache) GetClientRuntimeConfig(clientId string) client.Config {
 }
 
 func (n NoCache) SyncFromDatabase() error {
-	return nil
+	return n.UserRoleNoCache.initialization()
 }
@@ -30,7 +30,7 @@ func (noCachedClusterNode *ClusterNodeNoCache) GetNodeInformationCollection() (s
 }
 
 func (noCachedClusterNode *ClusterNodeNoCache) SaveOrUpdateNodeInformation(nodeId string, information schema.NodeInformation) error {
-	return createOrUpdateNodeInformationToDB(nodeId, information, noCachedClusterNode.etcdConfig)
+	return createOrUpdateNodeInformationToDB(nodeId, &information, noCachedClusterNode.etcdConfig)
 }
 
 func (noCachedClusterNode *ClusterNodeNoCache) DeleteNodeInformation(nodeId string) error {
@@ -52,7 +52,7 @@ func (noCachedClusterNode *ClusterNodeNoCache) SetNodeStatus(nodeId, status stri
 		return err
 	}
 	information.Status = status
-	return createOrUpdateNodeInformationToDB(nodeId, *information, noCachedClusterNode.etcdConfig)
+	return createOrUpdateNodeInformationToDB(nodeId, information, noCachedClusterNode.etcdConfig)
 }
 
 func (noCachedClusterNode *ClusterNodeNoCache) GetClusterCollection() (schema.ClusterCollection, error) {
@@ -60,7 +60,7 @@ func (noCachedClusterNode *ClusterNodeNoCache) GetClusterCollection() (schema.Cl
 }
 
 func (noCachedClusterNode *ClusterNodeNoCache) SaveOrUpdateClusterCollection(clusterId string, cluster schema.Cluster) error {
-	return createOrUpdateClusterToDB(clusterId, cluster, noCachedClusterNode.etcdConfig)
+	return createOrUpdateClusterToDB(clusterId, &cluster, noCachedClusterNode.etcdConfig)
 }
 
 func (noCachedClusterNode *ClusterNodeNoCache) GetCluster(clusterId string) (*schema.Cluster, error) {
@@ -67,7 +67,7 @@ func (noCachedOperation *OperationNoCache) GetOperation(operationId string) (*sc
 }
 
 func (noCachedOperation *OperationNoCache) SaveOrUpdateOperationCollection(operationId string, operation schema.Operation) error {
-	return createOrUpdateOperationToDB(operationId, operation, noCachedOperation.etcdConfig)
+	return createOrUpdateOperationToDB(operationId, &operation, noCachedOperation.etcdConfig)
 }
 
 func (noCachedOperation *OperationNoCache) SetOperationStatus(operationId, status string) error {
@@ -76,7 +76,12 @@ func (noCachedOperation *OperationNoCache) SetOperationStatus(operationId, statu
 		return err
 	}
 	operation.Status = status
-	return createOrUpdateOperationToDB(operationId, *operation, noCachedOperation.etcdConfig)
+	return createOrUpdateOperationToDB(operationId, operation, noCachedOperation.etcdConfig)
+}
+
+func (noCachedOperation *OperationNoCache) ClaimOperation(operationId, fromHost, toHost string) (bool, error) {
+	claimed, err := claimOperationInDB(operationId, fromHost, toHost, noCachedOperation.etcdConfig)
+	return claimed != nil, err
 }
 
 func (noCachedOperation *OperationNoCache) DeleteOperation(operationId string) error {
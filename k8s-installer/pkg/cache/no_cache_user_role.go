@@ -11,7 +11,7 @@ type UserRoleNoCache struct {
 }
 
 func (noCachedUser *UserRoleNoCache) initialization() error {
-	return nil
+	return migratePlaintextPasswords(noCachedUser.etcdConfig)
 }
 
 func (noCachedUser *UserRoleNoCache) CreateOrUpdateRole(roleId string, role schema.Role) error {
@@ -45,24 +45,12 @@ func (noCachedUser *UserRoleNoCache) DeleteRole(roleId string) {
 	}
 }
 
-func (noCachedUser *UserRoleNoCache) Login(username, password string) (*schema.User, error) {
+func (noCachedUser *UserRoleNoCache) Login(username, plain string) (*schema.User, error) {
 	userList, err := getUserListFromDB(noCachedUser.etcdConfig)
 	if err != nil {
 		return nil, err
 	}
-	for _, user := range userList {
-		if username == user.Username && password == user.Password {
-			return &schema.User{
-				Id:        user.Id,
-				Username:  user.Username,
-				Password:  user.Password,
-				Roles:     user.Roles,
-				Phone:     user.Phone,
-				KsAccount: user.KsAccount,
-			}, nil
-		}
-	}
-	return nil, nil
+	return loginUser(userList, username, plain, noCachedUser.etcdConfig, nil), nil
 }
 
 func (noCachedUser *UserRoleNoCache) CreateOrUpdateUser(username string, user schema.User) error {
@@ -81,12 +69,15 @@ func (noCachedUser *UserRoleNoCache) GetUser(username string) (*schema.User, err
 	for _, user := range userList {
 		if username == user.Username {
 			return &schema.User{
-				Id:        user.Id,
-				Username:  user.Username,
-				Password:  user.Password,
-				Roles:     user.Roles,
-				Phone:     user.Phone,
-				KsAccount: user.KsAccount,
+				Id:                user.Id,
+				Username:          user.Username,
+				Password:          user.Password,
+				Roles:             user.Roles,
+				Phone:             user.Phone,
+				KsAccount:         user.KsAccount,
+				PasswordChangedAt: user.PasswordChangedAt,
+				Source:            user.Source,
+				TokensValidAfter:  user.TokensValidAfter,
 			}, nil
 		}
 	}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/schema"
+)
+
+/*
+role bindings are never cached in ram
+authorization reads them on every request, revoking one on a server has to take effect on every server
+*/
+type RoleBindingStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+func (store RoleBindingStore) GetRoleBinding(id string) (*schema.RoleBinding, error) {
+	return getRoleBindingFromDB(id, store.etcdConfig)
+}
+
+func (store RoleBindingStore) GetRoleBindingCollection() (schema.RoleBindingCollection, error) {
+	return getRoleBindingCollectionFromDB(store.etcdConfig)
+}
+
+func (store RoleBindingStore) SaveOrUpdateRoleBinding(binding schema.RoleBinding) error {
+	return createOrUpdateRoleBindingToDB(binding, store.etcdConfig)
+}
+
+func (store RoleBindingStore) DeleteRoleBinding(id string) error {
+	return deleteRoleBindingFromDB(id, store.etcdConfig)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+)
+
+/*
+servers in high availability mode register themselves with a key bound to their lease
+key is gone once a server stops renewing the lease, which is how other servers tell it is dead
+registry is never cached in ram for the same reason sessions are not
+*/
+type ServerRegistryStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+// GetLiveServers returns registration time by server id of every server still holding its lease
+func (store ServerRegistryStore) GetLiveServers() (map[string]string, error) {
+	return getLiveServersFromDB(store.etcdConfig)
+}
+
+// ServerRegistryKey is the key server registers itself at
+func ServerRegistryKey(serverId string) string {
+	return etcdDataTree["server-registry"] + serverId
+}
+
+// LeaderElectionPrefix is the prefix servers campaign for leadership under
+func LeaderElectionPrefix() string {
+	return etcdDataTree["leader-election"]
+}
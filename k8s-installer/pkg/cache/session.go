@@ -0,0 +1,30 @@
+package cache
+
+import (
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/schema"
+)
+
+/*
+login sessions are never cached in ram
+they are the revocation list of access tokens, logout on one server has to take effect on every server
+*/
+type SessionStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+func (store SessionStore) GetSession(id string) (*schema.Session, error) {
+	return getSessionFromDB(id, store.etcdConfig)
+}
+
+func (store SessionStore) GetSessionCollection() (schema.SessionCollection, error) {
+	return getSessionCollectionFromDB(store.etcdConfig)
+}
+
+func (store SessionStore) SaveOrUpdateSession(session schema.Session) error {
+	return createOrUpdateSessionToDB(session, store.etcdConfig)
+}
+
+func (store SessionStore) DeleteSession(id string) error {
+	return deleteSessionFromDB(id, store.etcdConfig)
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/schema"
+)
+
+/*
+mfa enrollments are never cached in ram
+last used totp step has to be shared by every server otherwise a code could be replayed against another server
+*/
+type MFAStore struct {
+	etcdConfig etcdClientConfig.EtcdConfig
+}
+
+func (store MFAStore) GetUserMFA(username string) (*schema.UserMFA, error) {
+	return getUserMFAFromDB(username, store.etcdConfig)
+}
+
+func (store MFAStore) SaveOrUpdateUserMFA(mfa schema.UserMFA) error {
+	return createOrUpdateUserMFAToDB(mfa, store.etcdConfig)
+}
+
+func (store MFAStore) DeleteUserMFA(username string) error {
+	return deleteUserMFAFromDB(username, store.etcdConfig)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"time"
+
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/password"
+	"k8s-installer/schema"
+)
+
+// migratePlaintextPasswords replaces every plaintext password left by older version with it`s hash
+func migratePlaintextPasswords(config etcdClientConfig.EtcdConfig) error {
+	users, err := getUserListFromDB(config)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.Password == "" || password.IsHashed(user.Password) {
+			continue
+		}
+		if _, err := migrateUserPassword(user, user.Password, config); err != nil {
+			return err
+		}
+		log.Infof("Password of user %s is migrated to hash", user.Username)
+	}
+	return nil
+}
+
+func migrateUserPassword(user schema.User, plain string, config etcdClientConfig.EtcdConfig) (schema.User, error) {
+	hashed, err := password.Hash(plain)
+	if err != nil {
+		return user, err
+	}
+	user.Password = hashed
+	// expiry counts from migration as there is no record of when plaintext was set
+	if user.PasswordChangedAt == 0 {
+		user.PasswordChangedAt = time.Now().Unix()
+	}
+	return user, createOrUpdateUserToDB(user.Id, user, config)
+}
+
+/*
+loginUser verifies password of given user and returns user without password on success
+legacy plaintext password is replaced with hash once it matches, onMigrated is called with the saved user
+*/
+func loginUser(users schema.UserCollection, username, plain string, config etcdClientConfig.EtcdConfig, onMigrated func(user schema.User)) *schema.User {
+	for _, user := range users {
+		if username != user.Username {
+			continue
+		}
+		match, legacy := password.Verify(user.Password, plain)
+		if !match {
+			return nil
+		}
+		if legacy {
+			if migrated, err := migrateUserPassword(user, plain, config); err != nil {
+				log.Warnf("Failed to migrate password of user %s to hash due to error %s", user.Username, err.Error())
+			} else if onMigrated != nil {
+				onMigrated(migrated)
+			}
+		}
+		return &schema.User{
+			Id:                user.Id,
+			Username:          user.Username,
+			Roles:             user.Roles,
+			Phone:             user.Phone,
+			KsAccount:         user.KsAccount,
+			PasswordChangedAt: user.PasswordChangedAt,
+			Source:            user.Source,
+			TokensValidAfter:  user.TokensValidAfter,
+		}
+	}
+	return nil
+}
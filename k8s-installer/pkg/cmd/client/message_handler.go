@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -9,14 +10,20 @@ import (
 	clientOSFamily "k8s-installer/node/os/family"
 	centos "k8s-installer/node/os/family/centos"
 	centosVersion "k8s-installer/node/os/family/centos/version"
+	"k8s-installer/node/os/family/el"
+	elVersion "k8s-installer/node/os/family/el/version"
+	"k8s-installer/node/os/family/openeuler"
+	openeulerVersion "k8s-installer/node/os/family/openeuler/version"
 	"k8s-installer/node/os/family/ubuntu"
 	ubuntuVersion "k8s-installer/node/os/family/ubuntu/version"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/tracing"
 	"k8s-installer/schema"
 
 	natsLib "github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 /*
@@ -40,6 +47,13 @@ func messageHandler(msg *natsLib.Msg) {
 		family.ReplyMsg(reply, msg)
 		return
 	}
+	if dataReceived.TaskType == constants.TaskAbortNodeStep {
+		// abort does not need os family at all
+		aborted := abortNodeSteps(dataReceived.OperationId, dataReceived.Task.(schema.TaskAbortNodeStep).NodeStepIds)
+		reply := family.CreateReplyBody(dataReceived.OperationId, cache.NodeId, constants.StatusSuccessful, fmt.Sprintf("Aborted node steps %v", aborted), dataReceived.NodeStepId, map[string]string{})
+		family.ReplyMsg(reply, msg)
+		return
+	}
 	log.Debugf("Start processing node step with id %s", dataReceived.NodeStepId)
 	nodeOSFamilyClient, errGetClientOSFamily := GetOSFamily(dataReceived.OperationId)
 	if errGetClientOSFamily != nil {
@@ -50,6 +64,12 @@ func messageHandler(msg *natsLib.Msg) {
 	}
 	// runtimeCache := cache.GetCurrentCache()
 	// config := runtimeCache.GetClientRuntimeConfig(cache.NodeId)
+	// span of task joins the trace of node step server sends
+	ctx, span := tracing.Start(tracing.Extract(context.Background(), dataReceived.TraceContext), "task "+dataReceived.TaskType,
+		attribute.String("operation.id", dataReceived.OperationId),
+		attribute.String("node_step.id", dataReceived.NodeStepId),
+		attribute.String("node.id", cache.NodeId))
+	ctx, nodeStepDone := registerNodeStep(ctx, dataReceived.OperationId, dataReceived.NodeStepId)
 	var clientOperationErr error
 	stat := constants.StatusSuccessful
 	message := ""
@@ -68,10 +88,12 @@ func messageHandler(msg *natsLib.Msg) {
 	case constants.TaskTypeKubectl:
 		returnData, clientOperationErr = nodeOSFamilyClient.GetOSVersion().RunKubectl(dataReceived.Task.(schema.TaskKubectl), dataReceived.StepReturnData, dataReceived.Cluster)
 	case constants.TaskTypeRunCommand:
-		returnData, clientOperationErr = nodeOSFamilyClient.GetOSVersion().RunCommand(dataReceived.Task.(schema.TaskRunCommand), dataReceived.StepReturnData, dataReceived.Cluster)
+		returnData, clientOperationErr = nodeOSFamilyClient.GetOSVersion().RunCommand(ctx, dataReceived.Task.(schema.TaskRunCommand), dataReceived.StepReturnData, dataReceived.Cluster)
 	case constants.TaskTypeRunAsyncCommand:
 		go func() {
-			nodeOSFamilyClient.GetOSVersion().AsyncRunCommand(dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.Task.(schema.TaskRunCommand), dataReceived.StepReturnData, dataReceived.Cluster)
+			defer nodeStepDone()
+			_, err := nodeOSFamilyClient.GetOSVersion().AsyncRunCommand(ctx, dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.Task.(schema.TaskRunCommand), dataReceived.StepReturnData, dataReceived.Cluster)
+			tracing.End(span, err)
 		}()
 		return
 	case constants.TaskTypeCopyTextFile:
@@ -98,12 +120,16 @@ func messageHandler(msg *natsLib.Msg) {
 		returnData, clientOperationErr = nodeOSFamilyClient.GetOSVersion().CommonLink(dataReceived.Task.(schema.TaskLink).From, dataReceived.Task.(schema.TaskLink).SaveTo, dataReceived.Task.(schema.TaskLink).LinkTo)
 	case constants.TaskDownloadDep:
 		go func() {
-			nodeOSFamilyClient.GetOSVersion().CommonDownloadDep(dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.ResourceServerURL, dataReceived.Task.(schema.TaskCommonDownloadDep).Dep, dataReceived.Task.(schema.TaskCommonDownloadDep).SaveTo, dataReceived.Task.(schema.TaskCommonDownloadDep).K8sVersion, dataReceived.Task.(schema.TaskCommonDownloadDep).Md5)
+			defer nodeStepDone()
+			_, err := nodeOSFamilyClient.GetOSVersion().CommonDownloadDep(ctx, dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.ResourceServerURL, dataReceived.Task.(schema.TaskCommonDownloadDep).Dep, dataReceived.Task.(schema.TaskCommonDownloadDep).SaveTo, dataReceived.Task.(schema.TaskCommonDownloadDep).K8sVersion, dataReceived.Task.(schema.TaskCommonDownloadDep).Md5)
+			tracing.End(span, err)
 		}()
 		return
 	case constants.TaskDownload:
 		go func() {
-			nodeOSFamilyClient.GetOSVersion().CommonDownload(dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.ResourceServerURL, dataReceived.Task.(schema.TaskCommonDownload).FromDir, dataReceived.Task.(schema.TaskCommonDownload).K8sVersion, dataReceived.Task.(schema.TaskCommonDownload).FileList, dataReceived.Task.(schema.TaskCommonDownload).SaveTo, dataReceived.Task.(schema.TaskCommonDownload).IsUseDefaultPath)
+			defer nodeStepDone()
+			_, err := nodeOSFamilyClient.GetOSVersion().CommonDownload(ctx, dataReceived.OperationId, cache.NodeId, dataReceived.NodeStepId, msg, dataReceived.ResourceServerURL, dataReceived.Task.(schema.TaskCommonDownload).FromDir, dataReceived.Task.(schema.TaskCommonDownload).K8sVersion, dataReceived.Task.(schema.TaskCommonDownload).FileList, dataReceived.Task.(schema.TaskCommonDownload).SaveTo, dataReceived.Task.(schema.TaskCommonDownload).IsUseDefaultPath)
+			tracing.End(span, err)
 		}()
 		return
 	case constants.TaskGenerateKSClusterConfig:
@@ -120,6 +146,8 @@ func messageHandler(msg *natsLib.Msg) {
 		stat = constants.StatusError
 	}
 
+	nodeStepDone()
+	tracing.End(span, clientOperationErr)
 	if clientOperationErr != nil {
 		message = fmt.Sprintf("Operation %s excution failed with node task %s on node %s due to error %s",
 			dataReceived.OperationId,
@@ -141,7 +169,9 @@ func GetOSFamily(operationId string) (clientOSFamily.IOSFamily, error) {
 	} else {
 		runtimeCache := cache.GetCurrentCache()
 		cfg := runtimeCache.GetClientRuntimeConfig(cache.NodeId)
-		switch osInfo.OS.Vendor {
+		// rocky, almalinux, rhel and centos 8 or later are all handled as el
+		osFamily, osVersion := osSysInfo.NormalizeOSFamily(osInfo.OS.Vendor, osInfo.OS.Version)
+		switch osFamily {
 		case constants.OSFamilyCentos:
 			log.Debug("Method getOSFamily go with centos")
 			switch osInfo.OS.Version {
@@ -157,11 +187,86 @@ func GetOSFamily(operationId string) (clientOSFamily.IOSFamily, error) {
 				return nil, nil
 			}
 		case constants.OSFamilyUbuntu:
-			return ubuntu.Ubuntu{
-				Version: ubuntuVersion.V1804{
-					Config: cfg,
-				},
-			}, nil
+			log.Debug("Method getOSFamily go with ubuntu")
+			switch osInfo.OS.Version {
+			case "18.04":
+				log.Debugf("Method getOSFamily go with ubuntu with version 18.04")
+				return ubuntu.Ubuntu{
+					Version: ubuntuVersion.V1804{
+						Config: cfg,
+					},
+				}, nil
+			case "20.04":
+				log.Debugf("Method getOSFamily go with ubuntu with version 20.04")
+				return ubuntu.Ubuntu{
+					Version: ubuntuVersion.V2004{
+						V1804: ubuntuVersion.V1804{
+							Config: cfg,
+						},
+					},
+				}, nil
+			default:
+				log.Debugf("Ubuntu with version %s is not a support version", osInfo.OS.Version)
+				return nil, nil
+			}
+		case constants.OSFamilyEL:
+			log.Debugf("Method getOSFamily go with el for %s", osInfo.OS.Vendor)
+			switch osVersion {
+			case "8":
+				log.Debugf("Method getOSFamily go with el with version 8")
+				return el.EL{
+					Version: elVersion.V8{
+						V7: centosVersion.V7{
+							Config: cfg,
+						},
+					},
+				}, nil
+			case "9":
+				log.Debugf("Method getOSFamily go with el with version 9")
+				return el.EL{
+					Version: elVersion.V9{
+						V8: elVersion.V8{
+							V7: centosVersion.V7{
+								Config: cfg,
+							},
+						},
+					},
+				}, nil
+			default:
+				log.Debugf("EL with version %s is not a support version", osVersion)
+				return nil, nil
+			}
+		case constants.OSFamilyOpenEuler:
+			log.Debug("Method getOSFamily go with openEuler")
+			switch osVersion {
+			case "20.03":
+				log.Debugf("Method getOSFamily go with openEuler with version 20.03")
+				return openeuler.OpenEuler{
+					Version: openeulerVersion.V2003{
+						V2203: openeulerVersion.V2203{
+							V8: elVersion.V8{
+								V7: centosVersion.V7{
+									Config: cfg,
+								},
+							},
+						},
+					},
+				}, nil
+			case "22.03":
+				log.Debugf("Method getOSFamily go with openEuler with version 22.03")
+				return openeuler.OpenEuler{
+					Version: openeulerVersion.V2203{
+						V8: elVersion.V8{
+							V7: centosVersion.V7{
+								Config: cfg,
+							},
+						},
+					},
+				}, nil
+			default:
+				log.Debugf("OpenEuler with version %s is not a support version", osVersion)
+				return nil, nil
+			}
 		default:
 			log.Debugf("Os Family %s is not a support os type", osInfo.OS.Vendor)
 			return nil, nil
@@ -311,6 +416,13 @@ func ParseQueueBody(data []byte) (schema.QueueBody, error) {
 			} else {
 				dataReceived.Task = taskSetHosts
 			}
+		case constants.TaskAbortNodeStep:
+			taskAbortNodeStep := schema.TaskAbortNodeStep{}
+			if err = json.Unmarshal(dataReceived.TaskData, &taskAbortNodeStep); err != nil {
+				log.Debugf("Failed to parse msg.TaskData to struct TaskAbortNodeStep due to error %s", err.Error())
+			} else {
+				dataReceived.Task = taskAbortNodeStep
+			}
 		default:
 			err = fmt.Errorf("Task type %s is not valid task type", dataReceived.TaskType)
 		}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"k8s-installer/pkg/log"
+)
+
+/*
+keep cancel func of all node steps currently running on this node
+so server is able to abort them when operation is cancelled
+*/
+
+var runningNodeSteps = struct {
+	lock  sync.Mutex
+	steps map[string]context.CancelFunc
+}{
+	steps: map[string]context.CancelFunc{},
+}
+
+func nodeStepKey(operationId, nodeStepId string) string {
+	return operationId + "/" + nodeStepId
+}
+
+// registerNodeStep returns context of node step derived from parent and a func which should be called once node step is done
+func registerNodeStep(parent context.Context, operationId, nodeStepId string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	key := nodeStepKey(operationId, nodeStepId)
+	runningNodeSteps.lock.Lock()
+	runningNodeSteps.steps[key] = cancel
+	runningNodeSteps.lock.Unlock()
+	return ctx, func() {
+		runningNodeSteps.lock.Lock()
+		delete(runningNodeSteps.steps, key)
+		runningNodeSteps.lock.Unlock()
+		cancel()
+	}
+}
+
+// abortNodeSteps cancels given node steps of operation and returns the ones actually running
+func abortNodeSteps(operationId string, nodeStepIds []string) []string {
+	runningNodeSteps.lock.Lock()
+	defer runningNodeSteps.lock.Unlock()
+	var aborted []string
+	for _, nodeStepId := range nodeStepIds {
+		key := nodeStepKey(operationId, nodeStepId)
+		if cancel, found := runningNodeSteps.steps[key]; found {
+			log.Warnf("Abort node step %s of operation %s", nodeStepId, operationId)
+			cancel()
+			delete(runningNodeSteps.steps, key)
+			aborted = append(aborted, nodeStepId)
+		}
+	}
+	return aborted
+}
@@ -2,10 +2,16 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"syscall"
 
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // run command
@@ -22,6 +28,41 @@ func RunCmd(command string, args ...string) (bytes.Buffer, bytes.Buffer, error)
 	return out, outErr, nil
 }
 
+// run command and kill it with all it`s child processes once ctx is done
+func RunCmdContext(ctx context.Context, command string, args ...string) (bytes.Buffer, bytes.Buffer, error) {
+	// arguments are left out of span since they may carry secrets
+	_, span := tracing.Start(ctx, "command "+filepath.Base(command), attribute.Int("command.args", len(args)))
+	out, outErr, err := runCmdContext(ctx, command, args...)
+	tracing.End(span, err)
+	return out, outErr, err
+}
+
+func runCmdContext(ctx context.Context, command string, args ...string) (bytes.Buffer, bytes.Buffer, error) {
+	cmd := exec.Command(command, args...)
+	var out, outErr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &outErr
+	// run in it`s own process group so child processes are killed as well
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	log.Debug(fmt.Sprintf("Running command %s", cmd.String()))
+	if err := cmd.Start(); err != nil {
+		return out, outErr, err
+	}
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- cmd.Wait()
+	}()
+	select {
+	case err := <-waitDone:
+		return out, outErr, err
+	case <-ctx.Done():
+		log.Warnf("Command %s is aborted, kill process group %d", cmd.String(), cmd.Process.Pid)
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-waitDone
+		return out, outErr, ctx.Err()
+	}
+}
+
 // run pipe command like cat a | grep -i "bla"
 func CmdPipeline(cmds ...*exec.Cmd) (pipeLineOutput, collectedStandardError []byte, pipeLineError error) {
 	// Require at least one command
@@ -9,5 +9,92 @@ type ApiConfig struct {
 	EnableTLS              bool   `yaml:"enable-tls"`
 	JWTSignString          string `yaml:"jwt-sign-string"`
 	JWTSignMethod          string `yaml:"jwt-sign-method"`
-	JWTExpiredAfterHours   uint32 `yaml:"jwt-expired-after-hours"`
+	// how long refresh token of a login session lives, every refresh rotates it and starts over
+	JWTExpiredAfterHours uint32 `yaml:"jwt-expired-after-hours"`
+	// access token is short lived so revoked session and changed roles take effect soon, 0 falls back to jwt-expired-after-hours
+	AccessTokenExpiredAfterMinutes uint32          `yaml:"access-token-expired-after-minutes"`
+	PasswordPolicy                 PasswordPolicy  `yaml:"password-policy"`
+	LoginProtection                LoginProtection `yaml:"login-protection"`
+	MFAPolicy                      MFAPolicy       `yaml:"mfa-policy"`
+	AuthProviders                  []AuthProvider  `yaml:"auth-providers"`
+}
+
+/*
+AuthProvider authenticates users against external directory instead of local users in etcd
+name is what client sends in X-Auth-Type header and what oidc login path carries
+*/
+type AuthProvider struct {
+	Name string `yaml:"name"`
+	// ldap or oidc
+	Type string        `yaml:"type"`
+	LDAP *LDAPProvider `yaml:"ldap"`
+	OIDC *OIDCProvider `yaml:"oidc"`
+	// group of external user to role name or id
+	GroupRoleMapping map[string][]string `yaml:"group-role-mapping"`
+	// roles given to every user of the provider
+	DefaultRoles []string `yaml:"default-roles"`
+	// create user on first login, when disabled only users provisioned before are let in
+	JITProvisioning bool `yaml:"jit-provisioning"`
+}
+
+type LDAPProvider struct {
+	// ldap://host:389 or ldaps://host:636
+	URL                string `yaml:"url"`
+	StartTLS           bool   `yaml:"start-tls"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify"`
+	// service account used to search user and groups
+	BindDN       string `yaml:"bind-dn"`
+	BindPassword string `yaml:"bind-password"`
+	UserBaseDN   string `yaml:"user-base-dn"`
+	// %s is replaced by escaped username e.g. (uid=%s)
+	UserFilter  string `yaml:"user-filter"`
+	GroupBaseDN string `yaml:"group-base-dn"`
+	// %s is replaced by escaped user dn e.g. (member=%s)
+	GroupFilter        string `yaml:"group-filter"`
+	GroupNameAttribute string `yaml:"group-name-attribute"`
+	PhoneAttribute     string `yaml:"phone-attribute"`
+}
+
+type OIDCProvider struct {
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client-id"`
+	ClientSecret string `yaml:"client-secret"`
+	// installer callback e.g. https://installer/api/core/v1/oidc/{name}/callback
+	RedirectURL   string   `yaml:"redirect-url"`
+	Scopes        []string `yaml:"scopes"`
+	UsernameClaim string   `yaml:"username-claim"`
+	GroupsClaim   string   `yaml:"groups-claim"`
+	PhoneClaim    string   `yaml:"phone-claim"`
+}
+
+type LoginProtection struct {
+	// failures of one user before it is locked
+	MaxUserFailures int `yaml:"max-user-failures"`
+	// failures from one source ip before it is locked, higher than user one as users may share a nat address
+	MaxSourceFailures    int `yaml:"max-source-failures"`
+	FailureWindowMinutes int `yaml:"failure-window-minutes"`
+	LockoutMinutes       int `yaml:"lockout-minutes"`
+	// delay before next attempt doubles on every failure up to this
+	MaxDelaySeconds int `yaml:"max-delay-seconds"`
+}
+
+type MFAPolicy struct {
+	// issuer shown by authenticator app next to account name
+	Issuer string `yaml:"issuer"`
+	/*
+		users holding a role with any of these role functions have to enroll totp before they are able to login
+		bitwise or of challenge codes same as role function e.g. 8 for delete cluster, 0 meaning mfa is optional
+	*/
+	RequiredFunction  uint64 `yaml:"required-function"`
+	RecoveryCodeCount int    `yaml:"recovery-code-count"`
+}
+
+type PasswordPolicy struct {
+	MinLength      int  `yaml:"min-length"`
+	RequireUpper   bool `yaml:"require-upper"`
+	RequireLower   bool `yaml:"require-lower"`
+	RequireDigit   bool `yaml:"require-digit"`
+	RequireSpecial bool `yaml:"require-special"`
+	// 0 means password never expires
+	ExpireAfterDays int `yaml:"expire-after-days"`
 }
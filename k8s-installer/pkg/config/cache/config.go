@@ -1,11 +1,34 @@
 package cache
 
+const (
+	RuntimeLocalRam = "local-ram"
+	RuntimeNoCache  = "no-cache"
+	// data is kept in an etcd running inside server rather than an external one, cached in ram same as local-ram
+	RuntimeEmbedded = "embedded"
+)
+
 type CacheConfig struct {
-	CacheRuntime string `yaml:"cache-runtime"`
+	CacheRuntime string              `yaml:"cache-runtime"`
+	Embedded     EmbeddedStoreConfig `yaml:"embedded"`
+}
+
+/*
+EmbeddedStoreConfig is where embedded store keeps data and listens, only used by cache runtime embedded
+it listens on loopback only, ports differ from etcd defaults so it runs side by side with an external etcd while migrating
+*/
+type EmbeddedStoreConfig struct {
+	DataDir         string `yaml:"data-dir"`
+	ListenClientURL string `yaml:"listen-client-url"`
+	ListenPeerURL   string `yaml:"listen-peer-url"`
 }
 
 func DefaultConfig() CacheConfig {
 	return CacheConfig{
-		CacheRuntime: "no-cache",
+		CacheRuntime: RuntimeNoCache,
+		Embedded: EmbeddedStoreConfig{
+			DataDir:         "/var/lib/k8s-installer/store",
+			ListenClientURL: "http://127.0.0.1:2389",
+			ListenPeerURL:   "http://127.0.0.1:2390",
+		},
 	}
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"k8s-installer/pkg/config/cache"
 	etcdConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/pkg/config/tracing"
 	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/message_queue/nats"
 
@@ -18,6 +19,8 @@ const (
 type Config struct {
 	ClientId              string                  `yaml:"client-id"`
 	SignalPort            int                     `yaml:"signal-port"`
+	MetricsPort           int                     `yaml:"metrics-port"` // 0 meaning metrics endpoint is disabled
+	Tracing               tracing.TracingConfig   `yaml:"tracing"`
 	Log                   log.LogConfig           `yaml:"log"`
 	MessageQueue          nats.MessageQueueConfig `yaml:"message-queue"`
 	Etcd                  etcdConfig.EtcdConfig   `yaml:"etcd"`
@@ -41,6 +44,8 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		SignalPort:            9090,
+		MetricsPort:           9181,
+		Tracing:               tracing.DefaultConfig(),
 		Log:                   log.DefaultConfig(),
 		MessageQueue:          nats.DefaultConfig(),
 		Etcd:                  etcdConfig.DefaultConfig(),
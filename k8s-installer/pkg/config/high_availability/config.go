@@ -0,0 +1,18 @@
+package high_availability
+
+type HighAvailabilityConfig struct {
+	// run several servers against the same etcd, only no-cache cache runtime is supported
+	Enabled bool `yaml:"enabled"`
+	// unit seconds, a server failed to renew its lease for this long is considered dead
+	LeaseTTL int `yaml:"lease-ttl"`
+	// unit seconds, how often leader looks for operations left behind by dead servers
+	TakeoverFrequency int `yaml:"takeover-frequency"`
+}
+
+func DefaultConfig() HighAvailabilityConfig {
+	return HighAvailabilityConfig{
+		Enabled:           false,
+		LeaseTTL:          15,
+		TakeoverFrequency: 30,
+	}
+}
@@ -0,0 +1,20 @@
+package tracing
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// otlp over http endpoint of the collector, spans are posted to {endpoint}/v1/traces
+	Endpoint string `yaml:"endpoint"`
+	// ratio of new traces to record from 0 to 1, traces started by others follow their decision
+	SampleRatio float64 `yaml:"sample-ratio"`
+	// unit seconds
+	ExportTimeout int `yaml:"export-timeout"`
+}
+
+func DefaultConfig() TracingConfig {
+	return TracingConfig{
+		Enabled:       false,
+		Endpoint:      "http://127.0.0.1:4318",
+		SampleRatio:   1,
+		ExportTimeout: 10,
+	}
+}
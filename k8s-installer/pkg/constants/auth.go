@@ -10,6 +10,49 @@ const (
 	NoAuthRequire = 0
 )
 
+// challenge code of route any authenticated user may call e.g. to manage it`s own api tokens, no role holds this bit
+const AuthenticatedOnly uint64 = 1 << 63
+
+const (
+	// source of users created as service accounts, they have no password and only authenticate with api token
+	UserSourceServiceAccount = "service-account"
+	APITokenPrefix           = "kit_"
+)
+
+const (
+	AuthProviderTypeLDAP = "ldap"
+	AuthProviderTypeOIDC = "oidc"
+)
+
+// prefix of login attempt keys, attempts are tracked per user, per source ip and per sms receiver
+const (
+	LoginAttemptUserPrefix = "user:"
+	LoginAttemptIPPrefix   = "ip:"
+	LoginAttemptSMSPrefix  = "sms:"
+)
+
+const (
+	AuditActionLoginLockout = "login-lockout"
+	AuditActionLoginUnlock  = "login-unlock"
+	AuditActionMFAEnable    = "mfa-enable"
+	AuditActionMFAReset     = "mfa-reset"
+	AuditActionTokenCreate  = "api-token-create"
+	AuditActionTokenRevoke  = "api-token-revoke"
+	AuditActionRefreshReuse = "refresh-token-reuse"
+	AuditActionRoleBind     = "role-bind"
+	AuditActionRoleUnbind   = "role-unbind"
+	// every non-GET api request, command sent to node and ssh session through the api server
+	AuditActionAPIRequest    = "api-request"
+	AuditActionRemoteCommand = "remote-command"
+	AuditActionSSHSession    = "ssh-session"
+)
+
+// error code of login response telling client which second factor step is missing
+const (
+	ErrMFARequired           = 10001
+	ErrMFAEnrollmentRequired = 10002
+)
+
 const (
 	//私钥
 	// openssl genrsa -out key.pem 1024
@@ -59,6 +59,11 @@ const (
 const CRITypeDocker = "docker"
 const CRITypeContainerd = "containerd"
 
+const CgroupDriverSystemd = "systemd"
+const CgroupDriverCgroupfs = "cgroupfs"
+
+const InitSystemSystemd = "systemd"
+
 const KubeadmTaskInitFirstControlPlane = "InitOrDestroyFirstControlPlane"
 const KubeadmTaskJoinControlPlane = "JoinOrDestroyControlPlane"
 const KubeadmTaskJoinWorker = "JoinWorker"
@@ -80,6 +85,8 @@ const StatusDeletingNode = "deleting-nodes"
 const StatusDeployAddons = "deploy-addons"
 const StatusProcessing = "processing"
 const StatusUpgrading = "upgrading"
+const StatusCancelled = "cancelled"
+const StatusPaused = "paused"
 
 const NodeRoleMaster = 1
 const NodeRoleWorker = 2
@@ -89,6 +96,22 @@ const NodeRoleExternalLB = 8
 const OSFamilyCentos = "centos"
 const OSFamilyUbuntu = "ubuntu"
 
+// rhel 8/9 and it`s rebuilds, nodes report their own vendor and are folded into this family by major version
+const OSFamilyEL = "el"
+const OSFamilyOpenEuler = "openEuler"
+
+const OSVendorRHEL = "rhel"
+const OSVendorRocky = "rocky"
+const OSVendorAlmaLinux = "almalinux"
+
+// versions of each os family whose packages are kept on resource server
+var OSFamilyVersions = map[string][]string{
+	OSFamilyCentos:    {"7"},
+	OSFamilyUbuntu:    {"18.04", "20.04"},
+	OSFamilyEL:        {"8", "9"},
+	OSFamilyOpenEuler: {"20.03", "22.03"},
+}
+
 const CpuArchX86 = "x86_64"
 const CpuAarch64 = "aarch64"
 
@@ -117,6 +140,7 @@ const TaskDownloadDep = "TaskDownloadDep"
 const TaskDownload = "TaskDownload"
 const TaskGenerateKSClusterConfig = "TaskGenerateKSClusterConfig"
 const TaskConfigPromtail = "TaskConfigPromtail"
+const TaskAbortNodeStep = "TaskAbortNodeStep"
 
 const Available = "available"
 const UnAvailable = "unAvailable"
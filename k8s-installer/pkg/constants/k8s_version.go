@@ -1,3 +1,6 @@
 package constants
 
 const V1_18_6 = "1.18.6"
+
+// kubelet supports cgroup v2 since 1.19
+const CgroupV2MinK8sVersion = "1.19.0"
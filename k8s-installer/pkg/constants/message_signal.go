@@ -2,3 +2,21 @@ package constants
 
 const MessageSignalDone = 0
 const MessageSignalError = 1
+
+// operation progress event type
+const (
+	OperationEventStepStart     = "step-start"
+	OperationEventStepDone      = "step-done"
+	OperationEventStepError     = "step-error"
+	OperationEventNodeReply     = "node-reply"
+	OperationEventLog           = "log"
+	OperationEventOperationDone = "operation-done"
+)
+
+// kinds of node step failure a retry policy is able to retry
+const (
+	// no reply within task timeout
+	RetryOnTimeout = "timeout"
+	// node replied with error state
+	RetryOnError = "error"
+)
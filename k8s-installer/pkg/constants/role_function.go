@@ -27,3 +27,8 @@ const (
 	ChallengeCodeConfigPlatform
 	ChallengeCodeManageCert
 )
+
+// functions managing accounts and platform wide settings, role binding never grants them on a scope
+const GlobalOnlyFunctions = ChallengeCodeManageDNS | ChallengeCodeManageUser | ChallengeCodeDeleteUser | ChallengeCodeManageRole |
+	ChallengeCodeViewRoleDetail | ChallengeCodeCreateRole | ChallengeCodeDeleteRole | ChallengeConfigUpgradeVersion |
+	ChallengeCodeConfigPlatform | ChallengeCodeManageCert
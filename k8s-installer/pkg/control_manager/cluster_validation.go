@@ -3,6 +3,7 @@ package control_manager
 import (
 	"errors"
 	"fmt"
+	osInfoProvider "k8s-installer/node/os"
 	"k8s-installer/pkg/task_breaker"
 	"net"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/network"
+	"k8s-installer/pkg/util"
 	"k8s-installer/schema"
 )
 
@@ -70,6 +72,8 @@ func IsVipProperSet(cluster schema.Cluster) bool {
 
 func CheckNodeExitsAndReachable(nodes []schema.ClusterNode, cluster schema.Cluster) error {
 	errList, _ := checkNodeExitsAndReachable(nodes, cluster, true, false)
+	// new nodes inherit kubelet config of the cluster so they have to agree with existing nodes on cgroup driver
+	errList = append(errList, checkClusterCgroupDriver(append(append(append([]schema.ClusterNode{}, cluster.Masters...), cluster.Workers...), nodes...), cluster.ControlPlane.KubernetesVersion)...)
 	if len(errList) > 0 {
 		return errors.New(strings.Join(errList, " , "))
 	}
@@ -347,6 +351,9 @@ func PreCheck(cluster schema.Cluster) (canProceed bool, errorList []string, warn
 	// check k8s node layout
 	errorList = append(errorList, checkNodeLayout(cluster)...)
 
+	// check cgroup driver of masters and workers
+	errorList = append(errorList, checkClusterCgroupDriver(append(append([]schema.ClusterNode{}, cluster.Masters...), cluster.Workers...), cluster.ControlPlane.KubernetesVersion)...)
+
 	// check ks setting
 	errorList = append(errorList, KsTargetHostClusterCheck(cluster)...)
 
@@ -370,6 +377,58 @@ func PreCheck(cluster schema.Cluster) (canProceed bool, errorList []string, warn
 	return
 }
 
+func checkClusterCgroupDriver(nodes []schema.ClusterNode, k8sVersion string) []string {
+	runtimeCache := cache.GetCurrentCache()
+	nodeCollection, err := runtimeCache.GetNodeInformationCollection()
+	if err != nil {
+		return []string{fmt.Sprintf("Internal server error %s", err.Error())}
+	}
+	var nodeInfos []schema.NodeInformation
+	seen := map[string]bool{}
+	for _, node := range nodes {
+		// missing node is reported by checkNodeExitsAndReachable already
+		if foundNode, found := nodeCollection[node.NodeId]; found && !seen[node.NodeId] {
+			seen[node.NodeId] = true
+			nodeInfos = append(nodeInfos, foundNode)
+		}
+	}
+	return checkCgroupDriverConsistency(nodeInfos, k8sVersion)
+}
+
+/*
+kubelet config is rendered once on first control plane and shared by all nodes via kubelet-config configmap
+so every node has to end up with the same cgroup driver as kubelet and it`s container runtime
+cgroup v2 nodes also require kubelet which supports it
+*/
+func checkCgroupDriverConsistency(nodes []schema.NodeInformation, k8sVersion string) (errorList []string) {
+	k8sVersion = util.StringDefaultIfNotSet(k8sVersion, constants.V1_18_6)
+	var driverOrder []string
+	drivers := map[string][]string{}
+	for _, node := range nodes {
+		driver := osInfoProvider.CgroupDriver(node.InitSystem)
+		if _, found := drivers[driver]; !found {
+			driverOrder = append(driverOrder, driver)
+		}
+		drivers[driver] = append(drivers[driver], node.Id)
+		if node.CgroupVersion != 2 {
+			continue
+		}
+		if newer, err := util.NewerK8sVersion(k8sVersion, constants.CgroupV2MinK8sVersion); err != nil {
+			errorList = append(errorList, fmt.Sprintf("Unable to compare kubernetes version %s with %s due to error %s", k8sVersion, constants.CgroupV2MinK8sVersion, err.Error()))
+		} else if newer == constants.CgroupV2MinK8sVersion {
+			errorList = append(errorList, fmt.Sprintf("Node with id: %s runs cgroup v2 which requires kubernetes %s or later but cluster version is %s", node.Id, constants.CgroupV2MinK8sVersion, k8sVersion))
+		}
+	}
+	if len(driverOrder) > 1 {
+		var detail []string
+		for _, driver := range driverOrder {
+			detail = append(detail, fmt.Sprintf("%s: %s", driver, strings.Join(drivers[driver], ",")))
+		}
+		errorList = append(errorList, fmt.Sprintf("Nodes do not share the same cgroup driver (%s), all nodes must run the same init system", strings.Join(detail, "; ")))
+	}
+	return
+}
+
 func KsTargetHostClusterCheck(cluster schema.Cluster) []string {
 	if cluster.KsClusterConf == nil || !cluster.KsClusterConf.Enabled || cluster.KsClusterConf.MultiClusterConfig.ClusterRole == constants.ClusterRoleHost {
 		return nil
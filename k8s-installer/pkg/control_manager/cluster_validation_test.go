@@ -0,0 +1,29 @@
+package control_manager
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-installer/schema"
+)
+
+func TestCheckCgroupDriverConsistency(t *testing.T) {
+	nodes := []schema.NodeInformation{
+		{Id: "master", CgroupVersion: 1, InitSystem: "systemd"},
+		{Id: "worker", CgroupVersion: 2, InitSystem: "systemd"},
+		// reported by old agent which knows nothing about cgroup
+		{Id: "legacy"},
+	}
+	if errs := checkCgroupDriverConsistency(nodes, "1.21.2"); len(errs) != 0 {
+		t.Fatalf("Expected no error, got %v", errs)
+	}
+	errs := checkCgroupDriverConsistency(nodes, "")
+	if len(errs) != 1 || !strings.Contains(errs[0], "worker") {
+		t.Fatalf("Expected cgroup v2 node to be refused by default kubernetes version, got %v", errs)
+	}
+	nodes = append(nodes, schema.NodeInformation{Id: "openrc", CgroupVersion: 1, InitSystem: "init"})
+	errs = checkCgroupDriverConsistency(nodes, "1.21.2")
+	if len(errs) != 1 || !strings.Contains(errs[0], "cgroupfs: openrc") {
+		t.Fatalf("Expected mixed cgroup driver to be refused, got %v", errs)
+	}
+}
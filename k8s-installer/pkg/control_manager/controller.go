@@ -44,31 +44,34 @@ func NodeStateReportInHandler(msg *natsLib.Msg) {
 			} else {
 				nodeInformation.IssueList = append(nodeInformation.IssueList, "Operation system is not support yet")
 			}
-			log.Debugf("Attempt to get node existing data: %s", nodeInformation.Id)
-			lastStat, errGetNodeInfo := runtimeCache.GetNodeInformation(nodeInformation.Id)
-			if errGetNodeInfo != nil {
-				log.Errorf("Failed to retrieve node last report in status due to error: %s", errGetNodeInfo.Error())
-				return
-			}
-			if lastStat != nil {
-				// keep following stat
-				log.Debugf("Got existing data with node %s. So keep role , cluster relationship and some key stat", nodeInformation.Id)
-				nodeInformation.Role = lastStat.Role
-				nodeInformation.BelongsToCluster = lastStat.BelongsToCluster
-				nodeInformation.KubeNodeStat = lastStat.KubeNodeStat
-				nodeInformation.IsDisabled = lastStat.IsDisabled
-				//nodeInformation.Region = lastStat.DeepCopyRegion()
-			}
 		}
 		log.Debugf("Attempt to update node: %s", nodeInformation.Id)
 		nodeInformation.LastReportInDate = time.Now().Format("2006-01-02T15:04:05Z07:00")
-		if err := runtimeCache.SaveOrUpdateNodeInformation(nodeInformation.Id, nodeInformation); err != nil {
+		if _, err := cache.UpdateNodeInformation(runtimeCache, nodeInformation.Id, mergeReportedNode(nodeInformation)); err != nil {
 			log.Errorf("Failed to save reported in node stat to cache due to error %s", err)
 		}
 		handlerReportInRegion(nodeInformation.Region, runtimeCache)
 	}
 }
 
+/*
+mergeReportedNode replaces node with the one agent reports
+except role, cluster relationship and some key stat which are set by operations and users rather than agent
+they are taken from the latest node every time, otherwise report in and running operation clobber each other
+*/
+func mergeReportedNode(reported schema.NodeInformation) func(latest *schema.NodeInformation) error {
+	return func(latest *schema.NodeInformation) error {
+		merged := reported
+		merged.Role = latest.Role
+		merged.BelongsToCluster = latest.BelongsToCluster
+		merged.KubeNodeStat = latest.KubeNodeStat
+		merged.IsDisabled = latest.IsDisabled
+		merged.ResourceVersion = latest.ResourceVersion
+		*latest = merged
+		return nil
+	}
+}
+
 func handlerReportInRegion(region *schema.Region, runtimeCache cache.ICache) {
 	existRegion, err := runtimeCache.GetRegion(region.ID)
 	if err != nil {
@@ -124,17 +127,7 @@ func ApplyUpgrade(plan *schema.UpgradePlan, cluster *schema.Cluster, runByUser s
 		return nodeCollectionErr
 	}
 
-	upgradeTaskBreakDown := taskBreaker.UpgradingTaskBreakDown{
-		Cluster:          *cluster,
-		Operation:        operation,
-		NodesCollection:  nodesCollections,
-		TargetK8sVersion: plan.TargetVersion,
-		ApplyVersion:     *upgradeVersion,
-		Dep:              upgradeVersion.ToDep(),
-		Config:           runtimeCache.GetServerRuntimeConfig(cache.NodeId),
-	}
-
-	operationBD, errBreakDownTask := upgradeTaskBreakDown.BreakDownTask()
+	operationBD, errBreakDownTask := breakDownUpgrade(operation, plan, *upgradeVersion, *cluster, nodesCollections)
 
 	if errBreakDownTask != nil {
 		log.Errorf("Failed to break down upgrading task due to error: %s", errBreakDownTask.Error())
@@ -206,21 +199,8 @@ func AddonsManage(cluster *schema.Cluster, addons schema.Addons, runByUser strin
 	// we should keep cluster addons stat not changed until related task is done
 	copyCluster := *cluster
 
-	sumLicenseLabel, errLicense := license.SumLicenseLabel()
-	if errLicense != nil {
-		return errLicense
-	}
-
-	batchAddonsTaskBreakdown := taskBreaker.BatchAddonsTaskBreakDown{
-		Operation:       &operation,
-		Cluster:         &copyCluster,
-		OriginalCluster: *cluster,
-		NewAddons:       addons,
-		SumLicenseLabel: sumLicenseLabel,
-	}
-
 	var err error
-	operation, err = batchAddonsTaskBreakdown.BreakDownTask()
+	operation, err = breakDownAddons(operation, &copyCluster, *cluster, addons)
 
 	if err != nil {
 		log.Errorf("Failed to break down task due to error: %s", err.Error())
@@ -254,10 +234,14 @@ func OperationContinue(cluster schema.Cluster, operation schema.Operation, runBy
 		return err
 	} else {
 		runtimeContext := cache.GetCurrentCache()
-		if err := saveClusterStatus(runtimeContext, cluster); err != nil {
+		if err := claimOperation(runtimeContext, &operation); err != nil {
+			return err
+		}
+		if err := saveClusterStatus(runtimeContext, operation.Id, cluster); err != nil {
 			return err
 		}
-		operation.Logs = append(operation.Logs, util.LogStyleMessage("debug", fmt.Sprintf("Operation %s is going to resume from error step %s", operation.Id, operation.Step[operation.CurrentStep].Name)))
+		resumeCompletedSteps(&operation)
+		operation.Logs = append(operation.Logs, util.LogStyleMessage("debug", fmt.Sprintf("Operation %s is going to resume with %d of %d steps already done", operation.Id, len(operation.CompletedSteps), len(operation.Step))))
 		if err := saveOperationStatus(runtimeContext, operation); err != nil {
 			return err
 		}
@@ -274,15 +258,7 @@ func OperationContinue(cluster schema.Cluster, operation schema.Operation, runBy
 
 func SetupOrDestroyCluster(cluster *schema.Cluster, runByUser string) error {
 	runtimeCache := cache.GetCurrentCache()
-	operationName := "SetupCluster"
-	if cluster.Action == constants.ActionDelete {
-		operationName = "DestroyCluster"
-	}
-
-	// mock meaning do not actually do setup or destroy cluster instead just setup db stat as normal setup
-	if cluster.Mock {
-		operationName = "Mock" + operationName
-	}
+	operationName := setupOrDestroyOperationName(*cluster)
 
 	SetReclaimNamespaces(cluster)
 
@@ -304,6 +280,7 @@ func SetupOrDestroyCluster(cluster *schema.Cluster, runByUser string) error {
 
 	operation.RunByUser = runByUser
 	operation.OperationType = constants.OperationTypeClusterSetupOrDestroy
+	operation.RollbackOnFailure = cluster.RollbackOnFailure && cluster.Action == constants.ActionCreate
 
 	cluster.CurrentOperation = map[string]byte{}
 	// set current operation id in order to recover it from error stat in future
@@ -328,22 +305,15 @@ func SetupOrDestroyCluster(cluster *schema.Cluster, runByUser string) error {
 		// update node
 		for nodeId, role := range nodeRoleList {
 			nodeToClusterRelation = append(nodeToClusterRelation, nodeId)
-			nodeData, errGetNodeInfo := runtimeCache.GetNodeInformation(nodeId)
-			if errGetNodeInfo != nil {
-				log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-				return errGetNodeInfo
-			}
-			if nodeData == nil {
-				log.Errorf("Unable to get node %s information", nodeId)
-				return fmt.Errorf("Unable to get node %s information ", nodeId)
-			}
-			nodeData.Role = role
-			nodeData.BelongsToCluster = cluster.ClusterId
-			if (role&constants.NodeRoleMaster == constants.NodeRoleMaster) || (role&constants.NodeRoleWorker == constants.NodeRoleWorker) {
-				// only master and worker need to set kube stat
-				nodeData.KubeNodeStat = constants.UnAvailable
-			}
-			if err := saveNodeStatus(runtimeCache, *nodeData); err != nil {
+			role := role
+			if err := updateNodeStatus(runtimeCache, nodeId, func(nodeData *schema.NodeInformation) {
+				nodeData.Role = role
+				nodeData.BelongsToCluster = cluster.ClusterId
+				if (role&constants.NodeRoleMaster == constants.NodeRoleMaster) || (role&constants.NodeRoleWorker == constants.NodeRoleWorker) {
+					// only master and worker need to set kube stat
+					nodeData.KubeNodeStat = constants.UnAvailable
+				}
+			}); err != nil {
 				return err
 			}
 		}
@@ -359,7 +329,7 @@ func SetupOrDestroyCluster(cluster *schema.Cluster, runByUser string) error {
 	}
 
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -370,11 +340,7 @@ func SetupOrDestroyCluster(cluster *schema.Cluster, runByUser string) error {
 
 	// if mock
 	if cluster.Mock {
-		// always reset the operation step to nothing but wait 1 seconds
-		mockStep := taskBreaker.CreateWaitStep(1, "MockStep")
-		operation.Step = map[int]*schema.Step{
-			0: &mockStep,
-		}
+		mockOperationStep(&operation)
 	}
 
 	go doTask(&operation, cluster, &nodeInfoCollection, clusterOperationDoneHandler, clusterOperationErrorHandler)
@@ -416,13 +382,9 @@ func RemoveNodeToClusterDBOnly(cluster schema.Cluster, nodesToAddOrRemove []sche
 	// during the remove node phase
 	// we do not unlock node immediately by set role to 0 only remove the relation with node only for data consistency reason
 	for _, node := range nodesToAddOrRemove {
-		nodeData, errGetNodeInfo := runtimeCache.GetNodeInformation(node.NodeId)
-		if errGetNodeInfo != nil {
-			log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-			return errGetNodeInfo
-		}
-		nodeData.KubeNodeStat = ""
-		if err := saveNodeStatus(runtimeCache, *nodeData); err != nil {
+		if err := updateNodeStatus(runtimeCache, node.NodeId, func(nodeData *schema.NodeInformation) {
+			nodeData.KubeNodeStat = ""
+		}); err != nil {
 			return err
 		}
 	}
@@ -461,32 +423,25 @@ func RemoveNodeToClusterDBOnly(cluster schema.Cluster, nodesToAddOrRemove []sche
 	}
 
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, cluster); err != nil {
 		return err
 	}
 	go doTask(&operation, &cluster, &nodeInfoCollection, addOrRemoveNodeDoneHandlerCreator(addOrRemove, nodesToAddOrRemove), clusterOperationIgnoreErrorHandler)
 	return nil
 }
 
-func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schema.ClusterNode, addOrRemove string, runByUser string) error {
+func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schema.ClusterNode, addOrRemove string, runByUser string, rollbackOnFailure bool) error {
 	runtimeCache := cache.GetCurrentCache()
 	var err error
-	initState := ""
-	operationName := ""
+	initState := constants.StatusDeletingNode
 	if addOrRemove == constants.ActionCreate {
 		initState = constants.StatusAddingNode
-		operationName = "AddNodeToCluster"
-	} else {
-		initState = constants.StatusDeletingNode
-		operationName = "RemoveNodeFromCluster"
 	}
 
-	if cluster.Mock {
-		operationName = "Mock" + operationName
-	}
-
-	operation := createOperation(operationName, cluster.ClusterId, uuid.New().String(), constants.StatusProcessing)
+	operation := createOperation(addOrRemoveNodeOperationName(cluster, addOrRemove), cluster.ClusterId, uuid.New().String(), constants.StatusProcessing)
 	operation.RunByUser = runByUser
+	// only joining nodes is able to be rolled back
+	operation.RollbackOnFailure = rollbackOnFailure && addOrRemove == constants.ActionCreate
 	// set current operation id in order to recover it from error stat in future
 
 	if cluster.CurrentOperation == nil {
@@ -499,15 +454,7 @@ func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schem
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 
-	operation.OperationType = constants.OperationTypeAddOrRemoveNodesToCluster
-	// save request parameters in operation
-	operation.RequestParameter.BodyParameters = nodesToAddOrRemove
-	operation.RequestParameter.QueryParameters = map[string]interface{}{
-		"operationId": operation.Id,
-	}
-	operation.RequestParameter.RuntimeParameters = map[string]interface{}{
-		"addOrRemove": addOrRemove,
-	}
+	setAddOrRemoveNodeRequestParameter(&operation, nodesToAddOrRemove, addOrRemove)
 
 	if addOrRemove == constants.ActionCreate {
 		// during the add node phase
@@ -520,15 +467,12 @@ func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schem
 
 		for nodeId, role := range nodeRoleList {
 			nodeToClusterRelation = append(nodeToClusterRelation, nodeId)
-			nodeData, errGetNodeInfo := runtimeCache.GetNodeInformation(nodeId)
-			if errGetNodeInfo != nil {
-				log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-				return errGetNodeInfo
-			}
-			nodeData.Role = role
-			nodeData.BelongsToCluster = cluster.ClusterId
-			nodeData.KubeNodeStat = constants.UnAvailable
-			if err := saveNodeStatus(runtimeCache, *nodeData); err != nil {
+			role := role
+			if err := updateNodeStatus(runtimeCache, nodeId, func(nodeData *schema.NodeInformation) {
+				nodeData.Role = role
+				nodeData.BelongsToCluster = cluster.ClusterId
+				nodeData.KubeNodeStat = constants.UnAvailable
+			}); err != nil {
 				return err
 			}
 		}
@@ -541,57 +485,23 @@ func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schem
 		// during the remove node phase
 		// we do not unlock node immediately by set role to 0 only remove the relation with node only for data consistency reason
 		for _, node := range nodesToAddOrRemove {
-			nodeData, errGetNodeInfo := runtimeCache.GetNodeInformation(node.NodeId)
-			if errGetNodeInfo != nil {
-				log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-				return errGetNodeInfo
-			}
-			nodeData.KubeNodeStat = ""
-			if err := saveNodeStatus(runtimeCache, *nodeData); err != nil {
+			if err := updateNodeStatus(runtimeCache, node.NodeId, func(nodeData *schema.NodeInformation) {
+				nodeData.KubeNodeStat = ""
+			}); err != nil {
 				return err
 			}
 		}
 	}
 
-	sumLicenseLabel, err := license.SumLicenseLabel()
 	nodeInfoCollection, errGetNode := runtimeCache.GetNodeInformationCollection()
 	if errGetNode != nil {
 		return errGetNode
 	}
 
-	clusterNodeTaskBreakDown := taskBreaker.ClusterNodeTaskBreakDown{
-		Operation:          operation,
-		Cluster:            cluster,
-		Config:             runtimeCache.GetServerRuntimeConfig(cache.NodeId),
-		NodesToAddOrRemove: nodesToAddOrRemove,
-		NodeCollectionList: nodeInfoCollection,
-		Action:             addOrRemove,
-		SumLicenseLabel:    sumLicenseLabel,
-	}
-
-	operation, err = clusterNodeTaskBreakDown.BreakDownTask()
+	operation, err = breakDownAddOrRemoveNode(operation, cluster, nodesToAddOrRemove, addOrRemove, nodeInfoCollection)
 	if err != nil {
-		log.Errorf("Failed to break down task due to error %s", err.Error())
 		return err
 	}
-	// if mock
-	if cluster.Mock {
-		if cluster.ClusterInstaller == constants.ClusterInstallerRancher {
-			// always reset the operation step to nothing but wait 1 seconds
-			rancherOp, err := clusterNodeTaskBreakDown.BreakDownRancherTask()
-			if err != nil {
-				log.Errorf("Failed to rancher break down task due to error %s", err.Error())
-				return err
-			}
-			operation.Step = rancherOp.Step
-		} else {
-			// always reset the operation step to nothing but wait 1 seconds
-			mockStep := taskBreaker.CreateWaitStep(1, "MockStep")
-			operation.Step = map[int]*schema.Step{
-				0: &mockStep,
-			}
-		}
-	}
 
 	// put operation in db and cache
 	if err := saveOperationStatus(runtimeCache, operation); err != nil {
@@ -599,7 +509,7 @@ func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schem
 	}
 
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, cluster); err != nil {
 		return err
 	}
 	go doTask(&operation, &cluster, &nodeInfoCollection, addOrRemoveNodeDoneHandlerCreator(addOrRemove, nodesToAddOrRemove), clusterOperationIgnoreErrorHandler)
@@ -609,32 +519,144 @@ func AddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schem
 func recordDebugLogToOperationLog(operation *schema.Operation, msg string) {
 	log.Debug(msg)
 	operation.Logs = append(operation.Logs, util.LogStyleMessage("debug", msg))
+	publishLogEvent(operation, "debug", msg)
 }
 
 func recordErrorLogToOperationLog(operation *schema.Operation, msg string) {
 	log.Error(msg)
 	operation.Logs = append(operation.Logs, util.LogStyleMessage("error", msg))
+	publishLogEvent(operation, "error", msg)
 }
 
+/*
+saveOperationStatus saves progress of operation, the fields server running it owns, over the latest one
+the rest are kept as saved in between, unless another server took it over in between
+it stops running operation from clobbering the new owner then
+*/
 func saveOperationStatus(runtimeCache cache.ICache, operation schema.Operation) error {
-	if err := runtimeCache.SaveOrUpdateOperationCollection(operation.Id, operation); err != nil {
+	_, err := cache.UpdateOperation(runtimeCache, operation.Id, func(latest *schema.Operation) error {
+		// zero version meaning operation is not saved yet, nothing to keep
+		if latest.ResourceVersion == 0 {
+			*latest = operation
+			latest.ResourceVersion = 0
+			return nil
+		}
+		if latest.Host != "" && latest.Host != operation.Host {
+			return fmt.Errorf("operation %s is taken over by server %s", operation.Id, latest.Host)
+		}
+		latest.Host = operation.Host
+		latest.Status = operation.Status
+		latest.CurrentStep = operation.CurrentStep
+		latest.CompletedSteps = operation.CompletedSteps
+		latest.Step = operation.Step
+		latest.Logs = operation.Logs
+		latest.OperationLog = operation.OperationLog
+		latest.PreStepReturnData = operation.PreStepReturnData
+		latest.LastRun = operation.LastRun
+		latest.RunByUser = operation.RunByUser
+		latest.TraceContext = operation.TraceContext
+		return nil
+	})
+	if err != nil {
 		log.Errorf("Failed to set operation status to %s due to error %s", constants.StatusSuccessful, err.Error())
 		return err
 	}
 	return nil
 }
 
-func saveClusterStatus(runtimeCache cache.ICache, cluster schema.Cluster) error {
-	if err := runtimeCache.SaveOrUpdateClusterCollection(cluster.ClusterId, cluster); err != nil {
+/*
+saveClusterStatus saves the fields of cluster operation owns over the latest one, the rest are kept as saved in between
+e.g. labels changed through api or current operation of another operation
+*/
+func saveClusterStatus(runtimeCache cache.ICache, operationId string, cluster schema.Cluster) error {
+	_, err := cache.UpdateCluster(runtimeCache, cluster.ClusterId, func(latest *schema.Cluster) error {
+		// zero version meaning cluster is not saved yet, nothing to keep
+		if latest.ResourceVersion == 0 {
+			*latest = cluster
+			latest.ResourceVersion = 0
+			return nil
+		}
+		mergeOperationCurrentOperation(latest, operationId, cluster)
+		latest.Status = cluster.Status
+		latest.Action = cluster.Action
+		latest.Modified = cluster.Modified
+		latest.Masters = cluster.Masters
+		latest.Workers = cluster.Workers
+		latest.Ingress = cluster.Ingress
+		latest.ControlPlane = cluster.ControlPlane
+		latest.ClusterLB = cluster.ClusterLB
+		latest.CloudProvider = cluster.CloudProvider
+		latest.DnsServerDeploy = cluster.DnsServerDeploy
+		latest.ClusterDnsUpstream = cluster.ClusterDnsUpstream
+		latest.MiddlePlatform = cluster.MiddlePlatform
+		latest.PostgresOperator = cluster.PostgresOperator
+		latest.Console = cluster.Console
+		latest.EFK = cluster.EFK
+		latest.GAP = cluster.GAP
+		latest.Helm = cluster.Helm
+		latest.AutoRestarter = cluster.AutoRestarter
+		latest.KsClusterConf = cluster.KsClusterConf
+		latest.ReclaimNamespaces = cluster.ReclaimNamespaces
+		latest.AdditionalVersionDep = cluster.AdditionalVersionDep
+		latest.ClusterAdminToken = cluster.ClusterAdminToken
+		latest.BackupRegularName = cluster.BackupRegularName
+		latest.BackupRegularEnable = cluster.BackupRegularEnable
+		return nil
+	})
+	if err != nil {
 		log.Errorf("Failed to set cluster status to %s due to error %s", constants.StatusSuccessful, err.Error())
 		return err
 	}
 	return nil
 }
 
-func saveNodeStatus(runtimeCache cache.ICache, node schema.NodeInformation) error {
-	if err := runtimeCache.SaveOrUpdateNodeInformation(node.Id, node); err != nil {
-		log.Errorf("Failed to set node status to %s due to error %s", constants.StatusSuccessful, err.Error())
+/*
+mergeOperationCurrentOperation only adds or removes entry of operation itself to current and related operations of latest
+an operation starting replaces current operations as a whole though, which drops ones left behind by operations before it
+*/
+func mergeOperationCurrentOperation(latest *schema.Cluster, operationId string, cluster schema.Cluster) {
+	_, running := cluster.CurrentOperation[operationId]
+	_, saved := latest.CurrentOperation[operationId]
+	switch {
+	case running && !saved:
+		latest.CurrentOperation = cluster.CurrentOperation
+	case !running && saved:
+		// latest may share map with cache, so it is not changed in place
+		currentOperation := map[string]byte{}
+		for id, value := range latest.CurrentOperation {
+			if id != operationId {
+				currentOperation[id] = value
+			}
+		}
+		latest.CurrentOperation = currentOperation
+	}
+	for _, id := range latest.ClusterOperationIDs {
+		if id == operationId {
+			return
+		}
+	}
+	for _, id := range cluster.ClusterOperationIDs {
+		if id == operationId {
+			latest.ClusterOperationIDs = append(append([]string{}, latest.ClusterOperationIDs...), operationId)
+			return
+		}
+	}
+}
+
+/*
+updateNodeStatus changes node by mutate and saves it
+node is read again and mutated again when it is saved in between, e.g. by agent reporting in
+*/
+func updateNodeStatus(runtimeCache cache.ICache, nodeId string, mutate func(node *schema.NodeInformation)) error {
+	_, err := cache.UpdateNodeInformation(runtimeCache, nodeId, func(latest *schema.NodeInformation) error {
+		if latest.ResourceVersion == 0 {
+			return fmt.Errorf("Unable to get node %s information ", nodeId)
+		}
+		mutate(latest)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Failed to set node status due to error %s", err.Error())
 		return err
 	}
 	return nil
@@ -649,14 +671,10 @@ func saveRelationshipStatus(runtimeCache cache.ICache, clusterId string, nodes [
 }
 
 func cleanNodeStat(runtimeCache cache.ICache, nodeId string) error {
-	node, errGetNodeInfo := runtimeCache.GetNodeInformation(nodeId)
-	if errGetNodeInfo != nil {
-		log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-		return errGetNodeInfo
-	}
-	node.BelongsToCluster = ""
-	node.Role = 0
-	return saveNodeStatus(runtimeCache, *node)
+	return updateNodeStatus(runtimeCache, nodeId, func(node *schema.NodeInformation) {
+		node.BelongsToCluster = ""
+		node.Role = 0
+	})
 }
 
 func releaseClusterNode(runtimeCache cache.ICache, cluster *schema.Cluster) error {
@@ -742,13 +760,9 @@ func saveMasterWorkerKubeStat(cluster schema.Cluster, kubeStat string) error {
 		reducedNode[worker.NodeId] = 0
 	}
 	for nodeId := range reducedNode {
-		nodeData, errGetNodeInfo := runtimeCache.GetNodeInformation(nodeId)
-		if errGetNodeInfo != nil {
-			log.Errorf("Failed to retrieve node information due to error: %s", errGetNodeInfo.Error())
-			return errGetNodeInfo
-		}
-		nodeData.KubeNodeStat = kubeStat
-		if err := saveNodeStatus(runtimeCache, *nodeData); err != nil {
+		if err := updateNodeStatus(runtimeCache, nodeId, func(nodeData *schema.NodeInformation) {
+			nodeData.KubeNodeStat = kubeStat
+		}); err != nil {
 			return err
 		}
 	}
@@ -797,12 +811,12 @@ func addOrRemoveNodeDoneHandlerCreator(action string, nodesToRemove []schema.Clu
 
 func clusterOperationErrorHandler(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache, err error) error {
 	cluster.Status = constants.StatusError
-	return saveClusterStatus(runtimeCache, *cluster)
+	return saveClusterStatus(runtimeCache, operation.Id, *cluster)
 }
 
 func clusterOperationIgnoreErrorHandler(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache, err error) error {
 	cluster.Status = constants.ClusterStatusRunning
-	return saveClusterStatus(runtimeCache, *cluster)
+	return saveClusterStatus(runtimeCache, operation.Id, *cluster)
 }
 
 func RemoveNodeFromNodeList(nodes []schema.ClusterNode, nodesToRemove []schema.ClusterNode) []schema.ClusterNode {
@@ -871,8 +885,7 @@ func applyUpgradeTaskErrorHandler(plan *schema.UpgradePlan) func(operation *sche
 			log.Errorf("Failed to save upgrade plan due to error: %s", err.Error())
 			return err
 		}
-		if err := runtimeCache.SaveOrUpdateClusterCollection(cluster.ClusterId, *cluster); err != nil {
-			log.Errorf("Failed to save cluster due to error: %s", err.Error())
+		if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 			return err
 		}
 		return nil
@@ -925,7 +938,7 @@ func BackupCreate(v schema.Backup, cluster *schema.Cluster, runByUser string) er
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -995,7 +1008,7 @@ func BackupCreateWait(v schema.Backup, cluster *schema.Cluster, runByUser string
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -1162,7 +1175,7 @@ func RestoreCreate(v schema.Restore, cluster *schema.Cluster, runByUser string)
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -1331,7 +1344,7 @@ func BackupRegularCreate(v schema.BackupRegular, cluster *schema.Cluster, runByU
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -1391,7 +1404,7 @@ func BackupRegularDelete(backupRegularName string, cluster *schema.Cluster, runB
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return err
 	}
 
@@ -1462,7 +1475,7 @@ func BackupRegularGet(backupRegularName string, cluster *schema.Cluster, runByUs
 	// add operation id to it`s related operations
 	cluster.ClusterOperationIDs = append(cluster.ClusterOperationIDs, operation.Id)
 	// put cluster in db and cache
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		return "", err
 	}
 
@@ -1487,7 +1500,7 @@ func backupRestoreTaskDoneHandler(operation *schema.Operation, cluster *schema.C
 		log.Errorf("Failed to record error data on all step is done due to error %s", err.Error())
 	}
 
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		log.Errorf("Failed to save cluster data to db when all step is done due to error %s", err.Error())
 	}
 	return nil
@@ -1499,7 +1512,7 @@ func backupRestoreTaskErrorHandler(operation *schema.Operation, cluster *schema.
 		log.Errorf("Failed to record error data on all step is done due to error %s", err.Error())
 	}
 
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		log.Errorf("Failed to save cluster data to db when all step is done due to error %s", err.Error())
 	}
 	return nil
@@ -0,0 +1,105 @@
+package control_manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+/*
+nodeStepRetry sends failed node steps of a running step again according to their retry policy
+step handlers only see the result of the last attempt
+*/
+
+type nodeStepRetry struct {
+	operation *schema.Operation
+	step      *schema.Step
+	lock      sync.Locker
+	run       *runningOperation
+	// closed once step returns, pending retries are dropped after that
+	stepFinished   <-chan struct{}
+	taskDoneSignal chan int
+	// node step id -> how many times it failed
+	failedAttempts map[string]int
+	// send node step to node, called with lock held
+	send func(nodeStep schema.NodeStep) string
+}
+
+func retryPolicyOf(step *schema.Step, nodeStep schema.NodeStep) *schema.RetryPolicy {
+	if nodeStep.RetryPolicy != nil {
+		return nodeStep.RetryPolicy
+	}
+	return step.RetryPolicy
+}
+
+/*
+retry is called by reply and timeout handlers with lock held
+returns true if node step is going to be sent again, step handlers should not be invoked in that case
+*/
+func (retry *nodeStepRetry) retry(nodeStepId, failure, message string) bool {
+	if retry == nil || retry.send == nil {
+		return false
+	}
+	var nodeStep schema.NodeStep
+	found := false
+	for _, candidate := range retry.step.NodeSteps {
+		if candidate.Id == nodeStepId {
+			nodeStep = candidate
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	policy := retryPolicyOf(retry.step, nodeStep)
+	if policy == nil {
+		return false
+	}
+
+	retry.failedAttempts[nodeStepId] += 1
+	failedAttempts := retry.failedAttempts[nodeStepId]
+	if !policy.IsRetryable(failedAttempts, failure, message) || retry.run.isCancelled() {
+		if failedAttempts > 1 {
+			recordErrorLogToOperationLog(retry.operation, fmt.Sprintf("Node step %s of step %s on node %s result in %s state at attempt %d of %d, give up",
+				nodeStep.Name, retry.step.Name, nodeStep.NodeID, failure, failedAttempts, policy.MaxAttempts))
+		}
+		return false
+	}
+
+	backoff := policy.Backoff(failedAttempts)
+	recordErrorLogToOperationLog(retry.operation, fmt.Sprintf("Node step %s of step %s on node %s result in %s state at attempt %d of %d, retry in %s. %s",
+		nodeStep.Name, retry.step.Name, nodeStep.NodeID, failure, failedAttempts, policy.MaxAttempts, backoff, message))
+
+	go func() {
+		select {
+		case <-time.After(backoff):
+		case <-retry.stepFinished:
+			return
+		case <-retry.run.cancelled:
+			return
+		}
+		if failure == constants.RetryOnTimeout {
+			// previous attempt may be still running on node
+			abortNodeStepsOnNode(retry.operation.Id, nodeStep.NodeID, []string{nodeStep.Id})
+		}
+
+		retry.lock.Lock()
+		defer retry.lock.Unlock()
+		select {
+		case <-retry.stepFinished:
+			return
+		default:
+		}
+		recordDebugLogToOperationLog(retry.operation, fmt.Sprintf("Sending node step %s of step %s to node %s again, attempt %d of %d",
+			nodeStep.Name, retry.step.Name, nodeStep.NodeID, failedAttempts+1, policy.MaxAttempts))
+		if errMsg := retry.send(nodeStep); errMsg != "" {
+			recordErrorLogToOperationLog(retry.operation, errMsg)
+			retry.taskDoneSignal <- constants.MessageSignalError
+		}
+	}()
+	return true
+}
@@ -0,0 +1,78 @@
+package control_manager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestNodeStepRetryUntilMaxAttempts(t *testing.T) {
+	lock := &sync.Mutex{}
+	operation := &schema.Operation{Id: "operation-retry-test"}
+	step := &schema.Step{
+		Name: "InstallCRI",
+		NodeSteps: []schema.NodeStep{
+			{Id: "node-step-1", NodeID: "node-1"},
+		},
+		RetryPolicy: &schema.RetryPolicy{
+			MaxAttempts:       2,
+			RetryOn:           []string{constants.RetryOnError},
+			RetryableMessages: []string{"download"},
+		},
+	}
+	sent := make(chan string, 2)
+	retry := &nodeStepRetry{
+		operation:      operation,
+		step:           step,
+		lock:           lock,
+		run:            &runningOperation{cancelled: make(chan struct{})},
+		stepFinished:   make(chan struct{}),
+		failedAttempts: map[string]int{},
+		taskDoneSignal: make(chan int, 1),
+		send: func(nodeStep schema.NodeStep) string {
+			sent <- nodeStep.Id
+			return ""
+		},
+	}
+
+	lock.Lock()
+	if retry.retry("node-step-1", constants.RetryOnTimeout, "") {
+		t.Fatal("Expected timeout not to be retried")
+	}
+	retry.failedAttempts = map[string]int{}
+	if retry.retry("node-step-1", constants.RetryOnError, "permission denied") {
+		t.Fatal("Expected error whose message does not match retryable messages not to be retried")
+	}
+	retry.failedAttempts = map[string]int{}
+	if !retry.retry("node-step-1", constants.RetryOnError, "failed to download docker.tar.gz") {
+		t.Fatal("Expected first download error to be retried")
+	}
+	lock.Unlock()
+
+	select {
+	case nodeStepId := <-sent:
+		if nodeStepId != "node-step-1" {
+			t.Fatalf("Expected node-step-1 to be sent again, got %s", nodeStepId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected node step to be sent again")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if retry.retry("node-step-1", constants.RetryOnError, "failed to download docker.tar.gz") {
+		t.Fatal("Expected no more retry once max attempts is reached")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &schema.RetryPolicy{InitialBackoff: 10, MaxBackoff: 30}
+	for failedAttempts, expected := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 30 * time.Second, 10: 30 * time.Second} {
+		if backoff := policy.Backoff(failedAttempts); backoff != expected {
+			t.Fatalf("Expected backoff %s after %d failed attempts, got %s", expected, failedAttempts, backoff)
+		}
+	}
+}
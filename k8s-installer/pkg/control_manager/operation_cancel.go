@@ -0,0 +1,184 @@
+package control_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	messageQueue "k8s-installer/pkg/message_queue/nats"
+	mqHelper "k8s-installer/pkg/server/message_queue"
+	"k8s-installer/schema"
+
+	"github.com/google/uuid"
+)
+
+// how long to wait for client to reply abort message
+const abortNodeStepTimeout = 10
+
+var ErrOperationNotRunning = errors.New("Operation is not running on this server ")
+
+/*
+runningOperation is registered by doTask for as long as the operation is running
+so it can be cancelled from api server
+*/
+
+type runningOperation struct {
+	operationId string
+	// operation lock which is shared by all running steps of the operation
+	operationLock sync.Locker
+	operation     *schema.Operation
+	cancelled     chan struct{}
+	cancelOnce    sync.Once
+	lock          sync.Mutex
+	// node step id -> node id of node steps which are sent to client but not finished yet
+	nodeSteps map[string]string
+}
+
+var runningOperations = struct {
+	lock       sync.Mutex
+	operations map[string]*runningOperation
+}{
+	operations: map[string]*runningOperation{},
+}
+
+func registerRunningOperation(operation *schema.Operation, operationLock sync.Locker) *runningOperation {
+	run := &runningOperation{
+		operationId:   operation.Id,
+		operationLock: operationLock,
+		operation:     operation,
+		cancelled:     make(chan struct{}),
+		nodeSteps:     map[string]string{},
+	}
+	runningOperations.lock.Lock()
+	runningOperations.operations[operation.Id] = run
+	runningOperations.lock.Unlock()
+	return run
+}
+
+func (run *runningOperation) unregister() {
+	runningOperations.lock.Lock()
+	defer runningOperations.lock.Unlock()
+	if runningOperations.operations[run.operationId] == run {
+		delete(runningOperations.operations, run.operationId)
+	}
+}
+
+func (run *runningOperation) isCancelled() bool {
+	select {
+	case <-run.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (run *runningOperation) nodeStepSent(nodeStepId, nodeId string) {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+	run.nodeSteps[nodeStepId] = nodeId
+}
+
+func (run *runningOperation) nodeStepsFinished(nodeSteps []schema.NodeStep) {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+	for _, nodeStep := range nodeSteps {
+		delete(run.nodeSteps, nodeStep.Id)
+	}
+}
+
+func isOperationRunningHere(operationId string) bool {
+	runningOperations.lock.Lock()
+	defer runningOperations.lock.Unlock()
+	_, found := runningOperations.operations[operationId]
+	return found
+}
+
+func isOperationCancelled(operationId string) bool {
+	runningOperations.lock.Lock()
+	run, found := runningOperations.operations[operationId]
+	runningOperations.lock.Unlock()
+	return found && run.isCancelled()
+}
+
+/*
+CancelOperation stops scheduling new steps of a running operation and asks clients to abort the running node steps
+operation error handler runs as usual once running steps returned and operation is marked as cancelled
+*/
+func CancelOperation(operationId string, runByUser string) error {
+	runningOperations.lock.Lock()
+	run, found := runningOperations.operations[operationId]
+	runningOperations.lock.Unlock()
+	if !found {
+		return ErrOperationNotRunning
+	}
+
+	cancelled := false
+	run.cancelOnce.Do(func() {
+		close(run.cancelled)
+		cancelled = true
+	})
+	if !cancelled {
+		return fmt.Errorf("Operation %s is already being cancelled ", operationId)
+	}
+
+	run.operationLock.Lock()
+	recordDebugLogToOperationLog(run.operation, fmt.Sprintf("Operation %s is cancelled by user %s", operationId, runByUser))
+	run.operationLock.Unlock()
+
+	run.lock.Lock()
+	nodeSteps := map[string][]string{}
+	for nodeStepId, nodeId := range run.nodeSteps {
+		nodeSteps[nodeId] = append(nodeSteps[nodeId], nodeStepId)
+	}
+	run.lock.Unlock()
+
+	for nodeId, nodeStepIds := range nodeSteps {
+		go abortNodeStepsOnNode(operationId, nodeId, nodeStepIds)
+	}
+	return nil
+}
+
+func abortNodeStepsOnNode(operationId, nodeId string, nodeStepIds []string) {
+	runtimeCache := cache.GetCurrentCache()
+	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	nodeData, err := runtimeCache.GetNodeInformation(nodeId)
+	if err != nil || nodeData == nil {
+		log.Errorf("Failed to abort node steps %v due to node %s information not found", nodeStepIds, nodeId)
+		return
+	}
+
+	task := schema.TaskAbortNodeStep{
+		TaskType:    constants.TaskAbortNodeStep,
+		TimeOut:     abortNodeStepTimeout,
+		NodeStepIds: nodeStepIds,
+	}
+	abortStepId := "abort-" + uuid.New().String()
+	msgData, err := createMsgBody(operationId, "", abortStepId, task, schema.Cluster{}, nil, nil)
+	if err != nil {
+		log.Errorf("Failed to create abort message for node %s due to error: %s", nodeId, err.Error())
+		return
+	}
+
+	result, err := messageQueue.SendingMessageAndWaitReply(mqHelper.GeneratorNodeSubscribe(*nodeData, config.MessageQueue.SubjectSuffix),
+		cache.NodeId,
+		nodeId,
+		abortStepId,
+		config.MessageQueue,
+		abortNodeStepTimeout*time.Second,
+		[]byte(msgData))
+	if err != nil {
+		log.Errorf("Failed to abort node steps %v on node %s due to error: %s", nodeStepIds, nodeId, err.Error())
+		return
+	}
+	reply := schema.QueueReply{}
+	if err := json.Unmarshal(result, &reply); err != nil {
+		log.Errorf("Failed to unmarshal abort reply of node %s due to error: %s", nodeId, err.Error())
+		return
+	}
+	log.Debugf("Node %s replied abort request with: %s", nodeId, reply.Message)
+}
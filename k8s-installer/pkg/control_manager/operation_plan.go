@@ -0,0 +1,370 @@
+package control_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"k8s-installer/pkg/cache"
+	serverConfig "k8s-installer/pkg/config/server"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/license"
+	"k8s-installer/pkg/log"
+	taskBreaker "k8s-installer/pkg/task_breaker"
+	"k8s-installer/schema"
+
+	"github.com/google/uuid"
+)
+
+/*
+dry run of cluster operations
+all Plan* functions break down the operation exactly the same way the real one does
+but never save the operation or send any message to agents
+*/
+
+func PlanSetupOrDestroyCluster(cluster schema.Cluster) (schema.OperationPlan, error) {
+	runtimeCache := cache.GetCurrentCache()
+	cluster, err := copyClusterForPlan(cluster)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+	SetReclaimNamespaces(&cluster)
+
+	nodeInfoCollection, errGetNode := runtimeCache.GetNodeInformationCollection()
+	if errGetNode != nil {
+		return schema.OperationPlan{}, errGetNode
+	}
+
+	sumLicenseLabel, err := license.SumLicenseLabel()
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+
+	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	operation, err := createOperationFromCluster(setupOrDestroyOperationName(cluster), cluster, config, nodeInfoCollection, constants.StatusProcessing, sumLicenseLabel)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+	operation.OperationType = constants.OperationTypeClusterSetupOrDestroy
+	if cluster.Mock {
+		mockOperationStep(&operation)
+	}
+	return renderOperationPlan(operation, nodeInfoCollection, config)
+}
+
+func PlanAddOrRemoveNodeToCluster(cluster schema.Cluster, nodesToAddOrRemove []schema.ClusterNode, addOrRemove string) (schema.OperationPlan, error) {
+	runtimeCache := cache.GetCurrentCache()
+	cluster, err := copyClusterForPlan(cluster)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+
+	operation := createOperation(addOrRemoveNodeOperationName(cluster, addOrRemove), cluster.ClusterId, uuid.New().String(), constants.StatusProcessing)
+	setAddOrRemoveNodeRequestParameter(&operation, nodesToAddOrRemove, addOrRemove)
+
+	nodeInfoCollection, errGetNode := runtimeCache.GetNodeInformationCollection()
+	if errGetNode != nil {
+		return schema.OperationPlan{}, errGetNode
+	}
+	if addOrRemove == constants.ActionCreate {
+		// real operation locks down node before break down task so do the same in memory only
+		// local ram cache returns it`s own collection so copy it first
+		collection := schema.NodeInformationCollection{}
+		for nodeId, nodeData := range nodeInfoCollection {
+			collection[nodeId] = nodeData
+		}
+		nodeInfoCollection = collection
+		for _, node := range nodesToAddOrRemove {
+			if nodeData, found := nodeInfoCollection[node.NodeId]; found {
+				nodeData.Role = constants.NodeRoleWorker
+				nodeData.BelongsToCluster = cluster.ClusterId
+				nodeData.KubeNodeStat = constants.UnAvailable
+				nodeInfoCollection[node.NodeId] = nodeData
+			}
+		}
+	}
+
+	operation, err = breakDownAddOrRemoveNode(operation, cluster, nodesToAddOrRemove, addOrRemove, nodeInfoCollection)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+	return renderOperationPlan(operation, nodeInfoCollection, runtimeCache.GetServerRuntimeConfig(cache.NodeId))
+}
+
+func PlanAddonsManage(cluster schema.Cluster, addons schema.Addons) (schema.OperationPlan, error) {
+	runtimeCache := cache.GetCurrentCache()
+	// break down changes addons of the cluster it works on
+	// so make sure none of them are shared with cached cluster
+	copyCluster, err := copyClusterForPlan(cluster)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+	originalCluster, err := copyClusterForPlan(cluster)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+
+	operation := createOperation("DeployAddons", cluster.ClusterId, uuid.New().String(), constants.StatusProcessing)
+	operation.OperationType = constants.OperationTypeManageAddonsToCluster
+	operation, err = breakDownAddons(operation, &copyCluster, originalCluster, addons)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+
+	nodeInfoCollection, errGetNode := runtimeCache.GetNodeInformationCollection()
+	if errGetNode != nil {
+		return schema.OperationPlan{}, errGetNode
+	}
+	return renderOperationPlan(operation, nodeInfoCollection, runtimeCache.GetServerRuntimeConfig(cache.NodeId))
+}
+
+func PlanApplyUpgrade(plan schema.UpgradePlan, cluster schema.Cluster) (schema.OperationPlan, error) {
+	runtimeCache := cache.GetCurrentCache()
+	cluster, err := copyClusterForPlan(cluster)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+
+	upgradeVersion, err := runtimeCache.GetUpgradeVersion(plan.TargetVersion)
+	if err != nil {
+		log.Errorf("Failed to get upgrade version %s information due to error: %s", plan.TargetVersion, err.Error())
+		return schema.OperationPlan{}, err
+	}
+	if upgradeVersion == nil {
+		return schema.OperationPlan{}, errors.New(fmt.Sprintf("Failed to get upgrade version %s information due to not found", plan.TargetVersion))
+	}
+
+	nodesCollections, nodeCollectionErr := runtimeCache.GetNodeInformationCollection()
+	if nodeCollectionErr != nil {
+		return schema.OperationPlan{}, nodeCollectionErr
+	}
+
+	operation := createOperation("UpgradeCluster", cluster.ClusterId, uuid.New().String(), constants.StatusProcessing)
+	operation.OperationType = constants.OperationTypeUpgradeCluster
+	operation, err = breakDownUpgrade(operation, &plan, *upgradeVersion, cluster, nodesCollections)
+	if err != nil {
+		return schema.OperationPlan{}, err
+	}
+	return renderOperationPlan(operation, nodesCollections, runtimeCache.GetServerRuntimeConfig(cache.NodeId))
+}
+
+func renderOperationPlan(operation schema.Operation, nodeCollection schema.NodeInformationCollection, config serverConfig.Config) (schema.OperationPlan, error) {
+	plan := schema.OperationPlan{
+		OperationName: operation.Name,
+		OperationType: operation.OperationType,
+		ClusterId:     operation.ClusterId,
+		Steps:         []schema.StepPlan{},
+	}
+
+	graph, err := buildStepGraph(&operation)
+	if err != nil {
+		return plan, err
+	}
+
+	for _, index := range graph.order {
+		step := operation.Step[index]
+		stepPlan := schema.StepPlan{
+			Index:               index,
+			Id:                  step.Id,
+			Name:                step.Name,
+			DependsOnSteps:      graph.dependencies[index],
+			WaitBeforeRun:       step.WaitBeforeRun,
+			HasDynamicNodeSteps: step.DynamicNodeSteps != nil,
+			NodeSteps:           []schema.NodeStepPlan{},
+			RetryPolicy:         step.RetryPolicy,
+			PauseGate:           step.PauseGate != nil,
+		}
+		stepPlan.NodeSteps = append(stepPlan.NodeSteps, renderNodeStepPlans(step.NodeSteps, nodeCollection, config)...)
+		if len(step.RollbackNodeSteps) > 0 {
+			stepPlan.RollbackNodeSteps = renderNodeStepPlans(step.RollbackNodeSteps, nodeCollection, config)
+		}
+		plan.Steps = append(plan.Steps, stepPlan)
+	}
+	return plan, nil
+}
+
+func renderNodeStepPlans(nodeSteps []schema.NodeStep, nodeCollection schema.NodeInformationCollection, config serverConfig.Config) []schema.NodeStepPlan {
+	var nodeStepPlans []schema.NodeStepPlan
+	for _, nodeStep := range nodeSteps {
+		task := nodeStep.Tasks[0]
+		nodeStepPlan := schema.NodeStepPlan{
+			Id:     nodeStep.Id,
+			Name:   nodeStep.Name,
+			NodeId: nodeStep.NodeID,
+			Task:   task,
+		}
+		if node, found := nodeCollection[nodeStep.NodeID]; found {
+			nodeStepPlan.NodeIp = node.Ipv4DefaultIp
+		}
+		if task != nil {
+			nodeStepPlan.TaskType = task.GetTaskType()
+			nodeStepPlan.TimeOut = task.GetTaskTimeOut()
+			nodeStepPlan.RenderedFiles = renderTaskFiles(task, nodeCollection, config)
+		}
+		nodeStepPlans = append(nodeStepPlans, nodeStepPlan)
+	}
+	return nodeStepPlans
+}
+
+// renderTaskFiles collects text files a task is going to write on target node
+func renderTaskFiles(task schema.ITask, nodeCollection schema.NodeInformationCollection, config serverConfig.Config) map[string]string {
+	files := map[string]string{}
+	switch t := task.(type) {
+	case schema.TaskCopyTextBaseFile:
+		for filePath, content := range t.TextFiles {
+			files[filePath] = string(content)
+		}
+	case schema.TaskCommonDownload:
+		// task_breaker.CommonConfig renders config files into resource server dir named after node id
+		// other downloads are packages which are not worth to show
+		if _, isNodeDir := nodeCollection[t.FromDir]; !isNodeDir {
+			break
+		}
+		for _, file := range t.FileList {
+			if !strings.HasPrefix(file, "/"+t.FromDir+"/") {
+				continue
+			}
+			content, err := ioutil.ReadFile(path.Join(config.ApiServer.ResourceServerFilePath, file))
+			if err != nil {
+				log.Warnf("(ignore) Failed to read rendered file %s due to error: %s", file, err.Error())
+				continue
+			}
+			files[path.Join(t.SaveTo, path.Base(file))] = string(content)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return files
+}
+
+func copyClusterForPlan(cluster schema.Cluster) (schema.Cluster, error) {
+	var result schema.Cluster
+	data, err := json.Marshal(cluster)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+func setupOrDestroyOperationName(cluster schema.Cluster) string {
+	operationName := "SetupCluster"
+	if cluster.Action == constants.ActionDelete {
+		operationName = "DestroyCluster"
+	}
+
+	// mock meaning do not actually do setup or destroy cluster instead just setup db stat as normal setup
+	if cluster.Mock {
+		operationName = "Mock" + operationName
+	}
+	return operationName
+}
+
+func addOrRemoveNodeOperationName(cluster schema.Cluster, addOrRemove string) string {
+	operationName := "RemoveNodeFromCluster"
+	if addOrRemove == constants.ActionCreate {
+		operationName = "AddNodeToCluster"
+	}
+	if cluster.Mock {
+		operationName = "Mock" + operationName
+	}
+	return operationName
+}
+
+func setAddOrRemoveNodeRequestParameter(operation *schema.Operation, nodesToAddOrRemove []schema.ClusterNode, addOrRemove string) {
+	operation.OperationType = constants.OperationTypeAddOrRemoveNodesToCluster
+	// save request parameters in operation
+	operation.RequestParameter.BodyParameters = nodesToAddOrRemove
+	operation.RequestParameter.QueryParameters = map[string]interface{}{
+		"operationId": operation.Id,
+	}
+	operation.RequestParameter.RuntimeParameters = map[string]interface{}{
+		"addOrRemove": addOrRemove,
+	}
+}
+
+func mockOperationStep(operation *schema.Operation) {
+	// always reset the operation step to nothing but wait 1 seconds
+	mockStep := taskBreaker.CreateWaitStep(1, "MockStep")
+	operation.Step = map[int]*schema.Step{
+		0: &mockStep,
+	}
+}
+
+func breakDownAddOrRemoveNode(operation schema.Operation, cluster schema.Cluster, nodesToAddOrRemove []schema.ClusterNode, addOrRemove string, nodeInfoCollection schema.NodeInformationCollection) (schema.Operation, error) {
+	sumLicenseLabel, err := license.SumLicenseLabel()
+	if err != nil {
+		return operation, err
+	}
+
+	clusterNodeTaskBreakDown := taskBreaker.ClusterNodeTaskBreakDown{
+		Operation:          operation,
+		Cluster:            cluster,
+		Config:             cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId),
+		NodesToAddOrRemove: nodesToAddOrRemove,
+		NodeCollectionList: nodeInfoCollection,
+		Action:             addOrRemove,
+		SumLicenseLabel:    sumLicenseLabel,
+	}
+
+	operation, err = clusterNodeTaskBreakDown.BreakDownTask()
+	if err != nil {
+		log.Errorf("Failed to break down task due to error %s", err.Error())
+		return operation, err
+	}
+	// if mock
+	if cluster.Mock {
+		if cluster.ClusterInstaller == constants.ClusterInstallerRancher {
+			// always reset the operation step to nothing but wait 1 seconds
+			rancherOp, err := clusterNodeTaskBreakDown.BreakDownRancherTask()
+			if err != nil {
+				log.Errorf("Failed to rancher break down task due to error %s", err.Error())
+				return operation, err
+			}
+			operation.Step = rancherOp.Step
+		} else {
+			mockOperationStep(&operation)
+		}
+	}
+	return operation, nil
+}
+
+func breakDownAddons(operation schema.Operation, copyCluster *schema.Cluster, originalCluster schema.Cluster, addons schema.Addons) (schema.Operation, error) {
+	sumLicenseLabel, errLicense := license.SumLicenseLabel()
+	if errLicense != nil {
+		return operation, errLicense
+	}
+
+	batchAddonsTaskBreakdown := taskBreaker.BatchAddonsTaskBreakDown{
+		Operation:       &operation,
+		Cluster:         copyCluster,
+		OriginalCluster: originalCluster,
+		NewAddons:       addons,
+		SumLicenseLabel: sumLicenseLabel,
+	}
+	return batchAddonsTaskBreakdown.BreakDownTask()
+}
+
+func breakDownUpgrade(operation schema.Operation, plan *schema.UpgradePlan, upgradeVersion schema.UpgradableVersion, cluster schema.Cluster, nodesCollections schema.NodeInformationCollection) (schema.Operation, error) {
+	sumLicenseLabel, err := license.SumLicenseLabel()
+	if err != nil {
+		return operation, err
+	}
+	upgradeTaskBreakDown := taskBreaker.UpgradingTaskBreakDown{
+		Cluster:          cluster,
+		Operation:        operation,
+		NodesCollection:  nodesCollections,
+		TargetK8sVersion: plan.TargetVersion,
+		ApplyVersion:     upgradeVersion,
+		Dep:              upgradeVersion.ToDep(),
+		Config:           cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId),
+		Strategy:         plan.Strategy,
+		SumLicenseLabel:  sumLicenseLabel,
+		PauseGate:        upgradePauseGate(plan),
+	}
+	return upgradeTaskBreakDown.BreakDownTask()
+}
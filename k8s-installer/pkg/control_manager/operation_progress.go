@@ -0,0 +1,252 @@
+package control_manager
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/metrics"
+	"k8s-installer/schema"
+)
+
+// only keep the latest events of an operation in memory
+const maxOperationEvents = 5000
+
+// how long events of a finished operation stay for reconnecting clients
+const operationProgressRetention = 10 * time.Minute
+
+// events pending on a subscriber before it is considered too slow and get dropped
+const operationSubscriberBuffer = 256
+
+/*
+operationProgressHub keeps live progress of operations handled by this server
+every line written to operation log, step start/finish and node reply are published here as they happen
+so api server is able to stream them without waiting for operation log to be saved
+*/
+
+type operationProgressHub struct {
+	lock       sync.Mutex
+	operations map[string]*operationProgress
+	// passes every event to servers which stream the operation for their clients, nil until message queue is up
+	relay func(event schema.OperationEvent)
+}
+
+type operationProgress struct {
+	events      []schema.OperationEvent
+	nextSeq     uint64
+	finished    bool
+	finishedAt  time.Time
+	subscribers map[chan schema.OperationEvent]byte
+}
+
+var progressHub = &operationProgressHub{
+	operations: map[string]*operationProgress{},
+}
+
+func (hub *operationProgressHub) getOrCreate(operationId string) *operationProgress {
+	progress, found := hub.operations[operationId]
+	if !found {
+		progress = &operationProgress{
+			nextSeq:     1,
+			subscribers: map[chan schema.OperationEvent]byte{},
+		}
+		hub.operations[operationId] = progress
+	}
+	return progress
+}
+
+func (hub *operationProgressHub) publish(event schema.OperationEvent) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	progress := hub.getOrCreate(event.OperationId)
+	// operation continue after it was finished
+	progress.finished = false
+
+	event.Seq = progress.nextSeq
+	progress.nextSeq += 1
+	if event.Time == "" {
+		event.Time = time.Now().Format("2006-01-02T15:04:05Z07:00")
+	}
+	progress.events = append(progress.events, event)
+	if len(progress.events) > maxOperationEvents {
+		progress.events = progress.events[len(progress.events)-maxOperationEvents:]
+	}
+	// relayed with lock held so other servers get events in order of seq
+	if hub.relay != nil {
+		hub.relay(event)
+	}
+
+	for subscriber := range progress.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// never let a slow client block the operation
+			// client is expected to reconnect with the last seq it got
+			delete(progress.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+func (hub *operationProgressHub) finish(operationId, status string) {
+	hub.publish(schema.OperationEvent{
+		OperationId: operationId,
+		Type:        constants.OperationEventOperationDone,
+		StepIndex:   -1,
+		Status:      status,
+	})
+
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	progress := hub.getOrCreate(operationId)
+	progress.finished = true
+	progress.finishedAt = time.Now()
+	for subscriber := range progress.subscribers {
+		delete(progress.subscribers, subscriber)
+		close(subscriber)
+	}
+	time.AfterFunc(operationProgressRetention, func() {
+		hub.lock.Lock()
+		defer hub.lock.Unlock()
+		if progress, found := hub.operations[operationId]; found && progress.finished && time.Since(progress.finishedAt) >= operationProgressRetention {
+			delete(hub.operations, operationId)
+		}
+	})
+}
+
+/*
+subscribe returns all retained events whose seq >= fromSeq and a channel for the following ones
+channel is closed once operation is finished or subscriber is too slow to consume events
+running tells whether operation is still processing in case nothing of it is kept in memory
+*/
+func (hub *operationProgressHub) subscribe(operationId string, fromSeq uint64, running bool) ([]schema.OperationEvent, <-chan schema.OperationEvent, func()) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	if _, found := hub.operations[operationId]; !found && !running {
+		subscriber := make(chan schema.OperationEvent)
+		close(subscriber)
+		return nil, subscriber, func() {}
+	}
+	progress := hub.getOrCreate(operationId)
+
+	var history []schema.OperationEvent
+	for _, event := range progress.events {
+		if event.Seq >= fromSeq {
+			history = append(history, event)
+		}
+	}
+
+	subscriber := make(chan schema.OperationEvent, operationSubscriberBuffer)
+	if progress.finished {
+		close(subscriber)
+		return history, subscriber, func() {}
+	}
+	progress.subscribers[subscriber] = 0
+	return history, subscriber, func() {
+		hub.lock.Lock()
+		defer hub.lock.Unlock()
+		if _, found := progress.subscribers[subscriber]; found {
+			delete(progress.subscribers, subscriber)
+			close(subscriber)
+		}
+		// nothing ever published e.g. operation is handled by other server
+		if len(progress.events) == 0 && len(progress.subscribers) == 0 {
+			delete(hub.operations, operationId)
+		}
+	}
+}
+
+/*
+history returns retained events whose seq >= fromSeq, no more than maxSize bytes of them once marshalled
+more tells some are left out, found is false when nothing of the operation is kept
+*/
+func (hub *operationProgressHub) history(operationId string, fromSeq uint64, maxSize int) (events []schema.OperationEvent, more, finished, found bool) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	progress, found := hub.operations[operationId]
+	if !found {
+		return nil, false, false, false
+	}
+	size := 0
+	for _, event := range progress.events {
+		if event.Seq < fromSeq {
+			continue
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		size += len(data)
+		if size > maxSize && len(events) > 0 {
+			return events, true, progress.finished, true
+		}
+		events = append(events, event)
+	}
+	return events, false, progress.finished, true
+}
+
+func (hub *operationProgressHub) setRelay(relay func(event schema.OperationEvent)) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	hub.relay = relay
+}
+
+/*
+SubscribeOperationProgress is used by api server to stream operation progress, call unsubscribe when client is gone
+progress of an operation running on other server is relayed from that server, error is returned if it does not answer
+*/
+func SubscribeOperationProgress(operation schema.Operation, fromSeq uint64) (history []schema.OperationEvent, events <-chan schema.OperationEvent, unsubscribe func(), err error) {
+	running := operation.Status == constants.StatusProcessing
+	if running && operation.Host != "" && operation.Host != cache.NodeId && !isOperationRunningHere(operation.Id) {
+		return subscribeRelayedProgress(operation, fromSeq)
+	}
+	history, events, unsubscribe = progressHub.subscribe(operation.Id, fromSeq, running)
+	return history, events, unsubscribe, nil
+}
+
+func publishLogEvent(operation *schema.Operation, level, msg string) {
+	progressHub.publish(schema.OperationEvent{
+		OperationId: operation.Id,
+		Type:        constants.OperationEventLog,
+		StepIndex:   -1,
+		Level:       level,
+		Message:     msg,
+	})
+}
+
+func publishStepEvent(operation *schema.Operation, stepIndex int, eventType, msg string) {
+	event := schema.OperationEvent{
+		OperationId: operation.Id,
+		Type:        eventType,
+		StepIndex:   stepIndex,
+		Message:     msg,
+	}
+	if step, found := operation.Step[stepIndex]; found {
+		event.StepName = step.Name
+	}
+	progressHub.publish(event)
+}
+
+func publishNodeReplyEvent(operation *schema.Operation, step *schema.Step, stepIndex int, reply schema.QueueReply) {
+	progressHub.publish(schema.OperationEvent{
+		OperationId: operation.Id,
+		Type:        constants.OperationEventNodeReply,
+		StepIndex:   stepIndex,
+		StepName:    step.Name,
+		Status:      reply.Stat,
+		Message:     reply.Message,
+		Reply:       &reply,
+	})
+}
+
+func finishOperationProgress(operation *schema.Operation) {
+	progressHub.finish(operation.Id, operation.Status)
+	// last run is when the operation is started or resumed so time of being paused is left out
+	var duration time.Duration
+	if lastRun, err := time.Parse("2006-01-02T15:04:05Z07:00", operation.LastRun); err == nil {
+		duration = time.Since(lastRun)
+	}
+	metrics.ObserveOperation(operation.OperationType, operation.Status, duration)
+}
@@ -0,0 +1,49 @@
+package control_manager
+
+import (
+	"testing"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestOperationProgressResumeFromSeq(t *testing.T) {
+	hub := &operationProgressHub{operations: map[string]*operationProgress{}}
+	operation := &schema.Operation{Id: "operation-test"}
+	for _, msg := range []string{"a", "b", "c"} {
+		hub.publish(schema.OperationEvent{OperationId: operation.Id, Type: constants.OperationEventLog, Message: msg})
+	}
+
+	history, events, unsubscribe := hub.subscribe(operation.Id, 2, true)
+	defer unsubscribe()
+	if len(history) != 2 || history[0].Seq != 2 || history[0].Message != "b" {
+		t.Fatalf("Expected to resume from seq 2, got %v", history)
+	}
+
+	hub.publish(schema.OperationEvent{OperationId: operation.Id, Type: constants.OperationEventLog, Message: "d"})
+	if event := <-events; event.Seq != 4 || event.Message != "d" {
+		t.Fatalf("Expected live event with seq 4, got %v", event)
+	}
+
+	hub.finish(operation.Id, constants.StatusSuccessful)
+	if event := <-events; event.Type != constants.OperationEventOperationDone || event.Status != constants.StatusSuccessful {
+		t.Fatalf("Expected operation done event, got %v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("Expected events channel to be closed once operation is finished")
+	}
+}
+
+func TestOperationProgressUnknownFinishedOperation(t *testing.T) {
+	hub := &operationProgressHub{operations: map[string]*operationProgress{}}
+	history, events, _ := hub.subscribe("operation-not-running", 0, false)
+	if len(history) != 0 {
+		t.Fatalf("Expected no history, got %v", history)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("Expected events channel to be closed for operation which is not running")
+	}
+	if len(hub.operations) != 0 {
+		t.Fatal("Subscribe to operation which is not running should not keep anything in memory")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/util"
 	"k8s-installer/schema"
 )
 
@@ -95,3 +96,57 @@ func (upgrade UpgradeRecover) Recover(operation *schema.Operation, cluster *sche
 		return nil, nil, errors.New("Unable to find runtime parameter: 'planId' ")
 	}
 }
+
+/*
+TakeOverOperation resumes operation left behind by previousHost which is gone
+operation is claimed first so only one server resumes it, false meaning another server claimed it already
+*/
+func TakeOverOperation(cluster schema.Cluster, operation schema.Operation, previousHost string) (bool, error) {
+	runtimeCache := cache.GetCurrentCache()
+	claimed, err := runtimeCache.ClaimOperation(operation.Id, previousHost, cache.NodeId)
+	if err != nil || !claimed {
+		return false, err
+	}
+	// saved along with resumed operation, otherwise it looks left behind again until first step is done
+	operation.Host = cache.NodeId
+	operation.Logs = append(operation.Logs, util.LogStyleMessage("info", fmt.Sprintf("Server %s running operation %s is gone, server %s takes it over", previousHost, operation.Id, cache.NodeId)))
+	if _, err := createRecover(operation.OperationType); err != nil {
+		// nothing knows how to resume it, fail it so user is able to tell and rerun
+		SetOperationToFailedStat(&operation, runtimeCache, fmt.Sprintf("Server %s running operation %s is gone and operation type %s is not able to resume", previousHost, operation.Id, operation.OperationType))
+		return true, nil
+	}
+	return true, OperationContinue(cluster, operation, operation.RunByUser)
+}
+
+/*
+claimOperation moves operation to this server before it is run here again, e.g. operation failed on another server is continued through this one
+saving progress of operation is refused once it is run by another server, so it has to be claimed before first save
+*/
+func claimOperation(runtimeCache cache.ICache, operation *schema.Operation) error {
+	if operation.Host == cache.NodeId {
+		return nil
+	}
+	claimed, err := runtimeCache.ClaimOperation(operation.Id, operation.Host, cache.NodeId)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("operation %s is claimed by another server in the meantime", operation.Id)
+	}
+	operation.Host = cache.NodeId
+	return nil
+}
+
+// resumeCompletedSteps converts operation saved before steps were tracked one by one
+// at that time every step before current step is done
+func resumeCompletedSteps(operation *schema.Operation) {
+	if operation.CompletedSteps != nil {
+		return
+	}
+	operation.CompletedSteps = map[int]byte{}
+	for index := range operation.Step {
+		if index < operation.CurrentStep {
+			operation.CompletedSteps[index] = 0
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package control_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	messageQueue "k8s-installer/pkg/message_queue/nats"
+	"k8s-installer/schema"
+
+	"github.com/nats-io/nats.go"
+)
+
+// how long to wait for server running an operation to answer a relayed request
+const relayTimeout = 10 * time.Second
+
+// progress history is sent in parts well below max payload of message queue
+const relayHistoryMaxSize = 512 * 1024
+
+var ErrServerNotAnswering = errors.New("Server running operation does not answer ")
+
+/*
+api requests hit any server while an operation only runs on the server which started or took it over
+requests about an operation are relayed to that server through message queue, queues of servers are clustered so every server reaches the others
+*/
+
+type relayCancelRequest struct {
+	OperationId string `json:"operation_id"`
+	RunByUser   string `json:"run_by_user"`
+}
+
+type relayReply struct {
+	NotRunning bool   `json:"not_running,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type relayHistoryRequest struct {
+	OperationId string `json:"operation_id"`
+	FromSeq     uint64 `json:"from_seq"`
+}
+
+type relayHistoryReply struct {
+	Events []schema.OperationEvent `json:"events,omitempty"`
+	// more events are left for next request
+	More     bool   `json:"more,omitempty"`
+	Finished bool   `json:"finished,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func relaySubject(kind, serverId string) string {
+	config := cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId)
+	return fmt.Sprintf("operation-%s.%s.%s", kind, serverId, config.MessageQueue.SubjectSuffix)
+}
+
+/*
+ListenOperationRelay answers requests relayed by other servers about operations this server runs
+it has to be called once message queue is connected
+*/
+func ListenOperationRelay() error {
+	if _, err := messageQueue.Subscribe(relaySubject("cancel", cache.NodeId), func(msg *nats.Msg) {
+		respondRelay(msg, handleRelayedCancel(msg.Data))
+	}); err != nil {
+		return err
+	}
+	if _, err := messageQueue.Subscribe(relaySubject("progress-history", cache.NodeId), func(msg *nats.Msg) {
+		respondRelay(msg, handleRelayedProgressHistory(msg.Data))
+	}); err != nil {
+		return err
+	}
+	// nobody listens to progress of an operation unless its client is connected to other server
+	progressHub.setRelay(func(event schema.OperationEvent) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			log.Errorf("Failed to marshal progress event of operation %s due to error: %s", event.OperationId, err.Error())
+			return
+		}
+		if err := messageQueue.SendingMessage(relaySubject("progress", event.OperationId), string(data), cache.NodeId); err != nil {
+			log.Errorf("Failed to relay progress event of operation %s due to error: %s", event.OperationId, err.Error())
+		}
+	})
+	return nil
+}
+
+func respondRelay(msg *nats.Msg, reply interface{}) {
+	data, err := json.Marshal(reply)
+	if err != nil {
+		log.Errorf("Failed to marshal reply of relayed request due to error: %s", err.Error())
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		log.Errorf("Failed to reply relayed request due to error: %s", err.Error())
+	}
+}
+
+func handleRelayedCancel(data []byte) relayReply {
+	request := relayCancelRequest{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return relayReply{Error: fmt.Sprintf("Cancel request is broken: %s", err.Error())}
+	}
+	err := CancelOperation(request.OperationId, request.RunByUser)
+	switch {
+	case err == ErrOperationNotRunning:
+		return relayReply{NotRunning: true}
+	case err != nil:
+		return relayReply{Error: err.Error()}
+	}
+	return relayReply{}
+}
+
+// CancelOperationOnServer asks server running operation to cancel it, ErrOperationNotRunning meaning it does not run there either
+func CancelOperationOnServer(serverId, operationId, runByUser string) error {
+	data, err := json.Marshal(relayCancelRequest{OperationId: operationId, RunByUser: runByUser})
+	if err != nil {
+		return err
+	}
+	config := cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId)
+	replyData, err := messageQueue.SendingMessageAndWaitReply(relaySubject("cancel", serverId), cache.NodeId, serverId, operationId, config.MessageQueue, relayTimeout, data)
+	if err != nil {
+		return fmt.Errorf("%w: server %s %s", ErrServerNotAnswering, serverId, err.Error())
+	}
+	reply := relayReply{}
+	if err := json.Unmarshal(replyData, &reply); err != nil {
+		return err
+	}
+	if reply.NotRunning {
+		return ErrOperationNotRunning
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	return nil
+}
+
+func handleRelayedProgressHistory(data []byte) relayHistoryReply {
+	request := relayHistoryRequest{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return relayHistoryReply{Error: fmt.Sprintf("Progress request is broken: %s", err.Error())}
+	}
+	events, more, finished, found := progressHub.history(request.OperationId, request.FromSeq, relayHistoryMaxSize)
+	return relayHistoryReply{
+		Events: events,
+		More:   more,
+		// nothing kept and not running either, nothing is coming
+		Finished: finished || !found && !isOperationRunningHere(request.OperationId),
+	}
+}
+
+func relayedProgressHistory(serverId, operationId string, fromSeq uint64) (history []schema.OperationEvent, finished bool, err error) {
+	config := cache.GetCurrentCache().GetServerRuntimeConfig(cache.NodeId)
+	for {
+		data, err := json.Marshal(relayHistoryRequest{OperationId: operationId, FromSeq: fromSeq})
+		if err != nil {
+			return nil, false, err
+		}
+		replyData, err := messageQueue.SendingMessageAndWaitReply(relaySubject("progress-history", serverId), cache.NodeId, serverId, operationId, config.MessageQueue, relayTimeout, data)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: server %s %s", ErrServerNotAnswering, serverId, err.Error())
+		}
+		reply := relayHistoryReply{}
+		if err := json.Unmarshal(replyData, &reply); err != nil {
+			return nil, false, err
+		}
+		if reply.Error != "" {
+			return nil, false, errors.New(reply.Error)
+		}
+		history = append(history, reply.Events...)
+		if !reply.More || len(reply.Events) == 0 {
+			return history, reply.Finished, nil
+		}
+		fromSeq = reply.Events[len(reply.Events)-1].Seq + 1
+	}
+}
+
+/*
+relayedProgress holds events of an operation relayed by the server running it
+it listens before history is asked for so nothing is missed in between, events already in history are skipped
+*/
+
+type relayedProgress struct {
+	lock     sync.Mutex
+	closed   bool
+	live     chan schema.OperationEvent
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newRelayedProgress() *relayedProgress {
+	return &relayedProgress{
+		live:    make(chan schema.OperationEvent, operationSubscriberBuffer),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (relayed *relayedProgress) push(event schema.OperationEvent) {
+	relayed.lock.Lock()
+	defer relayed.lock.Unlock()
+	if relayed.closed {
+		return
+	}
+	select {
+	case relayed.live <- event:
+	default:
+		// same as local subscriber, a slow client reconnects with the last seq it got
+		relayed.closed = true
+		close(relayed.live)
+	}
+}
+
+func (relayed *relayedProgress) stop() {
+	relayed.stopOnce.Do(func() {
+		close(relayed.stopped)
+	})
+	relayed.lock.Lock()
+	defer relayed.lock.Unlock()
+	if !relayed.closed {
+		relayed.closed = true
+		close(relayed.live)
+	}
+}
+
+// forward passes events newer than seq after to events until operation is done
+func (relayed *relayedProgress) forward(after uint64, events chan<- schema.OperationEvent) {
+	defer close(events)
+	for event := range relayed.live {
+		if event.Seq <= after {
+			continue
+		}
+		select {
+		case events <- event:
+		case <-relayed.stopped:
+			return
+		}
+		if event.Type == constants.OperationEventOperationDone {
+			return
+		}
+	}
+}
+
+func subscribeRelayedProgress(operation schema.Operation, fromSeq uint64) ([]schema.OperationEvent, <-chan schema.OperationEvent, func(), error) {
+	relayed := newRelayedProgress()
+	subscription, err := messageQueue.Subscribe(relaySubject("progress", operation.Id), func(msg *nats.Msg) {
+		event := schema.OperationEvent{}
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Errorf("Failed to unmarshal relayed progress event of operation %s due to error: %s", operation.Id, err.Error())
+			return
+		}
+		relayed.push(event)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	unsubscribe := func() {
+		if err := subscription.Unsubscribe(); err != nil {
+			log.Debugf("Failed to stop listening progress of operation %s due to error: %s", operation.Id, err.Error())
+		}
+		relayed.stop()
+	}
+
+	history, finished, err := relayedProgressHistory(operation.Host, operation.Id, fromSeq)
+	if err != nil {
+		unsubscribe()
+		return nil, nil, nil, err
+	}
+	events := make(chan schema.OperationEvent, operationSubscriberBuffer)
+	if finished {
+		close(events)
+		return history, events, unsubscribe, nil
+	}
+	var after uint64
+	if fromSeq > 0 {
+		after = fromSeq - 1
+	}
+	if len(history) > 0 {
+		after = history[len(history)-1].Seq
+	}
+	go relayed.forward(after, events)
+	return history, events, unsubscribe, nil
+}
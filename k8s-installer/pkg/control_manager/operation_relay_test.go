@@ -0,0 +1,87 @@
+package control_manager
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestHandleRelayedCancel(t *testing.T) {
+	request, _ := json.Marshal(relayCancelRequest{OperationId: "relayed-operation", RunByUser: "admin"})
+	if reply := handleRelayedCancel(request); !reply.NotRunning {
+		t.Fatalf("expected operation not running here, got %+v", reply)
+	}
+
+	run := registerRunningOperation(&schema.Operation{Id: "relayed-operation"}, &sync.Mutex{})
+	defer run.unregister()
+	if reply := handleRelayedCancel(request); reply.NotRunning || reply.Error != "" {
+		t.Fatalf("expected operation cancelled, got %+v", reply)
+	}
+	if !run.isCancelled() {
+		t.Fatal("expected running operation to be cancelled")
+	}
+	if reply := handleRelayedCancel(request); reply.Error == "" {
+		t.Fatal("expected second cancel to be refused")
+	}
+	if reply := handleRelayedCancel([]byte("{")); reply.Error == "" {
+		t.Fatal("expected broken request to be refused")
+	}
+}
+
+func TestOperationProgressHistoryInParts(t *testing.T) {
+	hub := &operationProgressHub{operations: map[string]*operationProgress{}}
+	for _, msg := range []string{"a", "b", "c"} {
+		hub.publish(schema.OperationEvent{OperationId: "operation-test", Type: constants.OperationEventLog, Message: msg})
+	}
+	var history []schema.OperationEvent
+	fromSeq := uint64(2)
+	for {
+		// room for one event only
+		events, more, finished, found := hub.history("operation-test", fromSeq, 1)
+		if !found || finished || len(events) != 1 {
+			t.Fatalf("Expected one event of running operation, got %v found %v finished %v", events, found, finished)
+		}
+		history = append(history, events...)
+		if !more {
+			break
+		}
+		fromSeq = events[0].Seq + 1
+	}
+	if len(history) != 2 || history[0].Message != "b" || history[1].Message != "c" {
+		t.Fatalf("Expected events from seq 2, got %v", history)
+	}
+	if _, _, _, found := hub.history("operation-unknown", 0, relayHistoryMaxSize); found {
+		t.Fatal("Expected nothing found of unknown operation")
+	}
+}
+
+func TestRelayedProgressSkipsHistory(t *testing.T) {
+	relayed := newRelayedProgress()
+	defer relayed.stop()
+	// events relayed while history is being asked for
+	for seq := uint64(3); seq <= 5; seq++ {
+		relayed.push(schema.OperationEvent{Seq: seq, Type: constants.OperationEventLog})
+	}
+	relayed.push(schema.OperationEvent{Seq: 6, Type: constants.OperationEventOperationDone})
+	relayed.push(schema.OperationEvent{Seq: 7, Type: constants.OperationEventLog})
+
+	events := make(chan schema.OperationEvent, operationSubscriberBuffer)
+	go relayed.forward(4, events)
+	var seqs []uint64
+	for event := range events {
+		seqs = append(seqs, event.Seq)
+	}
+	if len(seqs) != 2 || seqs[0] != 5 || seqs[1] != 6 {
+		t.Fatalf("Expected events after history until operation is done, got %v", seqs)
+	}
+}
+
+func TestHandleRelayedProgressHistoryOfUnknownOperation(t *testing.T) {
+	request, _ := json.Marshal(relayHistoryRequest{OperationId: "operation-gone"})
+	if reply := handleRelayedProgressHistory(request); !reply.Finished || len(reply.Events) != 0 {
+		t.Fatalf("Expected operation neither kept nor running to be finished, got %+v", reply)
+	}
+}
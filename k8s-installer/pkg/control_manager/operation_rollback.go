@@ -0,0 +1,136 @@
+package control_manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	taskBreaker "k8s-installer/pkg/task_breaker"
+	"k8s-installer/pkg/tracing"
+	"k8s-installer/schema"
+)
+
+/*
+rollbackOperation undoes what a failed operation has done so far
+rollback node steps of every started step run one step after another in reverse order
+error and timeout of them are ignored so nodes get as clean as possible before they are released back to available pool
+it is called with lock of operation held, lock is released while rollback steps run since their node replies are handled with it
+*/
+func rollbackOperation(operation *schema.Operation, cluster *schema.Cluster, nodeCollection *schema.NodeInformationCollection, runtimeCache cache.ICache, startedSteps map[int]byte, lock *sync.Mutex) {
+	rollback := createRollbackOperation(operation, startedSteps)
+	recordDebugLogToOperationLog(operation, fmt.Sprintf("Rolling back operation %s with %d steps", operation.Id, len(rollback.Step)))
+
+	if len(rollback.Step) > 0 {
+		config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+		resourceServerURL := combineResourceServer(config)
+		// rollback is not registered as running operation so it is not able to be cancelled
+		run := &runningOperation{
+			operationId:   operation.Id,
+			operationLock: lock,
+			operation:     rollback,
+			cancelled:     make(chan struct{}),
+			nodeSteps:     map[string]string{},
+		}
+		stepReturnData := map[string]string{}
+		ctx, span := tracing.Start(tracing.Extract(context.Background(), operation.TraceContext), "rollback operation "+operation.Name)
+		lock.Unlock()
+		for index := 0; index < len(rollback.Step); index++ {
+			result := runStep(ctx, rollback, cluster, nodeCollection, index, stepReturnData, resourceServerURL, lock, run)
+			if result.errMsg != "" {
+				lock.Lock()
+				recordErrorLogToOperationLog(rollback, fmt.Sprintf("(ignore) %s", result.errMsg))
+				lock.Unlock()
+			}
+		}
+		lock.Lock()
+		span.End()
+		operation.Logs = append(operation.Logs, rollback.Logs...)
+	}
+
+	if err := releaseRolledBackNodes(operation, cluster, runtimeCache); err != nil {
+		recordErrorLogToOperationLog(operation, fmt.Sprintf("Failed to release nodes of operation %s after rollback due to error: %s", operation.Id, err.Error()))
+		return
+	}
+	recordDebugLogToOperationLog(operation, fmt.Sprintf("Operation %s is rolled back and nodes are released", operation.Id))
+}
+
+// createRollbackOperation collects rollback node steps of started steps, the latest started step is undone first
+func createRollbackOperation(operation *schema.Operation, startedSteps map[int]byte) *schema.Operation {
+	var indexes []int
+	for index := range startedSteps {
+		indexes = append(indexes, index)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	rollback := &schema.Operation{
+		Id:             operation.Id,
+		ClusterId:      operation.ClusterId,
+		Step:           map[int]*schema.Step{},
+		CompletedSteps: map[int]byte{},
+	}
+	for _, index := range indexes {
+		step, found := operation.Step[index]
+		if !found || len(step.RollbackNodeSteps) == 0 {
+			continue
+		}
+		rollback.Step[len(rollback.Step)] = &schema.Step{
+			Id:                       "rollback-" + step.Id,
+			Name:                     "Rollback" + step.Name,
+			NodeSteps:                step.RollbackNodeSteps,
+			OnStepDoneOrErrorHandler: taskBreaker.OnErrorIgnoreHandler{},
+			OnStepTimeOutHandler:     taskBreaker.OnTimeOutIgnoreHandler{},
+		}
+	}
+	return rollback
+}
+
+// releaseRolledBackNodes returns nodes which were going to join cluster back to available pool
+func releaseRolledBackNodes(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache) error {
+	switch operation.OperationType {
+	case constants.OperationTypeClusterSetupOrDestroy:
+		if err := saveMasterWorkerKubeStat(*cluster, ""); err != nil {
+			return err
+		}
+		if err := releaseClusterNode(runtimeCache, cluster); err != nil {
+			return err
+		}
+		if err := runtimeCache.RemoveAllClusterNodeRelationShip(cluster.ClusterId); err != nil {
+			return err
+		}
+		// so it is able to be re-created
+		cluster.Status = constants.ClusterStatusDestroyed
+	case constants.OperationTypeAddOrRemoveNodesToCluster:
+		var nodesToAdd []schema.ClusterNode
+		data, err := json.Marshal(&operation.RequestParameter.BodyParameters)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &nodesToAdd); err != nil {
+			return err
+		}
+		rolledBack := &schema.Cluster{Workers: nodesToAdd}
+		if err := saveMasterWorkerKubeStat(*rolledBack, ""); err != nil {
+			return err
+		}
+		if err := releaseClusterNode(runtimeCache, rolledBack); err != nil {
+			return err
+		}
+		var nodeIds []string
+		for _, node := range nodesToAdd {
+			nodeIds = append(nodeIds, node.NodeId)
+		}
+		if err := runtimeCache.RemoveClusterNodeRelationShip(cluster.ClusterId, nodeIds); err != nil {
+			return err
+		}
+		cluster.Status = constants.ClusterStatusRunning
+	default:
+		log.Warnf("Operation type %s does not support releasing nodes on rollback", operation.OperationType)
+		return nil
+	}
+	return saveClusterStatus(runtimeCache, operation.Id, *cluster)
+}
@@ -0,0 +1,30 @@
+package control_manager
+
+import (
+	"testing"
+
+	"k8s-installer/schema"
+)
+
+func TestCreateRollbackOperationReversesStartedSteps(t *testing.T) {
+	operation := &schema.Operation{
+		Id: "operation-test",
+		Step: map[int]*schema.Step{
+			0: {Id: "rename", Name: "Rename", RollbackNodeSteps: []schema.NodeStep{{Id: "restore"}}},
+			1: {Id: "basic", Name: "Basic"},
+			2: {Id: "cri", Name: "CRI", RollbackNodeSteps: []schema.NodeStep{{Id: "remove-cri"}}},
+			3: {Id: "join", Name: "Join", RollbackNodeSteps: []schema.NodeStep{{Id: "reset"}}},
+		},
+	}
+	// join is never started so it is not rolled back
+	rollback := createRollbackOperation(operation, map[int]byte{0: 0, 1: 0, 2: 0})
+	if len(rollback.Step) != 2 {
+		t.Fatalf("Expected 2 rollback steps, got %d", len(rollback.Step))
+	}
+	if rollback.Step[0].NodeSteps[0].Id != "remove-cri" || rollback.Step[1].NodeSteps[0].Id != "restore" {
+		t.Fatalf("Expected steps to be rolled back in reverse order, got %s then %s", rollback.Step[0].Name, rollback.Step[1].Name)
+	}
+	if rollback.Step[0].OnStepDoneOrErrorHandler == nil || rollback.Step[0].OnStepTimeOutHandler == nil {
+		t.Fatal("Expected rollback steps to ignore node error and timeout")
+	}
+}
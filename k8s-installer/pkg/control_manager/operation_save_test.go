@@ -0,0 +1,150 @@
+package control_manager
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+// racingOperations keeps one operation saved only at the version it is read at, GetOperation is left out on purpose
+type racingOperations struct {
+	cache.ICache
+	stored       schema.Operation
+	interleaving func(stored *schema.Operation)
+}
+
+func (r *racingOperations) GetOperationDoNotLoadStep(operationId string) (*schema.Operation, error) {
+	operation := r.stored
+	return &operation, nil
+}
+
+func (r *racingOperations) SaveOrUpdateOperationCollection(operationId string, operation schema.Operation) error {
+	if r.interleaving != nil {
+		r.interleaving(&r.stored)
+		r.stored.ResourceVersion++
+		r.interleaving = nil
+	}
+	if operation.ResourceVersion != r.stored.ResourceVersion {
+		return fmt.Errorf("/operations/%s: %w", operationId, cache.ErrConflict)
+	}
+	r.stored = operation
+	r.stored.ResourceVersion++
+	return nil
+}
+
+func (r *racingOperations) ClaimOperation(operationId, fromHost, toHost string) (bool, error) {
+	if r.stored.Host != fromHost {
+		return false, nil
+	}
+	r.stored.Host = toHost
+	r.stored.ResourceVersion++
+	return true, nil
+}
+
+func TestSaveOperationStatusKeepsChangesOfOthers(t *testing.T) {
+	operations := &racingOperations{
+		stored: schema.Operation{Id: "operation-1", Name: "install", Host: cache.NodeId, ResourceVersion: 3},
+		interleaving: func(stored *schema.Operation) {
+			stored.Name = "install renamed"
+		},
+	}
+	running := schema.Operation{Id: "operation-1", Name: "install", Host: cache.NodeId, Status: constants.StatusSuccessful, Logs: []string{"done"}, ResourceVersion: 3}
+	if err := saveOperationStatus(operations, running); err != nil {
+		t.Fatal(err)
+	}
+	if operations.stored.Status != constants.StatusSuccessful || len(operations.stored.Logs) != 1 {
+		t.Errorf("expected progress of operation to be saved got %+v", operations.stored)
+	}
+	if operations.stored.Name != "install renamed" {
+		t.Errorf("expected change saved in between to survive got name %s", operations.stored.Name)
+	}
+}
+
+func TestContinueOperationOfAnotherServer(t *testing.T) {
+	nodeId := cache.NodeId
+	cache.NodeId = "server-c"
+	defer func() { cache.NodeId = nodeId }()
+	operations := &racingOperations{stored: schema.Operation{Id: "operation-1", Host: "server-a", Status: constants.StatusError, ResourceVersion: 3}}
+	continued := operations.stored
+	if err := claimOperation(operations, &continued); err != nil {
+		t.Fatal(err)
+	}
+	continued.Status = constants.StatusProcessing
+	if err := saveOperationStatus(operations, continued); err != nil {
+		t.Fatal(err)
+	}
+	continued.Status = constants.StatusSuccessful
+	if err := saveOperationStatus(operations, continued); err != nil {
+		t.Errorf("expected operation claimed by this server to be saved got %v", err)
+	}
+	if operations.stored.Host != cache.NodeId || operations.stored.Status != constants.StatusSuccessful {
+		t.Errorf("expected operation to be run by this server got %+v", operations.stored)
+	}
+
+	// operation taken over by someone else is not saved by us any more
+	operations.stored.Host = "server-b"
+	if err := saveOperationStatus(operations, continued); err == nil {
+		t.Error("expected saving operation taken over by another server to be refused")
+	}
+}
+
+// racingClusters keeps one cluster saved only at the version it is read at, like racingOperations does
+type racingClusters struct {
+	cache.ICache
+	stored       schema.Cluster
+	interleaving func(stored *schema.Cluster)
+}
+
+func (r *racingClusters) GetCluster(clusterId string) (*schema.Cluster, error) {
+	cluster := r.stored
+	return &cluster, nil
+}
+
+func (r *racingClusters) SaveOrUpdateClusterCollection(clusterId string, cluster schema.Cluster) error {
+	if r.interleaving != nil {
+		r.interleaving(&r.stored)
+		r.stored.ResourceVersion++
+		r.interleaving = nil
+	}
+	if cluster.ResourceVersion != r.stored.ResourceVersion {
+		return fmt.Errorf("/clusters/%s: %w", clusterId, cache.ErrConflict)
+	}
+	r.stored = cluster
+	r.stored.ResourceVersion++
+	return nil
+}
+
+func TestSaveClusterStatusKeepsChangesOfOthers(t *testing.T) {
+	clusters := &racingClusters{
+		stored: schema.Cluster{ClusterId: "cluster-1", Description: "before", CurrentOperation: map[string]byte{"operation-1": 0}, ClusterOperationIDs: []string{"operation-1"}, ResourceVersion: 3},
+		interleaving: func(stored *schema.Cluster) {
+			stored.Description = "changed through api"
+			stored.Labels = map[string]string{"env": "prod"}
+			stored.CurrentOperation = map[string]byte{"operation-1": 0, "operation-2": 0}
+			stored.ClusterOperationIDs = append([]string{}, "operation-1", "operation-2")
+		},
+	}
+	// stale copy of the cluster operation-1 has been running with
+	running := clusters.stored
+	running.CurrentOperation = map[string]byte{}
+	running.Status = constants.ClusterStatusRunning
+	if err := saveClusterStatus(clusters, "operation-1", running); err != nil {
+		t.Fatal(err)
+	}
+	stored := clusters.stored
+	if stored.Status != constants.ClusterStatusRunning {
+		t.Errorf("expected status of operation to be saved got %s", stored.Status)
+	}
+	if stored.Description != "changed through api" || stored.Labels["env"] != "prod" {
+		t.Errorf("expected fields changed in between to survive got %+v", stored)
+	}
+	if _, found := stored.CurrentOperation["operation-1"]; found {
+		t.Errorf("expected finished operation to be removed from current operations got %v", stored.CurrentOperation)
+	}
+	if _, found := stored.CurrentOperation["operation-2"]; !found || len(stored.ClusterOperationIDs) != 2 {
+		t.Errorf("expected operation started in between to survive got %v %v", stored.CurrentOperation, stored.ClusterOperationIDs)
+	}
+}
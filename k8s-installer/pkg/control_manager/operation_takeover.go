@@ -0,0 +1,73 @@
+package control_manager
+
+import (
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+/*
+OperationTakeoverDaemon looks for operations left behind by dead servers and resumes them on this server
+only leader runs it so two servers never scan at the same time
+*/
+func OperationTakeoverDaemon(stopChan <-chan struct{}) {
+	runtimeCache := cache.GetCurrentCache()
+	serverConfig := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	ticker := time.NewTicker(time.Second * time.Duration(serverConfig.HighAvailability.TakeoverFrequency))
+	defer ticker.Stop()
+	for {
+		if liveServers, err := runtimeCache.GetLiveServers(); err != nil {
+			log.Errorf("Operation takeover: Failed to get live servers due to error: %s", err.Error())
+		} else {
+			TakeOverOperations(func(host string) bool {
+				_, alive := liveServers[host]
+				return !alive
+			})
+		}
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+TakeOverOperations resumes every running operation whose host is gone
+server also calls it once on start for operations it ran before restart, those look alive as it registers with the same id
+*/
+func TakeOverOperations(hostIsGone func(host string) bool) {
+	runtimeCache := cache.GetCurrentCache()
+	clusters, err := runtimeCache.GetClusterCollection()
+	if err != nil {
+		log.Errorf("Operation takeover: Failed to get cluster collection due to error: %s", err.Error())
+		return
+	}
+	for _, cluster := range clusters {
+		for operationId := range cluster.CurrentOperation {
+			operation, err := runtimeCache.GetOperation(operationId)
+			if err != nil {
+				log.Errorf("Operation takeover: Failed to get operation %s due to error: %s", operationId, err.Error())
+				continue
+			}
+			if operation == nil || !isLeftBehind(*operation, hostIsGone) {
+				continue
+			}
+			previousHost := operation.Host
+			if claimed, err := TakeOverOperation(cluster, *operation, previousHost); err != nil {
+				log.Errorf("Operation takeover: Failed to resume operation %s of server %s due to error: %s", operationId, previousHost, err.Error())
+			} else if claimed {
+				log.Infof("Operation takeover: Operation %s of server %s is resumed on server %s", operationId, previousHost, cache.NodeId)
+			}
+		}
+	}
+}
+
+// isLeftBehind tells operation still marked running while server running it is gone
+// operations saved before host was recorded are never taken over, nobody knows who runs them
+func isLeftBehind(operation schema.Operation, hostIsGone func(host string) bool) bool {
+	return operation.Status == constants.StatusProcessing && operation.Host != "" && hostIsGone(operation.Host)
+}
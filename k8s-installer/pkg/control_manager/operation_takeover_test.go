@@ -0,0 +1,32 @@
+package control_manager
+
+import (
+	"testing"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestIsLeftBehind(t *testing.T) {
+	liveServers := map[string]string{"server-alive": "2021-01-01T00:00:00Z"}
+	hostIsGone := func(host string) bool {
+		_, alive := liveServers[host]
+		return !alive
+	}
+	cases := []struct {
+		name      string
+		operation schema.Operation
+		expected  bool
+	}{
+		{"running on dead server", schema.Operation{Status: constants.StatusProcessing, Host: "server-dead"}, true},
+		{"running on live server", schema.Operation{Status: constants.StatusProcessing, Host: "server-alive"}, false},
+		{"failed on dead server", schema.Operation{Status: constants.StatusError, Host: "server-dead"}, false},
+		{"paused on dead server", schema.Operation{Status: constants.StatusPaused, Host: "server-dead"}, false},
+		{"running without host", schema.Operation{Status: constants.StatusProcessing}, false},
+	}
+	for _, c := range cases {
+		if got := isLeftBehind(c.operation, hostIsGone); got != c.expected {
+			t.Errorf("%s: expected %v got %v", c.name, c.expected, got)
+		}
+	}
+}
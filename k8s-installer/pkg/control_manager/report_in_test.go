@@ -0,0 +1,61 @@
+package control_manager
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+// racingNodes saves node only at the version it is read at, interleaving is a write landing right before save
+type racingNodes struct {
+	cache.IClusterNode
+	stored       schema.NodeInformation
+	interleaving func(stored *schema.NodeInformation)
+}
+
+func (r *racingNodes) GetNodeInformation(nodeId string) (*schema.NodeInformation, error) {
+	node := r.stored
+	return &node, nil
+}
+
+func (r *racingNodes) SaveOrUpdateNodeInformation(nodeId string, node schema.NodeInformation) error {
+	if r.interleaving != nil {
+		r.interleaving(&r.stored)
+		r.stored.ResourceVersion++
+		r.interleaving = nil
+	}
+	if node.ResourceVersion != r.stored.ResourceVersion {
+		return fmt.Errorf("/nodes/%s: %w", nodeId, cache.ErrConflict)
+	}
+	r.stored = node
+	r.stored.ResourceVersion++
+	return nil
+}
+
+func TestReportInDoesNotClobberOperation(t *testing.T) {
+	nodes := &racingNodes{
+		stored: schema.NodeInformation{Id: "node-1", Ipv4DefaultIp: "10.0.0.1", ResourceVersion: 3},
+		// operation takes node into cluster while agent report is being saved
+		interleaving: func(stored *schema.NodeInformation) {
+			stored.Role = constants.NodeRoleWorker
+			stored.BelongsToCluster = "cluster-1"
+			stored.KubeNodeStat = constants.UnAvailable
+		},
+	}
+	reported := schema.NodeInformation{Id: "node-1", Ipv4DefaultIp: "10.0.0.2", Role: 0, ResourceVersion: 1}
+	if _, err := cache.UpdateNodeInformation(nodes, "node-1", mergeReportedNode(reported)); err != nil {
+		t.Fatal(err)
+	}
+	if nodes.stored.Ipv4DefaultIp != "10.0.0.2" {
+		t.Errorf("expected reported stat to be saved got ip %s", nodes.stored.Ipv4DefaultIp)
+	}
+	if nodes.stored.Role != constants.NodeRoleWorker || nodes.stored.BelongsToCluster != "cluster-1" || nodes.stored.KubeNodeStat != constants.UnAvailable {
+		t.Errorf("expected change of operation to survive report in got %+v", nodes.stored)
+	}
+	if nodes.stored.ResourceVersion != 5 {
+		t.Errorf("expected node saved once after conflict got version %d", nodes.stored.ResourceVersion)
+	}
+}
@@ -0,0 +1,134 @@
+package control_manager
+
+import (
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/task_breaker"
+	"k8s-installer/schema"
+)
+
+// breaking down reads server config from cache, nothing is loaded from database
+func initBreakDownCache() {
+	cache.InitCacheRuntime(cacheConfig.CacheConfig{CacheRuntime: cacheConfig.RuntimeLocalRam}, etcdClientConfig.EtcdConfig{}, task_breaker.SetOperationStep)
+}
+
+func stepIndexByName(operation schema.Operation, name string) int {
+	for index, step := range operation.Step {
+		if step.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// readyAlong returns steps ready together with steps done
+func readyAlong(t *testing.T, operation schema.Operation, done ...int) map[int]byte {
+	graph, err := buildStepGraph(&operation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed := map[int]byte{}
+	for _, index := range done {
+		completed[index] = 0
+	}
+	ready := map[int]byte{}
+	for _, index := range graph.readySteps(completed, map[int]byte{}) {
+		ready[index] = 0
+	}
+	return ready
+}
+
+// waitsFor tells whether step at index is run only after step at dependency, directly or not
+func waitsFor(t *testing.T, operation schema.Operation, index, dependency int) bool {
+	graph, err := buildStepGraph(&operation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visited := map[int]bool{}
+	var visit func(index int) bool
+	visit = func(index int) bool {
+		if visited[index] {
+			return false
+		}
+		visited[index] = true
+		for _, depended := range graph.dependencies[index] {
+			if depended == dependency || visit(depended) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(index)
+}
+
+func TestClusterBreakDownStartsIndependentStepsTogether(t *testing.T) {
+	initBreakDownCache()
+	nodes := schema.NodeInformationCollection{
+		"master-1": {Id: "master-1", Ipv4DefaultIp: "10.0.0.1"},
+		"worker-1": {Id: "worker-1", Ipv4DefaultIp: "10.0.0.2"},
+		"lb-1":     {Id: "lb-1", Ipv4DefaultIp: "10.0.0.3"},
+	}
+	breakdown := task_breaker.ClusterTaskBreakDown{
+		Operation: schema.Operation{Id: "operation-1", ClusterId: "cluster-1"},
+		Cluster: schema.Cluster{
+			ClusterId:  "cluster-1",
+			Action:     constants.ActionCreate,
+			Masters:    []schema.ClusterNode{{NodeId: "master-1"}},
+			Workers:    []schema.ClusterNode{{NodeId: "worker-1"}},
+			ExternalLB: schema.ExternalLB{NodeIds: []schema.ClusterNode{{NodeId: "lb-1"}}},
+			CNI:        schema.CNI{CNIType: constants.CNITypeCalico, PodV4CIDR: "10.244.0.0/16"},
+		},
+		NodeCollectionList: nodes,
+	}
+	operation, err := breakdown.BreakDownTask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic, cri, vip, lb := stepIndexByName(operation, "BasicSetup"), stepIndexByName(operation, "InstallOrRemoveCRI"), stepIndexByName(operation, "AddOrRemoveLocalVip"), stepIndexByName(operation, "StepSetupExternalLB")
+	ready := readyAlong(t, operation, basic)
+	for name, index := range map[string]int{"cri": cri, "local vip": vip, "external lb": lb} {
+		if _, found := ready[index]; !found {
+			t.Errorf("expected %s to be ready along with cri once basic setup is done got %v", name, ready)
+		}
+	}
+
+	// joining workers still waits for control plane as well as local vip
+	join := stepIndexByName(operation, "JoinOrDestroyWorkNode")
+	for name, index := range map[string]int{"local vip": vip, "control plane": stepIndexByName(operation, "InitOrDestroyFirstControlPlane"), "cri": cri} {
+		if !waitsFor(t, operation, join, index) {
+			t.Errorf("expected joining workers to wait for %s", name)
+		}
+	}
+}
+
+func TestClusterNodeBreakDownStartsIndependentStepsTogether(t *testing.T) {
+	initBreakDownCache()
+	nodes := schema.NodeInformationCollection{
+		"master-1": {Id: "master-1", Ipv4DefaultIp: "10.0.0.1", Role: constants.NodeRoleMaster},
+		"worker-1": {Id: "worker-1", Ipv4DefaultIp: "10.0.0.2", Role: constants.NodeRoleWorker},
+	}
+	breakdown := task_breaker.ClusterNodeTaskBreakDown{
+		Operation:          schema.Operation{Id: "operation-1", ClusterId: "cluster-1"},
+		Cluster:            schema.Cluster{ClusterId: "cluster-1", Masters: []schema.ClusterNode{{NodeId: "master-1"}}},
+		NodesToAddOrRemove: []schema.ClusterNode{{NodeId: "worker-1"}},
+		NodeCollectionList: nodes,
+		Action:             constants.ActionCreate,
+	}
+	operation, err := breakdown.BreakDownTask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready := readyAlong(t, operation)
+	for _, name := range []string{"GetJoinString", "InstallOrRemoveCRI", "AddOrRemoveLocalVip"} {
+		if _, found := ready[stepIndexByName(operation, name)]; !found {
+			t.Errorf("expected %s to be ready as operation starts got %v", name, ready)
+		}
+	}
+	if _, found := ready[stepIndexByName(operation, "JoinOrDestroyWorkNode")]; found {
+		t.Error("expected joining workers to wait for cri and local vip")
+	}
+}
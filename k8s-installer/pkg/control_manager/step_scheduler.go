@@ -0,0 +1,212 @@
+package control_manager
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s-installer/schema"
+)
+
+/*
+stepGraph describes which step has to wait for which steps
+all steps in one operation are addressed by their index in operation.Step
+*/
+
+type stepGraph struct {
+	// index of step -> index of steps it depends on
+	dependencies map[int][]int
+	// sorted step index for stable scheduling order
+	order []int
+}
+
+func buildStepGraph(operation *schema.Operation) (*stepGraph, error) {
+	graph := &stepGraph{
+		dependencies: map[int][]int{},
+	}
+	for index := range operation.Step {
+		graph.order = append(graph.order, index)
+	}
+	sort.Ints(graph.order)
+
+	// a shared id leaves it unclear which step depends on which, so it is refused rather than guessed
+	idToIndex := map[string]int{}
+	for _, index := range graph.order {
+		step := operation.Step[index]
+		if step.Id == "" {
+			continue
+		}
+		if other, found := idToIndex[step.Id]; found {
+			return nil, fmt.Errorf("Step %s and step %s share id %s in operation %s ", operation.Step[other].Name, step.Name, step.Id, operation.Id)
+		}
+		idToIndex[step.Id] = index
+	}
+
+	for position, index := range graph.order {
+		step := operation.Step[index]
+		if step.DependsOn == nil {
+			// fall back to sequential behavior
+			if position > 0 {
+				graph.dependencies[index] = []int{graph.order[position-1]}
+			}
+			continue
+		}
+		for _, stepId := range step.DependsOn {
+			dependencyIndex, found := idToIndex[stepId]
+			if !found {
+				return nil, fmt.Errorf("Step %s depends on step %s which is not found in operation %s ", step.Name, stepId, operation.Id)
+			}
+			if dependencyIndex != index {
+				graph.dependencies[index] = append(graph.dependencies[index], dependencyIndex)
+			}
+		}
+	}
+
+	if err := graph.checkCycle(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+func (graph *stepGraph) checkCycle() error {
+	// 0 = not visited 1 = visiting 2 = done
+	stat := map[int]int{}
+	var visit func(index int) error
+	visit = func(index int) error {
+		switch stat[index] {
+		case 1:
+			return fmt.Errorf("Step with index %d is part of a dependency cycle ", index)
+		case 2:
+			return nil
+		}
+		stat[index] = 1
+		for _, dependency := range graph.dependencies[index] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		stat[index] = 2
+		return nil
+	}
+	for _, index := range graph.order {
+		if err := visit(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readySteps returns steps which are not done or running yet and all their dependencies are done
+func (graph *stepGraph) readySteps(completed map[int]byte, running map[int]byte) []int {
+	var ready []int
+	for _, index := range graph.order {
+		if _, done := completed[index]; done {
+			continue
+		}
+		if _, isRunning := running[index]; isRunning {
+			continue
+		}
+		satisfied := true
+		for _, dependency := range graph.dependencies[index] {
+			if _, done := completed[dependency]; !done {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			ready = append(ready, index)
+		}
+	}
+	return ready
+}
+
+type stepResult struct {
+	stepIndex int
+	// empty meaning step is done
+	errMsg string
+	// step is done and operation should pause after it
+	paused bool
+}
+
+/*
+stepScheduler runs every step whose dependencies are satisfied concurrently
+lock guards operation, cluster and step return data which are shared by all running steps
+and the message queue callbacks of them
+*/
+
+type stepScheduler struct {
+	graph         *stepGraph
+	operation     *schema.Operation
+	maxConcurrent int
+	lock          *sync.Mutex
+	runStep       func(stepIndex int) stepResult
+	onStepDone    func(stepIndex int)
+	// closed when operation is cancelled, no more step will be started after that
+	cancelled <-chan struct{}
+	// set by run once a done step asks operation to pause
+	paused bool
+}
+
+func (scheduler *stepScheduler) isCancelled() bool {
+	if scheduler.cancelled == nil {
+		return false
+	}
+	select {
+	case <-scheduler.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// run returns error message of the first failed step. empty meaning all steps are done or operation is paused
+func (scheduler *stepScheduler) run() string {
+	results := make(chan stepResult)
+	running := map[int]byte{}
+	errMsg := ""
+	for {
+		if errMsg == "" && scheduler.isCancelled() {
+			errMsg = fmt.Sprintf("Operation %s is cancelled with %d of %d steps done", scheduler.operation.Id, len(scheduler.operation.CompletedSteps), len(scheduler.graph.order))
+		}
+		if errMsg == "" && !scheduler.paused {
+			scheduler.lock.Lock()
+			ready := scheduler.graph.readySteps(scheduler.operation.CompletedSteps, running)
+			scheduler.lock.Unlock()
+			for _, index := range ready {
+				if scheduler.maxConcurrent > 0 && len(running) >= scheduler.maxConcurrent {
+					break
+				}
+				running[index] = 0
+				go func(index int) {
+					results <- scheduler.runStep(index)
+				}(index)
+			}
+		}
+		if len(running) == 0 {
+			break
+		}
+		// once a step failed or asked to pause no more step will be started
+		// but wait for the running ones to finish so operation stat stay consistent
+		result := <-results
+		delete(running, result.stepIndex)
+		if result.errMsg != "" {
+			if errMsg == "" {
+				errMsg = result.errMsg
+			}
+			continue
+		}
+		scheduler.lock.Lock()
+		scheduler.operation.CompletedSteps[result.stepIndex] = 0
+		scheduler.lock.Unlock()
+		if result.paused {
+			scheduler.paused = true
+		}
+		if scheduler.onStepDone != nil {
+			scheduler.onStepDone(result.stepIndex)
+		}
+	}
+	if errMsg == "" && !scheduler.paused && len(scheduler.operation.CompletedSteps) < len(scheduler.graph.order) {
+		errMsg = fmt.Sprintf("Operation %s stops with %d of %d steps done because remaining steps are never able to run", scheduler.operation.Id, len(scheduler.operation.CompletedSteps), len(scheduler.graph.order))
+	}
+	return errMsg
+}
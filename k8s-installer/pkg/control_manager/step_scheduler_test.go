@@ -0,0 +1,208 @@
+package control_manager
+
+import (
+	"sync"
+	"testing"
+
+	"k8s-installer/schema"
+)
+
+func TestBuildStepGraphSequentialByDefault(t *testing.T) {
+	operation := &schema.Operation{
+		Id: "operation-test",
+		Step: map[int]*schema.Step{
+			0: {Id: "a"},
+			1: {Id: "b"},
+			2: {Id: "c"},
+		},
+	}
+	graph, err := buildStepGraph(operation)
+	if err != nil {
+		t.Fatalf("Failed to build step graph: %v", err)
+	}
+	ready := graph.readySteps(map[int]byte{}, map[int]byte{})
+	if len(ready) != 1 || ready[0] != 0 {
+		t.Fatalf("Expected only step 0 to be ready, got %v", ready)
+	}
+	ready = graph.readySteps(map[int]byte{0: 0}, map[int]byte{})
+	if len(ready) != 1 || ready[0] != 1 {
+		t.Fatalf("Expected only step 1 to be ready, got %v", ready)
+	}
+}
+
+func TestBuildStepGraphWithDependencies(t *testing.T) {
+	operation := &schema.Operation{
+		Id: "operation-test",
+		Step: map[int]*schema.Step{
+			0: {Id: "basic"},
+			1: {Id: "promtail", DependsOn: []string{"basic"}},
+			2: {Id: "cri", DependsOn: []string{"basic"}},
+			3: {Id: "init"},
+		},
+	}
+	graph, err := buildStepGraph(operation)
+	if err != nil {
+		t.Fatalf("Failed to build step graph: %v", err)
+	}
+	ready := graph.readySteps(map[int]byte{0: 0}, map[int]byte{})
+	if len(ready) != 2 || ready[0] != 1 || ready[1] != 2 {
+		t.Fatalf("Expected step 1 and 2 to be ready, got %v", ready)
+	}
+	ready = graph.readySteps(map[int]byte{0: 0, 2: 0}, map[int]byte{1: 0})
+	if len(ready) != 1 || ready[0] != 3 {
+		t.Fatalf("Expected step 3 to be ready, got %v", ready)
+	}
+}
+
+func TestBuildStepGraphRejectsCycleAndUnknownStep(t *testing.T) {
+	cycle := &schema.Operation{
+		Step: map[int]*schema.Step{
+			0: {Id: "a", DependsOn: []string{"b"}},
+			1: {Id: "b", DependsOn: []string{"a"}},
+		},
+	}
+	if _, err := buildStepGraph(cycle); err == nil {
+		t.Fatal("Expected dependency cycle to be rejected")
+	}
+	unknown := &schema.Operation{
+		Step: map[int]*schema.Step{
+			0: {Id: "a", DependsOn: []string{"not-exists"}},
+		},
+	}
+	if _, err := buildStepGraph(unknown); err == nil {
+		t.Fatal("Expected unknown dependency to be rejected")
+	}
+	shared := &schema.Operation{
+		Step: map[int]*schema.Step{
+			0: {Id: "a"},
+			1: {Id: "a"},
+			2: {Id: "c", DependsOn: []string{"a"}},
+		},
+	}
+	if _, err := buildStepGraph(shared); err == nil {
+		t.Fatal("Expected steps sharing an id to be rejected")
+	}
+	// steps without id are never depended on so they may be many
+	unnamed := &schema.Operation{
+		Step: map[int]*schema.Step{
+			0: {},
+			1: {},
+		},
+	}
+	if _, err := buildStepGraph(unnamed); err != nil {
+		t.Fatalf("Expected steps without id to be accepted, got %v", err)
+	}
+}
+
+func TestStepSchedulerStopsAfterFailure(t *testing.T) {
+	operation := &schema.Operation{
+		Id:             "operation-test",
+		CompletedSteps: map[int]byte{},
+		Step: map[int]*schema.Step{
+			0: {Id: "a", DependsOn: []string{}},
+			1: {Id: "b", DependsOn: []string{}},
+			2: {Id: "c", DependsOn: []string{"a", "b"}},
+		},
+	}
+	graph, err := buildStepGraph(operation)
+	if err != nil {
+		t.Fatalf("Failed to build step graph: %v", err)
+	}
+	var ran sync.Map
+	scheduler := stepScheduler{
+		graph:     graph,
+		operation: operation,
+		lock:      &sync.Mutex{},
+		runStep: func(stepIndex int) stepResult {
+			ran.Store(stepIndex, true)
+			if stepIndex == 1 {
+				return stepResult{stepIndex: stepIndex, errMsg: "failed"}
+			}
+			return stepResult{stepIndex: stepIndex}
+		},
+	}
+	if errMsg := scheduler.run(); errMsg != "failed" {
+		t.Fatalf("Expected scheduler to report failure, got %q", errMsg)
+	}
+	if _, found := ran.Load(2); found {
+		t.Fatal("Step 2 should not run when one of its dependencies failed")
+	}
+	if _, done := operation.CompletedSteps[0]; !done {
+		t.Fatal("Step 0 should be recorded as completed")
+	}
+}
+
+func TestStepSchedulerStopsAfterCancel(t *testing.T) {
+	operation := &schema.Operation{
+		Id:             "operation-test",
+		CompletedSteps: map[int]byte{},
+		Step: map[int]*schema.Step{
+			0: {Id: "a"},
+			1: {Id: "b"},
+		},
+	}
+	graph, err := buildStepGraph(operation)
+	if err != nil {
+		t.Fatalf("Failed to build step graph: %v", err)
+	}
+	cancelled := make(chan struct{})
+	var ran sync.Map
+	scheduler := stepScheduler{
+		graph:     graph,
+		operation: operation,
+		lock:      &sync.Mutex{},
+		cancelled: cancelled,
+		runStep: func(stepIndex int) stepResult {
+			ran.Store(stepIndex, true)
+			// cancelled while first step is running
+			close(cancelled)
+			return stepResult{stepIndex: stepIndex}
+		},
+	}
+	if errMsg := scheduler.run(); errMsg == "" {
+		t.Fatal("Expected scheduler to report cancellation")
+	}
+	if _, found := ran.Load(1); found {
+		t.Fatal("Step 1 should not run after operation is cancelled")
+	}
+}
+
+func TestStepSchedulerPausesAndResumes(t *testing.T) {
+	operation := &schema.Operation{
+		Id:             "operation-test",
+		CompletedSteps: map[int]byte{},
+		Step: map[int]*schema.Step{
+			0: {Id: "batch-0"},
+			1: {Id: "gate-0"},
+			2: {Id: "batch-1"},
+		},
+	}
+	graph, err := buildStepGraph(operation)
+	if err != nil {
+		t.Fatalf("Failed to build step graph: %v", err)
+	}
+	var ran sync.Map
+	runStep := func(stepIndex int) stepResult {
+		ran.Store(stepIndex, true)
+		return stepResult{stepIndex: stepIndex, paused: stepIndex == 1}
+	}
+	scheduler := stepScheduler{graph: graph, operation: operation, lock: &sync.Mutex{}, runStep: runStep}
+	if errMsg := scheduler.run(); errMsg != "" || !scheduler.paused {
+		t.Fatalf("Expected scheduler to pause without error, got %q paused %v", errMsg, scheduler.paused)
+	}
+	if _, found := ran.Load(2); found {
+		t.Fatal("Step 2 should not run after operation is paused")
+	}
+	if _, done := operation.CompletedSteps[1]; !done {
+		t.Fatal("Step which paused operation should be recorded as completed")
+	}
+
+	ran = sync.Map{}
+	resumed := stepScheduler{graph: graph, operation: operation, lock: &sync.Mutex{}, runStep: runStep}
+	if errMsg := resumed.run(); errMsg != "" || resumed.paused {
+		t.Fatalf("Expected resumed operation to finish, got %q paused %v", errMsg, resumed.paused)
+	}
+	if _, found := ran.Load(1); found {
+		t.Fatal("Step which paused operation should not run again on resume")
+	}
+}
@@ -1,10 +1,12 @@
 package control_manager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"k8s-installer/pkg/cache"
@@ -12,15 +14,18 @@ import (
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/log"
 	messageQueue "k8s-installer/pkg/message_queue/nats"
+	"k8s-installer/pkg/metrics"
 	mqHelper "k8s-installer/pkg/server/message_queue"
 	taskBreaker "k8s-installer/pkg/task_breaker"
+	"k8s-installer/pkg/tracing"
 	"k8s-installer/schema"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func createTaskCallBackHandler(step *schema.Step, taskDoneSignal chan int, returnData map[string]string, stepIndex int, runtimeCache cache.ICache, cluster *schema.Cluster, operation *schema.Operation, nodeCollection *schema.NodeInformationCollection) func(msg *nats.Msg) error {
+func createTaskCallBackHandler(step *schema.Step, taskDoneSignal chan int, returnData map[string]string, stepIndex int, runtimeCache cache.ICache, cluster *schema.Cluster, operation *schema.Operation, nodeCollection *schema.NodeInformationCollection, lock sync.Locker, retry *nodeStepRetry) func(msg *nats.Msg) error {
 	// closure to hold input step and chan object to future operation
 	return func(msg *nats.Msg) error {
 		reply := schema.QueueReply{}
@@ -30,18 +35,25 @@ func createTaskCallBackHandler(step *schema.Step, taskDoneSignal chan int, retur
 		} else {
 			log.Debugf("Got reply message from node %s with operation id %s with message %v", reply.NodeId, reply.OperationId, reply.Message)
 		}
+		// other steps may running at the same time
+		lock.Lock()
+		defer lock.Unlock()
+		publishNodeReplyEvent(operation, step, stepIndex, reply)
+		if reply.Stat != constants.StatusSuccessful && retry.retry(reply.NodeStepId, constants.RetryOnError, reply.Message) {
+			return nil
+		}
 		if step.OnStepDoneOrErrorHandler == nil {
 			log.Debugf("Step %d on done or error handler is not set fall back to default handler onErrorAbort", stepIndex)
 			OnStepErrorHandler := taskBreaker.OnErrorAbortHandler{}
 			OnStepErrorHandler.OnStepDoneOrErrorHandler(reply, step, taskDoneSignal, returnData, cluster, operation, nodeCollection, func(cluster schema.Cluster) error {
-				return saveClusterStatus(runtimeCache, cluster)
+				return saveClusterStatus(runtimeCache, operation.Id, cluster)
 			}, func(operation schema.Operation) error {
 				return saveOperationStatus(runtimeCache, operation)
 			})
 		} else {
 			log.Debugf("Step %d on done or error handler is set use it", stepIndex)
 			step.OnStepDoneOrErrorHandler.OnStepDoneOrErrorHandler(reply, step, taskDoneSignal, returnData, cluster, operation, nodeCollection, func(cluster schema.Cluster) error {
-				return saveClusterStatus(runtimeCache, cluster)
+				return saveClusterStatus(runtimeCache, operation.Id, cluster)
 			}, func(operation schema.Operation) error {
 				return saveOperationStatus(runtimeCache, operation)
 			})
@@ -50,21 +62,26 @@ func createTaskCallBackHandler(step *schema.Step, taskDoneSignal chan int, retur
 	}
 }
 
-func createTaskTimeOutHandler(step *schema.Step, taskTimeoutSignal chan string, taskDoneSignal chan int, abortWhenCount int, stepIndex int, runtimeCache cache.ICache, cluster *schema.Cluster, operation *schema.Operation, nodeCollection *schema.NodeInformationCollection) func(nodeId string, nodeStepId string) {
+func createTaskTimeOutHandler(step *schema.Step, taskTimeoutSignal chan string, taskDoneSignal chan int, abortWhenCount int, stepIndex int, runtimeCache cache.ICache, cluster *schema.Cluster, operation *schema.Operation, nodeCollection *schema.NodeInformationCollection, lock sync.Locker, retry *nodeStepRetry) func(nodeId string, nodeStepId string) {
 	return func(nodeId string, nodeStepId string) {
+		lock.Lock()
+		defer lock.Unlock()
+		if retry.retry(nodeStepId, constants.RetryOnTimeout, "") {
+			return
+		}
 		log.Errorf("Step %d running into timeout stat. Leave decision to step time out handler", stepIndex)
 		if step.OnStepTimeOutHandler == nil {
 			log.Debugf("Step %d on timeout handler not set fall back to default handler onTimeoutAbort", stepIndex)
 			onTimeoutAbortHandler := taskBreaker.OnTimeOutAbortHandler{}
 			onTimeoutAbortHandler.OnStepTimeOutHandler(step, abortWhenCount, taskTimeoutSignal, cluster, operation, nodeCollection, func(cluster schema.Cluster) error {
-				return saveClusterStatus(runtimeCache, cluster)
+				return saveClusterStatus(runtimeCache, operation.Id, cluster)
 			}, func(operation schema.Operation) error {
 				return saveOperationStatus(runtimeCache, operation)
 			}, nodeStepId)
 		} else {
 			log.Debugf("Step %d on timeout handler is set use it", stepIndex)
 			step.OnStepTimeOutHandler.OnStepTimeOutHandler(step, abortWhenCount, taskTimeoutSignal, taskDoneSignal, cluster, operation, nodeCollection, func(cluster schema.Cluster) error {
-				return saveClusterStatus(runtimeCache, cluster)
+				return saveClusterStatus(runtimeCache, operation.Id, cluster)
 			}, func(operation schema.Operation) error {
 				return saveOperationStatus(runtimeCache, operation)
 			}, nodeStepId)
@@ -97,10 +114,13 @@ func DoSingleTask(operation *schema.Operation, cluster *schema.Cluster, metaData
 	// always send one master that handler this task as resource server
 	resourceServerURL := combineResourceServer(config)
 
+	ctx, span := startOperationSpan(operation)
+	defer endOperationSpan(span, operation)
+
 	if metaData == nil {
 		metaData = map[string]string{}
 	}
-	msgData, err := createMsgBody(operation.Id, resourceServerURL, operation.Step[0].Id, operation.Step[0].NodeSteps[0].Tasks[0], *cluster, metaData)
+	msgData, err := createMsgBody(operation.Id, resourceServerURL, operation.Step[0].Id, operation.Step[0].NodeSteps[0].Tasks[0], *cluster, metaData, tracing.Inject(ctx))
 	if err != nil {
 		SetOperationToFailedStat(operation, runtimeCache, fmt.Sprintf("Failed to marshal data for node task %d of step %d for operation %s due to error %s", 0, 0, operation.Id, err.Error()))
 		return nil, err
@@ -145,6 +165,7 @@ func DoSingleTask(operation *schema.Operation, cluster *schema.Cluster, metaData
 		log.Errorf("Failed to record error data on all step is done due to error %s", err.Error())
 		return nil, err
 	}
+	finishOperationProgress(operation)
 	return result, nil
 }
 
@@ -160,6 +181,8 @@ func doTask(operation *schema.Operation, cluster *schema.Cluster, nodeCollection
 
 	// ensure to record which handle this operation
 	operation.Host = cache.NodeId
+	ctx, span := startOperationSpan(operation)
+	defer endOperationSpan(span, operation)
 
 	// always send one master that handler this task as resource server
 	resourceServerURL := combineResourceServer(config)
@@ -173,93 +196,87 @@ func doTask(operation *schema.Operation, cluster *schema.Cluster, nodeCollection
 	if len(operation.PreStepReturnData) > 0 {
 		stepReturnData = operation.PreStepReturnData
 	}
+	operation.PreStepReturnData = stepReturnData
+	if operation.CompletedSteps == nil {
+		operation.CompletedSteps = map[int]byte{}
+	}
 	recordDebugLogToOperationLog(operation, fmt.Sprintf("Operation %s task starts", operation.Id))
-	for stepIndex := operation.CurrentStep; stepIndex < len(operation.Step); stepIndex++ {
-		if operation.Step[stepIndex].WaitBeforeRun > 0 {
-			log.Warnf("Step %d says we should wait for %d seconds before run. So we wait :(", stepIndex, operation.Step[stepIndex].WaitBeforeRun)
-			time.Sleep(time.Duration(operation.Step[stepIndex].WaitBeforeRun) * time.Second)
-		}
-		taskDoneSignal := make(chan int)
-		taskTimeOutSignal := make(chan string)
-		// use closure to create a message handler
-		taskDoneHandler := createTaskCallBackHandler(operation.Step[stepIndex], taskDoneSignal, stepReturnData, stepIndex, runtimeCache, cluster, operation, nodeCollection)
-		// use closure to create a timeout handler
-		taskTimeOutSignalHandler := createTaskTimeOutHandler(operation.Step[stepIndex], taskTimeOutSignal, taskDoneSignal, 1, stepIndex, runtimeCache, cluster, operation, nodeCollection)
-		stepErrMsg := fmt.Sprintf("Task %s with index %d result in error state...abort!!!!", operation.Step[stepIndex].Name, stepIndex)
-		operation.CurrentStep = stepIndex
-		operation.PreStepReturnData = stepReturnData
-		if operation.Step[stepIndex].DynamicNodeSteps != nil {
-			log.Debugf("Step %s contains dynamic steps bring it on then", operation.Step[stepIndex].Name)
-			dynamicSteps, err := operation.Step[stepIndex].DynamicNodeSteps(stepReturnData, *cluster, *operation)
-			if err != nil {
-				if operation.Step[stepIndex].IgnoreDynamicStepCreationError {
-					msg := fmt.Sprintf("(ignore) Failed to create dynamic steps from step %s due to error: %s", operation.Step[stepIndex].Name, err.Error())
-					recordDebugLogToOperationLog(operation, msg)
-				} else {
-					handlerStepError(operation, cluster, runtimeCache, operation.Step[stepIndex], stepIndex, taskErrorHandler, stepErrMsg)
-					return
-				}
-			}
-			operation.Step[stepIndex].NodeSteps = append(operation.Step[stepIndex].NodeSteps, dynamicSteps...)
-		}
-		if len(operation.Step[stepIndex].NodeSteps) == 0 {
-			recordDebugLogToOperationLog(operation, fmt.Sprintf("There is no node steps in step %d skip it!", stepIndex))
-			continue
-		}
-		for nodeTaskIndex, nodeTask := range operation.Step[stepIndex].NodeSteps {
-			msgData, err := createMsgBody(operation.Id, resourceServerURL, nodeTask.Id, nodeTask.Tasks[0], *cluster, stepReturnData)
-			if err != nil {
-				logString := fmt.Sprintf("Failed to marshal data for node task %d of step %d for operation %s due to error %s", nodeTaskIndex, stepIndex, operation.Id, err.Error())
-				handlerStepError(operation, cluster, runtimeCache, operation.Step[stepIndex], stepIndex, taskErrorHandler, logString)
-				return
-			}
 
-			nodeData, foundNode := (*nodeCollection)[nodeTask.NodeID]
-			if !foundNode {
-				log.Error("Unable to find node information with id", nodeTask.NodeID)
-				taskDoneSignal <- 1
-			} else {
-				// send message to all node task to do actual job such as set up cri
-				logMsg := fmt.Sprintf("Processing step %s by send a message for task %s to target node %s and wait %d seconds for reply", operation.Step[stepIndex].Name, nodeTask.Tasks[0].GetTaskType(), nodeData.Ipv4DefaultIp+fmt.Sprintf("(proxy:%s)", nodeData.ProxyIpv4CIDR), nodeTask.Tasks[0].GetTaskTimeOut())
-				recordDebugLogToOperationLog(operation, logMsg)
-				go messageQueue.SendingMessageWithReply(mqHelper.GeneratorNodeSubscribe(nodeData, config.MessageQueue.SubjectSuffix),
-					cache.NodeId,
-					nodeTask.NodeID,
-					nodeTask.Id,
-					config.MessageQueue,
-					time.Duration(nodeTask.Tasks[0].GetTaskTimeOut())*time.Second,
-					[]byte(msgData),
-					taskDoneHandler,
-					taskTimeOutSignalHandler)
+	// operation, cluster and step return data are shared by all running steps
+	lock := &sync.Mutex{}
+	graph, errGraph := buildStepGraph(operation)
+	if errGraph != nil {
+		handlerStepError(operation, cluster, nodeCollection, runtimeCache, lock, nil, taskErrorHandler, fmt.Sprintf("Unable to schedule steps of operation %s due to error: %s", operation.Id, errGraph.Error()))
+		return
+	}
+	run := registerRunningOperation(operation, lock)
+	defer run.unregister()
+	// steps which are ever started, they are the ones to roll back once operation fails
+	startedSteps := map[int]byte{}
+	scheduler := stepScheduler{
+		graph:         graph,
+		operation:     operation,
+		maxConcurrent: config.MaxConcurrentSteps,
+		lock:          lock,
+		cancelled:     run.cancelled,
+		runStep: func(stepIndex int) stepResult {
+			lock.Lock()
+			startedSteps[stepIndex] = 0
+			lock.Unlock()
+			publishStepEvent(operation, stepIndex, constants.OperationEventStepStart, "")
+			start := time.Now()
+			stepCtx, stepSpan := tracing.Start(ctx, "step "+operation.Step[stepIndex].Name, attribute.Int("step.index", stepIndex))
+			result := runStep(stepCtx, operation, cluster, nodeCollection, stepIndex, stepReturnData, resourceServerURL, lock, run)
+			stepStatus := constants.StatusSuccessful
+			var stepErr error
+			if result.errMsg != "" {
+				stepStatus = constants.StatusError
+				stepErr = errors.New(result.errMsg)
 			}
-		}
-		recordDebugLogToOperationLog(operation, fmt.Sprintf("Waiting for node reply for task %s with index %d", operation.Step[stepIndex].Name, stepIndex))
-		select {
-		case doneOrError := <-taskDoneSignal:
-			if doneOrError == 0 {
-				if stepIndex < len(operation.Step)-1 {
-					recordDebugLogToOperationLog(operation, fmt.Sprintf("All node task has been done. Processing next step: %s", operation.Step[stepIndex+1].Name))
+			metrics.ObserveStep(operation.Step[stepIndex].Name, stepStatus, time.Since(start))
+			tracing.End(stepSpan, stepErr)
+			if result.errMsg == "" {
+				if step := operation.Step[stepIndex]; step.PauseGate != nil {
+					lock.Lock()
+					result.paused = step.PauseGate(*operation)
+					lock.Unlock()
 				}
-				if config.DisableLazyOperationLog {
-					if err := saveOperationStatus(runtimeCache, *operation); err != nil {
-						log.Errorf("Failed to record error data on task failure due to error %s", err.Error())
-						handlerStepError(operation, cluster, runtimeCache, operation.Step[stepIndex], stepIndex, taskErrorHandler, stepErrMsg)
-						return
-					}
-				}
-				continue
+				publishStepEvent(operation, stepIndex, constants.OperationEventStepDone, "")
 			} else {
-				handlerStepError(operation, cluster, runtimeCache, operation.Step[stepIndex], stepIndex, taskErrorHandler, stepErrMsg)
-				return
+				publishStepEvent(operation, stepIndex, constants.OperationEventStepError, result.errMsg)
 			}
-		case msg := <-taskTimeOutSignal:
-			// got abort signal aborting ...
-			errMsg := fmt.Sprintf("Task %s with index %d reach maxmuim allowed time out count...abort!!!!", operation.Step[stepIndex].Name, stepIndex)
-			recordErrorLogToOperationLog(operation, msg)
-			handlerStepError(operation, cluster, runtimeCache, operation.Step[stepIndex], stepIndex, taskErrorHandler, errMsg)
-			return
+			return result
+		},
+		onStepDone: func(stepIndex int) {
+			if config.DisableLazyOperationLog {
+				lock.Lock()
+				defer lock.Unlock()
+				if err := saveOperationStatus(runtimeCache, *operation); err != nil {
+					log.Errorf("Failed to save operation status when step %d is done due to error %s", stepIndex, err.Error())
+				}
+			}
+		},
+	}
+
+	if errMsg := scheduler.run(); errMsg != "" {
+		handlerStepError(operation, cluster, nodeCollection, runtimeCache, lock, startedSteps, taskErrorHandler, errMsg)
+		return
+	}
+
+	if scheduler.paused {
+		// operation stays in cluster current operations so it is able to be continued later
+		recordDebugLogToOperationLog(operation, fmt.Sprintf("Operation %s is paused with %d of %d steps done", operation.Id, len(operation.CompletedSteps), len(operation.Step)))
+		operation.Status = constants.StatusPaused
+		if err := saveOperationStatus(runtimeCache, *operation); err != nil {
+			log.Errorf("Failed to save operation status when operation is paused due to error %s", err.Error())
+		}
+		if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
+			log.Errorf("Failed to save cluster data to db when operation is paused due to error %s", err.Error())
 		}
+		finishOperationProgress(operation)
+		return
 	}
+
 	recordDebugLogToOperationLog(operation, fmt.Sprintf("All step done set operation status to %s", constants.StatusSuccessful))
 	operation.Status = constants.StatusSuccessful
 	//remove current operation id from current operations map
@@ -275,12 +292,149 @@ func doTask(operation *schema.Operation, cluster *schema.Cluster, nodeCollection
 		log.Errorf("Failed to record error data on all step is done due to error %s", err.Error())
 	}
 
-	if err := saveClusterStatus(runtimeCache, *cluster); err != nil {
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
 		log.Errorf("Failed to save cluster data to db when all step is done due to error %s", err.Error())
 	}
+	finishOperationProgress(operation)
+}
+
+/*
+run all node steps of a single step and wait for them
+*/
+func runStep(ctx context.Context, operation *schema.Operation, cluster *schema.Cluster, nodeCollection *schema.NodeInformationCollection,
+	stepIndex int, stepReturnData map[string]string, resourceServerURL string, lock *sync.Mutex, run *runningOperation) stepResult {
+
+	runtimeCache := cache.GetCurrentCache()
+	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
+	step := operation.Step[stepIndex]
+
+	stepErrMsg := fmt.Sprintf("Task %s with index %d result in error state...abort!!!!", step.Name, stepIndex)
+	cancelledErrMsg := fmt.Sprintf("Task %s with index %d is cancelled", step.Name, stepIndex)
+	if step.WaitBeforeRun > 0 {
+		log.Warnf("Step %d says we should wait for %d seconds before run. So we wait :(", stepIndex, step.WaitBeforeRun)
+		select {
+		case <-time.After(time.Duration(step.WaitBeforeRun) * time.Second):
+		case <-run.cancelled:
+			return stepResult{stepIndex: stepIndex, errMsg: cancelledErrMsg}
+		}
+	}
+
+	lock.Lock()
+	recordDebugLogToOperationLog(operation, fmt.Sprintf("Processing step %s with index %d", step.Name, stepIndex))
+	if step.DynamicNodeSteps != nil {
+		log.Debugf("Step %s contains dynamic steps bring it on then", step.Name)
+		dynamicSteps, err := step.DynamicNodeSteps(stepReturnData, *cluster, *operation)
+		if err != nil {
+			if step.IgnoreDynamicStepCreationError {
+				msg := fmt.Sprintf("(ignore) Failed to create dynamic steps from step %s due to error: %s", step.Name, err.Error())
+				recordDebugLogToOperationLog(operation, msg)
+			} else {
+				lock.Unlock()
+				return stepResult{stepIndex: stepIndex, errMsg: stepErrMsg}
+			}
+		}
+		step.NodeSteps = append(step.NodeSteps, dynamicSteps...)
+	}
+	if len(step.NodeSteps) == 0 {
+		recordDebugLogToOperationLog(operation, fmt.Sprintf("There is no node steps in step %d skip it!", stepIndex))
+		lock.Unlock()
+		return stepResult{stepIndex: stepIndex}
+	}
+	lock.Unlock()
+
+	// handlers are invoked with lock held so signals have to be buffered to never block them
+	// every node step may reply and time out once
+	taskDoneSignal := make(chan int, 2*len(step.NodeSteps)+1)
+	taskTimeOutSignal := make(chan string, len(step.NodeSteps)+1)
+	// no more retry once step returns
+	stepFinished := make(chan struct{})
+	defer close(stepFinished)
+	retry := &nodeStepRetry{
+		operation:      operation,
+		step:           step,
+		lock:           lock,
+		run:            run,
+		stepFinished:   stepFinished,
+		failedAttempts: map[string]int{},
+		taskDoneSignal: taskDoneSignal,
+	}
+	// use closure to create a message handler
+	taskDoneHandler := createTaskCallBackHandler(step, taskDoneSignal, stepReturnData, stepIndex, runtimeCache, cluster, operation, nodeCollection, lock, retry)
+	// use closure to create a timeout handler
+	taskTimeOutSignalHandler := createTaskTimeOutHandler(step, taskTimeOutSignal, taskDoneSignal, 1, stepIndex, runtimeCache, cluster, operation, nodeCollection, lock, retry)
+	defer run.nodeStepsFinished(step.NodeSteps)
+
+	// sendNodeStep has to be called with lock held
+	sendNodeStep := func(nodeTask schema.NodeStep) string {
+		nodeStepCtx, nodeStepSpan := startNodeStepSpan(ctx, nodeTask)
+		msgData, err := createMsgBody(operation.Id, resourceServerURL, nodeTask.Id, nodeTask.Tasks[0], *cluster, stepReturnData, tracing.Inject(nodeStepCtx))
+		if err != nil {
+			tracing.End(nodeStepSpan, err)
+			return fmt.Sprintf("Failed to marshal data for node task %s of step %d for operation %s due to error %s", nodeTask.Id, stepIndex, operation.Id, err.Error())
+		}
+
+		nodeData, foundNode := (*nodeCollection)[nodeTask.NodeID]
+		if !foundNode {
+			log.Error("Unable to find node information with id", nodeTask.NodeID)
+			tracing.End(nodeStepSpan, fmt.Errorf("node %s is not found", nodeTask.NodeID))
+			taskDoneSignal <- 1
+		} else {
+			// send message to all node task to do actual job such as set up cri
+			logMsg := fmt.Sprintf("Processing step %s by send a message for task %s to target node %s and wait %d seconds for reply", step.Name, nodeTask.Tasks[0].GetTaskType(), nodeData.Ipv4DefaultIp+fmt.Sprintf("(proxy:%s)", nodeData.ProxyIpv4CIDR), nodeTask.Tasks[0].GetTaskTimeOut())
+			recordDebugLogToOperationLog(operation, logMsg)
+			run.nodeStepSent(nodeTask.Id, nodeTask.NodeID)
+			go func() {
+				// span ends once node replies, times out or message is failed to send
+				tracing.End(nodeStepSpan, messageQueue.SendingMessageWithReply(mqHelper.GeneratorNodeSubscribe(nodeData, config.MessageQueue.SubjectSuffix),
+					cache.NodeId,
+					nodeTask.NodeID,
+					nodeTask.Id,
+					config.MessageQueue,
+					time.Duration(nodeTask.Tasks[0].GetTaskTimeOut())*time.Second,
+					[]byte(msgData),
+					taskDoneHandler,
+					taskTimeOutSignalHandler))
+			}()
+		}
+		return ""
+	}
+	retry.send = sendNodeStep
+
+	for _, nodeTask := range step.NodeSteps {
+		lock.Lock()
+		if logString := sendNodeStep(nodeTask); logString != "" {
+			lock.Unlock()
+			return stepResult{stepIndex: stepIndex, errMsg: logString}
+		}
+		lock.Unlock()
+	}
+
+	lock.Lock()
+	recordDebugLogToOperationLog(operation, fmt.Sprintf("Waiting for node reply for task %s with index %d", step.Name, stepIndex))
+	lock.Unlock()
+
+	select {
+	case doneOrError := <-taskDoneSignal:
+		if doneOrError == constants.MessageSignalDone {
+			lock.Lock()
+			recordDebugLogToOperationLog(operation, fmt.Sprintf("All node task of step %s has been done", step.Name))
+			lock.Unlock()
+			return stepResult{stepIndex: stepIndex}
+		}
+		return stepResult{stepIndex: stepIndex, errMsg: stepErrMsg}
+	case msg := <-taskTimeOutSignal:
+		// got abort signal aborting ...
+		lock.Lock()
+		recordErrorLogToOperationLog(operation, msg)
+		lock.Unlock()
+		return stepResult{stepIndex: stepIndex, errMsg: fmt.Sprintf("Task %s with index %d reach maxmuim allowed time out count...abort!!!!", step.Name, stepIndex)}
+	case <-run.cancelled:
+		// clients are asked to abort node steps by CancelOperation, no need to wait for their reply
+		return stepResult{stepIndex: stepIndex, errMsg: cancelledErrMsg}
+	}
 }
 
-func createMsgBody(operationId, resourceServerURL, nodeStepId string, task schema.ITask, cluster schema.Cluster, stepReturnData map[string]string) (string, error) {
+func createMsgBody(operationId, resourceServerURL, nodeStepId string, task schema.ITask, cluster schema.Cluster, stepReturnData map[string]string, traceContext map[string]string) (string, error) {
 	msgBody := &schema.QueueBody{
 		OperationId:       operationId,
 		TaskType:          task.GetTaskType(),
@@ -288,6 +442,7 @@ func createMsgBody(operationId, resourceServerURL, nodeStepId string, task schem
 		ResourceServerURL: resourceServerURL,
 		StepReturnData:    stepReturnData,
 		NodeStepId:        nodeStepId,
+		TraceContext:      traceContext,
 	}
 	if data, err := json.Marshal(task); err != nil {
 		return "", err
@@ -308,20 +463,37 @@ func SetOperationToFailedStat(operation *schema.Operation, runtimeCache cache.IC
 	if err := saveOperationStatus(runtimeCache, *operation); err != nil {
 		log.Errorf("Failed to record error data on creating message body failure due to error %s", err.Error())
 	}
+	finishOperationProgress(operation)
 }
 
-func handlerStepError(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache, step *schema.Step, stepIndex int, taskErrorHandler func(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache, err error) error, msg string) {
-	//errMsg := fmt.Sprintf("Task %s with index %d result in error state...abort!!!!", step.Name, stepIndex)
+/*
+handlerStepError marks operation failed or cancelled, runs error handler and rolls back if asked to
+steps may return on cancel before their node replies come in, those replies are handled with lock held so it is held here as well
+*/
+func handlerStepError(operation *schema.Operation, cluster *schema.Cluster, nodeCollection *schema.NodeInformationCollection, runtimeCache cache.ICache, lock *sync.Mutex, startedSteps map[int]byte, taskErrorHandler func(operation *schema.Operation, cluster *schema.Cluster, runtimeCache cache.ICache, err error) error, msg string) {
+	lock.Lock()
+	defer lock.Unlock()
 	recordErrorLogToOperationLog(operation, msg)
 	recordDebugLogToOperationLog(operation, fmt.Sprintf("Operation %s abort. Saving error state to db", operation.Id))
 	operation.Status = constants.StatusError
+	if isOperationCancelled(operation.Id) {
+		operation.Status = constants.StatusCancelled
+	}
 	if taskErrorHandler != nil {
 		if err := taskErrorHandler(operation, cluster, runtimeCache, errors.New(msg)); err != nil {
 			log.Errorf("(ignore) Task error handler run into error stat: %s", err.Error())
 		}
 	}
+	if operation.RollbackOnFailure {
+		// rollback may take a while, let others know operation fails first
+		if err := saveOperationStatus(runtimeCache, *operation); err != nil {
+			log.Errorf("Failed to save operation status before rollback due to error %s", err.Error())
+		}
+		rollbackOperation(operation, cluster, nodeCollection, runtimeCache, startedSteps, lock)
+	}
 	if err := saveOperationStatus(runtimeCache, *operation); err != nil {
 		log.Errorf("Task control flow error handler result in error status,no more action can be taken,please see following error,this may leads to operation lost his correct status and certain operation log. which normally caused by db connection issue")
 		log.Errorf("Failed to record error data on task failure due to error %s", err.Error())
 	}
+	finishOperationProgress(operation)
 }
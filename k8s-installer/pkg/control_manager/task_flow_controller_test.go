@@ -0,0 +1,38 @@
+package control_manager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestHandlerStepErrorWaitsForNodeReplyBeingHandled(t *testing.T) {
+	operations := &racingOperations{stored: schema.Operation{Id: "operation-1", Host: cache.NodeId, ResourceVersion: 1}}
+	operation := &schema.Operation{Id: "operation-1", Host: cache.NodeId, ResourceVersion: 1}
+	cluster := &schema.Cluster{ClusterId: "cluster-1"}
+
+	// a step returned on cancel while reply of its node step is still being handled
+	lock := &sync.Mutex{}
+	lock.Lock()
+	handled := make(chan struct{})
+	go func() {
+		handlerStepError(operation, cluster, &schema.NodeInformationCollection{}, operations, lock, nil, nil, "cancelled")
+		close(handled)
+	}()
+	select {
+	case <-handled:
+		t.Fatal("expected error to be handled once node reply is handled")
+	case <-time.After(100 * time.Millisecond):
+	}
+	operation.Logs = append(operation.Logs, "node reply")
+	lock.Unlock()
+
+	<-handled
+	if operations.stored.Status != constants.StatusError || len(operations.stored.Logs) == 0 || operations.stored.Logs[0] != "node reply" {
+		t.Errorf("expected operation to be saved failed along with node reply got %+v", operations.stored)
+	}
+}
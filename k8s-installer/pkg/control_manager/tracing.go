@@ -0,0 +1,48 @@
+package control_manager
+
+import (
+	"context"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/tracing"
+	"k8s-installer/schema"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+startOperationSpan starts span of an operation run
+trace context is kept in operation the first time so runs after being resumed or handed over to another server stay in the same trace
+*/
+func startOperationSpan(operation *schema.Operation) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(tracing.Extract(context.Background(), operation.TraceContext), "operation "+operation.Name,
+		attribute.String("operation.id", operation.Id),
+		attribute.String("operation.type", operation.OperationType),
+		attribute.String("cluster.id", operation.ClusterId),
+		attribute.String("server.id", cache.NodeId))
+	if operation.TraceContext == nil {
+		operation.TraceContext = tracing.Inject(ctx)
+	}
+	return ctx, span
+}
+
+func endOperationSpan(span trace.Span, operation *schema.Operation) {
+	span.SetAttributes(attribute.String("operation.status", operation.Status))
+	if operation.Status == constants.StatusError || operation.Status == constants.StatusCancelled {
+		span.SetStatus(codes.Error, operation.OperationLog)
+	}
+	span.End()
+}
+
+/*
+startNodeStepSpan starts span of sending a node step to agent and waiting for it`s reply
+*/
+func startNodeStepSpan(ctx context.Context, nodeStep schema.NodeStep) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "node step "+nodeStep.Tasks[0].GetTaskType(),
+		attribute.String("node.id", nodeStep.NodeID),
+		attribute.String("node_step.id", nodeStep.Id),
+		attribute.String("node_step.name", nodeStep.Name))
+}
@@ -0,0 +1,110 @@
+package control_manager
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	"k8s-installer/pkg/constants"
+	"k8s-installer/pkg/log"
+	"k8s-installer/schema"
+)
+
+/*
+upgradePauseGate records batch progress of upgrade plan once a batch passed health gate
+and tells whether upgrade should pause there
+plan is the same one held by upgrade done and error handlers so they never save stale progress
+*/
+func upgradePauseGate(plan *schema.UpgradePlan) func(batch int) bool {
+	return func(batch int) bool {
+		runtimeCache := cache.GetCurrentCache()
+		// pause is requested through api after upgrade started
+		if stored, err := runtimeCache.GetUpgradePlan(plan.Id); err != nil {
+			log.Errorf("Failed to get upgrade plan %s due to error: %s", plan.Id, err.Error())
+		} else if stored != nil {
+			plan.PauseRequested = stored.PauseRequested
+		}
+
+		plan.CompletedBatches = batch + 1
+		pause := plan.PauseRequested || plan.Strategy.ShouldPauseAfter(batch)
+		if pause {
+			plan.PauseRequested = false
+			plan.Status = constants.StatusPaused
+		}
+		if err := runtimeCache.CreateOrUpdateUpgradePlan(*plan); err != nil {
+			log.Errorf("Failed to save upgrade plan %s progress due to error: %s", plan.Id, err.Error())
+		}
+		return pause
+	}
+}
+
+/*
+ResumeUpgrade continues a paused upgrade from the batch after the last completed one
+steps are broken down again because step handlers and gates are not kept in db
+*/
+func ResumeUpgrade(plan *schema.UpgradePlan, cluster *schema.Cluster, runByUser string) error {
+	if plan.Status != constants.StatusPaused {
+		return fmt.Errorf("Upgrade plan %s is not paused, current status is %s ", plan.Id, plan.Status)
+	}
+	runtimeCache := cache.GetCurrentCache()
+	operation, err := runtimeCache.GetOperation(plan.OperationId)
+	if err != nil {
+		return err
+	}
+	if operation == nil {
+		return fmt.Errorf("Unable to find operation %s of upgrade plan %s ", plan.OperationId, plan.Id)
+	}
+	if operation.Status != constants.StatusPaused {
+		return fmt.Errorf("Operation %s is not paused, current status is %s ", operation.Id, operation.Status)
+	}
+
+	upgradeVersion, err := runtimeCache.GetUpgradeVersion(plan.TargetVersion)
+	if err != nil {
+		return err
+	}
+	if upgradeVersion == nil {
+		return errors.New(fmt.Sprintf("Failed to get upgrade version %s information due to not found", plan.TargetVersion))
+	}
+	nodesCollections, err := runtimeCache.GetNodeInformationCollection()
+	if err != nil {
+		return err
+	}
+
+	resumed := *operation
+	resumed.Step = nil
+	resumed, err = breakDownUpgrade(resumed, plan, *upgradeVersion, *cluster, nodesCollections)
+	if err != nil {
+		return err
+	}
+	resumeCompletedSteps(&resumed)
+	for index := range resumed.CompletedSteps {
+		if _, found := resumed.Step[index]; !found {
+			return fmt.Errorf("Operation %s no longer matches cluster %s, step with index %d is not found ", operation.Id, cluster.ClusterId, index)
+		}
+	}
+
+	if err := claimOperation(runtimeCache, &resumed); err != nil {
+		return err
+	}
+	resumed.Status = constants.StatusProcessing
+	resumed.RunByUser = runByUser
+	resumed.LastRun = time.Now().Format("2006-01-02T15:04:05Z07:00")
+	recordDebugLogToOperationLog(&resumed, fmt.Sprintf("Upgrade is resumed by user %s after %d batches done", runByUser, plan.CompletedBatches))
+
+	plan.Status = constants.StatusUpgrading
+	plan.PauseRequested = false
+	if err := runtimeCache.CreateOrUpdateUpgradePlan(*plan); err != nil {
+		return err
+	}
+	cluster.Status = constants.StatusUpgrading
+	if err := saveClusterStatus(runtimeCache, operation.Id, *cluster); err != nil {
+		return err
+	}
+	if err := saveOperationStatus(runtimeCache, resumed); err != nil {
+		return err
+	}
+
+	go doTask(&resumed, cluster, &nodesCollections, applyUpgradeTaskDoneHandler(plan, upgradeVersion), applyUpgradeTaskErrorHandler(plan))
+	return nil
+}
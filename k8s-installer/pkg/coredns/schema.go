@@ -4,6 +4,8 @@ type TopLevelDomain struct {
 	Domain       string `json:"domain" validate:"required,fqdn" description:"domain name unique"`
 	Description  string `json:"description,omitempty"`
 	DomainCounts int    `json:"domain_counts" description:"auto generator, do not input"`
+	// schema version domain is saved at
+	SchemaVersion int `json:"schema_version,omitempty" description:"auto generator, do not input"`
 }
 
 // only use for api docs
@@ -26,6 +28,7 @@ type DNSDomain struct {
 	DomainResolve  []DomainResolve `json:"domain_resolve"  validate:"required" description:"ip address list of this domain resolve to"`
 	Description    string          `json:"description,omitempty"`
 	Action         string          `json:"action,omitempty" description:"auto generator, do not input"`
+	SchemaVersion  int             `json:"schema_version,omitempty" description:"auto generator, do not input, schema version domain is saved at"`
 }
 
 type DomainResolve struct {
@@ -6,3 +6,29 @@ type (
 	DepVersion map[string]DepArch
 	DepMap     map[string]DepVersion
 )
+
+// OSKey is key of packages which only apply to the given version of os family, e.g. ubuntu-18.04
+func OSKey(osFamily, osVersion string) string {
+	return osFamily + "-" + osVersion
+}
+
+/*
+Packages returns packages of os family with given os version
+packages kept under OSKey override those with the same name kept under os family
+so only packages which differ between os versions need to be listed there
+*/
+func (m DepMap) Packages(osFamily, osVersion, k8sVersion, cpuArch string) (DepPackage, bool) {
+	familyPackages, familyFound := m[osFamily][k8sVersion][cpuArch]
+	versionPackages, versionFound := m[OSKey(osFamily, osVersion)][k8sVersion][cpuArch]
+	if !familyFound && !versionFound {
+		return nil, false
+	}
+	packages := DepPackage{}
+	for name, file := range familyPackages {
+		packages[name] = file
+	}
+	for name, file := range versionPackages {
+		packages[name] = file
+	}
+	return packages, true
+}
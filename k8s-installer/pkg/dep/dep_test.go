@@ -0,0 +1,44 @@
+package dep
+
+import "testing"
+
+func TestPackagesOverrideByOSVersion(t *testing.T) {
+	m := DepMap{
+		"ubuntu": {
+			"1.18.6": {
+				"x86_64": {
+					"kubeadm":   "kubeadm_1.18.6-00_amd64.deb",
+					"docker-ce": "docker-ce_19.03.12~3-0~ubuntu-focal_amd64.deb",
+				},
+			},
+		},
+		OSKey("ubuntu", "18.04"): {
+			"1.18.6": {
+				"x86_64": {
+					"docker-ce": "docker-ce_19.03.12~3-0~ubuntu-bionic_amd64.deb",
+				},
+			},
+		},
+	}
+
+	packages, found := m.Packages("ubuntu", "18.04", "1.18.6", "x86_64")
+	if !found {
+		t.Fatal("Expect packages of ubuntu 18.04 to be found")
+	}
+	if packages["docker-ce"] != "docker-ce_19.03.12~3-0~ubuntu-bionic_amd64.deb" || packages["kubeadm"] != "kubeadm_1.18.6-00_amd64.deb" {
+		t.Fatalf("Unexpected packages of ubuntu 18.04 %v", packages)
+	}
+
+	packages, _ = m.Packages("ubuntu", "20.04", "1.18.6", "x86_64")
+	if packages["docker-ce"] != "docker-ce_19.03.12~3-0~ubuntu-focal_amd64.deb" {
+		t.Fatalf("Unexpected packages of ubuntu 20.04 %v", packages)
+	}
+	// override must not leak into the family packages
+	if m["ubuntu"]["1.18.6"]["x86_64"]["docker-ce"] != "docker-ce_19.03.12~3-0~ubuntu-focal_amd64.deb" {
+		t.Fatal("Family packages are modified")
+	}
+
+	if _, found := m.Packages("ubuntu", "20.04", "1.18.6", "aarch64"); found {
+		t.Fatal("Expect no packages for aarch64")
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	config "k8s-installer/pkg/config/downloader"
 	"k8s-installer/pkg/config/printer"
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/downloader"
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/metrics"
 	"k8s-installer/pkg/reader"
 	"k8s-installer/pkg/util"
 	"k8s-installer/pkg/util/fileutils"
@@ -81,7 +83,10 @@ func (bd *BackDownloader) Run(ctx context.Context) error {
 
 	buf := make([]byte, 512*1024)
 	reader := reader.NewFileReader(resp.Body, bd.Md5 != "")
-	if _, err = io.CopyBuffer(f, reader, buf); err != nil {
+	start := time.Now()
+	written, err := io.CopyBuffer(f, reader, buf)
+	metrics.ObserveDownload(written, time.Since(start), err)
+	if err != nil {
 		return err
 	}
 
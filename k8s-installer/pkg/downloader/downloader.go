@@ -35,12 +35,17 @@ func CalculateTimeout(cfg *config.DownloadConfig) time.Duration {
 }
 
 func DoDownloadTimeout(downloader Downloader, timeout time.Duration) error {
+	return DoDownloadTimeoutContext(context.Background(), downloader, timeout)
+}
+
+// DoDownloadTimeoutContext runs downloader with a context derived from parent
+func DoDownloadTimeoutContext(parent context.Context, downloader Downloader, timeout time.Duration) error {
 	if timeout <= 0 {
 		logrus.Warnf("invaild download timeout(%.3fs), use default:(%.3f)",
 			timeout.Seconds(), constants.DefaultDownloadTimeout.Seconds())
 		timeout = constants.DefaultDownloadTimeout
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 
 	var ch = make(chan error)
 	go func() {
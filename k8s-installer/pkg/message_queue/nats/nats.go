@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"k8s-installer/pkg/log"
+	"k8s-installer/pkg/metrics"
 
 	natServer "github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
@@ -80,9 +81,20 @@ func SendingMessage(subscribe, data, clientId string) error {
 	return nc.Publish(subscribe, []byte(data))
 }
 
+// Subscribe listens on subject with connection server opens for report in, so ListenQueueGroup has to be called first
+func Subscribe(subject string, handler func(msg *nats.Msg)) (*nats.Subscription, error) {
+	if nc == nil {
+		return nil, errors.New("message queue is not connected yet")
+	}
+	log.Debugf("Listening on subscribe %s", subject)
+	return nc.Subscribe(subject, handler)
+}
+
 func SendingMessageWithReply(subscribe, senderNodeId, toNodeId, nodeStepId string, mq MessageQueueConfig, secTimeout time.Duration, data []byte, replyHandler func(msg *nats.Msg) error, timeOutHandler func(nodeId string, nodeStepId string)) error {
 	log.Debugf("Sending message %s to channel %s message client id %s", data, subscribe, toNodeId)
+	start := time.Now()
 	msg, err := nc.Request(subscribe, data, secTimeout)
+	metrics.ObserveMessageQueueRequest(requestResult(err), time.Since(start))
 	if err != nil {
 		log.Error(err)
 		if errors.Is(err, nats.ErrTimeout) && timeOutHandler != nil {
@@ -95,7 +107,9 @@ func SendingMessageWithReply(subscribe, senderNodeId, toNodeId, nodeStepId strin
 
 func SendingMessageAndWaitReply(subscribe, clientId, nodeId, nodeStepId string, mq MessageQueueConfig, secTimeout time.Duration, data []byte) ([]byte, error) {
 	log.Debugf("Sending message %s to channel %s message client id %s", data, subscribe, clientId)
+	start := time.Now()
 	msg, err := nc.Request(subscribe, data, secTimeout)
+	metrics.ObserveMessageQueueRequest(requestResult(err), time.Since(start))
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -103,6 +117,17 @@ func SendingMessageAndWaitReply(subscribe, clientId, nodeId, nodeStepId string,
 	return msg.Data, nil
 }
 
+func requestResult(err error) string {
+	switch {
+	case err == nil:
+		return metrics.ResultSuccess
+	case errors.Is(err, nats.ErrTimeout):
+		return metrics.ResultTimeout
+	default:
+		return metrics.ResultError
+	}
+}
+
 func (nat MessageQueueConfig) getConnectionString() string {
 	switch nat.AuthMode {
 	case MQ_AUTHENTICATION_TYPE_BASIC:
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s-installer/pkg/log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "k8s_installer"
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+	ResultTimeout = "timeout"
+)
+
+// a registry of our own keeps metrics of imported libraries out of /metrics
+var registry = prometheus.NewRegistry()
+
+var (
+	operationTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Number of finished operations by type and status.",
+	}, []string{"type", "status"})
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "operation_duration_seconds",
+		Help:      "Time an operation takes from its last run to the moment it finishes.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"type", "status"})
+
+	stepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Time a step takes to run all of its node steps.",
+		Buckets:   []float64{0.5, 1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"step", "status"})
+
+	messageQueueRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "message_queue_request_duration_seconds",
+		Help:      "Round trip time of requests sent through message queue and waiting for reply.",
+		Buckets:   []float64{0.05, 0.1, 0.5, 1, 5, 15, 30, 60, 120, 300},
+	}, []string{"result"})
+
+	messageQueueRequestTimeout = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "message_queue_request_timeouts_total",
+		Help:      "Number of requests sent through message queue which got no reply in time.",
+	})
+
+	etcdRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "etcd_request_duration_seconds",
+		Help:      "Latency of requests sent to etcd by operation and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of api requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	downloadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "download_bytes_total",
+		Help:      "Bytes downloaded from source station by result.",
+	}, []string{"result"})
+
+	downloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "download_duration_seconds",
+		Help:      "Time a download from source station takes by result.",
+		Buckets:   []float64{0.5, 1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"result"})
+)
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		operationTotal,
+		operationDuration,
+		stepDuration,
+		messageQueueRequestDuration,
+		messageQueueRequestTimeout,
+		etcdRequestDuration,
+		apiRequestDuration,
+		downloadBytes,
+		downloadDuration,
+	)
+}
+
+/*
+Handler serves all metrics in prometheus text format
+*/
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+/*
+Serve exposes /metrics on given port until stopChan is closed, port 0 turns it off
+*/
+func Serve(port int, stopChan <-chan struct{}) {
+	if port == 0 {
+		log.Debug("Metrics port is not set, metrics endpoint is disabled")
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Metrics endpoint stopped due to error: %s", err.Error())
+		}
+	}()
+	log.Debugf("Metrics endpoint listening at port %d", port)
+	<-stopChan
+	server.Close()
+}
+
+/*
+Register adds extra collectors, e.g. those only make sense on server
+*/
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector)
+}
+
+func ObserveOperation(operationType, status string, duration time.Duration) {
+	operationTotal.WithLabelValues(operationType, status).Inc()
+	operationDuration.WithLabelValues(operationType, status).Observe(duration.Seconds())
+}
+
+func ObserveStep(step, status string, duration time.Duration) {
+	stepDuration.WithLabelValues(step, status).Observe(duration.Seconds())
+}
+
+func ObserveMessageQueueRequest(result string, duration time.Duration) {
+	messageQueueRequestDuration.WithLabelValues(result).Observe(duration.Seconds())
+	if result == ResultTimeout {
+		messageQueueRequestTimeout.Inc()
+	}
+}
+
+func ObserveEtcdRequest(operation string, start time.Time, err error) {
+	etcdRequestDuration.WithLabelValues(operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+func ObserveAPIRequest(method, route string, code int, duration time.Duration) {
+	apiRequestDuration.WithLabelValues(method, route, strconv.Itoa(code)).Observe(duration.Seconds())
+}
+
+func ObserveDownload(bytes int64, duration time.Duration, err error) {
+	downloadBytes.WithLabelValues(result(err)).Add(float64(bytes))
+	downloadDuration.WithLabelValues(result(err)).Observe(duration.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
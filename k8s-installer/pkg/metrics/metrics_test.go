@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveOperation("ClusterSetupOrDestroy", "Successful", 3*time.Second)
+	ObserveMessageQueueRequest(ResultTimeout, time.Second)
+	ObserveAPIRequest("GET", "/api/core/v1/clusters/{cluster-id}", 200, time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(recorder.Body)
+	for _, expected := range []string{
+		`k8s_installer_operations_total{status="Successful",type="ClusterSetupOrDestroy"} 1`,
+		`k8s_installer_message_queue_request_timeouts_total 1`,
+		`k8s_installer_api_request_duration_seconds_count{code="200",method="GET",route="/api/core/v1/clusters/{cluster-id}"} 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("metrics output is expected to contain %s", expected)
+		}
+	}
+}
+
+func TestReportInCollector(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 10, 0, 0, time.UTC)
+	collector := &reportInCollector{
+		lastReportIn: func() (map[string]time.Time, error) {
+			return map[string]time.Time{"node-1": now.Add(-90 * time.Second)}, nil
+		},
+		now: func() time.Time { return now },
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || len(families[0].Metric) != 1 {
+		t.Fatalf("expected one report in age metric got %v", families)
+	}
+	if age := families[0].Metric[0].GetGauge().GetValue(); age != 90 {
+		t.Errorf("expected report in age 90 got %v", age)
+	}
+
+	collector.lastReportIn = func() (map[string]time.Time, error) {
+		return nil, errors.New("etcd is down")
+	}
+	if _, err := registry.Gather(); err == nil {
+		t.Error("expected failure of listing nodes to be reported")
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var reportInAgeDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "agent_report_in_age_seconds"),
+	"Seconds since an agent last reported in, by node.",
+	[]string{"node"}, nil,
+)
+
+/*
+reportInCollector works out report-in age of every node at scrape time
+so nodes gone quiet keep getting older instead of freezing at last value
+*/
+type reportInCollector struct {
+	lastReportIn func() (map[string]time.Time, error)
+	now          func() time.Time
+}
+
+/*
+NewReportInCollector creates a collector of agent report-in age, lastReportIn returns last report in time by node id
+*/
+func NewReportInCollector(lastReportIn func() (map[string]time.Time, error)) prometheus.Collector {
+	return &reportInCollector{lastReportIn: lastReportIn, now: time.Now}
+}
+
+func (c *reportInCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- reportInAgeDesc
+}
+
+func (c *reportInCollector) Collect(ch chan<- prometheus.Metric) {
+	reports, err := c.lastReportIn()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(reportInAgeDesc, err)
+		return
+	}
+	now := c.now()
+	for node, last := range reports {
+		ch <- prometheus.MustNewConstMetric(reportInAgeDesc, prometheus.GaugeValue, now.Sub(last).Seconds(), node)
+	}
+}
@@ -0,0 +1,94 @@
+package password
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+)
+
+// bcrypt only takes first 72 bytes into account so longer password is refused rather than silently truncated
+const maxLength = 72
+
+func Hash(plain string) (string, error) {
+	if len(plain) > maxLength {
+		return "", fmt.Errorf("Password cannot be longer than %d bytes", maxLength)
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func IsHashed(stored string) bool {
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+/*
+Verify checks plain password against the stored one
+legacy is true when stored password is still plaintext written by older version, caller should replace it with hash once matched
+*/
+func Verify(stored, plain string) (match bool, legacy bool) {
+	if IsHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(plain)) == nil, false
+	}
+	return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(plain)) == 1, true
+}
+
+func CheckPolicy(policy apiServerConfig.PasswordPolicy, username, plain string) error {
+	var violations []string
+	if len(plain) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	if len(plain) > maxLength {
+		violations = append(violations, fmt.Sprintf("at most %d bytes", maxLength))
+	}
+	var upper, lower, digit, special bool
+	for _, c := range plain {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		default:
+			special = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		violations = append(violations, "an upper case letter")
+	}
+	if policy.RequireLower && !lower {
+		violations = append(violations, "a lower case letter")
+	}
+	if policy.RequireDigit && !digit {
+		violations = append(violations, "a digit")
+	}
+	if policy.RequireSpecial && !special {
+		violations = append(violations, "a special character")
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("Password must contain %s", strings.Join(violations, ", "))
+	}
+	if username != "" && strings.Contains(strings.ToLower(plain), strings.ToLower(username)) {
+		return errors.New("Password cannot contain username")
+	}
+	return nil
+}
+
+// IsExpired tells whether password changed at given unix time is expired, password never expires when expire days is not set
+func IsExpired(policy apiServerConfig.PasswordPolicy, changedAt int64, now time.Time) bool {
+	if policy.ExpireAfterDays <= 0 || changedAt <= 0 {
+		return false
+	}
+	return now.After(time.Unix(changedAt, 0).AddDate(0, 0, policy.ExpireAfterDays))
+}
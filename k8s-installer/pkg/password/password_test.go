@@ -0,0 +1,60 @@
+package password
+
+import (
+	"testing"
+	"time"
+
+	apiServerConfig "k8s-installer/pkg/config/api_server"
+)
+
+func TestVerify(t *testing.T) {
+	hashed, err := Hash("Secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsHashed(hashed) || IsHashed("Secret123") {
+		t.Fatal("Expected only bcrypt hash to be taken as hashed")
+	}
+	if match, legacy := Verify(hashed, "Secret123"); !match || legacy {
+		t.Fatalf("Expected hashed password to match, got match %v legacy %v", match, legacy)
+	}
+	if match, _ := Verify(hashed, "secret123"); match {
+		t.Fatal("Expected wrong password not to match")
+	}
+	if match, legacy := Verify("Secret123", "Secret123"); !match || !legacy {
+		t.Fatalf("Expected plaintext password to match as legacy, got match %v legacy %v", match, legacy)
+	}
+	if match, _ := Verify("", ""); match {
+		t.Fatal("Expected empty password never to match")
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	policy := apiServerConfig.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+	cases := map[string]bool{
+		"Secret123":   true,
+		"secret123":   false,
+		"Sec1":        false,
+		"SecretPass":  false,
+		"Alice12345x": false,
+	}
+	for plain, valid := range cases {
+		if err := CheckPolicy(policy, "alice", plain); (err == nil) != valid {
+			t.Errorf("Expected password %s valid to be %v, got error %v", plain, valid, err)
+		}
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now()
+	changedAt := now.AddDate(0, 0, -31).Unix()
+	if IsExpired(apiServerConfig.PasswordPolicy{}, changedAt, now) {
+		t.Fatal("Expected password never to expire without expire days")
+	}
+	if !IsExpired(apiServerConfig.PasswordPolicy{ExpireAfterDays: 30}, changedAt, now) {
+		t.Fatal("Expected password changed 31 days ago to be expired")
+	}
+	if IsExpired(apiServerConfig.PasswordPolicy{ExpireAfterDays: 90}, changedAt, now) {
+		t.Fatal("Expected password changed 31 days ago not to be expired")
+	}
+}
@@ -0,0 +1,150 @@
+package rbac
+
+import (
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+/*
+Resource is what a request touches, scope of role binding is matched against it
+zero value is touched by no scope so only global roles apply
+*/
+type Resource struct {
+	ClusterId string
+	Regions   []string
+	Labels    map[string]string
+}
+
+func ClusterResource(cluster schema.Cluster) Resource {
+	resource := Resource{ClusterId: cluster.ClusterId, Labels: cluster.Labels}
+	if cluster.Region != "" {
+		resource.Regions = append(resource.Regions, cluster.Region)
+	}
+	return resource
+}
+
+// NodeResource is selected by scope of the cluster node belongs to or by region of the node itself
+func NodeResource(node schema.NodeInformation, cluster *schema.Cluster) Resource {
+	resource := Resource{}
+	if cluster != nil {
+		resource = ClusterResource(*cluster)
+	}
+	if node.Region != nil && node.Region.ID != "" {
+		resource.Regions = append(resource.Regions, node.Region.ID)
+	}
+	return resource
+}
+
+func RegionResource(regionId string) Resource {
+	return Resource{Regions: []string{regionId}}
+}
+
+// Matches tells whether scope selects the resource, any of cluster ids, regions or the label selector as a whole is enough
+func Matches(scope schema.RoleBindingScope, resource Resource) bool {
+	if resource.ClusterId != "" {
+		for _, clusterId := range scope.Clusters {
+			if clusterId == resource.ClusterId {
+				return true
+			}
+		}
+	}
+	for _, region := range scope.Regions {
+		for _, resourceRegion := range resource.Regions {
+			if region == resourceRegion {
+				return true
+			}
+		}
+	}
+	if resource.ClusterId == "" || len(scope.ClusterLabels) == 0 {
+		return false
+	}
+	for key, value := range scope.ClusterLabels {
+		if found, isFound := resource.Labels[key]; !isFound || found != value {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Authorizer works out permission of one request
+roles of user apply globally, role bindings only on resources their scope selects
+*/
+type Authorizer struct {
+	Global uint64
+	// narrows down every permission e.g. to roles of api token, all bits set when nothing narrows
+	Mask     uint64
+	Bindings []schema.RoleBinding
+	// role list is the source of truth of role functions
+	Roles schema.RoleCollection
+}
+
+func NewAuthorizer(userRoles []schema.Role, roles schema.RoleCollection) Authorizer {
+	return Authorizer{
+		Global: RolesFunction(userRoles, roles),
+		Mask:   ^uint64(0),
+		Roles:  roles,
+	}
+}
+
+func RolesFunction(userRoles []schema.Role, roles schema.RoleCollection) uint64 {
+	function := uint64(0)
+	for _, role := range userRoles {
+		if found, isFound := roles[role.Id]; isFound {
+			function |= found.Function
+		}
+	}
+	return function
+}
+
+func (authorizer Authorizer) GlobalPermission() uint64 {
+	return authorizer.Global & authorizer.Mask
+}
+
+// Permission on the resource, functions of matching bindings are added to global ones
+func (authorizer Authorizer) Permission(resource Resource) uint64 {
+	permission := authorizer.Global
+	for _, binding := range authorizer.Bindings {
+		if Matches(binding.Scope, resource) {
+			permission |= authorizer.bindingFunction(binding)
+		}
+	}
+	return permission & authorizer.Mask
+}
+
+func (authorizer Authorizer) Allowed(challengeCode uint64, resource Resource) bool {
+	return authorizer.Permission(resource)&challengeCode == challengeCode
+}
+
+// Scoped tells whether challenge code is not granted globally, list of resources has to be filtered then
+func (authorizer Authorizer) Scoped(challengeCode uint64) bool {
+	return authorizer.GlobalPermission()&challengeCode != challengeCode
+}
+
+// Reachable tells whether challenge code is granted on at least some resource
+func (authorizer Authorizer) Reachable(challengeCode uint64) bool {
+	permission := authorizer.Global
+	for _, binding := range authorizer.Bindings {
+		permission |= authorizer.bindingFunction(binding)
+	}
+	return permission&authorizer.Mask&challengeCode == challengeCode
+}
+
+func (authorizer Authorizer) bindingFunction(binding schema.RoleBinding) uint64 {
+	role, isFound := authorizer.Roles[binding.RoleId]
+	if !isFound {
+		return 0
+	}
+	return role.Function &^ constants.GlobalOnlyFunctions
+}
+
+// UserBindings picks bindings of the user out of the collection
+func UserBindings(bindings schema.RoleBindingCollection, username string) []schema.RoleBinding {
+	var result []schema.RoleBinding
+	for _, binding := range bindings {
+		if binding.Username == username {
+			result = append(result, binding)
+		}
+	}
+	return result
+}
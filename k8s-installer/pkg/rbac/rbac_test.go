@@ -0,0 +1,88 @@
+package rbac
+
+import (
+	"testing"
+
+	"k8s-installer/pkg/constants"
+	"k8s-installer/schema"
+)
+
+func TestMatches(t *testing.T) {
+	prod := schema.Cluster{ClusterId: "c1", Region: "r1", Labels: map[string]string{"env": "prod", "team": "a"}}
+	scopes := []struct {
+		name     string
+		scope    schema.RoleBindingScope
+		resource Resource
+		expected bool
+	}{
+		{"cluster id", schema.RoleBindingScope{Clusters: []string{"c1"}}, ClusterResource(prod), true},
+		{"other cluster id", schema.RoleBindingScope{Clusters: []string{"c2"}}, ClusterResource(prod), false},
+		{"cluster region", schema.RoleBindingScope{Regions: []string{"r1"}}, ClusterResource(prod), true},
+		{"all labels", schema.RoleBindingScope{ClusterLabels: map[string]string{"env": "prod", "team": "a"}}, ClusterResource(prod), true},
+		{"one label differs", schema.RoleBindingScope{ClusterLabels: map[string]string{"env": "prod", "team": "b"}}, ClusterResource(prod), false},
+		{"labels never select region", schema.RoleBindingScope{ClusterLabels: map[string]string{"env": "prod"}}, RegionResource("r1"), false},
+		{"region", schema.RoleBindingScope{Regions: []string{"r1"}}, RegionResource("r1"), true},
+		{"node by own region", schema.RoleBindingScope{Regions: []string{"r2"}}, NodeResource(schema.NodeInformation{Region: &schema.Region{ID: "r2"}}, &prod), true},
+		{"node by cluster", schema.RoleBindingScope{Clusters: []string{"c1"}}, NodeResource(schema.NodeInformation{}, &prod), true},
+		{"node outside cluster", schema.RoleBindingScope{Clusters: []string{"c1"}}, NodeResource(schema.NodeInformation{}, nil), false},
+		{"empty scope", schema.RoleBindingScope{}, ClusterResource(prod), false},
+	}
+	for _, test := range scopes {
+		if result := Matches(test.scope, test.resource); result != test.expected {
+			t.Errorf("%s: expected %v but got %v", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	roles := schema.RoleCollection{
+		"viewer":   {Id: "viewer", Function: constants.ChallengeCodeListCluster | constants.ChallengeCodeListNode},
+		"operator": {Id: "operator", Function: constants.ChallengeCodeDeleteCluster | constants.ChallengeCodeManageUser},
+	}
+	authorizer := NewAuthorizer([]schema.Role{{Id: "viewer"}}, roles)
+	authorizer.Bindings = []schema.RoleBinding{
+		{Username: "u", RoleId: "operator", Scope: schema.RoleBindingScope{Clusters: []string{"c1"}}},
+		{Username: "u", RoleId: "missing", Scope: schema.RoleBindingScope{Clusters: []string{"c1"}}},
+	}
+	c1 := Resource{ClusterId: "c1"}
+	c2 := Resource{ClusterId: "c2"}
+
+	if !authorizer.Allowed(constants.ChallengeCodeListCluster, c2) {
+		t.Error("global role should apply to every cluster")
+	}
+	if !authorizer.Allowed(constants.ChallengeCodeDeleteCluster, c1) {
+		t.Error("binding should grant it`s role on cluster in scope")
+	}
+	if authorizer.Allowed(constants.ChallengeCodeDeleteCluster, c2) {
+		t.Error("binding should not grant it`s role on cluster out of scope")
+	}
+	if authorizer.Allowed(constants.ChallengeCodeManageUser, c1) {
+		t.Error("binding should never grant global only function")
+	}
+	if authorizer.Scoped(constants.ChallengeCodeListCluster) || !authorizer.Scoped(constants.ChallengeCodeDeleteCluster) {
+		t.Error("only function missing from global roles should be scoped")
+	}
+	if !authorizer.Reachable(constants.ChallengeCodeDeleteCluster) || authorizer.Reachable(constants.ChallengeCodeManageUser) {
+		t.Error("function should be reachable through binding unless it is global only")
+	}
+
+	// api token limited to viewer loses what binding grants
+	authorizer.Mask = RolesFunction([]schema.Role{{Id: "viewer"}}, roles)
+	if authorizer.Allowed(constants.ChallengeCodeDeleteCluster, c1) || authorizer.Reachable(constants.ChallengeCodeDeleteCluster) {
+		t.Error("mask should narrow down permission granted by binding")
+	}
+}
+
+func TestUserBindings(t *testing.T) {
+	bindings := schema.RoleBindingCollection{
+		"1": {Id: "1", Username: "a"},
+		"2": {Id: "2", Username: "b"},
+		"3": {Id: "3", Username: "a"},
+	}
+	if result := UserBindings(bindings, "a"); len(result) != 2 {
+		t.Errorf("expected 2 bindings of user a but got %d", len(result))
+	}
+	if result := UserBindings(bindings, "c"); len(result) != 0 {
+		t.Errorf("expected no binding of user c but got %d", len(result))
+	}
+}
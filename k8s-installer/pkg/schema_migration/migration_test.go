@@ -0,0 +1,155 @@
+package schema_migration
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+	"k8s-installer/pkg/server/embedded_store"
+	"k8s-installer/schema"
+)
+
+func TestUpgrade(t *testing.T) {
+	data, version, changed, err := upgrade("operation", []byte(`{"id":"op-1","current_step":2,"created":1606383475123456789}`))
+	if err != nil || data == nil || !changed || version != 0 {
+		t.Fatalf("expected operation to be migrated got %v %v %v", version, changed, err)
+	}
+	if !strings.Contains(string(data), `"completed_steps":{"0":0,"1":0}`) || !strings.Contains(string(data), "1606383475123456789") || !strings.Contains(string(data), `"schema_version":1`) {
+		t.Errorf("unexpected migrated operation %s", data)
+	}
+	if data, version, _, err := upgrade("operation", data); err != nil || data != nil || version != 1 {
+		t.Errorf("expected migrated operation to be left alone got %s %v %v", data, version, err)
+	}
+
+	// saved again by an older server which does not know schema version
+	data, _, changed, err = upgrade("operation", []byte(`{"id":"op-1","current_step":2,"completed_steps":{"0":0,"1":0}}`))
+	if err != nil || changed || !strings.Contains(string(data), `"schema_version":1`) {
+		t.Errorf("expected operation in shape to be only stamped got %s %v %v", data, changed, err)
+	}
+
+	data, _, changed, err = upgrade("cluster", []byte(`{"cluster_id":"c-1","control_plane":{"allow_virtual_kubelet":false}}`))
+	if err != nil || !changed || strings.Contains(string(data), "allow_virtual_kubelet") {
+		t.Errorf("expected allow virtual kubelet to be dropped got %s %v %v", data, changed, err)
+	}
+
+	if _, _, _, err := upgrade("cluster", []byte(`{"cluster_id":"c-1","schema_version":2}`)); err == nil {
+		t.Error("expected cluster saved by a newer binary to be refused")
+	}
+
+	// collections nothing has changed of yet are stamped and guarded all the same
+	for _, collection := range []string{"node", "user", "session", "sub-domain"} {
+		data, _, changed, err := upgrade(collection, []byte(`{"id":"r-1"}`))
+		if err != nil || changed || !strings.Contains(string(data), `"schema_version":1`) {
+			t.Errorf("expected %s to be only stamped got %s %v %v", collection, data, changed, err)
+		}
+		if _, _, _, err := upgrade(collection, []byte(`{"id":"r-1","schema_version":2}`)); err == nil {
+			t.Errorf("expected %s saved by a newer binary to be refused", collection)
+		}
+	}
+}
+
+func freeURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return fmt.Sprintf("http://%s", listener.Addr().String())
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := embedded_store.Start(cacheConfig.EmbeddedStoreConfig{
+		DataDir:         dir,
+		ListenClientURL: freeURL(t),
+		ListenPeerURL:   freeURL(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	client, err := (&cache.EtcdClient{EtcdClientConfig: store.EtcdConfig()}).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	put := func(key, value string) {
+		if _, err := client.Put(ctx, key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(key string) string {
+		response, err := client.Get(ctx, key)
+		if err != nil || len(response.Kvs) == 0 {
+			t.Fatalf("failed to get %s %v", key, err)
+		}
+		return string(response.Kvs[0].Value)
+	}
+	key := cache.DataPrefix("operation") + "op-1"
+	put(key, `{"id":"op-1","current_step":1}`)
+	put(cache.DataPrefix("cluster")+"c-1", fmt.Sprintf(`{"cluster_id":"c-1","schema_version":%d}`, Versions()["cluster"]))
+
+	reports, err := Run(store.EtcdConfig(), true)
+	if err != nil || len(reports) != 1 || reports[0].Collection != "operation" || reports[0].Records != 1 {
+		t.Fatalf("expected a report of operations only got %v %v", reports, err)
+	}
+	if strings.Contains(get(key), "completed_steps") {
+		t.Error("expected dry run to leave data alone")
+	}
+
+	if _, err := Run(store.EtcdConfig(), false); err != nil {
+		t.Fatal(err)
+	}
+	if value := get(key); !strings.Contains(value, `"completed_steps":{"0":0}`) || !strings.Contains(value, `"schema_version":1`) {
+		t.Errorf("expected operation to be migrated got %s", value)
+	}
+	if reports, err := Run(store.EtcdConfig(), false); err != nil || len(reports) != 0 {
+		t.Errorf("expected nothing left to migrate got %v %v", reports, err)
+	}
+
+	// an older server still running during a rolling upgrade saves in old schema after migration is done
+	rollingKey := cache.DataPrefix("operation") + "op-2"
+	put(rollingKey, `{"id":"op-2","current_step":2}`)
+	if reports, err := Run(store.EtcdConfig(), false); err != nil || len(reports) != 1 || reports[0].FromVersion != 0 {
+		t.Errorf("expected operation saved by older server to be migrated got %v %v", reports, err)
+	}
+	if value := get(rollingKey); !strings.Contains(value, `"completed_steps":{"0":0,"1":0}`) {
+		t.Errorf("expected operation saved by older server to be migrated got %s", value)
+	}
+
+	// records saved by this binary are stamped with its schema version so they are never migrated
+	runtime := cache.InitCacheRuntime(cacheConfig.CacheConfig{CacheRuntime: cacheConfig.RuntimeNoCache}, store.EtcdConfig(), nil)
+	if err := runtime.SaveOrUpdateClusterCollection("c-2", schema.Cluster{ClusterId: "c-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if value := get(cache.DataPrefix("cluster") + "c-2"); !strings.Contains(value, fmt.Sprintf(`"schema_version":%d`, Versions()["cluster"])) {
+		t.Errorf("expected saved cluster to be stamped with schema version got %s", value)
+	}
+	if err := runtime.SaveOrUpdateSession(schema.Session{Id: "s-1", Username: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if value := get(cache.DataPrefix("session") + "s-1"); !strings.Contains(value, fmt.Sprintf(`"schema_version":%d`, Versions()["session"])) {
+		t.Errorf("expected saved session to be stamped with schema version got %s", value)
+	}
+
+	outdatedKey := cache.DataPrefix("operation") + "op-3"
+	put(outdatedKey, `{"id":"op-3","current_step":1}`)
+	put(cache.DataPrefix("operation")+"op-4", fmt.Sprintf(`{"id":"op-4","schema_version":%d}`, Versions()["operation"]+1))
+	if _, err := Run(store.EtcdConfig(), false); err == nil {
+		t.Error("expected data stored by a newer binary to be refused")
+	}
+	if strings.Contains(get(outdatedKey), "completed_steps") {
+		t.Error("expected nothing to be migrated once data stored by a newer binary is found")
+	}
+}
@@ -0,0 +1,66 @@
+package schema_migration
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+/*
+every change to how a collection is stored comes with a migration here, appended with the next version
+deprecated fields go away from schema only once a migration has moved their data out of stored records
+*/
+func init() {
+	Register(Migration{
+		Collection:  "operation",
+		Version:     1,
+		Description: "track completed steps one by one instead of current step",
+		Migrate: func(record map[string]interface{}) (bool, error) {
+			if record["completed_steps"] != nil {
+				return false, nil
+			}
+			currentStep := int64(0)
+			if value, ok := record["current_step"].(json.Number); ok {
+				var err error
+				if currentStep, err = value.Int64(); err != nil {
+					return false, err
+				}
+			}
+			// at the time only steps were run one after another, every step before current step is done
+			completedSteps := map[string]interface{}{}
+			for index := int64(0); index < currentStep; index++ {
+				completedSteps[strconv.FormatInt(index, 10)] = 0
+			}
+			record["completed_steps"] = completedSteps
+			return true, nil
+		},
+	})
+
+	Register(Migration{
+		Collection:  "cluster",
+		Version:     1,
+		Description: "drop allow virtual kubelet of control plane which is always off since virtual kubelet is deprecated",
+		Migrate: func(record map[string]interface{}) (bool, error) {
+			controlPlane, ok := record["control_plane"].(map[string]interface{})
+			if !ok {
+				return false, nil
+			}
+			if _, found := controlPlane["allow_virtual_kubelet"]; !found {
+				return false, nil
+			}
+			delete(controlPlane, "allow_virtual_kubelet")
+			return true, nil
+		},
+	})
+
+	// nothing to change yet, version 1 only stamps records so data saved by a newer binary is told apart
+	for _, collection := range []string{"node", "user", "role", "role-binding", "mfa", "api-token", "session", "upgrade-plan", "top-level-domain", "sub-domain", "region"} {
+		Register(Migration{
+			Collection:  collection,
+			Version:     1,
+			Description: "start tracking schema version of " + collection,
+			Migrate: func(record map[string]interface{}) (bool, error) {
+				return false, nil
+			},
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package schema_migration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s-installer/pkg/cache"
+)
+
+/*
+Migration upgrades records of one collection by one version
+records are handed over as generic json so fields long gone from schema are still reachable
+Migrate returns false when record needs no change, it must be idempotent
+an older server saving a migrated record drops schema version of it, so the record is migrated again
+*/
+type Migration struct {
+	// same name cache stores collection with, e.g. cluster, operation
+	Collection  string
+	Version     int
+	Description string
+	Migrate     func(record map[string]interface{}) (bool, error)
+}
+
+// field every record keeps schema version it is saved at in, see SchemaVersion of schema.Cluster for one
+const schemaVersionField = "schema_version"
+
+// migrations by collection sorted by version
+var registry = map[string][]Migration{}
+
+/*
+Register adds migration of its collection to registry, versions of a collection start at 1 and go up one by one
+a gap means a migration is missing which is a bug, so it panics
+*/
+func Register(migration Migration) {
+	registered := registry[migration.Collection]
+	if migration.Version != len(registered)+1 {
+		panic(fmt.Sprintf("migration %s of %s is version %d, version %d is expected", migration.Description, migration.Collection, migration.Version, len(registered)+1))
+	}
+	registry[migration.Collection] = append(registered, migration)
+	cache.SetSchemaVersion(migration.Collection, migration.Version)
+}
+
+/*
+Versions is the version of every collection this binary stores
+collections without migrations are at version 0 and left out
+*/
+func Versions() map[string]int {
+	versions := map[string]int{}
+	for collection, migrations := range registry {
+		versions[collection] = len(migrations)
+	}
+	return versions
+}
+
+func collections() []string {
+	var result []string
+	for collection := range registry {
+		result = append(result, collection)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// pending returns migrations to run on record of collection at version
+func pending(collection string, version int) []Migration {
+	migrations := registry[collection]
+	if version >= len(migrations) {
+		return nil
+	}
+	return migrations[version:]
+}
+
+/*
+upgrade runs migrations a record of collection is missing in order, starting from schema version saved in it
+it returns record moved up to current version, nil when record is already at it, along with version record was at
+changed is false when no migration changed anything but schema version
+numbers are kept as they are rather than converted to float so large ids survive
+*/
+func upgrade(collection string, value []byte) (data []byte, version int, changed bool, err error) {
+	record := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, 0, false, err
+	}
+	if saved, ok := record[schemaVersionField].(json.Number); ok {
+		parsed, err := saved.Int64()
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("schema version %s is broken: %w", saved, err)
+		}
+		version = int(parsed)
+	}
+	if known := len(registry[collection]); version > known {
+		return nil, version, false, fmt.Errorf("record is saved at schema version %d while this binary only knows version %d, upgrade binary first", version, known)
+	}
+	migrations := pending(collection, version)
+	if len(migrations) == 0 {
+		return nil, version, false, nil
+	}
+	for _, migration := range migrations {
+		migrated, err := migration.Migrate(record)
+		if err != nil {
+			return nil, version, false, fmt.Errorf("migration %s to version %d failed: %w", migration.Description, migration.Version, err)
+		}
+		changed = changed || migrated
+	}
+	record[schemaVersionField] = migrations[len(migrations)-1].Version
+	data, err = json.Marshal(record)
+	return data, version, changed, err
+}
@@ -0,0 +1,154 @@
+package schema_migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+)
+
+const runTimeout = 10 * time.Minute
+
+/*
+Report is what migration does, or would do with dry run, to one collection
+*/
+type Report struct {
+	Collection string
+	// lowest schema version records are found at
+	FromVersion int
+	ToVersion   int
+	Migrations  []string
+	// records changed by migrations
+	Records int
+	// records migrations find already in shape, only their schema version is moved up
+	Stamped int
+}
+
+func (report Report) String() string {
+	return fmt.Sprintf("%s from version %d to %d, %d records changed and %d records stamped by: %s", report.Collection, report.FromVersion, report.ToVersion, report.Records, report.Stamped, strings.Join(report.Migrations, " , "))
+}
+
+// outdated is a record read at revision and what it looks like at current schema version
+type outdated struct {
+	collection string
+	key        string
+	revision   int64
+	data       []byte
+}
+
+/*
+Run brings every record in store up to the version of this binary by the schema version saved in the record, nothing is saved with dryRun
+records rather than collections carry version, so records an older server keeps saving during a rolling upgrade are picked up by next run
+it refuses to touch anything when any record is saved by a newer binary, running against it loses whatever that binary added
+*/
+func Run(config etcdClientConfig.EtcdConfig, dryRun bool) ([]Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	client, err := (&cache.EtcdClient{EtcdClientConfig: config}).Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	// every record is checked before any is saved
+	var reports []Report
+	var records []outdated
+	for _, collection := range collections() {
+		report, found, err := checkCollection(ctx, client, collection)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		reports = append(reports, report)
+		records = append(records, found...)
+	}
+	if dryRun {
+		return reports, nil
+	}
+	for _, record := range records {
+		if err := saveRecord(ctx, client, record); err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+// checkCollection returns every record of collection which is not at current schema version yet
+func checkCollection(ctx context.Context, client *etcdv3.Client, collection string) (Report, []outdated, error) {
+	report := Report{Collection: collection, ToVersion: len(registry[collection])}
+	prefix := cache.DataPrefix(collection)
+	if prefix == "" {
+		return report, nil, fmt.Errorf("collection %s is unknown to cache", collection)
+	}
+	response, err := client.Get(ctx, prefix, etcdv3.WithPrefix())
+	if err != nil {
+		return report, nil, err
+	}
+	report.FromVersion = report.ToVersion
+	var found []outdated
+	for _, kv := range response.Kvs {
+		data, version, changed, err := upgrade(collection, kv.Value)
+		if err != nil {
+			return report, nil, fmt.Errorf("failed to migrate %s: %w", string(kv.Key), err)
+		}
+		if data == nil {
+			continue
+		}
+		if changed {
+			report.Records++
+		} else {
+			report.Stamped++
+		}
+		if version < report.FromVersion {
+			report.FromVersion = version
+		}
+		found = append(found, outdated{collection: collection, key: string(kv.Key), revision: kv.ModRevision, data: data})
+	}
+	for _, migration := range pending(collection, report.FromVersion) {
+		report.Migrations = append(report.Migrations, migration.Description)
+	}
+	return report, found, nil
+}
+
+/*
+saveRecord saves record only if nobody changed it since read
+servers still running save records along, a record changed in the meantime is read and migrated again
+*/
+func saveRecord(ctx context.Context, client *etcdv3.Client, record outdated) error {
+	for retry := 0; retry < 5; retry++ {
+		saved, err := client.Txn(ctx).
+			If(etcdv3.Compare(etcdv3.ModRevision(record.key), "=", record.revision)).
+			Then(etcdv3.OpPut(record.key, string(record.data))).
+			Commit()
+		if err != nil {
+			return err
+		}
+		if saved.Succeeded {
+			return nil
+		}
+		response, err := client.Get(ctx, record.key)
+		if err != nil {
+			return err
+		}
+		// deleted in the meantime
+		if len(response.Kvs) == 0 {
+			return nil
+		}
+		data, _, _, err := upgrade(record.collection, response.Kvs[0].Value)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", record.key, err)
+		}
+		if data == nil {
+			return nil
+		}
+		record.revision, record.data = response.Kvs[0].ModRevision, data
+	}
+	return fmt.Errorf("%s keeps changing during migration, gave up migrating it", record.key)
+}
@@ -1,10 +1,12 @@
 package client_liveness
 
 import (
+	"fmt"
 	"k8s-installer/pkg/cache"
 	"k8s-installer/pkg/config/server"
 	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/network"
+	"k8s-installer/schema"
 	"time"
 )
 
@@ -37,8 +39,14 @@ func Detector(config server.Config, runtimeCache cache.ICache) {
 	for _, node := range nodes {
 		if err := network.CheckIpIsReachable(node.Ipv4DefaultIp, config.SignalPort, "tcp", 2*time.Second); err != nil {
 			log.Warnf("Detect Agent: agent is dead of node: %s", node.Id)
-			node.AgentStatus = "unReachable"
-			if err := runtimeCache.SaveOrUpdateNodeInformation(node.Id, node); err != nil {
+			// nodes may take a while to probe, only agent status is changed on the latest node
+			if _, err := cache.UpdateNodeInformation(runtimeCache, node.Id, func(latest *schema.NodeInformation) error {
+				if latest.ResourceVersion == 0 {
+					return fmt.Errorf("node %s is deleted", node.Id)
+				}
+				latest.AgentStatus = "unReachable"
+				return nil
+			}); err != nil {
 				log.Errorf("Detect Agent: Failed to update agent status  of node: %s  due to error: %s", node.Id, err.Error())
 				return
 			}
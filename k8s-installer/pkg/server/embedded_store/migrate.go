@@ -0,0 +1,83 @@
+package embedded_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+)
+
+const (
+	migrateTimeout = 10 * time.Minute
+	// etcd refuses transactions with more than 128 operations by default
+	migrateBatchSize = 100
+)
+
+/*
+Migrate copies every key in from to to and returns how many keys are copied
+keys bound to a lease (server registry, leader election) belong to servers running against from and are left behind
+to must be empty unless overwrite, so running migration twice by mistake does not mix two sets of data up
+servers using either store should be stopped first, anything written during migration may be lost
+*/
+func Migrate(from, to etcdClientConfig.EtcdConfig, overwrite bool) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+	source, err := (&cache.EtcdClient{EtcdClientConfig: from}).Connect()
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to source store: %w", err)
+	}
+	defer source.Close()
+	target, err := (&cache.EtcdClient{EtcdClientConfig: to}).Connect()
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to target store: %w", err)
+	}
+	defer target.Close()
+
+	if !overwrite {
+		existing, err := target.Get(ctx, "", etcdv3.WithPrefix(), etcdv3.WithCountOnly())
+		if err != nil {
+			return 0, err
+		}
+		if existing.Count > 0 {
+			return 0, fmt.Errorf("target store already has %d keys, migrate with overwrite to copy over them", existing.Count)
+		}
+	}
+
+	// empty key with prefix is every key
+	response, err := source.Get(ctx, "", etcdv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	var batch []etcdv3.Op
+	copied := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := target.Txn(ctx).Then(batch...).Commit(); err != nil {
+			return err
+		}
+		copied += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for _, kv := range response.Kvs {
+		if kv.Lease != 0 {
+			continue
+		}
+		batch = append(batch, etcdv3.OpPut(string(kv.Key), string(kv.Value)))
+		if len(batch) == migrateBatchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return copied, err
+	}
+	return copied, nil
+}
@@ -0,0 +1,87 @@
+package embedded_store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"k8s-installer/pkg/cache"
+	cacheConfig "k8s-installer/pkg/config/cache"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+)
+
+func freeURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return fmt.Sprintf("http://%s", listener.Addr().String())
+}
+
+func startStore(t *testing.T) *Store {
+	dir, err := ioutil.TempDir("", "embedded-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	store, err := Start(cacheConfig.EmbeddedStoreConfig{
+		DataDir:         dir,
+		ListenClientURL: freeURL(t),
+		ListenPeerURL:   freeURL(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestMigrate(t *testing.T) {
+	from, to := startStore(t), startStore(t)
+	client, err := (&cache.EtcdClient{EtcdClientConfig: from.EtcdConfig()}).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	for i := 0; i < migrateBatchSize+5; i++ {
+		if _, err := client.Put(ctx, fmt.Sprintf("/nodes/node-%d", i), "{}"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lease, err := client.Grant(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Put(ctx, "/server-registry/server-1", "alive", etcdv3.WithLease(lease.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := Migrate(from.EtcdConfig(), to.EtcdConfig(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != migrateBatchSize+5 {
+		t.Errorf("expected every key but the one bound to lease to be copied got %d", copied)
+	}
+	target, err := (&cache.EtcdClient{EtcdClientConfig: to.EtcdConfig()}).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	if response, err := target.Get(ctx, "/server-registry/", etcdv3.WithPrefix(), etcdv3.WithCountOnly()); err != nil || response.Count != 0 {
+		t.Errorf("expected server registry to be left behind got %v %v", response, err)
+	}
+
+	if _, err := Migrate(from.EtcdConfig(), to.EtcdConfig(), false); err == nil {
+		t.Error("expected migrating to a store with data to be refused")
+	}
+	if _, err := Migrate(from.EtcdConfig(), to.EtcdConfig(), true); err != nil {
+		t.Errorf("expected migrating with overwrite to work got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package embedded_store
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	cacheConfig "k8s-installer/pkg/config/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	"k8s-installer/pkg/log"
+
+	"go.etcd.io/etcd/embed"
+)
+
+// how long a store with plenty of data takes to replay its wal and serve
+const startTimeout = time.Minute
+
+/*
+Store is a single member etcd running inside server process
+every cache store talks to it through etcd client the same way they talk to an external etcd
+so nothing but the endpoint changes, watches, leases and transactions all keep working
+*/
+type Store struct {
+	etcd   *embed.Etcd
+	config cacheConfig.EmbeddedStoreConfig
+}
+
+/*
+Start starts store with data in config.DataDir and returns once it is ready to serve
+data dir is locked while store runs, a second server or migration on the same dir fails to start
+*/
+func Start(config cacheConfig.EmbeddedStoreConfig) (*Store, error) {
+	clientURL, err := url.Parse(config.ListenClientURL)
+	if err != nil {
+		return nil, fmt.Errorf("listen client url %s is invalid: %w", config.ListenClientURL, err)
+	}
+	peerURL, err := url.Parse(config.ListenPeerURL)
+	if err != nil {
+		return nil, fmt.Errorf("listen peer url %s is invalid: %w", config.ListenPeerURL, err)
+	}
+	etcdConfig := embed.NewConfig()
+	etcdConfig.Name = "k8s-installer"
+	etcdConfig.Dir = config.DataDir
+	etcdConfig.LCUrls, etcdConfig.ACUrls = []url.URL{*clientURL}, []url.URL{*clientURL}
+	etcdConfig.LPUrls, etcdConfig.APUrls = []url.URL{*peerURL}, []url.URL{*peerURL}
+	etcdConfig.InitialCluster = etcdConfig.InitialClusterFromName(etcdConfig.Name)
+	// nobody else is around to compact history, without it db grows with every report in
+	etcdConfig.AutoCompactionMode = embed.CompactorModePeriodic
+	etcdConfig.AutoCompactionRetention = "1h"
+	etcdConfig.Logger = "zap"
+	etcdConfig.LogLevel = "error"
+
+	etcd, err := embed.StartEtcd(etcdConfig)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-etcd.Server.ReadyNotify():
+	case err := <-etcd.Err():
+		etcd.Close()
+		return nil, err
+	case <-time.After(startTimeout):
+		etcd.Server.Stop()
+		etcd.Close()
+		return nil, errors.New("embedded store takes too long to start")
+	}
+	log.Debugf("Embedded store is serving %s with data in %s", config.ListenClientURL, config.DataDir)
+	return &Store{etcd: etcd, config: config}, nil
+}
+
+// EtcdConfig is how cache connects to store
+func (store *Store) EtcdConfig() etcdClientConfig.EtcdConfig {
+	return etcdClientConfig.EtcdConfig{
+		AuthMode:  "none",
+		EndPoints: store.config.ListenClientURL,
+	}
+}
+
+// Close stops store, data is flushed to disk first
+func (store *Store) Close() {
+	store.etcd.Close()
+}
@@ -0,0 +1,91 @@
+package high_availability
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s-installer/pkg/cache"
+	etcdClientConfig "k8s-installer/pkg/config/etcd_client"
+	haConfig "k8s-installer/pkg/config/high_availability"
+	"k8s-installer/pkg/log"
+
+	etcdv3 "go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/clientv3/concurrency"
+)
+
+/*
+Run keeps server registered in etcd and campaigns for leadership until stopChan is closed
+onJoined runs once after server registers itself for the first time
+leaderLoops only run while server is leader, stop chan given to them is closed once leadership is lost
+a server lost its lease (e.g. cut off from etcd longer than lease ttl) rejoins as a new member
+*/
+func Run(config haConfig.HighAvailabilityConfig, etcdConfig etcdClientConfig.EtcdConfig, serverId string, onJoined func(), leaderLoops []func(stopChan <-chan struct{}), stopChan <-chan struct{}) {
+	var joinOnce sync.Once
+	for {
+		err := runSession(config, etcdConfig, serverId, func() { joinOnce.Do(onJoined) }, leaderLoops, stopChan)
+		select {
+		case <-stopChan:
+			return
+		default:
+		}
+		log.Errorf("Server %s lost its membership due to error: %s, rejoin in %d seconds", serverId, err, config.LeaseTTL)
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(time.Duration(config.LeaseTTL) * time.Second):
+		}
+	}
+}
+
+func runSession(config haConfig.HighAvailabilityConfig, etcdConfig etcdClientConfig.EtcdConfig, serverId string, onJoined func(), leaderLoops []func(stopChan <-chan struct{}), stopChan <-chan struct{}) error {
+	client, err := (&cache.EtcdClient{EtcdClientConfig: etcdConfig}).Connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	// lease is kept alive by session in background, closing session revokes it so others know at once
+	session, err := concurrency.NewSession(client, concurrency.WithTTL(config.LeaseTTL))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopChan:
+		case <-session.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	registeredAt := time.Now().Format("2006-01-02T15:04:05Z07:00")
+	if _, err := client.Put(ctx, cache.ServerRegistryKey(serverId), registeredAt, etcdv3.WithLease(session.Lease())); err != nil {
+		return err
+	}
+	log.Infof("Server %s joined with lease %x", serverId, session.Lease())
+	onJoined()
+
+	// blocks until this server is leader
+	election := concurrency.NewElection(session, cache.LeaderElectionPrefix())
+	if err := election.Campaign(ctx, serverId); err != nil {
+		return err
+	}
+	log.Infof("Server %s is elected as leader", serverId)
+	leaderStop := make(chan struct{})
+	defer close(leaderStop)
+	for _, loop := range leaderLoops {
+		go loop(leaderStop)
+	}
+
+	select {
+	case <-stopChan:
+		return nil
+	case <-session.Done():
+		return errors.New("lease expired")
+	}
+}
@@ -57,7 +57,7 @@ func (c AddOnStorageCeph) DeployOrRemoveAddOn(operation *schema.Operation, clust
 	if action == constants.ActionCreate {
 		// create namespace first
 		operation.Step[len(operation.Step)] = &schema.Step{
-			Id:   "step-create-namespace" + operation.Id,
+			Id:   "step-create-ceph-namespace" + operation.Id,
 			Name: "step-create-namespaces",
 			NodeSteps: []schema.NodeStep{
 				{
@@ -114,7 +114,7 @@ func (params AddClusterDnsUpstream) DeployOrRemoveAddOn(operation *schema.Operat
 func (params AddClusterDnsUpstream) LivenessProbe(operationId string, cluster schema.Cluster, config serverConfig.Config) (schema.Step, error) {
 	// implement you LivenessProbe step to detect you addons state
 	return schema.Step{
-		Id:                       "stepCheckKubesphere-" + operationId,
+		Id:                       "stepCheckClusterDnsUpstream-" + operationId,
 		Name:                     "StepCheckKubesphere",
 		NodeSteps:                []schema.NodeStep{},
 		OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{},
@@ -273,6 +273,17 @@ func (a AddOnClusterLB) DeployOrRemoveAddonsWithCluster(operation *schema.Operat
 	return a.removeAddOn(operation, cluster, cluster.Action)
 }
 
+/*
+StartAfter lets load balancer be installed on its nodes while cluster is being set up, it only needs basic setup of nodes
+load balancer is removed along with cluster as it was, nil meaning no early start
+*/
+func (a AddOnClusterLB) StartAfter(operation schema.Operation, cluster schema.Cluster) []string {
+	if cluster.Action != constants.ActionCreate {
+		return nil
+	}
+	return []string{basicSetupStepId(operation.Id)}
+}
+
 func (a AddOnClusterLB) getLBNodesSet(clusterLB *schema.ClusterLB) (LBNodeSet, error) {
 	set := LBNodeSet{}
 	rc := cache.GetCurrentCache()
@@ -336,7 +347,7 @@ func (a AddOnClusterLB) deployAddOn(operation *schema.Operation, cluster schema.
 	keepalivedDepDir := path.Join(constants.DepResourceDir, KeepalivedSubDir)
 	nodeSteps = a.installKeepalivedDeps(lbNodeSet, action, keepalivedDepDir)
 	step = &schema.Step{
-		Id:        "stepSetupKeepalivedService-" + operation.Id,
+		Id:        "stepInstallKeepalivedDependencies-" + operation.Id,
 		Name:      "InstallKeeplivedDep",
 		NodeSteps: nodeSteps,
 		OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{
@@ -132,7 +132,7 @@ func (params AddDnsServerDeploy) DeployOrRemoveAddOn(operation *schema.Operation
 func (params AddDnsServerDeploy) LivenessProbe(operationId string, cluster schema.Cluster, config serverConfig.Config) (schema.Step, error) {
 	// implement you LivenessProbe step to detect you addons state
 	return schema.Step{
-		Id:                       "stepCheckKubesphere-" + operationId,
+		Id:                       "stepCheckDnsServerDeploy-" + operationId,
 		Name:                     "StepCheckKubesphere",
 		NodeSteps:                []schema.NodeStep{},
 		OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{},
@@ -227,7 +227,7 @@ type AddOnKsClusterConf struct {
 func (ks AddOnKsClusterConf) LivenessProbe(operationId string, cluster schema.Cluster, config serverConfig.Config) (schema.Step, error) {
 	// implement you LivenessProbe step to detect you addons state
 	return schema.Step{
-		Id:                       "stepCheckKubesphere-" + operationId,
+		Id:                       "stepCheckKsClusterConf-" + operationId,
 		Name:                     "StepCheckKubesphere",
 		NodeSteps:                []schema.NodeStep{},
 		OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{},
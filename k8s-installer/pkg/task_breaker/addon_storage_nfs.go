@@ -57,7 +57,7 @@ func (nfs AddOnStorageNFS) DeployOrRemoveAddOn(operation *schema.Operation, clus
 	if action == constants.ActionCreate {
 		// create namespace first
 		operation.Step[len(operation.Step)] = &schema.Step{
-			Id:   "step-create-namespace" + operation.Id,
+			Id:   "step-create-nfs-namespace" + operation.Id,
 			Name: "step-create-namespaces",
 			NodeSteps: []schema.NodeStep{
 				{
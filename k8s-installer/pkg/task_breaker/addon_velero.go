@@ -128,8 +128,8 @@ aws_secret_access_key = minio123`
 	}
 
 	operation.Step[len(operation.Step)] = &schema.Step{
-		Id:   "stepCreateChartAndInstall" + operation.Id,
-		Name: "stepCreateChartAndInstall",
+		Id:   "stepInstallVelero" + operation.Id,
+		Name: "stepInstallVelero",
 		NodeSteps: []schema.NodeStep{
 			installVelero(cluster.Masters[0].NodeId, params.TaskTimeOut),
 		},
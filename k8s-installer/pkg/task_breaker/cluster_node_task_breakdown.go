@@ -97,6 +97,8 @@ func (breakdown *ClusterNodeTaskBreakDown) BreakDownTask() (schema.Operation, er
 	}
 
 	breakdown.Operation.Step = map[int]*schema.Step{}
+	// cri and local vip of new nodes only wait for hostname to be renamed, join waits for all of them
+	var prepareNewNodes []string
 
 	if breakdown.Action == constants.ActionCreate {
 		stepPrintJoinString := &schema.Step{
@@ -113,13 +115,19 @@ func (breakdown *ClusterNodeTaskBreakDown) BreakDownTask() (schema.Operation, er
 				return breakdown.Operation, errRenameWorkerStep
 			}
 
+			renamedNodes := map[string]schema.NodeInformation{}
+			for _, node := range breakdown.NodesToAddOrRemove {
+				renamedNodes[node.NodeId] = breakdown.NodeCollectionList[node.NodeId]
+			}
 			stepRenameWorker := &schema.Step{
-				Id:        "step1-" + breakdown.Operation.Id,
-				Name:      "RenameWorkerHostname",
-				NodeSteps: renameWorkerStep,
+				Id:                "step1-" + breakdown.Operation.Id,
+				Name:              "RenameWorkerHostname",
+				NodeSteps:         renameWorkerStep,
+				RollbackNodeSteps: createRestoreHostnameNodeTask(renamedNodes, 5),
 			}
 
 			breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepRenameWorker
+			prepareNewNodes = []string{stepRenameWorker.Id}
 		}
 
 	} else if breakdown.Action == constants.ActionDelete {
@@ -193,6 +201,10 @@ func (breakdown *ClusterNodeTaskBreakDown) BreakDownTask() (schema.Operation, er
 			// only ignore error when deleting
 			// in case the node failed to join workers previously during setup cri
 			stepStepUpCRI.OnStepDoneOrErrorHandler = OnErrorIgnoreHandler{}
+		} else {
+			stepStepUpCRI.RetryPolicy = downloadRetryPolicy
+			stepStepUpCRI.RollbackNodeSteps = createCRINodeTask(breakdown.Cluster.ContainerRuntime, workerMapping, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskCRI)
+			stepStepUpCRI.DependsOn = append([]string{}, prepareNewNodes...)
 		}
 
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepStepUpCRI
@@ -202,6 +214,10 @@ func (breakdown *ClusterNodeTaskBreakDown) BreakDownTask() (schema.Operation, er
 			Name:      "AddOrRemoveLocalVip",
 			NodeSteps: createWorkNodeVipNodeTask(workerMapping, masterNodeCollection, breakdown.Action, breakdown.Config.TaskTimeOut.TaskVip),
 		}
+		if breakdown.Action == constants.ActionCreate {
+			stepSetUpLocalHaproxy.RollbackNodeSteps = createWorkNodeVipNodeTask(workerMapping, masterNodeCollection, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskVip)
+			stepSetUpLocalHaproxy.DependsOn = append([]string{}, prepareNewNodes...)
+		}
 
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepSetUpLocalHaproxy
 
@@ -210,6 +226,10 @@ func (breakdown *ClusterNodeTaskBreakDown) BreakDownTask() (schema.Operation, er
 			Name:      "JoinOrDestroyWorkNode",
 			NodeSteps: createKubeadmJoinWorkerNodeTask(workerMapping, breakdown.Action, breakdown.Config.TaskTimeOut.TaskKubeadmJoinWorker),
 		}
+		if breakdown.Action == constants.ActionCreate {
+			stepJoinWorker.RollbackNodeSteps = createKubeadmJoinWorkerNodeTask(workerMapping, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskKubeadmJoinWorker)
+			stepJoinWorker.DependsOn = []string{"step0-" + breakdown.Operation.Id, stepStepUpCRI.Id, stepSetUpLocalHaproxy.Id}
+		}
 
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepJoinWorker
 
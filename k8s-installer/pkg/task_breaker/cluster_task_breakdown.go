@@ -26,6 +26,11 @@ type ClusterTaskBreakDown struct {
 	SumLicenseLabel    uint16
 }
 
+// basicSetupStepId is id of step preparing every node of cluster being created, steps not needing cluster to be up start after it
+func basicSetupStepId(operationId string) string {
+	return "step1-" + operationId
+}
+
 /*
 break down input object to task with seq
 */
@@ -48,6 +53,9 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 	//virtual kubelet collection
 	virtualKubeletCollection := map[string]schema.ClusterNode{}
 
+	// steps started early instead of after every step before them, only when creating
+	var branches []stepBranch
+
 	// also we have make sure master`s hostname has to be unique
 	var hosts []string
 	hostnameChecker := map[string]byte{}
@@ -94,6 +102,19 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		NodeSteps: []schema.NodeStep{InitFirstControlPlaneNodeTask},
 	}
 
+	if breakdown.Cluster.Action == constants.ActionCreate {
+		step2.RetryPolicy = downloadRetryPolicy
+		// undo cri and kubeadm init if operation fails later on and rollback is asked
+		step2.RollbackNodeSteps = createCRINodeTask(breakdown.Cluster.ContainerRuntime, reducedNodeList, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskCRI)
+		destroyFirstControlPlaneNodeTask, err := createKubeadmInitFirstControlPlaneNodeTask(
+			breakdown.Cluster.Masters[0].NodeId, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskKubeadmInitFirstControlPlane,
+			breakdown.Cluster, breakdown.NodeCollectionList)
+		if err != nil {
+			return breakdown.Operation, err
+		}
+		step3.RollbackNodeSteps = []schema.NodeStep{destroyFirstControlPlaneNodeTask}
+	}
+
 	if breakdown.Operation.Step == nil {
 		breakdown.Operation.Step = map[int]*schema.Step{}
 	}
@@ -191,15 +212,16 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		// always rename the hostname for better control of cluster for future
 		if breakdown.Config.EnableHostnameRename {
 			stepRenameMaster := &schema.Step{
-				Id:        "step0-" + breakdown.Operation.Id,
-				Name:      "RenameMastersHostname",
-				NodeSteps: createRenameHostnameNodeTask(masterNodeCollection, nodeToHostNameMapping, breakdown.Cluster.ClusterId, constants.MasterHostnameSuffix, breakdown.Config.TaskTimeOut.TaskRenameHostName, hosts),
+				Id:                "step0-" + breakdown.Operation.Id,
+				Name:              "RenameMastersHostname",
+				NodeSteps:         createRenameHostnameNodeTask(masterNodeCollection, nodeToHostNameMapping, breakdown.Cluster.ClusterId, constants.MasterHostnameSuffix, breakdown.Config.TaskTimeOut.TaskRenameHostName, hosts),
+				RollbackNodeSteps: createRestoreHostnameNodeTask(masterNodeCollection, breakdown.Config.TaskTimeOut.TaskRenameHostName),
 			}
 			breakdown.Operation.Step[0] = stepRenameMaster
 		}
 
 		step1 := &schema.Step{
-			Id:        "step1-" + breakdown.Operation.Id,
+			Id:        basicSetupStepId(breakdown.Operation.Id),
 			Name:      "BasicSetup",
 			NodeSteps: createBasicConfigNodeTask(reducedNodeList, breakdown.Cluster.Action, breakdown.Config.TaskTimeOut.TaskBasicConfig),
 			// now you can define you own step handler
@@ -218,6 +240,10 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 			OnStepTimeOutHandler:     OnTimeOutIgnoreHandler{},
 		}
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepLogSetupDep
+
+		// promtail is not required by cri installation
+		// so let them run at the same time
+		step2.DependsOn = []string{step1.Id}
 	}
 
 	breakdown.Operation.Step[len(breakdown.Operation.Step)] = step2
@@ -233,6 +259,9 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		}
 		stepJoinControlPlane.Name = "StepJoinControlPlane"
 		stepJoinControlPlane.NodeSteps = createKubeadmJoinControlPlaneNodeTask(joinControlPlaneList, breakdown.Cluster.Action, breakdown.Config.TaskTimeOut.TaskKubeadmJoinControlPlane)
+		if breakdown.Cluster.Action == constants.ActionCreate {
+			stepJoinControlPlane.RollbackNodeSteps = createKubeadmJoinControlPlaneNodeTask(joinControlPlaneList, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskKubeadmJoinControlPlane)
+		}
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepJoinControlPlane
 	}
 
@@ -314,9 +343,10 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		if breakdown.Cluster.Action == constants.ActionCreate {
 			if breakdown.Config.EnableHostnameRename {
 				stepRenameWorker := &schema.Step{
-					Id:        "step0-" + breakdown.Operation.Id,
-					Name:      "RenameWorkersHostname",
-					NodeSteps: createRenameHostnameNodeTask(reducedNodeList, nodeToHostNameMapping, breakdown.Cluster.ClusterId, constants.WorkerHostnameSuffix, breakdown.Config.TaskTimeOut.TaskRenameHostName, hosts),
+					Id:                "stepRenameWorkers-" + breakdown.Operation.Id,
+					Name:              "RenameWorkersHostname",
+					NodeSteps:         createRenameHostnameNodeTask(reducedNodeList, nodeToHostNameMapping, breakdown.Cluster.ClusterId, constants.WorkerHostnameSuffix, breakdown.Config.TaskTimeOut.TaskRenameHostName, hosts),
+					RollbackNodeSteps: createRestoreHostnameNodeTask(reducedNodeList, breakdown.Config.TaskTimeOut.TaskRenameHostName),
 				}
 
 				breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepRenameWorker
@@ -332,6 +362,9 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepSetupLocalVip
 
 		if breakdown.Cluster.Action == constants.ActionCreate {
+			// local vip only points to masters, it is set up on workers while control plane comes up
+			branches = append(branches, stepBranch{from: len(breakdown.Operation.Step) - 1, to: len(breakdown.Operation.Step), dependsOn: []string{basicSetupStepId(breakdown.Operation.Id)}})
+			stepSetupLocalVip.RollbackNodeSteps = createWorkNodeVipNodeTask(reducedNodeList, masterNodeCollection, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskVip)
 			stepWaitBeforeJoinWorker := CreateWaitStep(5, "wait-control-plane-to-active")
 			breakdown.Operation.Step[len(breakdown.Operation.Step)] = &stepWaitBeforeJoinWorker
 		}
@@ -341,6 +374,9 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 			Name:      "JoinOrDestroyWorkNode",
 			NodeSteps: createKubeadmJoinWorkerNodeTask(reducedNodeList, breakdown.Cluster.Action, breakdown.Config.TaskTimeOut.TaskKubeadmJoinWorker),
 		}
+		if breakdown.Cluster.Action == constants.ActionCreate {
+			stepJoinWorker.RollbackNodeSteps = createKubeadmJoinWorkerNodeTask(reducedNodeList, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskKubeadmJoinWorker)
+		}
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepJoinWorker
 	}
 
@@ -461,6 +497,11 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 			Name:      "StepSetupExternalLB",
 			NodeSteps: createExternalLBNodeTask(breakdown.Cluster.ExternalLB, breakdown.Cluster.ExternalLB.NodeIds, breakdown.Cluster.Action, breakdown.Config.TaskTimeOut.TaskVip),
 		}
+		if breakdown.Cluster.Action == constants.ActionCreate {
+			stepSetupExternalLB.RollbackNodeSteps = createExternalLBNodeTask(breakdown.Cluster.ExternalLB, breakdown.Cluster.ExternalLB.NodeIds, constants.ActionDelete, breakdown.Config.TaskTimeOut.TaskVip)
+			// external lb nodes are not part of cluster, nothing there waits for cluster
+			branches = append(branches, stepBranch{from: len(breakdown.Operation.Step), to: len(breakdown.Operation.Step) + 1, dependsOn: []string{basicSetupStepId(breakdown.Operation.Id)}})
+		}
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = stepSetupExternalLB
 	}
 
@@ -696,9 +737,11 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 		TaskTimeOut: 20,
 	}.SetDataWithPlugin(breakdown.Cluster.AutoRestarter, breakdown.SumLicenseLabel, breakdown.Cluster))
 
-	if errs := InstallAddOnsWithCluster(addOnsRegister, &breakdown.Operation, breakdown.Cluster, breakdown.Config); len(errs) > 0 {
+	addOnBranches, errs := installAddOnsWithCluster(addOnsRegister, &breakdown.Operation, breakdown.Cluster, breakdown.Config)
+	if len(errs) > 0 {
 		// do nothing meaning we ignore add-on installation error
 	}
+	branches = append(branches, addOnBranches...)
 
 	if breakdown.Cluster.MiddlePlatform != nil &&
 		breakdown.Cluster.MiddlePlatform.Enable &&
@@ -713,6 +756,7 @@ func (breakdown *ClusterTaskBreakDown) BreakDownTask() (schema.Operation, error)
 
 		breakdown.Operation.Step[len(breakdown.Operation.Step)] = step
 	}
+	startEarly(&breakdown.Operation, branches)
 	return breakdown.Operation, nil
 }
 
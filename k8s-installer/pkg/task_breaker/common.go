@@ -1,8 +1,6 @@
 package task_breaker
 
 import (
-	"bytes"
-	"encoding/gob"
 	"errors"
 	"fmt"
 	"path"
@@ -320,17 +318,31 @@ func InstallAddOns(register *AddOnsRegister, operation *schema.Operation, cluste
 }
 
 func InstallAddOnsWithCluster(register *AddOnsRegister, operation *schema.Operation, cluster schema.Cluster, config serverConfig.Config) []error {
+	branches, errs := installAddOnsWithCluster(register, operation, cluster, config)
+	startEarly(operation, branches)
+	return errs
+}
+
+// installAddOnsWithCluster returns steps of add-ons able to start early, caller starts them early once every step is in operation
+func installAddOnsWithCluster(register *AddOnsRegister, operation *schema.Operation, cluster schema.Cluster, config serverConfig.Config) ([]stepBranch, []error) {
 	errs := []error{}
+	var branches []stepBranch
 	var livenessProbes []schema.Step
 	// create a wait step ensure all addons can properly setup
 	livenessProbes = append(livenessProbes, CreateWaitStep(3*60, "active-addons"))
 
 	for _, addon := range register.AddOns {
 		if addon.IsEnable() {
+			from := len(operation.Step)
 			if err := addon.DeployOrRemoveAddonsWithCluster(operation, cluster, config); err != nil {
 				errs = append(errs, err)
 				log.Errorf("Failed to breakdown task for add-on %s due to error %s", addon.GetAddOnName(), err.Error())
 			} else {
+				if earlyAddOn, ok := addon.(IStartEarlyAddOn); ok {
+					if dependsOn := earlyAddOn.StartAfter(*operation, cluster); dependsOn != nil {
+						branches = append(branches, stepBranch{from: from, to: len(operation.Step), dependsOn: dependsOn})
+					}
+				}
 				if lpStep, err := addon.LivenessProbe(operation.Id, cluster, config); err != nil {
 					errs = append(errs, err)
 					log.Errorf("Failed to setup add-on %s's liveness probe due to error: %s", addon.GetAddOnName(), err.Error())
@@ -346,7 +358,7 @@ func InstallAddOnsWithCluster(register *AddOnsRegister, operation *schema.Operat
 	if cluster.Action != constants.ActionDelete && len(livenessProbes) > 1 {
 		addStepsToOperation(operation, livenessProbes)
 	}
-	return errs
+	return branches, errs
 }
 
 func InstallAddOnsWithNodeAddOrRemove(register *AddOnsRegister, operation *schema.Operation, cluster schema.Cluster, config serverConfig.Config, nodeAction string, nodesToAddOrRemove []schema.ClusterNode) []error {
@@ -549,6 +561,35 @@ func createRenameHostnameNodeTask(masters map[string]schema.NodeInformation, nod
 	return nodeSteps
 }
 
+// downloadRetryPolicy is for node steps which download a lot from resource server and are safe to run again
+var downloadRetryPolicy = &schema.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10,
+	MaxBackoff:     60,
+	RetryOn:        []string{constants.RetryOnError, constants.RetryOnTimeout},
+}
+
+// createRestoreHostnameNodeTask set hostname of nodes back to what they were before being renamed
+func createRestoreHostnameNodeTask(nodes map[string]schema.NodeInformation, timeOut int) []schema.NodeStep {
+	var nodeSteps []schema.NodeStep
+	for nodeId, node := range nodes {
+		nodeSteps = append(nodeSteps, schema.NodeStep{
+			Id:     utils.GenNodeStepID(),
+			Name:   "TaskRestoreHostName-" + nodeId,
+			NodeID: nodeId,
+			Tasks: map[int]schema.ITask{
+				0: schema.TaskRenameHostName{
+					TaskType: constants.TaskTypeRenameHostname,
+					Action:   "",
+					TimeOut:  timeOut,
+					Hostname: node.SystemInfo.Node.Hostname,
+				},
+			},
+		})
+	}
+	return nodeSteps
+}
+
 func createWorkNodeVipNodeTask(nodeList, masters map[string]schema.NodeInformation, action string, timeOut int) []schema.NodeStep {
 	var nodeSteps []schema.NodeStep
 	// we always use haproxy as local proxy for kubernetes api server
@@ -770,29 +811,33 @@ func createLableNodeTask(firstMasterNodeId string, node schema.NodeInformation,
 	return nodeStep
 }
 
-func deepCopy(dst, src interface{}) error {
-	var buf bytes.Buffer
-	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
-		return err
-	}
-	return gob.NewDecoder(bytes.NewBuffer(buf.Bytes())).Decode(dst)
-}
-
 func GenerateDepMd5(dep depMap.DepMap) depMap.DepMap {
 
 	runtimeCache := cache.GetCurrentCache()
 	config := runtimeCache.GetServerRuntimeConfig(cache.NodeId)
 
+	// package files are kept per os version on resource server
+	// so md5 of every os version is saved under it`s own os key
 	md5Dep := make(depMap.DepMap)
-	deepCopy(&md5Dep, dep)
-
-	for osk, osv := range dep {
-		for kvk, kvv := range osv {
-			for arch, archv := range kvv {
-				for k, f := range archv {
-					fp := path.Join(config.ApiServer.ResourceServerFilePath, kvk, osk, "7", arch, "package", f)
-					md5 := fileutils.Md5Sum(fp)
-					md5Dep[osk][kvk][arch][k] = md5
+	for osFamily, osVersions := range constants.OSFamilyVersions {
+		for _, osVersion := range osVersions {
+			osKey := depMap.OSKey(osFamily, osVersion)
+			for _, depVersion := range []depMap.DepVersion{dep[osFamily], dep[osKey]} {
+				for kvk, kvv := range depVersion {
+					for arch := range kvv {
+						packages, _ := dep.Packages(osFamily, osVersion, kvk, arch)
+						if md5Dep[osKey] == nil {
+							md5Dep[osKey] = depMap.DepVersion{}
+						}
+						if md5Dep[osKey][kvk] == nil {
+							md5Dep[osKey][kvk] = depMap.DepArch{}
+						}
+						md5Dep[osKey][kvk][arch] = depMap.DepPackage{}
+						for k, f := range packages {
+							fp := path.Join(config.ApiServer.ResourceServerFilePath, kvk, osFamily, osVersion, arch, "package", f)
+							md5Dep[osKey][kvk][arch][k] = fileutils.Md5Sum(fp)
+						}
+					}
 				}
 			}
 		}
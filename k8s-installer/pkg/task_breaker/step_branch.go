@@ -0,0 +1,45 @@
+package task_breaker
+
+import (
+	"k8s-installer/schema"
+)
+
+/*
+stepBranch is a run of steps from index from up to index to (not included) which only need steps dependsOn to be done
+instead of every step before them, e.g. load balancer or local vip does not wait for control plane to be up
+*/
+type stepBranch struct {
+	from      int
+	to        int
+	dependsOn []string
+}
+
+/*
+IStartEarlyAddOn is implemented by add-ons whose steps do not need cluster to be up
+steps of it start once steps StartAfter returns are done, an empty one meaning they start along with operation
+*/
+type IStartEarlyAddOn interface {
+	StartAfter(operation schema.Operation, cluster schema.Cluster) []string
+}
+
+/*
+startEarly lets every branch start once its dependsOn are done, steps in a branch still run one after another
+the step right after a branch waits for both the branch and the step right before it, so the rest of operation runs as it did
+it must be called once every step is in operation, the step right after a branch is not known before that
+*/
+func startEarly(operation *schema.Operation, branches []stepBranch) {
+	for _, branch := range branches {
+		if branch.to <= branch.from {
+			continue
+		}
+		operation.Step[branch.from].DependsOn = branch.dependsOn
+		follower, found := operation.Step[branch.to]
+		if !found || follower.DependsOn != nil {
+			continue
+		}
+		follower.DependsOn = []string{operation.Step[branch.to-1].Id}
+		if branch.from > 0 {
+			follower.DependsOn = append(follower.DependsOn, operation.Step[branch.from-1].Id)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 
+	osInfoProvider "k8s-installer/node/os"
 	config "k8s-installer/pkg/config/server"
 	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/dep"
+	"k8s-installer/pkg/log"
 	"k8s-installer/pkg/task_breaker/utils"
 	"k8s-installer/schema"
 )
@@ -19,6 +21,10 @@ type UpgradingTaskBreakDown struct {
 	ApplyVersion     schema.UpgradableVersion
 	Dep              dep.DepMap
 	Config           config.Config
+	Strategy         schema.UpgradeStrategy
+	SumLicenseLabel  uint16
+	// PauseGate is asked once a batch passed health gate, returning true pauses the upgrade
+	PauseGate func(batch int) bool
 }
 
 func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) BreakDownTask() (schema.Operation, error) {
@@ -155,67 +161,58 @@ func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) BreakDownTask() (schema.Op
 		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepUnCordonMaster
 	}
 
-	for _, node := range UpgradingTaskBreakDown.Cluster.Workers {
-
-		// skip those workers share with masters
-		if _, found := masters[node.NodeId]; found {
-			continue
-		}
-
+	workers := UpgradingTaskBreakDown.workersToUpgrade(masters)
+	for _, node := range workers {
 		if _, found := UpgradingTaskBreakDown.NodesCollection[node.NodeId]; !found {
 			return UpgradingTaskBreakDown.Operation, errors.New(fmt.Sprintf("Failed to found node information with id: %s", node.NodeId))
 		}
+	}
 
-		// drain workers
-		stepCordonWorker := &schema.Step{
-			Id:   "cordon-worker-" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "cordons worker",
-			NodeSteps: []schema.NodeStep{
-				createCordonNodeStep(firstMasterNodeId, UpgradingTaskBreakDown.NodesCollection[node.NodeId].SystemInfo.Node.Hostname, false, []string{"--ignore-daemonsets", "--delete-local-data", "--force"}),
-			},
+	// control plane is the first batch
+	batches := [][]schema.ClusterNode{UpgradingTaskBreakDown.Cluster.Masters}
+	batchSize := UpgradingTaskBreakDown.Strategy.WorkerBatchSize()
+	for start := 0; start < len(workers); start += batchSize {
+		end := start + batchSize
+		if end > len(workers) {
+			end = len(workers)
 		}
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepCordonWorker
+		batches = append(batches, workers[start:end])
+	}
 
-		// we install new package deps
-		stepDownloadDeps := &schema.Step{
-			Id:   "download-new-package-deps" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "download new package deps",
-			NodeSteps: []schema.NodeStep{
-				{
-					Id:     utils.GenNodeStepID(),
-					Name:   "node step download new package deps",
-					NodeID: node.NodeId,
-					Tasks: map[int]schema.ITask{
-						0: schema.TaskCommonDownloadDep{
-							TaskType:   constants.TaskDownloadDep,
-							TimeOut:    300,
-							Dep:        UpgradingTaskBreakDown.Dep,
-							K8sVersion: UpgradingTaskBreakDown.ApplyVersion.Name,
-							SaveTo:     packageSaveDir,
-							Md5:        GenerateDepMd5(UpgradingTaskBreakDown.Dep),
-						},
-					},
-				},
-			},
+	previousGateId := UpgradingTaskBreakDown.addBatchGate(0, len(batches), UpgradingTaskBreakDown.Cluster.Masters, firstMasterNodeId, nil)
+	for batch := 1; batch < len(batches); batch++ {
+		// workers in the same batch are upgraded at the same time
+		// steps of each worker still run one after another
+		var lastStepIds []string
+		for _, node := range batches[batch] {
+			dependsOn := []string{previousGateId}
+			for _, step := range UpgradingTaskBreakDown.createWorkerUpgradeSteps(node, firstMasterNodeId, packageSaveDir) {
+				step.DependsOn = dependsOn
+				dependsOn = []string{step.Id}
+				UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = step
+			}
+			lastStepIds = append(lastStepIds, dependsOn...)
 		}
+		previousGateId = UpgradingTaskBreakDown.addBatchGate(batch, len(batches), batches[batch], firstMasterNodeId, lastStepIds)
+	}
 
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepDownloadDeps
-
-		// install new deps
-		stepInstallNewDeps := &schema.Step{
-			Id:   "install-new-package-deps" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "install new package deps",
+	for _, node := range UpgradingTaskBreakDown.Cluster.Masters {
+		// restart workers kubelet
+		stepRestartWorkerKubelet := &schema.Step{
+			Id:   "restart-worker-kubelet" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+			Name: "restart worker kubelet",
 			NodeSteps: []schema.NodeStep{
 				{
 					Id:     utils.GenNodeStepID(),
-					Name:   "node step install new package deps",
+					Name:   "restart worker kubelet",
 					NodeID: node.NodeId,
 					Tasks: map[int]schema.ITask{
 						0: schema.TaskRunCommand{
 							TaskType: constants.TaskTypeRunCommand,
-							TimeOut:  300,
+							TimeOut:  30,
 							Commands: map[int][]string{
-								0: updatePackageCommand(UpgradingTaskBreakDown.NodesCollection[node.NodeId], packageSaveDir),
+								0: {"systemctl", "daemon-reload"},
+								1: {"systemctl", "restart", "kubelet"},
 							},
 						},
 					},
@@ -223,128 +220,310 @@ func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) BreakDownTask() (schema.Op
 			},
 		}
 
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepInstallNewDeps
+		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepRestartWorkerKubelet
+	}
+	return UpgradingTaskBreakDown.Operation, nil
+}
 
-		// ask client to pre load images
-		stepPreloadImages := &schema.Step{
-			Id:   "pre-load-image" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "Pre Load Image",
-			// if image load failed locally, wo can simply ignore it and  let cri do the download job
-			OnStepTimeOutHandler:     OnTimeOutIgnoreHandler{},
-			OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{},
-			NodeSteps: []schema.NodeStep{
-				{
-					Id:     utils.GenNodeStepID(),
-					Name:   "pre load image",
-					NodeID: node.NodeId,
-					Tasks: map[int]schema.ITask{
-						0: schema.TaskPreLoadImage{
-							TaskType: constants.TaskTypePreLoadImage,
-							TimeOut:  300,
-						},
+func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) createWorkerUpgradeSteps(node schema.ClusterNode, firstMasterNodeId, packageSaveDir string) []*schema.Step {
+	var steps []*schema.Step
+	// drain workers
+	stepCordonWorker := &schema.Step{
+		Id:   "cordon-worker-" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "cordons worker",
+		NodeSteps: []schema.NodeStep{
+			createCordonNodeStep(firstMasterNodeId, UpgradingTaskBreakDown.NodesCollection[node.NodeId].SystemInfo.Node.Hostname, false, []string{"--ignore-daemonsets", "--delete-local-data", "--force"}),
+		},
+	}
+	steps = append(steps, stepCordonWorker)
+
+	// we install new package deps
+	stepDownloadDeps := &schema.Step{
+		Id:   "download-new-package-deps" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "download new package deps",
+		NodeSteps: []schema.NodeStep{
+			{
+				Id:     utils.GenNodeStepID(),
+				Name:   "node step download new package deps",
+				NodeID: node.NodeId,
+				Tasks: map[int]schema.ITask{
+					0: schema.TaskCommonDownloadDep{
+						TaskType:   constants.TaskDownloadDep,
+						TimeOut:    300,
+						Dep:        UpgradingTaskBreakDown.Dep,
+						K8sVersion: UpgradingTaskBreakDown.ApplyVersion.Name,
+						SaveTo:     packageSaveDir,
+						Md5:        GenerateDepMd5(UpgradingTaskBreakDown.Dep),
 					},
 				},
 			},
-		}
-
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepPreloadImages
+		},
+	}
 
-		// upgrade control plane to new version
-		stepKubeadmUpgradeApply := &schema.Step{
-			Id:   "kubeadm-apply-upgrade" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "kubeadm apply upgrade",
-			NodeSteps: []schema.NodeStep{
-				{
-					Id:     utils.GenNodeStepID(),
-					Name:   "node step upgrade to new k8s version",
-					NodeID: node.NodeId,
-					Tasks: map[int]schema.ITask{
-						0: schema.TaskRunCommand{
-							TaskType: constants.TaskTypeRunCommand,
-							TimeOut:  300,
-							Commands: map[int][]string{
-								0: {"kubeadm", "upgrade", "node"},
-							},
+	steps = append(steps, stepDownloadDeps)
+
+	// install new deps
+	stepInstallNewDeps := &schema.Step{
+		Id:   "install-new-package-deps" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "install new package deps",
+		NodeSteps: []schema.NodeStep{
+			{
+				Id:     utils.GenNodeStepID(),
+				Name:   "node step install new package deps",
+				NodeID: node.NodeId,
+				Tasks: map[int]schema.ITask{
+					0: schema.TaskRunCommand{
+						TaskType: constants.TaskTypeRunCommand,
+						TimeOut:  300,
+						Commands: map[int][]string{
+							0: updatePackageCommand(UpgradingTaskBreakDown.NodesCollection[node.NodeId], packageSaveDir),
 						},
 					},
 				},
 			},
-		}
-
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepKubeadmUpgradeApply
+		},
+	}
 
-		// restart workers kubelet
-		stepRestartWorkerKubelet := &schema.Step{
-			Id:   "restart-worker-kubelet" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "restart worker kubelet",
-			NodeSteps: []schema.NodeStep{
-				{
-					Id:     utils.GenNodeStepID(),
-					Name:   "restart worker kubelet",
-					NodeID: node.NodeId,
-					Tasks: map[int]schema.ITask{
-						0: schema.TaskRunCommand{
-							TaskType: constants.TaskTypeRunCommand,
-							TimeOut:  30,
-							Commands: map[int][]string{
-								0: {"systemctl", "daemon-reload"},
-								1: {"systemctl", "restart", "kubelet"},
-							},
-						},
+	steps = append(steps, stepInstallNewDeps)
+
+	// ask client to pre load images
+	stepPreloadImages := &schema.Step{
+		Id:   "pre-load-image" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "Pre Load Image",
+		// if image load failed locally, wo can simply ignore it and  let cri do the download job
+		OnStepTimeOutHandler:     OnTimeOutIgnoreHandler{},
+		OnStepDoneOrErrorHandler: OnErrorIgnoreHandler{},
+		NodeSteps: []schema.NodeStep{
+			{
+				Id:     utils.GenNodeStepID(),
+				Name:   "pre load image",
+				NodeID: node.NodeId,
+				Tasks: map[int]schema.ITask{
+					0: schema.TaskPreLoadImage{
+						TaskType: constants.TaskTypePreLoadImage,
+						TimeOut:  300,
 					},
 				},
 			},
-		}
-
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepRestartWorkerKubelet
+		},
+	}
 
-		// uncordon the workers
-		stepUnCordonMaster := &schema.Step{
-			Id:   "uncordon-master-" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "uncordon master",
-			NodeSteps: []schema.NodeStep{
-				createCordonNodeStep(firstMasterNodeId, UpgradingTaskBreakDown.NodesCollection[node.NodeId].SystemInfo.Node.Hostname, true, nil),
+	steps = append(steps, stepPreloadImages)
+
+	// upgrade control plane to new version
+	stepKubeadmUpgradeApply := &schema.Step{
+		Id:   "kubeadm-apply-upgrade" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "kubeadm apply upgrade",
+		NodeSteps: []schema.NodeStep{
+			{
+				Id:     utils.GenNodeStepID(),
+				Name:   "node step upgrade to new k8s version",
+				NodeID: node.NodeId,
+				Tasks: map[int]schema.ITask{
+					0: schema.TaskRunCommand{
+						TaskType: constants.TaskTypeRunCommand,
+						TimeOut:  300,
+						Commands: map[int][]string{
+							0: {"kubeadm", "upgrade", "node"},
+						},
+					},
+				},
 			},
-		}
-
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepUnCordonMaster
+		},
 	}
 
-	for _, node := range UpgradingTaskBreakDown.Cluster.Masters {
-		// restart workers kubelet
-		stepRestartWorkerKubelet := &schema.Step{
-			Id:   "restart-worker-kubelet" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
-			Name: "restart worker kubelet",
-			NodeSteps: []schema.NodeStep{
-				{
-					Id:     utils.GenNodeStepID(),
-					Name:   "restart worker kubelet",
-					NodeID: node.NodeId,
-					Tasks: map[int]schema.ITask{
-						0: schema.TaskRunCommand{
-							TaskType: constants.TaskTypeRunCommand,
-							TimeOut:  30,
-							Commands: map[int][]string{
-								0: {"systemctl", "daemon-reload"},
-								1: {"systemctl", "restart", "kubelet"},
-							},
+	steps = append(steps, stepKubeadmUpgradeApply)
+
+	// restart workers kubelet
+	stepRestartWorkerKubelet := &schema.Step{
+		Id:   "restart-worker-kubelet" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "restart worker kubelet",
+		NodeSteps: []schema.NodeStep{
+			{
+				Id:     utils.GenNodeStepID(),
+				Name:   "restart worker kubelet",
+				NodeID: node.NodeId,
+				Tasks: map[int]schema.ITask{
+					0: schema.TaskRunCommand{
+						TaskType: constants.TaskTypeRunCommand,
+						TimeOut:  30,
+						Commands: map[int][]string{
+							0: {"systemctl", "daemon-reload"},
+							1: {"systemctl", "restart", "kubelet"},
 						},
 					},
 				},
 			},
-		}
+		},
+	}
 
-		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = stepRestartWorkerKubelet
+	steps = append(steps, stepRestartWorkerKubelet)
+
+	// uncordon the workers
+	stepUnCordonMaster := &schema.Step{
+		Id:   "uncordon-master-" + node.NodeId + "-" + UpgradingTaskBreakDown.Operation.Id,
+		Name: "uncordon master",
+		NodeSteps: []schema.NodeStep{
+			createCordonNodeStep(firstMasterNodeId, UpgradingTaskBreakDown.NodesCollection[node.NodeId].SystemInfo.Node.Hostname, true, nil),
+		},
 	}
-	return UpgradingTaskBreakDown.Operation, nil
+
+	steps = append(steps, stepUnCordonMaster)
+	return steps
 }
 
 func updatePackageCommand(information schema.NodeInformation, packagePath string) []string {
-	if information.SystemInfo.OS.Vendor == constants.OSFamilyCentos {
+	osFamily, _ := osInfoProvider.NormalizeOSFamily(information.SystemInfo.OS.Vendor, information.SystemInfo.OS.Version)
+	if osFamily == constants.OSFamilyCentos || osFamily == constants.OSFamilyEL || osFamily == constants.OSFamilyOpenEuler {
 		return []string{"rpm", "-ivh", "--replacefiles", "--replacepkgs", "--nodeps", packagePath + "*.rpm"}
-	} else if information.SystemInfo.OS.Vendor == constants.OSFamilyUbuntu {
-		return []string{}
+	} else if osFamily == constants.OSFamilyUbuntu {
+		// dpkg does not expand wildcard by itself
+		return []string{"/bin/sh", "-c", "dpkg -i --force-overwrite --force-depends " + packagePath + "*.deb"}
 	} else {
 		return []string{}
 	}
 }
+
+func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) workersToUpgrade(masters map[string]byte) []schema.ClusterNode {
+	var workers []schema.ClusterNode
+	for _, node := range UpgradingTaskBreakDown.Cluster.Workers {
+		// skip those workers share with masters
+		if _, found := masters[node.NodeId]; found {
+			continue
+		}
+		workers = append(workers, node)
+	}
+	return workers
+}
+
+/*
+addBatchGate adds health gate of a batch and a gate step which every step of next batch depends on
+returns id of the gate step
+*/
+func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) addBatchGate(batch, batchCount int, nodes []schema.ClusterNode, firstMasterNodeId string, dependsOn []string) string {
+	operationId := UpgradingTaskBreakDown.Operation.Id
+	gateDependsOn := dependsOn
+	if !UpgradingTaskBreakDown.Strategy.DisableHealthGate {
+		healthCheckId := fmt.Sprintf("upgrade-batch-%d-health-check-%s", batch, operationId)
+		UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = &schema.Step{
+			Id:        healthCheckId,
+			Name:      fmt.Sprintf("health check of upgrade batch %d", batch),
+			DependsOn: dependsOn,
+			NodeSteps: []schema.NodeStep{
+				UpgradingTaskBreakDown.createHealthCheckNodeStep(nodes, firstMasterNodeId),
+			},
+		}
+		gateDependsOn = []string{healthCheckId}
+		for _, probe := range UpgradingTaskBreakDown.addOnsLivenessProbes() {
+			probe := probe
+			probe.Id = fmt.Sprintf("upgrade-batch-%d-%s", batch, probe.Id)
+			probe.DependsOn = []string{healthCheckId}
+			UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = &probe
+			gateDependsOn = append(gateDependsOn, probe.Id)
+		}
+	}
+
+	gateId := fmt.Sprintf("upgrade-batch-%d-done-%s", batch, operationId)
+	gate := &schema.Step{
+		Id:        gateId,
+		Name:      fmt.Sprintf("upgrade batch %d of %d done", batch, batchCount-1),
+		DependsOn: gateDependsOn,
+		NodeSteps: []schema.NodeStep{},
+	}
+	// no need to pause after the last batch
+	if UpgradingTaskBreakDown.PauseGate != nil && batch < batchCount-1 {
+		pauseGate := UpgradingTaskBreakDown.PauseGate
+		gate.PauseGate = func(operation schema.Operation) bool {
+			return pauseGate(batch)
+		}
+	}
+	UpgradingTaskBreakDown.Operation.Step[len(UpgradingTaskBreakDown.Operation.Step)] = gate
+	return gateId
+}
+
+// createHealthCheckNodeStep waits for nodes of batch and all kube-system pods become ready
+func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) createHealthCheckNodeStep(nodes []schema.ClusterNode, firstMasterNodeId string) schema.NodeStep {
+	timeout := UpgradingTaskBreakDown.Strategy.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = 300
+	}
+	waitNodes := []string{"kubectl", "wait", "--for=condition=Ready"}
+	for _, node := range nodes {
+		waitNodes = append(waitNodes, "node/"+UpgradingTaskBreakDown.NodesCollection[node.NodeId].SystemInfo.Node.Hostname)
+	}
+	waitNodes = append(waitNodes, fmt.Sprintf("--timeout=%ds", timeout))
+	// pods of finished jobs never become ready
+	waitPods := []string{"kubectl", "wait", "--for=condition=Ready", "pods", "--all", "-n", "kube-system",
+		"--field-selector=status.phase!=Succeeded", fmt.Sprintf("--timeout=%ds", timeout)}
+
+	return schema.NodeStep{
+		Id:     utils.GenNodeStepID(),
+		Name:   "wait for nodes and kube-system pods ready",
+		NodeID: firstMasterNodeId,
+		Tasks: map[int]schema.ITask{
+			0: schema.TaskRunCommand{
+				TaskType: constants.TaskTypeRunCommand,
+				TimeOut:  2*timeout + 30,
+				Commands: map[int][]string{
+					0: waitNodes,
+					1: waitPods,
+				},
+			},
+		},
+	}
+}
+
+/*
+addOnsLivenessProbes collects liveness probe of every enabled addon which actually checks something
+probes are part of health gate so their failure stops the upgrade
+*/
+func (UpgradingTaskBreakDown *UpgradingTaskBreakDown) addOnsLivenessProbes() []schema.Step {
+	cluster := UpgradingTaskBreakDown.Cluster
+	label := UpgradingTaskBreakDown.SumLicenseLabel
+	firstMasterId := cluster.Masters[0].NodeId
+	register := &AddOnsRegister{}
+	register.RegisterUp(AddOnClusterLB{
+		Name: "ClusterLBAddOn",
+	}.SetDataWithPlugin(cluster.ClusterLB, label, cluster))
+	register.RegisterUp(AddOnPostgresOperator{
+		Name: "PostgresOperatorAddon",
+	}.SetDataWithPlugin(cluster.PostgresOperator, label, cluster))
+	register.RegisterUp(AddOnMiddlePlatform{
+		Name: "MiddlePlatformAddOn",
+	}.SetDataWithPlugin(cluster.MiddlePlatform, label, cluster))
+	register.RegisterUp(AddOnConsole{
+		Name: "ConsoleAddOn",
+	}.SetDataWithPlugin(cluster.Console, label, cluster))
+	register.RegisterUp(AddOnEFK{
+		Name:          "EFKAddOn",
+		FirstMasterId: firstMasterId,
+	}.SetDataWithPlugin(cluster.EFK, label, cluster))
+	register.RegisterUp(AddOnGAP{
+		Name:          "GAPAddOn",
+		FirstMasterId: firstMasterId,
+	}.SetDataWithPlugin(cluster.GAP, label, cluster))
+
+	var probes []schema.Step
+	for _, addon := range register.AddOns {
+		if !addon.IsEnable() {
+			continue
+		}
+		probe, err := addon.LivenessProbe(UpgradingTaskBreakDown.Operation.Id, cluster, UpgradingTaskBreakDown.Config)
+		if err != nil {
+			log.Warnf("(ignore) Failed to setup add-on %s's liveness probe for upgrade health gate due to error: %s", addon.GetAddOnName(), err.Error())
+			continue
+		}
+		if len(probe.NodeSteps) == 0 && probe.DynamicNodeSteps == nil {
+			continue
+		}
+		if handler, ok := probe.OnStepDoneOrErrorHandler.(OnErrorIgnoreHandler); ok {
+			probe.OnStepDoneOrErrorHandler = OnErrorAbortHandler{
+				OnStepReturnCustomerHandler: handler.OnStepReturnCustomerHandler,
+				OnAllDoneCustomerHandler:    handler.OnAllDoneCustomerHandler,
+			}
+		}
+		probe.OnStepTimeOutHandler = nil
+		probes = append(probes, probe)
+	}
+	return probes
+}
@@ -0,0 +1,65 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// vectors of rfc 6238 appendix b truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := Code(secret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != expected {
+			t.Fatalf("Expected code %s at %d, got %s", expected, unix, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1600000000, 0)
+	previous, _ := Code(secret, Step(now)-1)
+	step, ok := Validate(secret, previous, now, 0)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("Expected code of previous step to be accepted")
+	}
+	if _, ok := Validate(secret, previous, now, step); ok {
+		t.Fatalf("Expected replayed code to be refused")
+	}
+	old, _ := Code(secret, Step(now)-2)
+	if _, ok := Validate(secret, old, now, 0); ok {
+		t.Fatalf("Expected code out of skew to be refused")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("k8s-installer", "alice", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/k8s-installer:alice?") || !strings.Contains(uri, "secret=ABC") {
+		t.Fatalf("Unexpected provisioning uri %s", uri)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(2)
+	if err != nil || len(codes) != 2 {
+		t.Fatalf("Failed to generate recovery codes %v %v", codes, err)
+	}
+	if NormalizeRecoveryCode(strings.ToUpper(strings.Replace(codes[0], "-", "", 1))) != codes[0] {
+		t.Fatalf("Expected recovery code %s after normalize", codes[0])
+	}
+}
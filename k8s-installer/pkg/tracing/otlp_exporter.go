@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+/*
+otlpExporter posts spans to collector with otlp over http in json encoding
+the official otlp exporters pull in a grpc version etcd client does not work with, json over http needs none of it
+*/
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+var _ sdkTrace.SpanExporter = &otlpExporter{}
+
+func newOTLPExporter(endpoint string, timeout time.Duration) *otlpExporter {
+	return &otlpExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdkTrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(toOTLP(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain body so connection is able to be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("collector %s responds with status %d", e.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId           string         `json:"traceId"`
+	SpanId            string         `json:"spanId"`
+	ParentSpanId      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func toOTLP(spans []sdkTrace.ReadOnlySpan) otlpTraces {
+	// every span of a provider shares one resource so only library needs grouping
+	var scopes []otlpScopeSpans
+	scopeIndex := map[string]int{}
+	for _, span := range spans {
+		library := span.InstrumentationLibrary()
+		key := library.Name + "@" + library.Version
+		index, found := scopeIndex[key]
+		if !found {
+			index = len(scopes)
+			scopeIndex[key] = index
+			scopes = append(scopes, otlpScopeSpans{Scope: otlpScope{Name: library.Name, Version: library.Version}})
+		}
+		scopes[index].Spans = append(scopes[index].Spans, toOTLPSpan(span))
+	}
+	var resourceAttributes []otlpKeyValue
+	if resource := spans[0].Resource(); resource != nil {
+		resourceAttributes = toOTLPAttributes(resource.Attributes())
+	}
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: resourceAttributes},
+		ScopeSpans: scopes,
+	}}}
+}
+
+func toOTLPSpan(span sdkTrace.ReadOnlySpan) otlpSpan {
+	result := otlpSpan{
+		TraceId:           span.SpanContext().TraceID().String(),
+		SpanId:            span.SpanContext().SpanID().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: unixNano(span.StartTime()),
+		EndTimeUnixNano:   unixNano(span.EndTime()),
+		Attributes:        toOTLPAttributes(span.Attributes()),
+		Status:            otlpStatus{Message: span.Status().Description},
+	}
+	if span.Parent().IsValid() {
+		result.ParentSpanId = span.Parent().SpanID().String()
+	}
+	// otlp status code goes unset, ok, error while otel goes unset, error, ok
+	switch span.Status().Code {
+	case codes.Ok:
+		result.Status.Code = 1
+	case codes.Error:
+		result.Status.Code = 2
+	}
+	for _, event := range span.Events() {
+		result.Events = append(result.Events, otlpEvent{
+			TimeUnixNano: unixNano(event.Time),
+			Name:         event.Name,
+			Attributes:   toOTLPAttributes(event.Attributes),
+		})
+	}
+	return result
+}
+
+func toOTLPAttributes(attributes []attribute.KeyValue) []otlpKeyValue {
+	var result []otlpKeyValue
+	for _, kv := range attributes {
+		value := map[string]interface{}{}
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			value["boolValue"] = kv.Value.AsBool()
+		case attribute.INT64:
+			// 64 bits integers are strings in otlp json
+			value["intValue"] = strconv.FormatInt(kv.Value.AsInt64(), 10)
+		case attribute.FLOAT64:
+			value["doubleValue"] = kv.Value.AsFloat64()
+		default:
+			value["stringValue"] = kv.Value.Emit()
+		}
+		result = append(result, otlpKeyValue{Key: string(kv.Key), Value: value})
+	}
+	return result
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
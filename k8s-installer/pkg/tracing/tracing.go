@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	tracingConfig "k8s-installer/pkg/config/tracing"
+	"k8s-installer/pkg/log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "k8s-installer"
+
+// w3c trace context travels in message body between server and agent
+var propagator = propagation.TraceContext{}
+
+/*
+Init sets up global tracer provider which exports spans to collector in config
+without calling it or with tracing disabled every span is a no-op one
+spans not exported yet are flushed once stopChan is closed
+*/
+func Init(config tracingConfig.TracingConfig, serviceName, nodeId string, stopChan <-chan struct{}) {
+	if !config.Enabled {
+		log.Debug("Tracing is disabled")
+		return
+	}
+	exportTimeout := time.Duration(config.ExportTimeout) * time.Second
+	provider := sdkTrace.NewTracerProvider(
+		sdkTrace.WithBatcher(newOTLPExporter(config.Endpoint, exportTimeout), sdkTrace.WithExportTimeout(exportTimeout)),
+		sdkTrace.WithSampler(sdkTrace.ParentBased(sdkTrace.TraceIDRatioBased(config.SampleRatio))),
+		sdkTrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceInstanceIDKey.String(nodeId),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	log.Debugf("Tracing is enabled, spans are exported to %s", config.Endpoint)
+	go func() {
+		<-stopChan
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Warnf("Failed to flush spans due to error: %s", err.Error())
+		}
+	}()
+}
+
+/*
+Start starts a span as child of span in ctx if there is one
+*/
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+/*
+End ends span and marks it failed when err is not nil
+*/
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+/*
+Inject returns trace context of span in ctx which can be carried in message or saved along with data
+*/
+func Inject(ctx context.Context) map[string]string {
+	carrier := Carrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+/*
+Extract puts trace context got by Inject into ctx so spans started with it join the same trace
+*/
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, Carrier(traceContext))
+}
+
+/*
+Carrier keeps trace context in a plain map
+*/
+type Carrier map[string]string
+
+func (c Carrier) Get(key string) string {
+	return c[key]
+}
+
+func (c Carrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c Carrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
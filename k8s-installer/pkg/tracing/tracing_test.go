@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	provider := sdkTrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+
+	traceContext := Inject(ctx)
+	if traceContext["traceparent"] == "" {
+		t.Fatalf("expected traceparent to be injected got %v", traceContext)
+	}
+	_, child := provider.Tracer("test").Start(Extract(context.Background(), traceContext), "task")
+	defer child.End()
+	if child.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Error("expected span started with extracted context to join the same trace")
+	}
+
+	if Inject(context.Background()) != nil {
+		t.Error("expected nothing to be injected without span")
+	}
+	if Extract(context.Background(), nil) != context.Background() {
+		t.Error("expected ctx to stay untouched without trace context")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var posted otlpTraces
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &posted); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer collector.Close()
+
+	provider := sdkTrace.NewTracerProvider(sdkTrace.WithSyncer(newOTLPExporter(collector.URL+"/", time.Second)))
+	tracer := provider.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "step")
+	_, child := tracer.Start(ctx, "node step")
+	child.SetAttributes(attribute.Int("step.index", 3))
+	End(child, errors.New("timeout"))
+
+	if len(posted.ResourceSpans) != 1 || len(posted.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected one resource with one scope got %+v", posted)
+	}
+	spans := posted.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected one span got %d", len(spans))
+	}
+	span := spans[0]
+	if span.TraceId != parent.SpanContext().TraceID().String() || span.ParentSpanId != parent.SpanContext().SpanID().String() {
+		t.Errorf("expected span to be child of parent got trace %s parent %s", span.TraceId, span.ParentSpanId)
+	}
+	if span.Status.Code != 2 || span.Status.Message != "timeout" {
+		t.Errorf("expected error status got %+v", span.Status)
+	}
+	if len(span.Attributes) != 1 || span.Attributes[0].Value["intValue"] != "3" {
+		t.Errorf("expected int attribute in string got %+v", span.Attributes)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("expected error to be recorded as event got %+v", span.Events)
+	}
+	parent.End()
+}
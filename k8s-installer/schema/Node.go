@@ -24,6 +24,10 @@ type NodeInformation struct {
 	Region                  *Region          `json:"region,omitempty"  description:"node region status"`
 	PortStatus              string           `json:"port_status,omitempty" description:"do not input, port status only show in node detail api"`
 	ClusterInstaller        string           `json:"cluster_installer"`
+	CgroupVersion           int              `json:"cgroup_version" description:"cgroup version of node 1 or 2, 0 means not reported"`
+	InitSystem              string           `json:"init_system" description:"init system of node such as systemd"`
+	ResourceVersion         int64            `json:"resource_version,omitempty" description:"auto generated, etcd revision node is last saved at, saving a node changed by others since fails with conflict"`
+	SchemaVersion           int              `json:"schema_version,omitempty" description:"auto generated, schema version node is saved at"`
 }
 
 func (n *NodeInformation) DeepCopyRegion() *Region {
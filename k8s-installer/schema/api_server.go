@@ -10,12 +10,16 @@ type HttpErrorResult struct {
 }
 
 type User struct {
-	Id        string `json:"id,omitempty" description:"do not input auto generator"`
-	Username  string `json:"username" validate:"required"`
-	Password  string `json:"password" validate:"required"`
-	Roles     []Role `json:"roles,omitempty" description:"contain one or more roles"`
-	Phone     string `json:"phone" description:"user phone number"`
-	KsAccount string `json:"ks_account,omitempty" description:"kubespere user account"`
+	Id                string `json:"id,omitempty" description:"do not input auto generator"`
+	Username          string `json:"username" validate:"required"`
+	Password          string `json:"password" validate:"required" description:"input only, never returned"`
+	Roles             []Role `json:"roles,omitempty" description:"contain one or more roles"`
+	Phone             string `json:"phone" description:"user phone number"`
+	KsAccount         string `json:"ks_account,omitempty" description:"kubespere user account"`
+	PasswordChangedAt int64  `json:"password_changed_at,omitempty" description:"do not input auto generator, unix time of last password change"`
+	Source            string `json:"source,omitempty" description:"do not input auto generator, auth provider the user is created by, empty for local user"`
+	TokensValidAfter  int64  `json:"tokens_valid_after,omitempty" description:"do not input auto generator, unix time, login tokens issued earlier are invalid e.g. after roles change"`
+	SchemaVersion     int    `json:"schema_version,omitempty" description:"do not input auto generator, schema version user is saved at"`
 	jwt.StandardClaims
 }
 
@@ -23,6 +27,8 @@ type Role struct {
 	Id       string `json:"id,omitempty" description:"do not input auto generator"`
 	Name     string `json:"name" validate:"required"`
 	Function uint64 `json:"function" validate:"required" description:"function code represent capability of the role"`
+	// schema version role is saved at, roles held by users and tokens keep the one they are copied with
+	SchemaVersion int `json:"schema_version,omitempty" description:"do not input auto generator"`
 }
 
 type CaaSOauthRequest struct {
@@ -78,8 +84,14 @@ type CaaSRules struct {
 }
 
 type SystemAuthType struct {
-	Standard bool `json:"standard" description:"local auth"`
-	CaaS     bool `json:"caas" description:"caas redirect auth"`
+	Standard  bool               `json:"standard" description:"local auth"`
+	CaaS      bool               `json:"caas" description:"caas redirect auth"`
+	Providers []AuthProviderInfo `json:"providers" description:"external auth providers, send name as X-Auth-Type header to login with ldap provider, oidc provider logs in through /oidc/{name}/login"`
+}
+
+type AuthProviderInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type" description:"ldap or oidc"`
 }
 
 type SendMesssageResp struct {
@@ -102,9 +114,169 @@ type ValidateDataObject struct {
 	Result bool `json:"result"`
 }
 
+type LoginRequest struct {
+	Username     string `json:"username" validate:"required"`
+	Password     string `json:"password" validate:"required"`
+	OTPCode      string `json:"otp_code,omitempty" description:"totp code of authenticator, required when user has mfa enabled"`
+	RecoveryCode string `json:"recovery_code,omitempty" description:"one time recovery code used instead of otp code when authenticator is lost"`
+}
+
+/*
+UserMFA is totp enrollment of one user, it is pending until user proves the authenticator works with a valid code
+*/
+type UserMFA struct {
+	Username      string   `json:"username"`
+	Secret        string   `json:"secret"`
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"recovery_codes" description:"bcrypt hashes of unused recovery codes"`
+	LastUsedStep  int64    `json:"last_used_step" description:"totp step last accepted, codes of same or older step are refused"`
+	EnabledAt     int64    `json:"enabled_at,omitempty" description:"unix time"`
+	SchemaVersion int      `json:"schema_version,omitempty" description:"schema version enrollment is saved at"`
+}
+
+type MFAEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioning_uri" description:"otpauth uri, render it as qr code for authenticator app"`
+	RecoveryCodes   []string `json:"recovery_codes" description:"shown only once, each code can login once instead of otp code"`
+}
+
+/*
+APIToken is a long lived credential for automation acting as owner with at most roles of the token
+effective permission is what both token roles and current roles of owner grant
+*/
+type APIToken struct {
+	Id         string `json:"id" description:"do not input auto generator"`
+	Name       string `json:"name" validate:"required"`
+	Owner      string `json:"owner,omitempty" description:"username or service account the token acts as, default to current user"`
+	Roles      []Role `json:"roles,omitempty" description:"roles of the token, each must be held by owner, default to all roles of owner"`
+	ExpiresAt  int64  `json:"expires_at,omitempty" description:"unix time, 0 meaning never expires"`
+	CreatedAt  int64  `json:"created_at,omitempty" description:"do not input auto generator, unix time"`
+	CreatedBy  string `json:"created_by,omitempty" description:"do not input auto generator"`
+	LastUsedAt int64  `json:"last_used_at,omitempty" description:"do not input auto generator, unix time"`
+	LastUsedIP string `json:"last_used_ip,omitempty" description:"do not input auto generator"`
+	RevokedAt  int64  `json:"revoked_at,omitempty" description:"do not input auto generator, unix time"`
+	Hash       string `json:"hash,omitempty" description:"never returned"`
+	// schema version token is saved at
+	SchemaVersion int `json:"schema_version,omitempty" description:"do not input auto generator"`
+}
+
+type APITokenCreated struct {
+	APIToken
+	Token string `json:"token" description:"send it as token header, shown only once"`
+}
+
+type LoginResponse struct {
+	Token              string `json:"token" description:"access token, send it as token header"`
+	ExpiredDate        string `json:"expired_date"`
+	RefreshToken       string `json:"refresh_token" description:"exchange it for new tokens before access token expires, it can be used only once"`
+	RefreshExpiredDate string `json:"refresh_expired_date"`
+	Permission         uint64 `json:"permission"`
+	User               User   `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+/*
+Session is one login, access tokens carry it`s id so revoking the session revokes them on every server
+refresh token rotates on every refresh, presenting a replaced one means it leaked so the session is revoked
+*/
+type Session struct {
+	Id                  string `json:"id"`
+	Username            string `json:"username"`
+	RefreshHash         string `json:"refresh_hash"`
+	PreviousRefreshHash string `json:"previous_refresh_hash,omitempty"`
+	CreatedAt           int64  `json:"created_at"`
+	RefreshedAt         int64  `json:"refreshed_at,omitempty"`
+	ExpiresAt           int64  `json:"expires_at" description:"unix time refresh token expires"`
+	RevokedAt           int64  `json:"revoked_at,omitempty"`
+	// schema version session is saved at
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+/*
+RoleBinding grants functions of a role to user on the clusters it`s scope selects only
+role listed in user roles stays global, binding never widens it
+*/
+type RoleBinding struct {
+	Id        string           `json:"id,omitempty" description:"do not input auto generator"`
+	Username  string           `json:"username" validate:"required"`
+	RoleId    string           `json:"role_id" validate:"required"`
+	Scope     RoleBindingScope `json:"scope"`
+	CreatedAt int64            `json:"created_at,omitempty" description:"do not input auto generator"`
+	CreatedBy string           `json:"created_by,omitempty" description:"do not input auto generator"`
+	// schema version binding is saved at
+	SchemaVersion int `json:"schema_version,omitempty" description:"do not input auto generator"`
+}
+
+// RoleBindingScope selects a cluster when any of it`s fields matches, labels have to match all together
+type RoleBindingScope struct {
+	Clusters      []string          `json:"clusters,omitempty" description:"cluster ids"`
+	Regions       []string          `json:"regions,omitempty" description:"region ids, nodes are selected by their own region as well"`
+	ClusterLabels map[string]string `json:"cluster_labels,omitempty" description:"selects clusters holding all of these labels"`
+}
+
+func (scope RoleBindingScope) IsEmpty() bool {
+	return len(scope.Clusters) == 0 && len(scope.Regions) == 0 && len(scope.ClusterLabels) == 0
+}
+
 type ValidateMesssageCode struct {
 	UserName   string `json:"username" validate:"required"`
 	RandomCode string `json:"randomcode" validate:"required"`
 	Type       string `json:"type" validate:"required"`
 	Phone      string `json:"phone"`
 }
+
+/*
+LoginAttempt records failures of one user, source ip or sms receiver
+every failure pushes back when next attempt is accepted, too many failures within the window locks it for a while
+*/
+type LoginAttempt struct {
+	Key           string `json:"key" description:"user:{username}, ip:{source ip} or sms:{phone}"`
+	Failures      int    `json:"failures" description:"failures within current window"`
+	FirstFailure  int64  `json:"first_failure" description:"unix time of first failure within current window"`
+	NextAttemptAt int64  `json:"next_attempt_at" description:"unix time before which attempts are refused"`
+	LockedUntil   int64  `json:"locked_until,omitempty" description:"unix time the lockout ends"`
+}
+
+/*
+AuditEvent is one link of the audit chain
+hash covers the event together with hash of the previous one, so editing or removing any event breaks every later link
+*/
+type AuditEvent struct {
+	Sequence uint64 `json:"sequence" description:"auto generated, position in the chain"`
+	Id       string `json:"id" description:"auto generated, sortable by sequence"`
+	Time     int64  `json:"time" description:"unix time"`
+	Action   string `json:"action" description:"e.g. login-lockout, api-request or remote-command"`
+	Username string `json:"username,omitempty" description:"user the event is about"`
+	Operator string `json:"operator,omitempty" description:"user who triggered the event, empty for system or unauthenticated request"`
+	// how operator is authenticated e.g. session:{id} or api-token:{id}
+	Credential string            `json:"credential,omitempty"`
+	SourceIP   string            `json:"source_ip,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Route      string            `json:"route,omitempty" description:"route template e.g. /api/core/v1/clusters/{cluster-id}"`
+	Path       string            `json:"path,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty" description:"path and query parameters, secrets redacted"`
+	Body       string            `json:"body,omitempty" description:"json request body, secrets redacted"`
+	Target     string            `json:"target,omitempty" description:"node remote command or ssh session is sent to"`
+	Status     int               `json:"status,omitempty" description:"http status of request"`
+	Result     string            `json:"result,omitempty" description:"success or error message"`
+	DurationMs int64             `json:"duration_ms,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	PrevHash   string            `json:"prev_hash" description:"hash of previous event, empty for the first one"`
+	Hash       string            `json:"hash"`
+}
+
+// AuditChainHead is the last event of the audit chain, appending compares and swaps it
+type AuditChainHead struct {
+	Sequence uint64 `json:"sequence"`
+	Hash     string `json:"hash"`
+}
+
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Count    int    `json:"count" description:"events checked"`
+	BrokenAt uint64 `json:"broken_at,omitempty" description:"sequence of the first event failing verification"`
+	Message  string `json:"message,omitempty"`
+}
@@ -65,6 +65,7 @@ type Cluster struct {
 	AdditionalVersionDep dep.DepMap                     `json:"additional_version_dep,omitempty"`
 	ClusterAdminToken    string                         `json:"cluster_admin_token,omitempty" description:"cluster admin sa token,api input will be ignored"`
 	Region               string                         `json:"region,omitempty" description:"region id"`
+	Labels               map[string]string              `json:"labels,omitempty" description:"cluster labels, role binding is able to select clusters by them"`
 	AddonsProxyIp        string                         `json:"addons_proxy_ip,omitempty" description:"addons float ip"`
 	ClusterRole          string                         `json:"cluster_role" description:"cluster role: host、member"`
 	KsInstaller          kubesphere.DeployKsInstaller   `json:"-"`
@@ -79,6 +80,9 @@ type Cluster struct {
 	ClusterInstaller     string                         `json:"cluster_installer" description:"kubeadm or rancher, if value input not one of kubeadm or rancher then value will set to kubeadm"`
 	Mock                 bool                           `json:"mock,omitempty" description:"mock means only during cluster install or destroy setup only change data in db and do not actually install or destroy cluster"`
 	Rancher              RancherRequest                 `json:"rancher,omitempty" description:"parameters required to operate rancher"`
+	RollbackOnFailure    bool                           `json:"rollback_on_failure,omitempty" description:"undo what is done and release all nodes when cluster installation fails"`
+	ResourceVersion      int64                          `json:"resource_version,omitempty" description:"auto generated, etcd revision cluster is last saved at, saving a cluster changed by others since fails with conflict"`
+	SchemaVersion        int                            `json:"schema_version,omitempty" description:"auto generated, schema version cluster is saved at, clusters saved by older servers get migrated up from it"`
 }
 
 type ClusterApi struct {
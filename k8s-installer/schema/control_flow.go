@@ -1,8 +1,12 @@
 package schema
 
 import (
+	"strings"
+	"time"
+
 	"k8s-installer/pkg/config/client"
 	"k8s-installer/pkg/config/server"
+	"k8s-installer/pkg/constants"
 	"k8s-installer/pkg/coredns"
 )
 
@@ -15,6 +19,10 @@ type ClientRuntimeConfigCollection map[string]client.Config
 type ClusterNodeRelationShipCollection map[string]map[string]byte
 type UserCollection map[string]User
 type RoleCollection map[string]Role
+type LoginAttemptCollection map[string]LoginAttempt
+type APITokenCollection map[string]APIToken
+type RoleBindingCollection map[string]RoleBinding
+type SessionCollection map[string]Session
 type UpgradeVersionCollection map[string]UpgradableVersion
 type UpgradePlanCollection map[string]UpgradePlan
 type RegionCollection map[string]Region
@@ -29,7 +37,7 @@ type Operation struct {
 	Id                string            `json:"operation_id"`
 	Name              string            `json:"operation_name"`
 	Status            string            `json:"status"`
-	CurrentStep       int               `json:"current_step"`
+	CurrentStep       int               `json:"current_step"` // Deprecated, will be remove in future. Use CompletedSteps instead
 	OperationLog      string            `json:"operation_log"`
 	Step              map[int]*Step     `json:"-"`
 	Logs              []string          `json:"logs"`
@@ -42,6 +50,17 @@ type Operation struct {
 	RunByUser         string            `json:"run_by_user"`
 	// host stands for which bastion handle this operation
 	Host string `json:"host"`
+	// completed steps records index of every step which is already done
+	// it is how a partially finished operation get resumed
+	CompletedSteps map[int]byte `json:"completed_steps"`
+	// run rollback node steps of every started step when operation fails
+	RollbackOnFailure bool `json:"rollback_on_failure"`
+	// trace context of the operation so a resumed one stays in the same trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// etcd revision operation is last saved at, saving an operation changed by others since fails with conflict
+	ResourceVersion int64 `json:"resource_version,omitempty"`
+	// schema version operation is saved at, operations saved by older servers get migrated up from it
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
 
 type RequestParameter struct {
@@ -79,6 +98,18 @@ type Step struct {
 	// this is how we create a node steps base on previously step`s return data
 	DynamicNodeSteps               func(returnData map[string]string, cluster Cluster, operation Operation) ([]NodeStep, error) `json:"-"`
 	IgnoreDynamicStepCreationError bool                                                                                         `json:"-"`
+	// DependsOn holds ids of steps which have to be done before this step can run
+	// nil meaning step depends on the one right before it in Operation.Step which keeps the old sequential behavior
+	// set it to an empty slice to let step run as soon as operation starts
+	DependsOn []string `json:"-"`
+	// RollbackNodeSteps undo what node steps of this step did
+	// they only run when operation fails with rollback on failure set
+	RollbackNodeSteps []NodeStep `json:"-"`
+	// RetryPolicy applies to every node step of this step unless node step has its own
+	RetryPolicy *RetryPolicy `json:"-"`
+	// PauseGate is asked once step is done, returning true stops operation with paused status
+	// steps after it run when operation is continued
+	PauseGate func(operation Operation) bool `json:"-"`
 }
 
 type NodeStep struct {
@@ -87,4 +118,117 @@ type NodeStep struct {
 	NodeID           string        `json:"node_step_node_id"`
 	ServerMSGTimeOut int           `json:"-"`
 	Tasks            map[int]ITask `json:"-"`
+	RetryPolicy      *RetryPolicy  `json:"-"`
+}
+
+/*
+RetryPolicy tells control manager to send a failed node step again before leaving the decision to step handlers
+*/
+
+type RetryPolicy struct {
+	// attempts including the first one, 0 or 1 meaning never retry
+	MaxAttempts int `json:"max_attempts"`
+	// seconds to wait before the second attempt, doubled for every following attempt
+	InitialBackoff int `json:"initial_backoff"`
+	// upper limit of seconds to wait between two attempts, 0 meaning no limit
+	MaxBackoff int `json:"max_backoff"`
+	// kinds of failure which are retryable, see constants.RetryOnTimeout and constants.RetryOnError
+	RetryOn []string `json:"retry_on"`
+	// only retry error reply whose message contains one of them, empty meaning any error reply is retryable
+	RetryableMessages []string `json:"retryable_messages,omitempty"`
+}
+
+// IsRetryable tells whether node step which has already failed failedAttempts times should be sent again
+func (policy *RetryPolicy) IsRetryable(failedAttempts int, failure, message string) bool {
+	if policy == nil || failedAttempts >= policy.MaxAttempts {
+		return false
+	}
+	retryable := false
+	for _, retryOn := range policy.RetryOn {
+		if retryOn == failure {
+			retryable = true
+			break
+		}
+	}
+	if !retryable || failure != constants.RetryOnError || len(policy.RetryableMessages) == 0 {
+		return retryable
+	}
+	for _, retryableMessage := range policy.RetryableMessages {
+		if strings.Contains(message, retryableMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns how long to wait before sending node step which has already failed failedAttempts times
+func (policy *RetryPolicy) Backoff(failedAttempts int) time.Duration {
+	backoff := time.Duration(policy.InitialBackoff) * time.Second
+	maxBackoff := time.Duration(policy.MaxBackoff) * time.Second
+	for attempt := 1; attempt < failedAttempts; attempt++ {
+		backoff *= 2
+		if maxBackoff > 0 && backoff >= maxBackoff {
+			break
+		}
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+/*
+OperationPlan is the fully rendered breakdown of an operation
+it is what the operation would do without persisting it or sending any message to agents
+*/
+
+type OperationPlan struct {
+	OperationName string     `json:"operation_name"`
+	OperationType string     `json:"operation_type"`
+	ClusterId     string     `json:"cluster_id"`
+	Steps         []StepPlan `json:"steps"`
+}
+
+type StepPlan struct {
+	Index          int    `json:"index"`
+	Id             string `json:"step_id"`
+	Name           string `json:"step_name"`
+	DependsOnSteps []int  `json:"depends_on_steps"`
+	WaitBeforeRun  int    `json:"wait_before_run"`
+	// dynamic node steps are created with return data of previously steps so they are unknown until operation runs
+	HasDynamicNodeSteps bool           `json:"has_dynamic_node_steps"`
+	NodeSteps           []NodeStepPlan `json:"node_steps"`
+	RollbackNodeSteps   []NodeStepPlan `json:"rollback_node_steps,omitempty"`
+	RetryPolicy         *RetryPolicy   `json:"retry_policy,omitempty"`
+	// operation may pause once this step is done
+	PauseGate bool `json:"pause_gate,omitempty"`
+}
+
+type NodeStepPlan struct {
+	Id            string            `json:"node_step_id"`
+	Name          string            `json:"node_step_name"`
+	NodeId        string            `json:"node_id"`
+	NodeIp        string            `json:"node_ip"`
+	TaskType      string            `json:"task_type"`
+	TimeOut       int               `json:"time_out"`
+	Task          ITask             `json:"task"`
+	RenderedFiles map[string]string `json:"rendered_files,omitempty"`
+}
+
+/*
+OperationEvent is a single line of live operation progress
+Seq is increased by one for each event of the same operation so client can resume from it
+*/
+
+type OperationEvent struct {
+	Seq         uint64      `json:"seq"`
+	OperationId string      `json:"operation_id"`
+	Type        string      `json:"type"`
+	Time        string      `json:"time"`
+	StepIndex   int         `json:"step_index"` // -1 meaning not related to any step
+	StepName    string      `json:"step_name,omitempty"`
+	Level       string      `json:"level,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	Status      string      `json:"status,omitempty"`
+	Reply       *QueueReply `json:"reply,omitempty"`
 }
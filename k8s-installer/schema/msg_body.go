@@ -15,6 +15,8 @@ type QueueBody struct {
 	Task              ITask             `json:"-"`
 	ResourceServerURL string            `json:"resource_server_url"`
 	StepReturnData    map[string]string `json:"step_return_data"`
+	// trace context of the node step, agent spans join the trace with it
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
 
 type QueueReply struct {
@@ -159,6 +161,26 @@ func (t TaskSetHosts) GetTaskTimeOut() int {
 	return t.TimeOut
 }
 
+// TaskAbortNodeStep asks client to abort node steps of an operation which are still running
+type TaskAbortNodeStep struct {
+	TaskType    string   `json:"task_type"`
+	Action      string   `json:"action"`
+	TimeOut     int      `json:"time_out"`
+	NodeStepIds []string `json:"node_step_ids"`
+}
+
+func (t TaskAbortNodeStep) GetAction() string {
+	return t.Action
+}
+
+func (t TaskAbortNodeStep) GetTaskType() string {
+	return t.TaskType
+}
+
+func (t TaskAbortNodeStep) GetTaskTimeOut() int {
+	return t.TimeOut
+}
+
 type TaskKubectl struct {
 	TaskType        string            `json:"task_type"`
 	Action          string            `json:"action"`
@@ -7,5 +7,6 @@ type Region struct {
 	Name              string       `json:"name" validate:"required,hostname_rfc1123" description:"region name, format with hostname rfc1123"`
 	CreationTimestamp metav1.Time  `json:"creationTimestamp,omitempty" optional:"true" description:"creation ts, auto generated"`
 	UpdateTimestamp   *metav1.Time `json:"updateTimestamp,omitempty" optional:"true" description:"update ts, auto generated"`
+	SchemaVersion     int          `json:"schema_version,omitempty" optional:"true" description:"schema version region is saved at, auto generated"`
 	// TODO: add some extra field
 }
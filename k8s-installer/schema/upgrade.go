@@ -16,6 +16,54 @@ type UpgradePlan struct {
 	OperationId       string                  `json:"operation_id"`
 	Operations        []string                `json:"operations"`
 	ApplyDate         string                  `json:"apply_date"`
+	// how workers are upgraded batch by batch
+	Strategy UpgradeStrategy `json:"strategy"`
+	// batches which passed health gate, control plane counts as the first batch
+	CompletedBatches int `json:"completed_batches"`
+	// pause upgrade once the running batch passed health gate
+	PauseRequested bool `json:"pause_requested"`
+	// schema version plan is saved at
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+/*
+UpgradeStrategy controls rolling upgrade of workers
+masters are always upgraded one by one as the first batch
+*/
+
+type UpgradeStrategy struct {
+	// workers upgraded at the same time, 0 meaning 1
+	BatchSize int `json:"batch_size"`
+	// workers allowed to be unavailable at the same time, caps batch size when set
+	MaxUnavailable int `json:"max_unavailable"`
+	// seconds to wait for nodes and kube-system pods become ready after each batch
+	HealthCheckTimeout int `json:"health_check_timeout"`
+	// skip health gate between batches
+	DisableHealthGate bool `json:"disable_health_gate"`
+	// pause upgrade after these batches, 0 stands for control plane
+	PauseAfterBatches []int `json:"pause_after_batches,omitempty"`
+}
+
+// WorkerBatchSize returns how many workers are upgraded in one batch
+func (strategy UpgradeStrategy) WorkerBatchSize() int {
+	size := strategy.BatchSize
+	if strategy.MaxUnavailable > 0 && (size <= 0 || size > strategy.MaxUnavailable) {
+		size = strategy.MaxUnavailable
+	}
+	if size <= 0 {
+		size = 1
+	}
+	return size
+}
+
+// ShouldPauseAfter tells whether upgrade is configured to pause once batch is done
+func (strategy UpgradeStrategy) ShouldPauseAfter(batch int) bool {
+	for _, pauseAfter := range strategy.PauseAfterBatches {
+		if pauseAfter == batch {
+			return true
+		}
+	}
+	return false
 }
 
 type UpgradeNodeCheckState struct {
@@ -60,20 +108,42 @@ func (UpgradableVersion UpgradableVersion) ToDep() dep.DepMap {
 	}
 	if UpgradableVersion.Ubuntu != nil {
 		if len(UpgradableVersion.Ubuntu.OSVersions) > 0 {
-			initDeps(deps, "ubuntu", UpgradableVersion.Name)
-			for _, packageSets := range UpgradableVersion.Centos.OSVersions {
-				for _, packageName := range packageSets.X8664.RPMS {
-					deps["ubuntu"][UpgradableVersion.Name]["x86_64"][packageName.Name] = packageName.Name
+			// debs differ between ubuntu versions so each version keeps it`s own packages
+			for osVersion, packageSets := range UpgradableVersion.Ubuntu.OSVersions {
+				osKey := dep.OSKey("ubuntu", osVersion)
+				initDeps(deps, osKey, UpgradableVersion.Name)
+				for _, packageName := range packageSets.X8664.Debs {
+					deps[osKey][UpgradableVersion.Name]["x86_64"][packageName.Name] = packageName.Name
 				}
-				for _, packageName := range packageSets.Aarch64.RPMS {
-					deps["ubuntu"][UpgradableVersion.Name]["aarch64"][packageName.Name] = packageName.Name
+				for _, packageName := range packageSets.Aarch64.Debs {
+					deps[osKey][UpgradableVersion.Name]["aarch64"][packageName.Name] = packageName.Name
 				}
 			}
 		}
 	}
+	if UpgradableVersion.EL != nil {
+		rpmDepsByOSVersion(deps, "el", UpgradableVersion.Name, UpgradableVersion.EL.OSVersions)
+	}
+	if UpgradableVersion.OpenEuler != nil {
+		rpmDepsByOSVersion(deps, "openEuler", UpgradableVersion.Name, UpgradableVersion.OpenEuler.OSVersions)
+	}
 	return deps
 }
 
+// rpms differ between el and openEuler versions so each version keeps it`s own packages like ubuntu does
+func rpmDepsByOSVersion(deps dep.DepMap, osFamily, upgradeVer string, osVersions map[string]OSFamilyCentosVersion) {
+	for osVersion, packageSets := range osVersions {
+		osKey := dep.OSKey(osFamily, osVersion)
+		initDeps(deps, osKey, upgradeVer)
+		for _, packageName := range packageSets.X8664.RPMS {
+			deps[osKey][upgradeVer]["x86_64"][packageName.Name] = packageName.Name
+		}
+		for _, packageName := range packageSets.Aarch64.RPMS {
+			deps[osKey][upgradeVer]["aarch64"][packageName.Name] = packageName.Name
+		}
+	}
+}
+
 func initDeps(deps dep.DepMap, osFamily, upgradeVer string) {
 	deps[osFamily] = dep.DepVersion{}
 	deps[osFamily][upgradeVer] = dep.DepArch{}
@@ -91,6 +161,9 @@ type UpgradableVersion struct {
 	LastModifiedBy string          `json:"last_modified_by"`
 	Centos         *OSFamilyCentos `json:"centos,omitempty"`
 	Ubuntu         *OSFamilyUbuntu `json:"ubuntu,omitempty"`
+	// rhel 8/9, rocky and almalinux
+	EL        *OSFamilyEL        `json:"el,omitempty"`
+	OpenEuler *OSFamilyOpenEuler `json:"openeuler,omitempty"`
 }
 
 type OSFamilyCentos struct {
@@ -101,6 +174,15 @@ type OSFamilyUbuntu struct {
 	OSVersions map[string]OSFamilyUbuntuVersion `json:"os_versions,omitempty" validate:"required"`
 }
 
+// el and openEuler are rpm based so package sets are laid out the same way as centos
+type OSFamilyEL struct {
+	OSVersions map[string]OSFamilyCentosVersion `json:"os_versions,omitempty" validate:"required"`
+}
+
+type OSFamilyOpenEuler struct {
+	OSVersions map[string]OSFamilyCentosVersion `json:"os_versions,omitempty" validate:"required"`
+}
+
 type OSFamilyCentosVersion struct {
 	X8664   OSFamilyCentosCpuArchX8664   `json:"x86_64"`
 	Aarch64 OSFamilyCentosCpuArchAarch64 `json:"aarch_64"`